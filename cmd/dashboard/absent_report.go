@@ -95,7 +95,6 @@ func (a *AbsentMonitoredComponentReportChecker) checkForAbsentReports() {
 				continue
 			}
 
-			threshold := 5 * frequency
 			lastPingTime, err := a.pingRepo.GetLastPingTime(component.Slug, subComponent.Slug)
 			if err != nil {
 				componentLogger.WithField("error", err).Error("Failed to get last ping time")
@@ -103,20 +102,7 @@ func (a *AbsentMonitoredComponentReportChecker) checkForAbsentReports() {
 			}
 
 			now := time.Now()
-			var componentInOutage bool
-			var reason string
-
-			if lastPingTime == nil {
-				// No ping record exists - this is an absent report
-				componentInOutage = true
-				reason = "No report from component-monitor found"
-			} else {
-				timeSinceLastPing := now.Sub(*lastPingTime)
-				if timeSinceLastPing > threshold {
-					componentInOutage = true
-					reason = "Last report from component-monitor was " + timeSinceLastPing.Round(time.Second).String() + " ago, exceeding threshold of " + threshold.Round(time.Second).String()
-				}
-			}
+			reason, componentInOutage := absentReportReason(lastPingTime, frequency, now)
 
 			activeOutages, err := a.outageManager.GetActiveOutagesDiscoveredFrom(component.Slug, subComponent.Slug, AbsentReportSource)
 			if err != nil {
@@ -182,3 +168,17 @@ func (a *AbsentMonitoredComponentReportChecker) checkForAbsentReports() {
 		}
 	}
 }
+
+// absentReportReason reports whether a sub-component monitored every frequency has gone more than 5x its
+// frequency without a report at now, and describes why.
+func absentReportReason(lastPingTime *time.Time, frequency time.Duration, now time.Time) (string, bool) {
+	if lastPingTime == nil {
+		return "No report from component-monitor found", true
+	}
+	threshold := 5 * frequency
+	timeSinceLastPing := now.Sub(*lastPingTime)
+	if timeSinceLastPing > threshold {
+		return "Last report from component-monitor was " + timeSinceLastPing.Round(time.Second).String() + " ago, exceeding threshold of " + threshold.Round(time.Second).String(), true
+	}
+	return "", false
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"ship-status-dash/pkg/types"
+	"ship-status-dash/pkg/utils"
+)
+
+const (
+	defaultSecurityEventLimit = 100
+	maxSecurityEventLimit     = 1000
+)
+
+// GetGroupMembershipJSON returns the cached members of every configured rover_group with their refresh state,
+// so admins can see why a user does or does not have access.
+func (h *Handlers) GetGroupMembershipJSON(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.groupCache.GroupStatuses())
+}
+
+// GetSecurityEventsJSON returns security events, newest first, filtered by the type, user, delegator,
+// componentName, start, end and limit query parameters.
+func (h *Handlers) GetSecurityEventsJSON(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := types.SecurityEventFilter{
+		Type:          types.SecurityEventType(q.Get("type")),
+		User:          q.Get("user"),
+		Delegator:     q.Get("delegator"),
+		ComponentSlug: q.Get("componentName"),
+		Limit:         defaultSecurityEventLimit,
+	}
+
+	if filter.Type != "" && !slices.Contains(types.SecurityEventTypes, filter.Type) {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("type must be one of: %s, %s, %s, %s", types.SecurityEventAuthFailure, types.SecurityEventDelegation, types.SecurityEventAccessDenied, types.SecurityEventAdminAction))
+		return
+	}
+	// Components are not checked against the config, since events for removed components remain in the log.
+
+	if startStr := q.Get("start"); startStr != "" {
+		start, err := utils.ParseRFC3339OrNanoUTC(startStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid start time")
+			return
+		}
+		filter.Start = start
+	}
+	if endStr := q.Get("end"); endStr != "" {
+		end, err := utils.ParseRFC3339OrNanoUTC(endStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid end time")
+			return
+		}
+		filter.End = end
+	}
+	if !filter.Start.IsZero() && !filter.End.IsZero() && filter.Start.After(filter.End) {
+		respondWithError(w, http.StatusBadRequest, "start must not be after end")
+		return
+	}
+
+	if limitStr := q.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		if limit > maxSecurityEventLimit {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must not exceed %d", maxSecurityEventLimit))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	events, err := h.securityEventRepo.ListEvents(filter)
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"type":  filter.Type,
+			"user":  filter.User,
+			"error": err,
+		}).Error("Failed to query security events from database")
+		respondWithError(w, http.StatusInternalServerError, "Failed to get security events")
+		return
+	}
+	if events == nil {
+		events = []types.SecurityEvent{}
+	}
+	respondWithJSON(w, http.StatusOK, events)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ship-status-dash/pkg/auth"
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/types"
+)
+
+func TestRequireGlobalAdmin(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.Components[0].Owners = []types.Owner{{User: "carol"}}
+	cfg.GlobalRoles = []types.Owner{
+		{RoverGroup: "ship-admins", Role: types.RoleAdmin},
+		{User: "bob", Role: types.RoleResponder},
+	}
+	h := newTestHandlersWithGroups(t, cfg, &outage.MockOutageManager{}, map[string][]string{"ship-admins": {"alice"}})
+	handler := h.requireGlobalAdmin(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+	}{
+		{name: "global admin", req: withUser(httptest.NewRequest(http.MethodGet, "/", nil), "alice"), wantStatus: http.StatusOK},
+		{name: "global responder", req: withUser(httptest.NewRequest(http.MethodGet, "/", nil), "bob"), wantStatus: http.StatusForbidden},
+		{name: "component admin", req: withUser(httptest.NewRequest(http.MethodGet, "/", nil), "carol"), wantStatus: http.StatusForbidden},
+		{name: "unauthenticated", req: httptest.NewRequest(http.MethodGet, "/", nil), wantStatus: http.StatusUnauthorized},
+		{name: "unscoped API token", req: withAPIToken(httptest.NewRequest(http.MethodGet, "/", nil), &types.APIToken{User: "alice"}), wantStatus: http.StatusOK},
+		{
+			name:       "scoped API token",
+			req:        withAPIToken(httptest.NewRequest(http.MethodGet, "/", nil), &types.APIToken{User: "alice", Scopes: []types.Permission{types.PermissionView}}),
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler(rec, tt.req)
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestGetGroupMembershipJSON(t *testing.T) {
+	h := newTestHandlersWithGroups(t, minimalDashboardConfig(), &outage.MockOutageManager{}, map[string][]string{
+		"oncall": {"bob"},
+		"admins": {"alice"},
+	})
+	rec := httptest.NewRecorder()
+	h.GetGroupMembershipJSON(rec, withUser(httptest.NewRequest(http.MethodGet, "/api/admin/groups", nil), "alice"))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var got []auth.GroupStatus
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, []auth.GroupStatus{
+		{Name: "admins", Source: "mock", Members: []string{"alice"}},
+		{Name: "oncall", Source: "mock", Members: []string{"bob"}},
+	}, got)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/auth"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+const maxAPITokenNameLength = 100
+
+// tokenManagementUser returns the user managing their tokens. Tokens cannot be used to create, list or revoke
+// tokens, so a leaked token cannot be used to mint more or hide itself.
+func (h *Handlers) tokenManagementUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "no active user found")
+		return "", false
+	}
+	if _, ok := GetAPITokenFromContext(r.Context()); ok {
+		h.respondForbidden(w, r, "", "API tokens cannot be used to manage API tokens")
+		return "", false
+	}
+	return user, true
+}
+
+// ListAPITokensJSON returns the authenticated user's personal access tokens, without their secrets.
+func (h *Handlers) ListAPITokensJSON(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.tokenManagementUser(w, r)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.apiTokenRepo.ListTokens(user)
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"active_user": user,
+			"error":       err,
+		}).Error("Failed to list API tokens")
+		respondWithError(w, http.StatusInternalServerError, "Failed to list API tokens")
+		return
+	}
+	if tokens == nil {
+		tokens = []types.APIToken{}
+	}
+
+	respondWithJSON(w, http.StatusOK, tokens)
+}
+
+// CreateAPITokenJSON creates a personal access token for the authenticated user and returns its secret once.
+func (h *Handlers) CreateAPITokenJSON(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.tokenManagementUser(w, r)
+	if !ok {
+		return
+	}
+
+	logger := h.logger.WithField("active_user", user)
+
+	var req types.APITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		respondWithError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if len(name) > maxAPITokenNameLength {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("name must not exceed %d characters", maxAPITokenNameLength))
+		return
+	}
+	scopes := make([]types.Permission, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !types.IsValidPermission(scope) {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid scope %q", scope))
+			return
+		}
+		scopes = append(scopes, types.Permission(scope))
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		respondWithError(w, http.StatusBadRequest, "expires_at must be in the future")
+		return
+	}
+
+	secret, hash, prefix, err := auth.GenerateAPIToken()
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to generate API token")
+		respondWithError(w, http.StatusInternalServerError, "Failed to create API token")
+		return
+	}
+	token := types.APIToken{
+		User:      user,
+		Name:      name,
+		TokenHash: hash,
+		Prefix:    prefix,
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := h.apiTokenRepo.CreateToken(&token); err != nil {
+		logger.WithField("error", err).Error("Failed to create API token")
+		respondWithError(w, http.StatusInternalServerError, "Failed to create API token")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"token_id": token.ID,
+		"scopes":   scopes,
+	}).Info("Created API token")
+	respondWithJSON(w, http.StatusCreated, types.CreatedAPIToken{APIToken: token, Token: secret})
+}
+
+// RevokeAPITokenJSON revokes one of the authenticated user's personal access tokens.
+func (h *Handlers) RevokeAPITokenJSON(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.tokenManagementUser(w, r)
+	if !ok {
+		return
+	}
+
+	tokenIDStr := mux.Vars(r)["tokenId"]
+	logger := h.logger.WithFields(logrus.Fields{
+		"active_user": user,
+		"token_id":    tokenIDStr,
+	})
+
+	tokenID, err := strconv.ParseUint(tokenIDStr, 10, 32)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	if err := h.apiTokenRepo.RevokeToken(user, uint(tokenID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondWithError(w, http.StatusNotFound, "API token not found")
+			return
+		}
+		logger.WithField("error", err).Error("Failed to revoke API token")
+		respondWithError(w, http.StatusInternalServerError, "Failed to revoke API token")
+		return
+	}
+
+	logger.Info("Revoked API token")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// APITokenUsePruner deletes the records of API token uses older than the retention period. Every request made
+// with a token adds one, so without it api_token_uses grows without bound.
+type APITokenUsePruner struct {
+	repo          repositories.APITokenRepository
+	retention     time.Duration
+	checkInterval time.Duration
+	logger        *logrus.Logger
+}
+
+// NewAPITokenUsePruner creates a new APITokenUsePruner.
+func NewAPITokenUsePruner(repo repositories.APITokenRepository, retention, checkInterval time.Duration, logger *logrus.Logger) *APITokenUsePruner {
+	return &APITokenUsePruner{
+		repo:          repo,
+		retention:     retention,
+		checkInterval: checkInterval,
+		logger:        logger,
+	}
+}
+
+// Start prunes old token uses once, then on every check interval until ctx is done.
+func (p *APITokenUsePruner) Start(ctx context.Context) {
+	p.logger.WithFields(logrus.Fields{
+		"retention":      p.retention,
+		"check_interval": p.checkInterval,
+	}).Info("Starting API token use pruner")
+	p.prune(time.Now())
+
+	ticker := time.NewTicker(p.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("Stopping API token use pruner")
+			return
+		case now := <-ticker.C:
+			p.prune(now)
+		}
+	}
+}
+
+// prune deletes the token uses recorded more than the retention period before now.
+func (p *APITokenUsePruner) prune(now time.Time) {
+	deleted, err := p.repo.DeleteTokenUsesBefore(now.Add(-p.retention))
+	if err != nil {
+		p.logger.WithField("error", err).Error("Failed to prune API token uses")
+		return
+	}
+	if deleted > 0 {
+		p.logger.WithField("deleted", deleted).Info("Pruned old API token uses")
+	}
+}
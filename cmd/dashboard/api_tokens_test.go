@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/18F/hmacauth"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/auth"
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+func withAPIToken(req *http.Request, token *types.APIToken) *http.Request {
+	ctx := context.WithValue(req.Context(), userContextKey, token.User)
+	return req.WithContext(context.WithValue(ctx, apiTokenContextKey, token))
+}
+
+func TestCreateAPITokenJSON(t *testing.T) {
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	future := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "unscoped without expiry", body: `{"name":"ci script"}`, wantStatus: http.StatusCreated},
+		{name: "scoped with expiry", body: `{"name":"notes bot","scopes":["triage_note:create","link:write"],"expires_at":"` + future + `"}`, wantStatus: http.StatusCreated},
+		{name: "invalid body", body: `{`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "missing name", body: `{"name":"  "}`, wantStatus: http.StatusBadRequest, wantError: "name is required"},
+		{name: "long name", body: `{"name":"` + strings.Repeat("a", maxAPITokenNameLength+1) + `"}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown scope", body: `{"name":"x","scopes":["outage:everything"]}`, wantStatus: http.StatusBadRequest, wantError: `Invalid scope "outage:everything"`},
+		{name: "expiry in the past", body: `{"name":"x","expires_at":"` + past + `"}`, wantStatus: http.StatusBadRequest, wantError: "expires_at must be in the future"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})
+			req := withUser(httptest.NewRequest(http.MethodPost, "/api/user/tokens", strings.NewReader(tt.body)), "alice")
+			rec := httptest.NewRecorder()
+			h.CreateAPITokenJSON(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantError != "" {
+				var resp map[string]string
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				assert.Equal(t, tt.wantError, resp["error"])
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+
+			var created types.CreatedAPIToken
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+			assert.Equal(t, "alice", created.User)
+			assert.True(t, strings.HasPrefix(created.Token, created.Prefix))
+
+			stored := h.apiTokenRepo.(*repositories.MockAPITokenRepository).Tokens
+			require.Len(t, stored, 1)
+			assert.Equal(t, auth.HashAPIToken(created.Token), stored[0].TokenHash, "only the hash is stored")
+			assert.NotContains(t, rec.Body.String(), stored[0].TokenHash)
+		})
+	}
+}
+
+func TestAPITokenManagement(t *testing.T) {
+	h := newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})
+	repo := h.apiTokenRepo.(*repositories.MockAPITokenRepository)
+	require.NoError(t, repo.CreateToken(&types.APIToken{User: "alice", Name: "mine", TokenHash: "a"}))
+	require.NoError(t, repo.CreateToken(&types.APIToken{User: "bob", Name: "theirs", TokenHash: "b"}))
+
+	rec := httptest.NewRecorder()
+	h.ListAPITokensJSON(rec, withUser(httptest.NewRequest(http.MethodGet, "/api/user/tokens", nil), "alice"))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var tokens []types.APIToken
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&tokens))
+	require.Len(t, tokens, 1)
+	assert.Equal(t, "mine", tokens[0].Name)
+
+	revoke := func(req *http.Request, tokenID string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.RevokeAPITokenJSON(rec, mux.SetURLVars(req, map[string]string{"tokenId": tokenID}))
+		return rec
+	}
+	assert.Equal(t, http.StatusNotFound, revoke(withUser(httptest.NewRequest(http.MethodDelete, "/", nil), "alice"), "2").Code, "cannot revoke another user's token")
+	assert.Equal(t, http.StatusForbidden, revoke(withAPIToken(httptest.NewRequest(http.MethodDelete, "/", nil), &repo.Tokens[0]), "1").Code, "tokens cannot revoke tokens")
+	assert.Equal(t, http.StatusNoContent, revoke(withUser(httptest.NewRequest(http.MethodDelete, "/", nil), "alice"), "1").Code)
+	assert.Len(t, repo.Tokens, 1)
+
+	rec = httptest.NewRecorder()
+	h.CreateAPITokenJSON(rec, withAPIToken(httptest.NewRequest(http.MethodPost, "/api/user/tokens", strings.NewReader(`{"name":"x"}`)), &repo.Tokens[0]))
+	assert.Equal(t, http.StatusForbidden, rec.Code, "tokens cannot create tokens")
+}
+
+func TestAuthMiddleware_APIToken(t *testing.T) {
+	const secret = "ssd_secret"
+	expired := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name          string
+		authorization string
+		token         *types.APIToken
+		recordErr     error
+		wantStatus    int
+		wantUser      string
+		wantUses      int
+	}{
+		{
+			name:          "valid token",
+			authorization: "Bearer " + secret,
+			token:         &types.APIToken{User: "alice"},
+			wantStatus:    http.StatusOK,
+			wantUser:      "alice",
+			wantUses:      1,
+		},
+		{
+			name:          "unknown token",
+			authorization: "Bearer ssd_other",
+			token:         &types.APIToken{User: "alice"},
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "expired token",
+			authorization: "Bearer " + secret,
+			token:         &types.APIToken{User: "alice", ExpiresAt: &expired},
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "use cannot be recorded",
+			authorization: "Bearer " + secret,
+			token:         &types.APIToken{User: "alice"},
+			recordErr:     errors.New("db down"),
+			wantStatus:    http.StatusInternalServerError,
+		},
+		{
+			name:          "other bearer tokens use the HMAC path",
+			authorization: "Bearer something-else",
+			token:         &types.APIToken{User: "alice"},
+			wantStatus:    http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})
+			repo := h.apiTokenRepo.(*repositories.MockAPITokenRepository)
+			repo.RecordTokenUseError = tt.recordErr
+			tt.token.TokenHash = auth.HashAPIToken(secret)
+			require.NoError(t, repo.CreateToken(tt.token))
+
+			var gotUser string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = GetUserFromContext(r.Context())
+				_, hasToken := GetAPITokenFromContext(r.Context())
+				assert.True(t, hasToken)
+				w.WriteHeader(http.StatusOK)
+			})
+			logger := logrus.New()
+			logger.SetLevel(logrus.PanicLevel)
+			hmacAuth := hmacauth.NewHmacAuth(crypto.SHA256, []byte("secret"), auth.GAPSignatureHeader, auth.OAuthSignatureHeaders)
+			middleware := authMiddleware(next, logger, hmacAuth, h.configManager, repo, h.securityEvents, nil, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/components/alpha/one/outages", nil)
+			req.Header.Set("Authorization", tt.authorization)
+			rec := httptest.NewRecorder()
+			middleware.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			assert.Equal(t, tt.wantUser, gotUser)
+			require.Len(t, repo.Uses, tt.wantUses)
+			if tt.wantUses > 0 {
+				assert.Equal(t, types.APITokenUse{TokenID: 1, User: "alice", Method: http.MethodPost, Path: "/api/components/alpha/one/outages", RemoteAddr: req.RemoteAddr}, repo.Uses[0])
+			}
+		})
+	}
+}
+
+func TestRequireComponentPermission_APITokenScopes(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.Components[0].Owners = []types.Owner{{User: "alice", Role: types.RoleResponder}}
+	h := newTestHandlers(t, cfg, &outage.MockOutageManager{})
+
+	tests := []struct {
+		name       string
+		scopes     []types.Permission
+		permission types.Permission
+		wantStatus int
+	}{
+		{name: "unscoped token has the user's permissions", permission: types.PermissionCreateOutage, wantStatus: http.StatusOK},
+		{name: "scope allows permission", scopes: []types.Permission{types.PermissionCreateOutage}, permission: types.PermissionCreateOutage, wantStatus: http.StatusOK},
+		{name: "scope does not include permission", scopes: []types.Permission{types.PermissionAddTriageNote}, permission: types.PermissionCreateOutage, wantStatus: http.StatusForbidden},
+		{name: "scope does not add to the user's role", scopes: []types.Permission{types.PermissionDeleteOutage}, permission: types.PermissionDeleteOutage, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := h.requireComponentPermission(tt.permission, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			req := withAPIToken(httptest.NewRequest(http.MethodPost, "/", nil), &types.APIToken{User: "alice", Scopes: tt.scopes})
+			rec := httptest.NewRecorder()
+			handler(rec, mux.SetURLVars(req, map[string]string{"componentName": "alpha"}))
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestRequireTokenScope(t *testing.T) {
+	h := newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})
+
+	tests := []struct {
+		name       string
+		scopes     []types.Permission
+		scope      types.Permission
+		wantStatus int
+	}{
+		{name: "unscoped token on a route without a scope", wantStatus: http.StatusOK},
+		{name: "scoped token on a route without a scope", scopes: []types.Permission{types.PermissionView}, wantStatus: http.StatusForbidden},
+		{name: "scope includes the route scope", scopes: []types.Permission{types.PermissionReportStatus}, scope: types.PermissionReportStatus, wantStatus: http.StatusOK},
+		{name: "scope does not include the route scope", scopes: []types.Permission{types.PermissionView}, scope: types.PermissionReportStatus, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := h.requireTokenScope(tt.scope, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			req := withAPIToken(httptest.NewRequest(http.MethodPost, "/", nil), &types.APIToken{User: "alice", Scopes: tt.scopes})
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestProtectedRoutes_ViewScopedAPIToken(t *testing.T) {
+	h := newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})
+	s := &Server{handlers: h, rateLimiter: NewRateLimiter(h.configManager, logrus.New())}
+	forbidden := map[string]bool{
+		"POST /api/components/{componentName}/{subComponentName}/outages/report-suspected": true,
+		"POST /api/component-monitor/report":                                               true,
+		"POST /api/user/subscriptions":                                                     true,
+		"DELETE /api/user/subscriptions/{subscriptionId:[0-9]+}":                           true,
+		"PUT /api/user/notification-preferences":                                           true,
+		"GET /api/user/activity":                                                           true,
+	}
+	for _, route := range s.routes() {
+		key := route.method + " " + route.path
+		if !forbidden[key] {
+			continue
+		}
+		delete(forbidden, key)
+		t.Run(key, func(t *testing.T) {
+			req := withAPIToken(httptest.NewRequest(route.method, "/", strings.NewReader("{}")), &types.APIToken{User: "alice", Scopes: []types.Permission{types.PermissionView}})
+			req = mux.SetURLVars(req, map[string]string{"componentName": "alpha", "subComponentName": "one", "subscriptionId": "1"})
+			rec := httptest.NewRecorder()
+			s.protectedHandler(route)(rec, req)
+			assert.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+		})
+	}
+	assert.Empty(t, forbidden, "routes missing from the route table")
+}
+
+func TestAPITokenUsePruner(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &repositories.MockAPITokenRepository{Uses: []types.APITokenUse{
+		{Model: gorm.Model{ID: 1, CreatedAt: now.Add(-100 * 24 * time.Hour)}, TokenID: 1, User: "alice"},
+		{Model: gorm.Model{ID: 2, CreatedAt: now.Add(-time.Hour)}, TokenID: 1, User: "alice"},
+	}}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	NewAPITokenUsePruner(repo, 90*24*time.Hour, time.Hour, logger).prune(now)
+	require.Len(t, repo.Uses, 1)
+	assert.Equal(t, uint(2), repo.Uses[0].ID)
+}
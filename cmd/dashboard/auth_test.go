@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/18F/hmacauth"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	authenticationv1fake "k8s.io/client-go/kubernetes/typed/authentication/v1/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"ship-status-dash/pkg/auth"
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+const (
+	monitorServiceAccount = "system:serviceaccount:ship-status:component-monitor"
+	mcpServiceAccount     = "system:serviceaccount:ship-status:ship-status-mcp"
+)
+
+func TestAuthMiddleware_TokenReview(t *testing.T) {
+	tests := []struct {
+		name         string
+		tokenReview  bool
+		headers      map[string]string
+		wantStatus   int
+		wantUser     string
+		wantReviewed bool
+	}{
+		{
+			name:         "service account token",
+			tokenReview:  true,
+			headers:      map[string]string{"Authorization": "Bearer monitor-token"},
+			wantStatus:   http.StatusOK,
+			wantUser:     monitorServiceAccount,
+			wantReviewed: true,
+		},
+		{
+			name:         "trusted delegator acts for a user",
+			tokenReview:  true,
+			headers:      map[string]string{"Authorization": "Bearer mcp-token", actingForHeader: "alice"},
+			wantStatus:   http.StatusOK,
+			wantUser:     "alice",
+			wantReviewed: true,
+		},
+		{
+			name:         "trusted delegator without acting for",
+			tokenReview:  true,
+			headers:      map[string]string{"Authorization": "Bearer mcp-token"},
+			wantStatus:   http.StatusBadRequest,
+			wantReviewed: true,
+		},
+		{
+			name:         "user tokens are rejected",
+			tokenReview:  true,
+			headers:      map[string]string{"Authorization": "Bearer user-token"},
+			wantStatus:   http.StatusUnauthorized,
+			wantReviewed: true,
+		},
+		{
+			name:         "unknown token",
+			tokenReview:  true,
+			headers:      map[string]string{"Authorization": "Bearer garbage"},
+			wantStatus:   http.StatusUnauthorized,
+			wantReviewed: true,
+		},
+		{
+			name:         "API server unavailable",
+			tokenReview:  true,
+			headers:      map[string]string{"Authorization": "Bearer broken"},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantReviewed: true,
+		},
+		{
+			name:        "requests signed by oauth-proxy use the HMAC path",
+			tokenReview: true,
+			headers: map[string]string{
+				"Authorization":         "Bearer monitor-token",
+				"X-Forwarded-User":      monitorServiceAccount,
+				auth.GAPSignatureHeader: "sha256 invalid",
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "bearer tokens use the HMAC path when TokenReview is disabled",
+			headers:    map[string]string{"Authorization": "Bearer monitor-token"},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := minimalDashboardConfig()
+			cfg.TrustedDelegators = []string{mcpServiceAccount}
+			h := newTestHandlers(t, cfg, &outage.MockOutageManager{})
+
+			reviewed := false
+			client := &authenticationv1fake.FakeAuthenticationV1{Fake: &clienttesting.Fake{}}
+			client.AddReactor("create", "tokenreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				reviewed = true
+				review := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenReview).DeepCopy()
+				usernames := map[string]string{
+					"monitor-token": monitorServiceAccount,
+					"mcp-token":     mcpServiceAccount,
+					"user-token":    "alice",
+				}
+				switch username, ok := usernames[review.Spec.Token]; {
+				case review.Spec.Token == "broken":
+					return true, nil, errors.New("connection refused")
+				case ok:
+					review.Status.Authenticated = true
+					review.Status.User.Username = username
+					review.Status.Audiences = review.Spec.Audiences
+				}
+				return true, review, nil
+			})
+			var tokenReviewer *auth.TokenReviewAuthenticator
+			if tt.tokenReview {
+				var err error
+				tokenReviewer, err = auth.NewTokenReviewAuthenticator(client, []string{"ship-status-dash"}, 0)
+				require.NoError(t, err)
+			}
+
+			var gotUser string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = GetUserFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+			logger := logrus.New()
+			logger.SetLevel(logrus.PanicLevel)
+			hmacAuth := hmacauth.NewHmacAuth(crypto.SHA256, []byte("secret"), auth.GAPSignatureHeader, auth.OAuthSignatureHeaders)
+			middleware := authMiddleware(next, logger, hmacAuth, h.configManager, h.apiTokenRepo, h.securityEvents, tokenReviewer, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/component-monitor/report", nil)
+			for header, value := range tt.headers {
+				req.Header.Set(header, value)
+			}
+			rec := httptest.NewRecorder()
+			middleware.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			assert.Equal(t, tt.wantUser, gotUser)
+			assert.Equal(t, tt.wantReviewed, reviewed)
+		})
+	}
+}
+
+func TestAuthMiddleware_OIDCSession(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.Components[0].Owners = []types.Owner{{RoverGroup: "ship-admins", Role: types.RoleResponder}}
+	h := newTestHandlers(t, cfg, &outage.MockOutageManager{})
+	claimGroups := auth.NewClaimGroupMembership(h.groupCache)
+	h.groupCache = claimGroups
+	sessions, err := auth.NewSessionManager([]byte(strings.Repeat("k", 32)), time.Hour, true, claimGroups)
+	require.NoError(t, err)
+
+	issued := httptest.NewRecorder()
+	require.NoError(t, sessions.Issue(issued, "alice", []string{"ship-admins"}))
+	sessionCookie := issued.Result().Cookies()[0]
+
+	tests := []struct {
+		name              string
+		cookie            *http.Cookie
+		headers           map[string]string
+		wantStatus        int
+		wantSecurityEvent bool
+	}{
+		{name: "session with a group claim owning the component", cookie: sessionCookie, wantStatus: http.StatusOK},
+		{name: "no session", wantStatus: http.StatusUnauthorized},
+		{name: "tampered session", cookie: &http.Cookie{Name: auth.SessionCookieName, Value: sessionCookie.Value + "x"}, wantStatus: http.StatusUnauthorized, wantSecurityEvent: true},
+		{name: "unsigned session", cookie: &http.Cookie{Name: auth.SessionCookieName, Value: "e30"}, wantStatus: http.StatusUnauthorized, wantSecurityEvent: true},
+		{
+			name:       "oauth-proxy headers are not accepted in OIDC mode",
+			headers:    map[string]string{"X-Forwarded-User": "alice", auth.GAPSignatureHeader: "sha256 invalid"},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := h.requireComponentPermission(types.PermissionCreateOutage, func(w http.ResponseWriter, r *http.Request) {
+				user, _ := GetUserFromContext(r.Context())
+				assert.Equal(t, "alice", user)
+				w.WriteHeader(http.StatusOK)
+			})
+			logger := logrus.New()
+			logger.SetLevel(logrus.PanicLevel)
+			hmacAuth := hmacauth.NewHmacAuth(crypto.SHA256, nil, auth.GAPSignatureHeader, auth.OAuthSignatureHeaders)
+			middleware := authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handler(w, mux.SetURLVars(r, map[string]string{"componentName": "alpha"}))
+			}), logger, hmacAuth, h.configManager, h.apiTokenRepo, h.securityEvents, nil, sessions)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/components/alpha/one/outages", nil)
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			for header, value := range tt.headers {
+				req.Header.Set(header, value)
+			}
+			securityEvents := h.securityEventRepo.(*repositories.MockSecurityEventRepository)
+			recorded := len(securityEvents.Events)
+			rec := httptest.NewRecorder()
+			middleware.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			assert.Equal(t, tt.wantSecurityEvent, len(securityEvents.Events) > recorded, "only forged sessions are recorded as security events")
+		})
+	}
+}
+
+const chatBotServiceAccount = "system:serviceaccount:ship-status:chat-bot"
+
+// withDelegation sets up the context of a request delegator made on behalf of user.
+func withDelegation(req *http.Request, delegator, user string) *http.Request {
+	ctx := context.WithValue(req.Context(), userContextKey, user)
+	return req.WithContext(context.WithValue(ctx, delegatorContextKey, delegator))
+}
+
+func TestAuthMiddleware_DelegationRule(t *testing.T) {
+	t.Setenv("SKIP_AUTH", "1")
+	cfg := minimalDashboardConfig()
+	cfg.DelegationRules = []types.DelegationRule{{Delegator: "developer", Permissions: []types.Permission{types.PermissionCreateOutage}}}
+	h := newTestHandlers(t, cfg, &outage.MockOutageManager{})
+
+	var gotUser, gotDelegator string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, _ = GetUserFromContext(r.Context())
+		gotDelegator, _ = GetDelegatorFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	middleware := authMiddleware(next, logger, nil, h.configManager, h.apiTokenRepo, h.securityEvents, nil, nil)
+
+	rec := httptest.NewRecorder()
+	middleware.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/components/alpha/one/outages", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "delegators with a rule must send X-Acting-For")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/components/alpha/one/outages", nil)
+	req.Header.Set(actingForHeader, "alice")
+	rec = httptest.NewRecorder()
+	middleware.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "alice", gotUser)
+	assert.Equal(t, "developer", gotDelegator)
+}
+
+func TestRequireComponentPermission_DelegationRules(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.Components[0].Subcomponents[0].Tags = []string{"ci"}
+	cfg.Components = append(cfg.Components, &types.Component{
+		Name: "Beta", Slug: "beta",
+		Subcomponents: []types.SubComponent{{Name: "One", Slug: "one"}},
+	})
+	for _, component := range cfg.Components {
+		component.Owners = []types.Owner{{User: "alice"}, {User: "bob"}}
+	}
+	cfg.TrustedDelegators = []string{mcpServiceAccount}
+	cfg.DelegationRules = []types.DelegationRule{
+		{
+			Delegator:   chatBotServiceAccount,
+			Components:  []string{"Alpha"},
+			Permissions: []types.Permission{types.PermissionCreateOutage, types.PermissionAddTriageNote},
+			RoverGroup:  "bot-users",
+		},
+		{
+			Delegator:   "tagger",
+			Tags:        []string{"ci"},
+			Permissions: []types.Permission{types.PermissionCreateOutage},
+		},
+	}
+	h := newTestHandlersWithGroups(t, cfg, &outage.MockOutageManager{}, map[string][]string{"bot-users": {"alice"}})
+
+	tests := []struct {
+		name       string
+		delegator  string
+		user       string
+		component  string
+		permission types.Permission
+		wantStatus int
+	}{
+		{name: "within the rule", delegator: chatBotServiceAccount, user: "alice", component: "alpha", permission: types.PermissionCreateOutage, wantStatus: http.StatusOK},
+		{name: "permission outside the rule", delegator: chatBotServiceAccount, user: "alice", component: "alpha", permission: types.PermissionDeleteOutage, wantStatus: http.StatusForbidden},
+		{name: "component outside the rule", delegator: chatBotServiceAccount, user: "alice", component: "beta", permission: types.PermissionCreateOutage, wantStatus: http.StatusForbidden},
+		{name: "user outside the rule", delegator: chatBotServiceAccount, user: "bob", component: "alpha", permission: types.PermissionCreateOutage, wantStatus: http.StatusForbidden},
+		{name: "tagged sub-component", delegator: "tagger", user: "bob", component: "alpha", permission: types.PermissionCreateOutage, wantStatus: http.StatusOK},
+		{name: "untagged sub-component", delegator: "tagger", user: "bob", component: "beta", permission: types.PermissionCreateOutage, wantStatus: http.StatusForbidden},
+		{name: "trusted delegators are unrestricted", delegator: mcpServiceAccount, user: "bob", component: "beta", permission: types.PermissionDeleteOutage, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := h.requireComponentPermission(tt.permission, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			req := withDelegation(httptest.NewRequest(http.MethodPost, "/", nil), tt.delegator, tt.user)
+			rec := httptest.NewRecorder()
+			handler(rec, mux.SetURLVars(req, map[string]string{"componentName": tt.component, "subComponentName": "one"}))
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestRejectScopedDelegators(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.TrustedDelegators = []string{mcpServiceAccount}
+	cfg.DelegationRules = []types.DelegationRule{{Delegator: chatBotServiceAccount, Permissions: []types.Permission{types.PermissionCreateOutage}}}
+	om := &outage.MockOutageManager{}
+	h := newTestHandlers(t, cfg, om)
+	handler := h.rejectScopedDelegators(func(w http.ResponseWriter, r *http.Request) {
+		h.outagesFor(r)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+	}{
+		{name: "delegator with a rule", req: withDelegation(httptest.NewRequest(http.MethodGet, "/api/user/tokens", nil), chatBotServiceAccount, "alice"), wantStatus: http.StatusForbidden},
+		{name: "trusted delegator", req: withDelegation(httptest.NewRequest(http.MethodGet, "/api/user/tokens", nil), mcpServiceAccount, "alice"), wantStatus: http.StatusOK},
+		{name: "no delegation", req: withUser(httptest.NewRequest(http.MethodGet, "/api/user/tokens", nil), "alice"), wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler(rec, tt.req)
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+		})
+	}
+	assert.Equal(t, []string{mcpServiceAccount}, om.Delegators, "delegated changes must go through a manager recording the delegator")
+}
@@ -50,13 +50,13 @@ func (p *ComponentMonitorReportProcessor) ValidateRequest(req *types.ComponentMo
 			continue
 		}
 
-		// Service account must be an owner of the component
+		// Service account must be an owner of the component with a role that can report status
 		serviceAccountIsOwner := false
 		for _, owner := range component.Owners {
 			if owner.ServiceAccount == "" {
 				continue
 			}
-			if owner.ServiceAccount == serviceAccount {
+			if owner.ServiceAccount == serviceAccount && owner.EffectiveRole().Grants(types.PermissionReportStatus) {
 				serviceAccountIsOwner = true
 				break
 			}
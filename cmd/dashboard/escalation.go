@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+// EscalationChecker posts the escalation reminders configured on components for outages that have gone quiet.
+type EscalationChecker struct {
+	configManager   *config.Manager[types.DashboardConfig]
+	outageManager   outage.OutageManager
+	escalationRepo  repositories.EscalationRepository
+	slackThreadRepo repositories.SlackThreadRepository
+	slackClient     *slack.Client
+	baseURL         string
+	checkInterval   time.Duration
+	logger          *logrus.Logger
+}
+
+// NewEscalationChecker creates a new EscalationChecker. Reminders link to outages under baseURL.
+func NewEscalationChecker(configManager *config.Manager[types.DashboardConfig], outageManager outage.OutageManager, escalationRepo repositories.EscalationRepository, slackThreadRepo repositories.SlackThreadRepository, slackClient *slack.Client, baseURL string, checkInterval time.Duration, logger *logrus.Logger) *EscalationChecker {
+	return &EscalationChecker{
+		configManager:   configManager,
+		outageManager:   outageManager,
+		escalationRepo:  escalationRepo,
+		slackThreadRepo: slackThreadRepo,
+		slackClient:     slackClient,
+		baseURL:         baseURL,
+		checkInterval:   checkInterval,
+		logger:          logger,
+	}
+}
+
+// Start checks for outages to escalate every check interval until ctx is done.
+func (e *EscalationChecker) Start(ctx context.Context) {
+	e.logger.WithField("check_interval", e.checkInterval).Info("Starting escalation checker")
+	ticker := time.NewTicker(e.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.logger.Info("Stopping escalation checker")
+			return
+		case now := <-ticker.C:
+			e.checkForEscalations(now)
+		}
+	}
+}
+
+// checkForEscalations fires every escalation step whose quiet period has elapsed for an active outage.
+func (e *EscalationChecker) checkForEscalations(now time.Time) {
+	logger := e.logger.WithField("check", "escalation")
+
+	for _, component := range e.configManager.Get().Components {
+		outages, err := e.outageManager.GetActiveOutagesForComponent(component.Slug)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"component": component.Name,
+				"error":     err,
+			}).Error("Failed to get active outages")
+			continue
+		}
+		for i := range outages {
+			o := &outages[i]
+			steps := types.GetEscalation(component, component.GetSubComponentBySlug(o.SubComponentName))
+			for step, escalation := range steps {
+				e.escalate(logger.WithFields(logrus.Fields{
+					"outage_id": o.ID,
+					"step":      step,
+				}), component, o, step, escalation, now)
+			}
+		}
+	}
+}
+
+// lastActivity is when the outage was created or last had an update or triage note, whichever is later.
+func lastActivity(o *types.Outage) time.Time {
+	activity := o.CreatedAt
+	if o.LastAuditableUpdate.After(activity) {
+		activity = o.LastAuditableUpdate
+	}
+	// Postgres stores microseconds, so truncate for the stored value to match across checks.
+	return activity.UTC().Truncate(time.Second)
+}
+
+func (e *EscalationChecker) escalate(logger *logrus.Entry, component *types.Component, o *types.Outage, step int, escalation types.EscalationStep, now time.Time) {
+	after, err := time.ParseDuration(escalation.After)
+	if err != nil {
+		logger.WithField("error", err).Warn("Failed to parse escalation delay, skipping")
+		return
+	}
+	threshold := types.SeverityDown
+	if escalation.Severity != nil {
+		threshold = *escalation.Severity
+	}
+	if types.GetSeverityLevel(o.Severity) < types.GetSeverityLevel(threshold) {
+		return
+	}
+	activity := lastActivity(o)
+	quietFor := now.Sub(activity)
+	if quietFor < after {
+		return
+	}
+
+	recorded, err := e.escalationRepo.RecordEscalation(&types.OutageEscalation{OutageID: o.ID, Step: step, ActivityAt: activity})
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to record escalation")
+		return
+	}
+	if !recorded {
+		return
+	}
+
+	var mentions []string
+	if escalation.MentionOwners {
+		for _, owner := range component.Owners {
+			if owner.SlackMention != "" {
+				mentions = append(mentions, owner.SlackMention)
+			}
+		}
+	}
+	message := slack.MsgOptionText(outage.FormatEscalationReminder(o, e.configManager, e.baseURL, now, quietFor, mentions), false)
+
+	if escalation.Channel != "" {
+		if _, _, err := e.slackClient.PostMessage(escalation.Channel, message, slack.MsgOptionAsUser(true)); err != nil {
+			logger.WithFields(logrus.Fields{
+				"channel": escalation.Channel,
+				"error":   err,
+			}).Error("Failed to post escalation")
+			return
+		}
+		logger.WithField("channel", escalation.Channel).Info("Posted escalation")
+		return
+	}
+
+	threads, err := e.slackThreadRepo.GetThreadsForOutage(o.ID)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to get Slack threads for escalation")
+		return
+	}
+	if len(threads) == 0 {
+		logger.Warn("Outage has no Slack thread to post the escalation in")
+		return
+	}
+	for _, thread := range threads {
+		if _, _, err := e.slackClient.PostMessage(thread.ChannelID, message, slack.MsgOptionTS(thread.ThreadTimestamp), slack.MsgOptionAsUser(true)); err != nil {
+			logger.WithFields(logrus.Fields{
+				"channel": thread.Channel,
+				"error":   err,
+			}).Error("Failed to post escalation in thread")
+			continue
+		}
+		logger.WithField("channel", thread.Channel).Info("Posted escalation in thread")
+	}
+}
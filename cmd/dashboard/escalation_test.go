@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+func TestEscalationChecker_checkForEscalations(t *testing.T) {
+	degraded := types.SeverityDegraded
+	cfg := minimalDashboardConfig()
+	cfg.Components[0].Owners = []types.Owner{{User: "alice", SlackMention: "U123"}, {RoverGroup: "alpha-team", SlackMention: "S456"}, {User: "bob"}}
+	cfg.Components[0].Escalation = []types.EscalationStep{
+		{After: "15m", MentionOwners: true},
+		{After: "1h", Channel: "#alpha-escalations"},
+		{After: "30m", Severity: &degraded, Channel: "#alpha-degraded"},
+	}
+
+	created := time.Date(2024, 1, 17, 9, 0, 0, 0, time.UTC)
+	down := types.Outage{Model: gorm.Model{ID: 1, CreatedAt: created}, ComponentName: "alpha", SubComponentName: "one", Severity: types.SeverityDown, StartTime: created}
+	om := &outage.MockOutageManager{
+		GetActiveOutagesForComponentFn: func(string) ([]types.Outage, error) {
+			return []types.Outage{down}, nil
+		},
+	}
+	escalationRepo := &repositories.MockEscalationRepository{}
+	threadRepo := &repositories.MockSlackThreadRepository{ThreadsForOutage: []types.SlackThread{{OutageID: 1, Channel: "#alpha", ChannelID: "C1", ThreadTimestamp: "111.222"}}}
+	slackServer := outage.NewMockSlackServer(t)
+	defer slackServer.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	checker := NewEscalationChecker(newTestHandlers(t, cfg, om).configManager, om, escalationRepo, threadRepo, slackServer.Client(), "https://dashboard.example.com", time.Minute, logger)
+
+	checker.checkForEscalations(created.Add(10 * time.Minute))
+	assert.Empty(t, slackServer.PostedMessages(), "no step is due before its delay")
+
+	checker.checkForEscalations(created.Add(16 * time.Minute))
+	posted := slackServer.PostedMessages()
+	require.Len(t, posted, 1)
+	assert.Equal(t, "C1", posted[0].Channel)
+	assert.Equal(t, "111.222", posted[0].ThreadTimestamp)
+	assert.Contains(t, posted[0].Text, "⏰ Outage needs attention: Alpha/One (#1)")
+	assert.Contains(t, posted[0].Text, "`Down` for 16m with no update or triage note for 16m.")
+	assert.Contains(t, posted[0].Text, "<@U123> <!subteam^S456>")
+	assert.Contains(t, posted[0].Text, "<https://dashboard.example.com/alpha/one/outages/1|View Outage>")
+
+	checker.checkForEscalations(created.Add(20 * time.Minute))
+	assert.Len(t, slackServer.PostedMessages(), 1, "a step fires once per quiet period")
+
+	checker.checkForEscalations(created.Add(61 * time.Minute))
+	posted = slackServer.PostedMessages()
+	require.Len(t, posted, 3)
+	channels := []string{posted[1].Channel, posted[2].Channel}
+	assert.ElementsMatch(t, []string{"#alpha-escalations", "#alpha-degraded"}, channels)
+	assert.NotContains(t, posted[1].Text, "<@U123>", "owners are only mentioned by steps that ask for it")
+
+	// A triage note restarts the clock, so the steps fire again after another quiet period.
+	down.LastAuditableUpdate = created.Add(70 * time.Minute)
+	checker.checkForEscalations(created.Add(80 * time.Minute))
+	assert.Len(t, slackServer.PostedMessages(), 3)
+	checker.checkForEscalations(created.Add(86 * time.Minute))
+	assert.Len(t, slackServer.PostedMessages(), 4)
+	assert.Len(t, escalationRepo.Recorded, 4)
+}
+
+func TestEscalationChecker_checkForEscalations_BelowSeverity(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.Components[0].Escalation = []types.EscalationStep{{After: "15m", Channel: "#alpha-escalations"}}
+	created := time.Date(2024, 1, 17, 9, 0, 0, 0, time.UTC)
+	om := &outage.MockOutageManager{
+		GetActiveOutagesForComponentFn: func(string) ([]types.Outage, error) {
+			return []types.Outage{{Model: gorm.Model{ID: 1, CreatedAt: created}, ComponentName: "alpha", SubComponentName: "one", Severity: types.SeverityDegraded, StartTime: created}}, nil
+		},
+	}
+	escalationRepo := &repositories.MockEscalationRepository{}
+	slackServer := outage.NewMockSlackServer(t)
+	defer slackServer.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	checker := NewEscalationChecker(newTestHandlers(t, cfg, om).configManager, om, escalationRepo, &repositories.MockSlackThreadRepository{}, slackServer.Client(), "https://dashboard.example.com", time.Minute, logger)
+
+	checker.checkForEscalations(created.Add(time.Hour))
+	assert.Empty(t, slackServer.PostedMessages(), "steps default to Down outages only")
+	assert.Empty(t, escalationRepo.Recorded)
+}
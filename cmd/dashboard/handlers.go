@@ -16,7 +16,6 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
-	"k8s.io/apimachinery/pkg/util/sets"
 
 	"ship-status-dash/pkg/auth"
 	"ship-status-dash/pkg/config"
@@ -34,13 +33,18 @@ type Handlers struct {
 	pingRepo               repositories.ComponentPingRepository
 	triageNoteRepo         repositories.TriageNoteRepository
 	outageLinkRepo         repositories.OutageLinkRepository
+	watchRepo              repositories.WatchSubscriptionRepository
+	watchDestinations      outage.WatchDestinationPolicy
+	apiTokenRepo           repositories.APITokenRepository
+	securityEventRepo      repositories.SecurityEventRepository
+	securityEvents         *SecurityEventRecorder
 	groupCache             auth.GroupMembershipProvider
 	monitorReportProcessor *ComponentMonitorReportProcessor
 	externalPageCaches     map[string]*ExternalPageCache
 }
 
 // NewHandlers creates a new Handlers instance with the provided dependencies.
-func NewHandlers(logger *logrus.Logger, configManager *config.Manager[types.DashboardConfig], outageManager outage.OutageManager, pingRepo repositories.ComponentPingRepository, triageNoteRepo repositories.TriageNoteRepository, outageLinkRepo repositories.OutageLinkRepository, groupCache auth.GroupMembershipProvider) *Handlers {
+func NewHandlers(logger *logrus.Logger, configManager *config.Manager[types.DashboardConfig], outageManager outage.OutageManager, pingRepo repositories.ComponentPingRepository, triageNoteRepo repositories.TriageNoteRepository, outageLinkRepo repositories.OutageLinkRepository, watchRepo repositories.WatchSubscriptionRepository, apiTokenRepo repositories.APITokenRepository, securityEventRepo repositories.SecurityEventRepository, groupCache auth.GroupMembershipProvider) *Handlers {
 	return &Handlers{
 		logger:                 logger,
 		configManager:          configManager,
@@ -48,6 +52,10 @@ func NewHandlers(logger *logrus.Logger, configManager *config.Manager[types.Dash
 		pingRepo:               pingRepo,
 		triageNoteRepo:         triageNoteRepo,
 		outageLinkRepo:         outageLinkRepo,
+		watchRepo:              watchRepo,
+		apiTokenRepo:           apiTokenRepo,
+		securityEventRepo:      securityEventRepo,
+		securityEvents:         NewSecurityEventRecorder(securityEventRepo, logger),
 		groupCache:             groupCache,
 		monitorReportProcessor: NewComponentMonitorReportProcessor(outageManager, pingRepo, configManager, logger),
 		externalPageCaches: map[string]*ExternalPageCache{
@@ -65,6 +73,15 @@ func (h *Handlers) config() *types.DashboardConfig {
 	return h.configManager.Get()
 }
 
+// outagesFor returns the outage manager to make the changes requested by r with, so that delegated changes
+// record their delegator in the audit log.
+func (h *Handlers) outagesFor(r *http.Request) outage.OutageManager {
+	if delegator, ok := GetDelegatorFromContext(r.Context()); ok {
+		return h.outageManager.WithDelegator(delegator)
+	}
+	return h.outageManager
+}
+
 func respondWithJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -77,27 +94,36 @@ func respondWithError(w http.ResponseWriter, statusCode int, message string) {
 	})
 }
 
-// collectAuthorizedIdentities returns all identities authorized for a component:
-// Owner.User, Owner.ServiceAccount values, and expanded RoverGroup members.
-func (h *Handlers) collectAuthorizedIdentities(component *types.Component) []string {
-	identities := sets.NewString()
-	for _, owner := range component.Owners {
-		if owner.User != "" {
-			identities.Insert(owner.User)
-		}
-		if owner.ServiceAccount != "" {
-			identities.Insert(owner.ServiceAccount)
-		}
-		if owner.RoverGroup != "" {
-			identities.Insert(h.groupCache.GetGroupMembers(owner.RoverGroup)...)
+// componentRole returns the highest role user has on component through its owners or the global roles,
+// matching Owner.User, Owner.ServiceAccount and expanded RoverGroup members. It returns "" when user has none.
+func (h *Handlers) componentRole(user string, component *types.Component) types.Role {
+	return types.HigherRole(h.ownersRole(user, component.Owners), h.ownersRole(user, h.config().GlobalRoles))
+}
+
+// ownersRole returns the highest role of the owners entries matching user, or "" when none match.
+func (h *Handlers) ownersRole(user string, owners []types.Owner) types.Role {
+	var role types.Role
+	for _, owner := range owners {
+		if owner.Matches(user) || (owner.RoverGroup != "" && slices.Contains(h.groupCache.GetGroupMembers(owner.RoverGroup), user)) {
+			role = types.HigherRole(role, owner.EffectiveRole())
 		}
 	}
-	return identities.UnsortedList()
+	return role
 }
 
-// IsUserAuthorizedForComponent checks if a user is authorized to perform mutating actions on a component.
+// IsGlobalAdmin checks if a user has the admin role in global_roles, which gives access to the admin endpoints.
+func (h *Handlers) IsGlobalAdmin(user string) bool {
+	return h.ownersRole(user, h.config().GlobalRoles) == types.RoleAdmin
+}
+
+// IsUserAuthorizedForComponent checks if a user has any role on a component.
 func (h *Handlers) IsUserAuthorizedForComponent(user string, component *types.Component) bool {
-	return slices.Contains(h.collectAuthorizedIdentities(component), user)
+	return h.componentRole(user, component) != ""
+}
+
+// HasComponentPermission checks if a user's role on a component grants permission.
+func (h *Handlers) HasComponentPermission(user string, component *types.Component, permission types.Permission) bool {
+	return h.componentRole(user, component).Grants(permission)
 }
 
 // HealthJSON returns the health status of the dashboard service.
@@ -150,7 +176,7 @@ func (h *Handlers) GetOutagesJSON(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, outages)
+	respondWithJSON(w, http.StatusOK, h.visibleOutages(r, outages))
 }
 
 // GetSubComponentOutagesJSON retrieves outages for a specific sub-component.
@@ -183,7 +209,7 @@ func (h *Handlers) GetSubComponentOutagesJSON(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, outages)
+	respondWithJSON(w, http.StatusOK, h.visibleOutages(r, outages))
 }
 
 // CreateOutageJSON creates a new outage for a sub-component.
@@ -221,12 +247,6 @@ func (h *Handlers) CreateOutageJSON(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.IsUserAuthorizedForComponent(activeUser, component) {
-		logger.Warn("User not authorized to create outage")
-		respondWithError(w, http.StatusForbidden, "You are not authorized to perform this action on this component")
-		return
-	}
-
 	severity := ""
 	if outageReq.Severity != nil {
 		severity = *outageReq.Severity
@@ -245,13 +265,22 @@ func (h *Handlers) CreateOutageJSON(w http.ResponseWriter, r *http.Request) {
 		description = strings.TrimSpace(*outageReq.Description)
 	}
 
+	descriptionVisibility := types.VisibilityPublic
+	if outageReq.DescriptionVisibility != nil {
+		if descriptionVisibility, ok = parseVisibility(*outageReq.DescriptionVisibility, types.VisibilityPublic); !ok {
+			respondWithError(w, http.StatusBadRequest, invalidVisibilityMessage)
+			return
+		}
+	}
+
 	outage := types.Outage{
-		ComponentName:    componentName,
-		SubComponentName: subComponentName,
-		Severity:         types.Severity(severity),
-		Description:      description,
-		StartTime:        *outageReq.StartTime,
-		DiscoveredFrom:   discoveredFrom,
+		ComponentName:         componentName,
+		SubComponentName:      subComponentName,
+		Severity:              types.Severity(severity),
+		Description:           description,
+		DescriptionVisibility: descriptionVisibility,
+		StartTime:             *outageReq.StartTime,
+		DiscoveredFrom:        discoveredFrom,
 	}
 
 	outage.CreatedBy = activeUser
@@ -275,7 +304,7 @@ func (h *Handlers) CreateOutageJSON(w http.ResponseWriter, r *http.Request) {
 		initialTriageNote = strings.TrimSpace(*outageReq.InitialTriageNote)
 	}
 
-	if err := h.outageManager.CreateOutage(&outage, nil, activeUser, initialTriageNote); err != nil {
+	if err := h.outagesFor(r).CreateOutage(&outage, nil, activeUser, initialTriageNote); err != nil {
 		logger.WithField("error", err).Error("Failed to create outage in database")
 		respondWithError(w, http.StatusInternalServerError, "Failed to create outage")
 		return
@@ -331,12 +360,6 @@ func (h *Handlers) UpdateOutageJSON(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.IsUserAuthorizedForComponent(activeUser, component) {
-		logger.Warn("User not authorized to update outage")
-		respondWithError(w, http.StatusForbidden, "You are not authorized to perform this action on this component")
-		return
-	}
-
 	outage, err := h.outageManager.GetOutageByID(componentName, subComponentName, uint(outageID))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -367,6 +390,14 @@ func (h *Handlers) UpdateOutageJSON(w http.ResponseWriter, r *http.Request) {
 	if updateReq.Description != nil {
 		outage.Description = strings.TrimSpace(*updateReq.Description)
 	}
+	if updateReq.DescriptionVisibility != nil {
+		visibility, ok := parseVisibility(*updateReq.DescriptionVisibility, outage.DescriptionVisibility)
+		if !ok {
+			respondWithError(w, http.StatusBadRequest, invalidVisibilityMessage)
+			return
+		}
+		outage.DescriptionVisibility = visibility
+	}
 	if updateReq.Confirmed != nil {
 		if *updateReq.Confirmed && !outage.ConfirmedAt.Valid {
 			outage.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
@@ -379,7 +410,7 @@ func (h *Handlers) UpdateOutageJSON(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.outageManager.UpdateOutage(outage, activeUser); err != nil {
+	if err := h.outagesFor(r).UpdateOutage(outage, activeUser); err != nil {
 		logger.WithField("error", err).Error("Failed to update outage in database")
 		respondWithError(w, http.StatusInternalServerError, "Failed to update outage")
 		return
@@ -433,7 +464,7 @@ func (h *Handlers) GetOutageJSON(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logger.Info("Successfully retrieved outage")
-	respondWithJSON(w, http.StatusOK, outage)
+	respondWithJSON(w, http.StatusOK, h.visibleOutage(r, *outage))
 }
 
 // DeleteOutage deletes an outage by ID for a specific sub-component.
@@ -468,12 +499,6 @@ func (h *Handlers) DeleteOutage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.IsUserAuthorizedForComponent(activeUser, component) {
-		logger.Warn("User not authorized to delete outage")
-		respondWithError(w, http.StatusForbidden, "You are not authorized to perform this action on this component")
-		return
-	}
-
 	outageID, err := strconv.ParseUint(outageIDStr, 10, 32)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid outage ID")
@@ -491,7 +516,7 @@ func (h *Handlers) DeleteOutage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.outageManager.DeleteOutage(outage, activeUser); err != nil {
+	if err := h.outagesFor(r).DeleteOutage(outage, activeUser); err != nil {
 		logger.WithField("error", err).Error("Failed to delete outage from database")
 		respondWithError(w, http.StatusInternalServerError, "Failed to delete outage")
 		return
@@ -545,12 +570,6 @@ func (h *Handlers) AddTriageNoteJSON(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.IsUserAuthorizedForComponent(activeUser, component) {
-		logger.Warn("User not authorized to add triage note")
-		respondWithError(w, http.StatusForbidden, "You are not authorized to perform this action on this component")
-		return
-	}
-
 	// Scope the outage lookup to this component/sub-component to prevent cross-component access via guessed IDs.
 	if _, err := h.outageManager.GetOutageByID(componentName, subComponentName, uint(outageID)); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -567,13 +586,20 @@ func (h *Handlers) AddTriageNoteJSON(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	visibility, ok := parseVisibility(req.Visibility, types.VisibilityPublic)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, invalidVisibilityMessage)
+		return
+	}
+
 	note := &types.TriageNote{
-		OutageID: uint(outageID),
-		Body:     strings.TrimSpace(req.Body),
-		Author:   activeUser,
+		OutageID:   uint(outageID),
+		Body:       strings.TrimSpace(req.Body),
+		Author:     activeUser,
+		Visibility: visibility,
 	}
 
-	if err := h.outageManager.AddTriageNote(note); err != nil {
+	if err := h.outagesFor(r).AddTriageNote(note); err != nil {
 		logger.WithField("error", err).Error("Failed to add triage note")
 		respondWithError(w, http.StatusInternalServerError, "Failed to add triage note")
 		return
@@ -625,12 +651,15 @@ func (h *Handlers) resolveTriageNote(w http.ResponseWriter, r *http.Request) (ou
 		return 0, 0, "", nil, false
 	}
 
-	if component.GetSubComponentBySlug(subComponentName) == nil {
+	subComponent := component.GetSubComponentBySlug(subComponentName)
+	if subComponent == nil {
 		respondWithError(w, http.StatusNotFound, "Sub-Component not found")
 		return 0, 0, "", nil, false
 	}
 
-	isAdmin := h.IsUserAuthorizedForComponent(activeUser, component)
+	canManage := h.HasComponentPermission(activeUser, component, types.PermissionManageTriageNotes) &&
+		apiTokenAllows(r, types.PermissionManageTriageNotes) &&
+		h.delegationAllows(r, component, subComponent, types.PermissionManageTriageNotes)
 
 	// Verify the outage belongs to this component/sub-component to prevent cross-component access.
 	if _, err := h.outageManager.GetOutageByID(componentName, subComponentName, outageID); err != nil {
@@ -654,9 +683,11 @@ func (h *Handlers) resolveTriageNote(w http.ResponseWriter, r *http.Request) (ou
 		return 0, 0, "", nil, false
 	}
 
-	if !isAdmin && note.Author != activeUser {
+	isAuthor := note.Author == activeUser && apiTokenAllows(r, types.PermissionAddTriageNote) &&
+		h.delegationAllows(r, component, subComponent, types.PermissionAddTriageNote)
+	if !canManage && !isAuthor {
 		logger.Warn("User not authorized to modify triage note")
-		respondWithError(w, http.StatusForbidden, "You are not authorized to perform this action")
+		h.respondForbidden(w, r, types.PermissionManageTriageNotes, "You are not authorized to perform this action")
 		return 0, 0, "", nil, false
 	}
 
@@ -681,8 +712,13 @@ func (h *Handlers) UpdateTriageNoteJSON(w http.ResponseWriter, r *http.Request)
 		respondWithError(w, http.StatusBadRequest, "Body is required")
 		return
 	}
+	visibility, ok := parseVisibility(req.Visibility, "")
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, invalidVisibilityMessage)
+		return
+	}
 
-	updated, err := h.outageManager.UpdateTriageNote(outageID, noteID, body, activeUser)
+	updated, err := h.outagesFor(r).UpdateTriageNote(outageID, noteID, body, visibility, activeUser)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			respondWithError(w, http.StatusNotFound, "Triage note not found")
@@ -704,7 +740,7 @@ func (h *Handlers) DeleteTriageNoteJSON(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.outageManager.DeleteTriageNote(outageID, noteID, activeUser); err != nil {
+	if err := h.outagesFor(r).DeleteTriageNote(outageID, noteID, activeUser); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			respondWithError(w, http.StatusNotFound, "Triage note not found")
 			return
@@ -762,12 +798,6 @@ func (h *Handlers) AddOutageLinkJSON(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.IsUserAuthorizedForComponent(activeUser, component) {
-		logger.Warn("User not authorized to add outage link")
-		respondWithError(w, http.StatusForbidden, "You are not authorized to perform this action on this component")
-		return
-	}
-
 	// Scope the outage lookup to this component/sub-component to prevent cross-component access via guessed IDs.
 	if _, err := h.outageManager.GetOutageByID(componentName, subComponentName, uint(outageID)); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -803,14 +833,21 @@ func (h *Handlers) AddOutageLinkJSON(w http.ResponseWriter, r *http.Request) {
 		description = strings.TrimSpace(req.Description)
 	}
 
+	visibility, ok := parseVisibility(req.Visibility, types.VisibilityPublic)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, invalidVisibilityMessage)
+		return
+	}
+
 	link := &types.OutageLink{
 		OutageID:    uint(outageID),
 		URL:         rawURL,
 		LinkType:    linkType,
 		Description: description,
+		Visibility:  visibility,
 	}
 
-	if err := h.outageManager.AddOutageLink(link, activeUser); err != nil {
+	if err := h.outagesFor(r).AddOutageLink(link, activeUser); err != nil {
 		logger.WithField("error", err).Error("Failed to add outage link")
 		respondWithError(w, http.StatusInternalServerError, "Failed to add outage link")
 		return
@@ -864,12 +901,6 @@ func (h *Handlers) resolveOutageLink(w http.ResponseWriter, r *http.Request) (ou
 		return 0, 0, "", nil, false
 	}
 
-	if !h.IsUserAuthorizedForComponent(activeUser, component) {
-		logger.Warn("User not authorized to modify outage link")
-		respondWithError(w, http.StatusForbidden, "You are not authorized to perform this action on this component")
-		return 0, 0, "", nil, false
-	}
-
 	// Scope the outage lookup to this component/sub-component to prevent cross-component access via guessed IDs.
 	if _, err := h.outageManager.GetOutageByID(componentName, subComponentName, uint(parsedOutageID)); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -920,7 +951,13 @@ func (h *Handlers) UpdateOutageLinkJSON(w http.ResponseWriter, r *http.Request)
 		description = strings.TrimSpace(req.Description)
 	}
 
-	link, err := h.outageManager.UpdateOutageLink(outageID, linkID, rawURL, linkType, description, activeUser)
+	visibility, ok := parseVisibility(req.Visibility, "")
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, invalidVisibilityMessage)
+		return
+	}
+
+	link, err := h.outagesFor(r).UpdateOutageLink(outageID, linkID, rawURL, linkType, description, visibility, activeUser)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			respondWithError(w, http.StatusNotFound, "Link not found")
@@ -942,7 +979,7 @@ func (h *Handlers) DeleteOutageLinkJSON(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.outageManager.DeleteOutageLink(outageID, linkID, activeUser); err != nil {
+	if err := h.outagesFor(r).DeleteOutageLink(outageID, linkID, activeUser); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			respondWithError(w, http.StatusNotFound, "Link not found")
 			return
@@ -991,7 +1028,92 @@ func (h *Handlers) GetOutageAuditLogsJSON(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, auditLogs)
+	entries := make([]types.AuditLogEntry, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		entry := types.NewAuditLogEntry(auditLog)
+		entry.ComponentName = outage.ComponentName
+		entry.SubComponentName = outage.SubComponentName
+		entries = append(entries, entry)
+	}
+	respondWithJSON(w, http.StatusOK, h.visibleAuditLogEntries(r, entries))
+}
+
+const (
+	defaultAuditLogLimit = 100
+	maxAuditLogLimit     = 1000
+)
+
+// GetAuditLogsJSON returns audit logs across all outages, newest first, with field-level changes.
+// Query params: user, operation (CREATE, UPDATE, DELETE), componentName, start and end (RFC3339), limit (default 100, max 1000).
+func (h *Handlers) GetAuditLogsJSON(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := types.AuditLogFilter{
+		User:          q.Get("user"),
+		ComponentSlug: q.Get("componentName"),
+		Limit:         defaultAuditLogLimit,
+	}
+
+	logger := h.logger.WithFields(logrus.Fields{
+		"user":          filter.User,
+		"componentName": filter.ComponentSlug,
+	})
+
+	if operation := q.Get("operation"); operation != "" {
+		switch types.OperationType(strings.ToUpper(operation)) {
+		case types.Create, types.Update, types.Delete:
+			filter.Operation = strings.ToUpper(operation)
+		default:
+			respondWithError(w, http.StatusBadRequest, "operation must be one of: CREATE, UPDATE, DELETE")
+			return
+		}
+	}
+
+	if filter.ComponentSlug != "" && h.config().GetComponentBySlug(filter.ComponentSlug) == nil {
+		respondWithError(w, http.StatusNotFound, "Component not found")
+		return
+	}
+
+	if startStr := q.Get("start"); startStr != "" {
+		start, err := utils.ParseRFC3339OrNanoUTC(startStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid start time")
+			return
+		}
+		filter.Start = start
+	}
+	if endStr := q.Get("end"); endStr != "" {
+		end, err := utils.ParseRFC3339OrNanoUTC(endStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid end time")
+			return
+		}
+		filter.End = end
+	}
+	if !filter.Start.IsZero() && !filter.End.IsZero() && filter.Start.After(filter.End) {
+		respondWithError(w, http.StatusBadRequest, "start must not be after end")
+		return
+	}
+
+	if limitStr := q.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		if limit > maxAuditLogLimit {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must not exceed %d", maxAuditLogLimit))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.visibleAuditLogs(r, filter)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to query audit logs from database")
+		respondWithError(w, http.StatusInternalServerError, "Failed to get audit logs")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, entries)
 }
 
 // GetTriageNotesJSON returns all triage notes for a given outage.
@@ -1029,6 +1151,9 @@ func (h *Handlers) GetTriageNotesJSON(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusInternalServerError, "Failed to get triage notes")
 		return
 	}
+	if !h.viewsInternal(r, componentName, subComponentName) {
+		notes = types.PublicTriageNotes(notes)
+	}
 
 	respondWithJSON(w, http.StatusOK, notes)
 }
@@ -1068,6 +1193,9 @@ func (h *Handlers) GetOutageLinksJSON(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusInternalServerError, "Failed to get outage links")
 		return
 	}
+	if !h.viewsInternal(r, componentName, subComponentName) {
+		links = types.PublicOutageLinks(links)
+	}
 
 	respondWithJSON(w, http.StatusOK, links)
 }
@@ -1109,12 +1237,12 @@ func (h *Handlers) GetSubComponentStatusJSON(w http.ResponseWriter, r *http.Requ
 	response := types.ComponentStatus{
 		ComponentName: fmt.Sprintf("%s/%s", componentName, subComponentName),
 		Status:        active.Status,
-		ActiveOutages: active.Confirmed,
+		ActiveOutages: h.visibleOutages(r, active.Confirmed),
 		LastPingTime:  lastPingTime,
 	}
 
 	if len(active.Suspected) > 0 {
-		s := active.Suspected[0]
+		s := h.visibleOutage(r, active.Suspected[0])
 		reporters := make([]string, len(s.Reports))
 		for i, r := range s.Reports {
 			reporters[i] = r.User
@@ -1149,6 +1277,7 @@ func (h *Handlers) GetComponentStatusJSON(w http.ResponseWriter, r *http.Request
 		respondWithError(w, http.StatusInternalServerError, "Failed to get component status")
 		return
 	}
+	response.ActiveOutages = h.visibleOutages(r, response.ActiveOutages)
 	respondWithJSON(w, http.StatusOK, response)
 }
 
@@ -1165,6 +1294,7 @@ func (h *Handlers) GetAllComponentsStatusJSON(w http.ResponseWriter, r *http.Req
 			respondWithError(w, http.StatusInternalServerError, "Failed to get component status")
 			return
 		}
+		componentStatus.ActiveOutages = h.visibleOutages(r, componentStatus.ActiveOutages)
 
 		allComponentStatuses = append(allComponentStatuses, componentStatus)
 	}
@@ -1243,8 +1373,8 @@ func (h *Handlers) getComponentStatus(component *types.Component, logger *logrus
 	}, nil
 }
 
-// GetSubComponentHistoryJSON returns day-bucketed outage history for a sub-component.
-// Query param: days (int, default 90, max 365).
+// GetSubComponentHistoryJSON returns bucketed outage history for a sub-component.
+// Query params: days, start, end, tz, and granularity (see parseHistoryWindow).
 func (h *Handlers) GetSubComponentHistoryJSON(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	componentSlug := vars["componentName"]
@@ -1265,32 +1395,22 @@ func (h *Handlers) GetSubComponentHistoryJSON(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	days := 90
-	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
-		providedDays, err := strconv.Atoi(daysStr)
-		if err != nil || providedDays <= 0 {
-			respondWithError(w, http.StatusBadRequest, "days must be a positive integer")
-			return
-		}
-		if providedDays > 365 {
-			respondWithError(w, http.StatusBadRequest, "days must not exceed 365")
-			return
-		}
-		days = providedDays
-	}
-
 	now := time.Now().UTC()
-	queryStart := now.AddDate(0, 0, -days)
+	window, errMsg := parseHistoryWindow(r.URL.Query(), now)
+	if errMsg != "" {
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
 	refs := []types.SubComponentRef{{ComponentSlug: componentSlug, SubSlug: subComponentSlug}}
 
-	outages, err := h.outageManager.GetOutagesDuring(queryStart, now, refs)
+	outages, err := h.outageManager.GetOutagesDuring(window.first, window.end, refs)
 	if err != nil {
 		logger.WithField("error", err).Error("Failed to query outage history from database")
 		respondWithError(w, http.StatusInternalServerError, "Failed to get history")
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, buildHistoryBuckets(outages, days, now))
+	respondWithJSON(w, http.StatusOK, buildHistoryBuckets(outages, window, now))
 }
 
 // ListTagsJSON returns the list of configured tags.
@@ -1456,6 +1576,48 @@ func (h *Handlers) statusForSubComponent(componentSlug, subSlug string) (subComp
 	}, nil
 }
 
+// GetOutageHistoryJSON returns history buckets for every sub-component matching the optional
+// componentName, tag, and team filters, using a single outage query.
+func (h *Handlers) GetOutageHistoryJSON(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	componentSlug := q.Get("componentName")
+	tag := q.Get("tag")
+	team := q.Get("team")
+
+	logger := h.logger.WithFields(logrus.Fields{
+		"componentName": componentSlug,
+		"tag":           tag,
+		"team":          team,
+	})
+
+	if componentSlug != "" && h.config().GetComponentBySlug(componentSlug) == nil {
+		respondWithError(w, http.StatusNotFound, "Component not found")
+		return
+	}
+
+	now := time.Now().UTC()
+	window, errMsg := parseHistoryWindow(q, now)
+	if errMsg != "" {
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	refs := h.config().SubComponentRefsMatching(componentSlug, "", tag, team)
+	if len(refs) == 0 {
+		respondWithJSON(w, http.StatusOK, []types.SubComponentHistory{})
+		return
+	}
+
+	outages, err := h.outageManager.GetOutagesDuring(window.first, window.end, refs)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to query outage history from database")
+		respondWithError(w, http.StatusInternalServerError, "Failed to get history")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, buildSubComponentHistories(outages, refs, window, now))
+}
+
 // GetOutagesDuringJSON returns outages overlapping the requested time window (or a single instant when only one of start/end is set).
 func (h *Handlers) GetOutagesDuringJSON(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
@@ -1512,7 +1674,7 @@ func (h *Handlers) GetOutagesDuringJSON(w http.ResponseWriter, r *http.Request)
 	if outages == nil {
 		outages = []types.Outage{}
 	}
-	respondWithJSON(w, http.StatusOK, outages)
+	respondWithJSON(w, http.StatusOK, h.visibleOutages(r, outages))
 }
 
 func (h *Handlers) PostComponentMonitorReportJSON(w http.ResponseWriter, r *http.Request) {
@@ -1567,8 +1729,11 @@ func (h *Handlers) PostComponentMonitorReportJSON(w http.ResponseWriter, r *http
 }
 
 type AuthenticatedUser struct {
-	Username   string   `json:"username" yaml:"username"`
+	Username string `json:"username" yaml:"username"`
+	// Components lists the slugs of the components the user has a role on.
 	Components []string `json:"components" yaml:"components"`
+	// Permissions maps each of those component slugs to the permissions the user's role grants.
+	Permissions map[string][]types.Permission `json:"permissions" yaml:"permissions"`
 }
 
 func (h *Handlers) GetAuthenticatedUserJSON(w http.ResponseWriter, r *http.Request) {
@@ -1579,20 +1744,135 @@ func (h *Handlers) GetAuthenticatedUserJSON(w http.ResponseWriter, r *http.Reque
 	}
 
 	response := AuthenticatedUser{
-		Username:   user,
-		Components: []string{},
+		Username:    user,
+		Components:  []string{},
+		Permissions: map[string][]types.Permission{},
 	}
 
-	// Return only components the user is authorized for
+	// Return only components the user has a role on
 	for _, component := range h.config().Components {
-		if h.IsUserAuthorizedForComponent(user, component) {
+		if role := h.componentRole(user, component); role != "" {
 			response.Components = append(response.Components, component.Slug)
+			response.Permissions[component.Slug] = role.Permissions()
 		}
 	}
 
 	respondWithJSON(w, http.StatusOK, response)
 }
 
+const (
+	defaultActivityDays = 30
+	maxActivityDays     = 365
+)
+
+// GetUserActivityJSON returns the outages the authenticated (or delegated) user created or reported, the triage notes
+// they wrote, and the active outages on components they are authorized for.
+// Query param: days (int, default 30, max 365) bounds how far back resolved items are included; active outages are always returned.
+func (h *Handlers) GetUserActivityJSON(w http.ResponseWriter, r *http.Request) {
+	user, authenticated := GetUserFromContext(r.Context())
+	if !authenticated {
+		respondWithError(w, http.StatusUnauthorized, "No Authenticated user found")
+		return
+	}
+
+	logger := h.logger.WithField("active_user", user)
+
+	days := defaultActivityDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		providedDays, err := strconv.Atoi(daysStr)
+		if err != nil || providedDays <= 0 {
+			respondWithError(w, http.StatusBadRequest, "days must be a positive integer")
+			return
+		}
+		if providedDays > maxActivityDays {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("days must not exceed %d", maxActivityDays))
+			return
+		}
+		days = providedDays
+	}
+	now := time.Now().UTC()
+	since := now.AddDate(0, 0, -days)
+
+	activity := types.UserActivity{
+		Username:           user,
+		CreatedOutages:     []types.Outage{},
+		ReportedOutages:    []types.ReportedOutage{},
+		TriageNotes:        []types.AuthoredTriageNote{},
+		OwnedComponents:    []string{},
+		OwnedActiveOutages: []types.Outage{},
+	}
+
+	created, err := h.outageManager.GetOutagesCreatedBy(user, since)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to query outages created by user")
+		respondWithError(w, http.StatusInternalServerError, "Failed to get user activity")
+		return
+	}
+	activity.CreatedOutages = append(activity.CreatedOutages, created...)
+
+	reported, err := h.outageManager.GetOutagesReportedBy(user, since)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to query outages reported by user")
+		respondWithError(w, http.StatusInternalServerError, "Failed to get user activity")
+		return
+	}
+	for _, o := range reported {
+		activity.ReportedOutages = append(activity.ReportedOutages, reportedOutageFor(o, user, now))
+	}
+
+	notes, err := h.triageNoteRepo.ListTriageNotesByAuthor(user, since)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to query triage notes authored by user")
+		respondWithError(w, http.StatusInternalServerError, "Failed to get user activity")
+		return
+	}
+	activity.TriageNotes = append(activity.TriageNotes, notes...)
+
+	for _, component := range h.config().Components {
+		if !h.IsUserAuthorizedForComponent(user, component) {
+			continue
+		}
+		activity.OwnedComponents = append(activity.OwnedComponents, component.Slug)
+
+		active, err := h.outageManager.GetActiveOutagesForComponent(component.Slug)
+		if err != nil {
+			logger.WithFields(logrus.Fields{"component": component.Slug, "error": err}).Error("Failed to query active outages for owned component")
+			respondWithError(w, http.StatusInternalServerError, "Failed to get user activity")
+			return
+		}
+		suspected, err := h.outageManager.GetActiveSuspectedOutagesForComponent(component.Slug)
+		if err != nil {
+			logger.WithFields(logrus.Fields{"component": component.Slug, "error": err}).Error("Failed to query suspected outages for owned component")
+			respondWithError(w, http.StatusInternalServerError, "Failed to get user activity")
+			return
+		}
+		activity.OwnedActiveOutages = append(activity.OwnedActiveOutages, active...)
+		activity.OwnedActiveOutages = append(activity.OwnedActiveOutages, suspected...)
+	}
+
+	respondWithJSON(w, http.StatusOK, activity)
+}
+
+// reportedOutageFor pairs an outage with user's report on it and derives the outage's current state.
+func reportedOutageFor(o types.Outage, user string, now time.Time) types.ReportedOutage {
+	reported := types.ReportedOutage{Outage: o}
+	for _, report := range o.Reports {
+		if report.User == user {
+			reported.ReportedAt = report.CreatedAt
+			break
+		}
+	}
+	switch {
+	case o.EndTime.Valid && !o.EndTime.Time.After(now):
+		reported.State = types.ReportedOutageResolved
+	case o.Severity != types.SeveritySuspected || o.ConfirmedAt.Valid:
+		reported.State = types.ReportedOutageConfirmed
+	default:
+		reported.State = types.ReportedOutageOpen
+	}
+	return reported
+}
+
 type reportSuspectedResponse struct {
 	Outage      *types.Outage `json:"outage"`
 	ReportCount int64         `json:"report_count"`
@@ -1660,7 +1940,7 @@ func (h *Handlers) ReportSuspectedOutageJSON(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
-	result, err := h.outageManager.ReportSuspectedOutage(componentName, subComponentName, strings.TrimSpace(req.Description), activeUser, subComponent.ReportThreshold)
+	result, err := h.outagesFor(r).ReportSuspectedOutage(componentName, subComponentName, strings.TrimSpace(req.Description), activeUser, subComponent.ReportThreshold)
 	if err != nil {
 		logger.WithField("error", err).Error("Failed to process suspected outage report")
 		respondWithError(w, http.StatusInternalServerError, "Failed to process report")
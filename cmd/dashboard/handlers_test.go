@@ -1,16 +1,20 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 
 	"ship-status-dash/pkg/auth"
 	"ship-status-dash/pkg/config"
@@ -36,8 +40,11 @@ func newTestHandlersWithGroups(t *testing.T, cfg *types.DashboardConfig, om outa
 	pingRepo := &repositories.MockComponentPingRepository{}
 	triageNoteRepo := &repositories.MockTriageNoteRepository{}
 	outageLinkRepo := &repositories.MockOutageLinkRepository{}
+	watchRepo := &repositories.MockWatchSubscriptionRepository{}
+	apiTokenRepo := &repositories.MockAPITokenRepository{}
+	securityEventRepo := &repositories.MockSecurityEventRepository{}
 	cache := &auth.MockGroupMembershipProvider{Groups: groups}
-	return NewHandlers(logrus.New(), cfgManager, om, pingRepo, triageNoteRepo, outageLinkRepo, cache)
+	return NewHandlers(logrus.New(), cfgManager, om, pingRepo, triageNoteRepo, outageLinkRepo, watchRepo, apiTokenRepo, securityEventRepo, cache)
 }
 
 // minimalDashboardConfig is a tiny valid config (one component, one sub-component) for handler tests.
@@ -101,6 +108,154 @@ func TestIsUserAuthorizedForComponent(t *testing.T) {
 	}
 }
 
+func TestHasComponentPermission(t *testing.T) {
+	component := &types.Component{
+		Name: "Test", Slug: "test",
+		Owners: []types.Owner{
+			{User: "admin"},
+			{User: "responder", Role: types.RoleResponder},
+			{RoverGroup: "helpers", Role: types.RoleTriager},
+		},
+	}
+	cfg := &types.DashboardConfig{
+		Components:  []*types.Component{component},
+		GlobalRoles: []types.Owner{{RoverGroup: "sre", Role: types.RoleResponder}, {User: "auditor", Role: types.RoleViewer}},
+	}
+	groups := map[string][]string{"helpers": {"helper", "responder"}, "sre": {"sre-member", "helper"}}
+	h := newTestHandlersWithGroups(t, cfg, &outage.MockOutageManager{}, groups)
+
+	tests := []struct {
+		user    string
+		granted []types.Permission
+		denied  []types.Permission
+	}{
+		{
+			user:    "admin",
+			granted: []types.Permission{types.PermissionDeleteOutage, types.PermissionManageTriageNotes},
+		},
+		{
+			user:    "responder",
+			granted: []types.Permission{types.PermissionCreateOutage, types.PermissionUpdateOutage, types.PermissionAddTriageNote},
+			denied:  []types.Permission{types.PermissionDeleteOutage, types.PermissionManageTriageNotes},
+		},
+		{
+			user:    "helper",
+			granted: []types.Permission{types.PermissionAddTriageNote, types.PermissionCreateOutage},
+			denied:  []types.Permission{types.PermissionDeleteOutage},
+		},
+		{
+			user:    "auditor",
+			granted: []types.Permission{types.PermissionView},
+			denied:  []types.Permission{types.PermissionAddTriageNote},
+		},
+		{
+			user:   "stranger",
+			denied: []types.Permission{types.PermissionView},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.user, func(t *testing.T) {
+			for _, permission := range tt.granted {
+				assert.True(t, h.HasComponentPermission(tt.user, component, permission), permission)
+			}
+			for _, permission := range tt.denied {
+				assert.False(t, h.HasComponentPermission(tt.user, component, permission), permission)
+			}
+		})
+	}
+}
+
+func TestRequireComponentPermission(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.Components[0].Owners = []types.Owner{{User: "alice", Role: types.RoleTriager}, {User: "carol", Role: types.RoleResponder}}
+	h := newTestHandlers(t, cfg, &outage.MockOutageManager{})
+	handler := h.requireComponentPermission(types.PermissionCreateOutage, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name       string
+		user       string
+		component  string
+		wantStatus int
+	}{
+		{name: "role grants permission", user: "carol", component: "alpha", wantStatus: http.StatusNoContent},
+		{name: "role lacks permission", user: "alice", component: "alpha", wantStatus: http.StatusForbidden},
+		{name: "no role", user: "bob", component: "alpha", wantStatus: http.StatusForbidden},
+		{name: "unknown component is left to the handler", user: "bob", component: "missing", wantStatus: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/components/"+tt.component+"/one/outages", nil)
+			req = mux.SetURLVars(req, map[string]string{"componentName": tt.component, "subComponentName": "one"})
+			req = req.WithContext(context.WithValue(req.Context(), userContextKey, tt.user))
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestRoutePermissions(t *testing.T) {
+	s := &Server{handlers: newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})}
+	// These routes are open to any authenticated user: authors may edit their own triage notes, anyone may
+	// report a suspected outage, and component monitor reports are checked per component by the handler.
+	checkedByHandler := map[string]bool{
+		"PATCH /api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/triage-notes/{noteId:[0-9]+}":  true,
+		"DELETE /api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/triage-notes/{noteId:[0-9]+}": true,
+		"POST /api/components/{componentName}/{subComponentName}/outages/report-suspected":                                 true,
+	}
+	for _, r := range s.routes() {
+		if !r.protected || !strings.Contains(r.path, "{componentName}") {
+			assert.Empty(t, r.permission, "%s %s has no component to check a permission on", r.method, r.path)
+			continue
+		}
+		key := r.method + " " + r.path
+		if checkedByHandler[key] {
+			assert.Empty(t, r.permission, key)
+		} else {
+			assert.NotEmpty(t, r.permission, "%s must require a permission", key)
+		}
+	}
+	for _, r := range s.routes() {
+		// Only the triage note handlers apply delegation rules themselves, through resolveTriageNote.
+		assert.Equal(t, strings.HasSuffix(r.path, "/triage-notes/{noteId:[0-9]+}"), r.checksDelegation, "%s %s", r.method, r.path)
+		if r.tokenScope != "" {
+			assert.True(t, r.protected && r.permission == "", "%s %s only needs a token scope without a permission", r.method, r.path)
+		}
+		if strings.HasPrefix(r.path, "/api/admin/") {
+			assert.True(t, r.protected && r.admin, "%s %s must be restricted to admins", r.method, r.path)
+		}
+	}
+}
+
+func TestGetAuthenticatedUserJSON(t *testing.T) {
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{Name: "Alpha", Slug: "alpha", Owners: []types.Owner{{User: "alice", Role: types.RoleTriager}}},
+			{Name: "Beta", Slug: "beta", Owners: []types.Owner{{User: "bob"}}},
+			{Name: "Gamma", Slug: "gamma", Owners: []types.Owner{{User: "alice"}}},
+		},
+	}
+	h := newTestHandlers(t, cfg, &outage.MockOutageManager{})
+	req := httptest.NewRequest(http.MethodGet, "/api/user", nil)
+	req = req.WithContext(context.WithValue(req.Context(), userContextKey, "alice"))
+	rec := httptest.NewRecorder()
+	h.GetAuthenticatedUserJSON(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var got AuthenticatedUser
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, AuthenticatedUser{
+		Username:   "alice",
+		Components: []string{"alpha", "gamma"},
+		Permissions: map[string][]types.Permission{
+			"alpha": {types.PermissionView, types.PermissionAddTriageNote, types.PermissionWriteLink},
+			"gamma": types.RoleAdmin.Permissions(),
+		},
+	}, got)
+}
+
 func TestGetComponentStatusJSON_CriticalSubComponent(t *testing.T) {
 	now := time.Now()
 	cfg := &types.DashboardConfig{
@@ -334,3 +489,218 @@ func TestParseStatusFilters(t *testing.T) {
 		})
 	}
 }
+
+func TestGetOutageHistoryJSON(t *testing.T) {
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Name: "Alpha", Slug: "alpha", ShipTeam: "team-a",
+				Subcomponents: []types.SubComponent{
+					{Name: "One", Slug: "one", Tags: []string{"ci"}},
+					{Name: "Two", Slug: "two"},
+				},
+			},
+			{
+				Name: "Beta", Slug: "beta", ShipTeam: "team-b",
+				Subcomponents: []types.SubComponent{
+					{Name: "One", Slug: "one", Tags: []string{"ci"}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantRefs  []types.SubComponentRef
+		wantCalls int
+	}{
+		{
+			name:     "all sub-components",
+			query:    "days=2",
+			wantCode: http.StatusOK,
+			wantRefs: []types.SubComponentRef{
+				{ComponentSlug: "alpha", SubSlug: "one"},
+				{ComponentSlug: "alpha", SubSlug: "two"},
+				{ComponentSlug: "beta", SubSlug: "one"},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:      "filtered by component",
+			query:     "componentName=alpha&days=2",
+			wantCode:  http.StatusOK,
+			wantRefs:  []types.SubComponentRef{{ComponentSlug: "alpha", SubSlug: "one"}, {ComponentSlug: "alpha", SubSlug: "two"}},
+			wantCalls: 1,
+		},
+		{
+			name:      "filtered by tag and team",
+			query:     "tag=ci&team=team-b&days=2",
+			wantCode:  http.StatusOK,
+			wantRefs:  []types.SubComponentRef{{ComponentSlug: "beta", SubSlug: "one"}},
+			wantCalls: 1,
+		},
+		{
+			name:     "no matches skips the query",
+			query:    "tag=nonexistent&days=2",
+			wantCode: http.StatusOK,
+			wantRefs: []types.SubComponentRef{},
+		},
+		{
+			name:     "unknown component",
+			query:    "componentName=nope",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "invalid granularity",
+			query:    "granularity=month",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			mockOM := &outage.MockOutageManager{}
+			mockOM.GetOutagesDuringFn = func(queryStart, queryEnd time.Time, refs []types.SubComponentRef) ([]types.Outage, error) {
+				calls++
+				assert.Equal(t, tt.wantRefs, refs)
+				return []types.Outage{{
+					ComponentName:    "alpha",
+					SubComponentName: "one",
+					Severity:         types.SeverityDown,
+					StartTime:        queryEnd.Add(-time.Hour),
+				}}, nil
+			}
+
+			h := newTestHandlers(t, cfg, mockOM)
+			req := httptest.NewRequest(http.MethodGet, "/api/outage-history?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			h.GetOutageHistoryJSON(rec, req)
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var got []types.SubComponentHistory
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+			require.Len(t, got, len(tt.wantRefs))
+			for i, history := range got {
+				assert.Equal(t, tt.wantRefs[i].ComponentSlug, history.ComponentName)
+				assert.Equal(t, tt.wantRefs[i].SubSlug, history.SubComponentName)
+				require.Len(t, history.Buckets, 2)
+				last := history.Buckets[len(history.Buckets)-1]
+				if history.ComponentName == "alpha" && history.SubComponentName == "one" {
+					assert.Equal(t, 1, last.OutageCount)
+				} else {
+					assert.Equal(t, 0, last.OutageCount)
+				}
+			}
+		})
+	}
+}
+
+func TestGetUserActivityJSON(t *testing.T) {
+	now := time.Now().UTC()
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Name: "Alpha", Slug: "alpha",
+				Owners:        []types.Owner{{User: "alice"}},
+				Subcomponents: []types.SubComponent{{Name: "One", Slug: "one"}},
+			},
+			{
+				Name: "Beta", Slug: "beta",
+				Owners:        []types.Owner{{User: "bob"}},
+				Subcomponents: []types.SubComponent{{Name: "One", Slug: "one"}},
+			},
+		},
+	}
+
+	mockOM := &outage.MockOutageManager{}
+	mockOM.GetOutagesCreatedByFn = func(createdBy string, since time.Time) ([]types.Outage, error) {
+		assert.Equal(t, "alice", createdBy)
+		assert.WithinDuration(t, now.AddDate(0, 0, -7), since, time.Minute)
+		return []types.Outage{{ComponentName: "beta", SubComponentName: "one", CreatedBy: "alice"}}, nil
+	}
+	mockOM.GetOutagesReportedByFn = func(user string, since time.Time) ([]types.Outage, error) {
+		report := func(u string) types.OutageReport {
+			return types.OutageReport{Model: gorm.Model{CreatedAt: now.Add(-time.Hour)}, User: u}
+		}
+		return []types.Outage{
+			{Model: gorm.Model{ID: 1}, Severity: types.SeveritySuspected, Reports: []types.OutageReport{report("carol"), report("alice")}},
+			{Model: gorm.Model{ID: 2}, Severity: types.SeverityDegraded, Reports: []types.OutageReport{report("alice")}},
+			{
+				Model:    gorm.Model{ID: 3},
+				Severity: types.SeveritySuspected,
+				EndTime:  sql.NullTime{Time: now.Add(-time.Minute), Valid: true},
+				Reports:  []types.OutageReport{report("alice")},
+			},
+		}, nil
+	}
+	mockOM.GetActiveOutagesForComponentFn = func(slug string) ([]types.Outage, error) {
+		return []types.Outage{{ComponentName: slug, Severity: types.SeverityDown}}, nil
+	}
+	mockOM.GetActiveSuspectedOutagesForComponentFn = func(slug string) ([]types.Outage, error) {
+		return []types.Outage{{ComponentName: slug, Severity: types.SeveritySuspected}}, nil
+	}
+
+	h := newTestHandlers(t, cfg, mockOM)
+	h.triageNoteRepo = &repositories.MockTriageNoteRepository{
+		ListTriageNotesByAuthorFn: func(author string, since time.Time) ([]types.AuthoredTriageNote, error) {
+			return []types.AuthoredTriageNote{{
+				TriageNote:       types.TriageNote{OutageID: 2, Body: "on it", Author: author},
+				ComponentName:    "beta",
+				SubComponentName: "one",
+			}}, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/activity?days=7", nil)
+	req = req.WithContext(context.WithValue(req.Context(), userContextKey, "alice"))
+	rec := httptest.NewRecorder()
+	h.GetUserActivityJSON(rec, req)
+	res := rec.Result()
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var got types.UserActivity
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+	assert.Equal(t, "alice", got.Username)
+	assert.Len(t, got.CreatedOutages, 1)
+	require.Len(t, got.ReportedOutages, 3)
+	assert.Equal(t, types.ReportedOutageOpen, got.ReportedOutages[0].State)
+	assert.WithinDuration(t, now.Add(-time.Hour), got.ReportedOutages[0].ReportedAt, time.Second)
+	assert.Equal(t, types.ReportedOutageConfirmed, got.ReportedOutages[1].State)
+	assert.Equal(t, types.ReportedOutageResolved, got.ReportedOutages[2].State)
+	require.Len(t, got.TriageNotes, 1)
+	assert.Equal(t, "beta", got.TriageNotes[0].ComponentName)
+	assert.Equal(t, []string{"alpha"}, got.OwnedComponents)
+	require.Len(t, got.OwnedActiveOutages, 2)
+	for _, o := range got.OwnedActiveOutages {
+		assert.Equal(t, "alpha", o.ComponentName)
+	}
+}
+
+func TestGetUserActivityJSON_Errors(t *testing.T) {
+	h := newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.GetUserActivityJSON(rec, httptest.NewRequest(http.MethodGet, "/api/user/activity", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("invalid days", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/user/activity?days=0", nil)
+		req = req.WithContext(context.WithValue(req.Context(), userContextKey, "alice"))
+		rec := httptest.NewRecorder()
+		h.GetUserActivityJSON(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
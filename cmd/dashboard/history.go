@@ -1,10 +1,14 @@
 package main
 
 import (
+	"fmt"
+	"net/url"
 	"sort"
+	"strconv"
 	"time"
 
 	"ship-status-dash/pkg/types"
+	"ship-status-dash/pkg/utils"
 )
 
 type timeInterval struct {
@@ -34,27 +38,207 @@ func mergedDuration(intervals []timeInterval) time.Duration {
 	return total + curEnd.Sub(curStart)
 }
 
-type dayBucket struct {
-	dayStart, nextDay time.Time
-	intervals         []timeInterval
-	highestSeverity   *types.Severity
-	count             int
+// historyGranularity is the width of each bucket in an outage history response.
+type historyGranularity string
+
+const (
+	granularityHour historyGranularity = "hour"
+	granularityDay  historyGranularity = "day"
+	granularityWeek historyGranularity = "week"
+)
+
+const (
+	defaultHistoryDays     = 90
+	defaultHourHistoryDays = 2
+	maxHistoryDays         = 365
+	// maxHistoryBuckets bounds the response size; it allows a full year of daily buckets
+	// or about a month of hourly buckets.
+	maxHistoryBuckets = 24 * 31
+)
+
+// historyWindow describes the buckets requested for an outage history response.
+// Buckets start at first and cover whole granularity units until end; end itself is
+// the instant after which outage time is no longer counted.
+type historyWindow struct {
+	first       time.Time
+	end         time.Time
+	granularity historyGranularity
+}
+
+// bucketStart returns the start of the bucket containing t, in t's location.
+// Weeks start on Monday.
+func (g historyGranularity) bucketStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch g {
+	case granularityHour:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+	case granularityWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// next returns the start of the bucket that follows the bucket starting at t.
+// Calendar arithmetic keeps day and week buckets aligned across DST transitions.
+func (g historyGranularity) next(t time.Time) time.Time {
+	switch g {
+	case granularityHour:
+		return t.Add(time.Hour)
+	case granularityWeek:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// prev returns the start of the bucket that precedes the bucket starting at t.
+func (g historyGranularity) prev(t time.Time) time.Time {
+	switch g {
+	case granularityHour:
+		return g.bucketStart(t.Add(-time.Hour))
+	case granularityWeek:
+		return t.AddDate(0, 0, -7)
+	default:
+		return t.AddDate(0, 0, -1)
+	}
+}
+
+// bucketsForDays converts a days look-back into a bucket count for the granularity.
+func (g historyGranularity) bucketsForDays(days int) int {
+	switch g {
+	case granularityHour:
+		return days * 24
+	case granularityWeek:
+		return (days + 6) / 7
+	default:
+		return days
+	}
 }
 
-// buildHistoryBuckets aggregates outages into one bucket per calendar day for the past `days` days.
-func buildHistoryBuckets(outages []types.Outage, days int, now time.Time) []types.OutageDayBucket {
-	y, m, d := now.Date()
-	loc := now.Location()
+// parseHistoryWindow builds the history window from the query parameters days, start, end, tz and granularity.
+// days selects the most recent buckets ending with the one containing now; start/end select an explicit range
+// and cannot be combined with days. Returns a non-empty error message for invalid input.
+func parseHistoryWindow(q url.Values, now time.Time) (historyWindow, string) {
+	loc := time.UTC
+	if tz := q.Get("tz"); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return historyWindow{}, fmt.Sprintf("invalid tz: %s", tz)
+		}
+		loc = l
+	}
+	now = now.In(loc)
+
+	granularity := granularityDay
+	if g := q.Get("granularity"); g != "" {
+		switch historyGranularity(g) {
+		case granularityHour, granularityDay, granularityWeek:
+			granularity = historyGranularity(g)
+		default:
+			return historyWindow{}, "granularity must be one of: hour, day, week"
+		}
+	}
+
+	daysStr := q.Get("days")
+	startStr := q.Get("start")
+	endStr := q.Get("end")
+	if daysStr != "" && (startStr != "" || endStr != "") {
+		return historyWindow{}, "days cannot be combined with start or end"
+	}
+
+	window := historyWindow{granularity: granularity}
+	if startStr == "" && endStr == "" {
+		days := defaultHistoryDays
+		if granularity == granularityHour {
+			days = defaultHourHistoryDays
+		}
+		if daysStr != "" {
+			providedDays, err := strconv.Atoi(daysStr)
+			if err != nil || providedDays <= 0 {
+				return historyWindow{}, "days must be a positive integer"
+			}
+			if providedDays > maxHistoryDays {
+				return historyWindow{}, fmt.Sprintf("days must not exceed %d", maxHistoryDays)
+			}
+			days = providedDays
+		}
+		window.end = now
+		window.first = granularity.bucketStart(now)
+		for i := 1; i < granularity.bucketsForDays(days); i++ {
+			window.first = granularity.prev(window.first)
+		}
+	} else {
+		window.end = now
+		if endStr != "" {
+			end, err := utils.ParseRFC3339OrNanoUTC(endStr)
+			if err != nil {
+				return historyWindow{}, "invalid end time"
+			}
+			window.end = end.In(loc)
+		}
+		start := window.end.AddDate(0, 0, -defaultHistoryDays)
+		if startStr != "" {
+			parsed, err := utils.ParseRFC3339OrNanoUTC(startStr)
+			if err != nil {
+				return historyWindow{}, "invalid start time"
+			}
+			start = parsed.In(loc)
+		}
+		if !start.Before(window.end) {
+			return historyWindow{}, "start must be before end"
+		}
+		if start.AddDate(0, 0, maxHistoryDays).Before(window.end) {
+			return historyWindow{}, fmt.Sprintf("range must not exceed %d days", maxHistoryDays)
+		}
+		window.first = granularity.bucketStart(start)
+	}
+
+	if window.bucketCount() > maxHistoryBuckets {
+		return historyWindow{}, fmt.Sprintf("range produces more than %d buckets, use a coarser granularity or a shorter range", maxHistoryBuckets)
+	}
+	return window, ""
+}
 
-	buckets := make([]dayBucket, days)
-	for i := range buckets {
-		offset := days - 1 - i
-		dayStart := time.Date(y, m, d-offset, 0, 0, 0, 0, loc)
-		buckets[i] = dayBucket{
-			dayStart: dayStart,
-			nextDay:  dayStart.AddDate(0, 0, 1),
+// bucketCount returns the number of buckets between first and end.
+func (w historyWindow) bucketCount() int {
+	n := 0
+	for t := w.first; t.Before(w.end); t = w.granularity.next(t) {
+		n++
+		if n > maxHistoryBuckets {
+			break
 		}
 	}
+	return n
+}
+
+type historyBucket struct {
+	start, end      time.Time
+	intervals       map[types.Severity][]timeInterval
+	highestSeverity *types.Severity
+	count           int
+}
+
+// historySeverities lists the severities that can appear in history, most critical first.
+var historySeverities = []types.Severity{
+	types.SeverityDown,
+	types.SeverityDegraded,
+	types.SeverityCapacityExhausted,
+	types.SeveritySuspected,
+}
+
+// buildHistoryBuckets aggregates outages into one bucket per granularity unit of the window.
+// Ongoing outages are counted up to now, and no outage time is counted past the window end.
+func buildHistoryBuckets(outages []types.Outage, window historyWindow, now time.Time) []types.OutageHistoryBucket {
+	var buckets []historyBucket
+	for t := window.first; t.Before(window.end); t = window.granularity.next(t) {
+		buckets = append(buckets, historyBucket{
+			start:     t,
+			end:       window.granularity.next(t),
+			intervals: make(map[types.Severity][]timeInterval),
+		})
+	}
 
 	for _, o := range outages {
 		start := o.StartTime
@@ -64,13 +248,16 @@ func buildHistoryBuckets(outages []types.Outage, days int, now time.Time) []type
 		} else {
 			end = now
 		}
+		if end.After(window.end) {
+			end = window.end
+		}
 
 		level := types.GetSeverityLevel(o.Severity)
 
 		for i := range buckets {
 			b := &buckets[i]
 
-			if !start.Before(b.nextDay) || !end.After(b.dayStart) {
+			if !start.Before(b.end) || !end.After(b.start) {
 				continue
 			}
 
@@ -81,33 +268,77 @@ func buildHistoryBuckets(outages []types.Outage, days int, now time.Time) []type
 			}
 
 			clippedStart := start
-			if clippedStart.Before(b.dayStart) {
-				clippedStart = b.dayStart
+			if clippedStart.Before(b.start) {
+				clippedStart = b.start
 			}
 			clippedEnd := end
-			if clippedEnd.After(b.nextDay) {
-				clippedEnd = b.nextDay
+			if clippedEnd.After(b.end) {
+				clippedEnd = b.end
 			}
 			if clippedEnd.After(clippedStart) {
-				b.intervals = append(b.intervals, timeInterval{clippedStart, clippedEnd})
+				b.intervals[o.Severity] = append(b.intervals[o.Severity], timeInterval{clippedStart, clippedEnd})
 			}
 		}
 	}
 
-	result := make([]types.OutageDayBucket, days)
+	result := make([]types.OutageHistoryBucket, len(buckets))
 	for i, b := range buckets {
 		var highestSeverity *string
 		if b.highestSeverity != nil {
 			s := string(*b.highestSeverity)
 			highestSeverity = &s
 		}
-		result[i] = types.OutageDayBucket{
-			Date:               b.dayStart.Format("2006-01-02"),
+		severityMinutes, total := severityBreakdown(b.intervals)
+		result[i] = types.OutageHistoryBucket{
+			Date:               b.start.Format("2006-01-02"),
+			Start:              b.start,
+			End:                b.end,
 			HighestSeverity:    highestSeverity,
-			TotalOutageMinutes: mergedDuration(b.intervals).Minutes(),
+			TotalOutageMinutes: total.Minutes(),
+			SeverityMinutes:    severityMinutes,
 			OutageCount:        b.count,
 		}
 	}
 
 	return result
 }
+
+// severityBreakdown attributes each covered minute to the most critical severity active at that time,
+// so the per-severity minutes sum to the merged total.
+func severityBreakdown(intervals map[types.Severity][]timeInterval) (map[string]float64, time.Duration) {
+	minutes := make(map[string]float64)
+	var cumulative []timeInterval
+	var covered time.Duration
+	for _, sev := range historySeverities {
+		if len(intervals[sev]) == 0 {
+			continue
+		}
+		cumulative = append(cumulative, intervals[sev]...)
+		total := mergedDuration(cumulative)
+		if total > covered {
+			minutes[string(sev)] = (total - covered).Minutes()
+		}
+		covered = total
+	}
+	return minutes, covered
+}
+
+// buildSubComponentHistories builds history buckets for every ref from a single set of outages,
+// preserving the order of refs.
+func buildSubComponentHistories(outages []types.Outage, refs []types.SubComponentRef, window historyWindow, now time.Time) []types.SubComponentHistory {
+	byRef := make(map[types.SubComponentRef][]types.Outage)
+	for _, o := range outages {
+		ref := types.SubComponentRef{ComponentSlug: o.ComponentName, SubSlug: o.SubComponentName}
+		byRef[ref] = append(byRef[ref], o)
+	}
+
+	result := make([]types.SubComponentHistory, 0, len(refs))
+	for _, ref := range refs {
+		result = append(result, types.SubComponentHistory{
+			ComponentName:    ref.ComponentSlug,
+			SubComponentName: ref.SubSlug,
+			Buckets:          buildHistoryBuckets(byRef[ref], window, now),
+		})
+	}
+	return result
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"database/sql"
+	"net/url"
 	"testing"
 	"time"
 
@@ -73,9 +74,11 @@ func TestMergedDuration(t *testing.T) {
 func TestBuildHistoryBuckets(t *testing.T) {
 	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
 	days := 3
+	window, errMsg := parseHistoryWindow(url.Values{"days": {"3"}}, now)
+	require.Empty(t, errMsg)
 
 	t.Run("no outages produces all healthy buckets", func(t *testing.T) {
-		buckets := buildHistoryBuckets(nil, days, now)
+		buckets := buildHistoryBuckets(nil, window, now)
 		require.Len(t, buckets, days)
 		for _, b := range buckets {
 			assert.Equal(t, 0, b.OutageCount)
@@ -96,7 +99,7 @@ func TestBuildHistoryBuckets(t *testing.T) {
 			},
 		}
 
-		buckets := buildHistoryBuckets(outages, days, now)
+		buckets := buildHistoryBuckets(outages, window, now)
 		require.Len(t, buckets, days)
 
 		assert.Equal(t, "2024-01-08", buckets[0].Date)
@@ -123,7 +126,7 @@ func TestBuildHistoryBuckets(t *testing.T) {
 			},
 		}
 
-		buckets := buildHistoryBuckets(outages, days, now)
+		buckets := buildHistoryBuckets(outages, window, now)
 		last := buckets[len(buckets)-1]
 		assert.Equal(t, 1, last.OutageCount)
 		assert.InDelta(t, 30.0, last.TotalOutageMinutes, 0.01)
@@ -141,7 +144,7 @@ func TestBuildHistoryBuckets(t *testing.T) {
 			},
 		}
 
-		buckets := buildHistoryBuckets(outages, days, now)
+		buckets := buildHistoryBuckets(outages, window, now)
 		require.Len(t, buckets, days)
 
 		jan8 := buckets[0]
@@ -171,11 +174,152 @@ func TestBuildHistoryBuckets(t *testing.T) {
 			},
 		}
 
-		buckets := buildHistoryBuckets(outages, days, now)
+		buckets := buildHistoryBuckets(outages, window, now)
 		b := buckets[1] // Jan 9
 		assert.Equal(t, 2, b.OutageCount)
 		assert.InDelta(t, 120.0, b.TotalOutageMinutes, 0.01)
 		require.NotNil(t, b.HighestSeverity)
 		assert.Equal(t, "Down", *b.HighestSeverity, "highest severity should be Down")
 	})
+
+	t.Run("severity minutes are attributed to the most severe active outage", func(t *testing.T) {
+		// Down 00:00-01:00 overlaps Degraded 00:30-02:00: 60 min Down, 60 min Degraded.
+		day := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+		outages := []types.Outage{
+			{
+				Severity:  types.SeverityDegraded,
+				StartTime: day.Add(30 * time.Minute),
+				EndTime:   sql.NullTime{Time: day.Add(120 * time.Minute), Valid: true},
+			},
+			{
+				Severity:  types.SeverityDown,
+				StartTime: day,
+				EndTime:   sql.NullTime{Time: day.Add(60 * time.Minute), Valid: true},
+			},
+		}
+
+		buckets := buildHistoryBuckets(outages, window, now)
+		b := buckets[1]
+		assert.InDelta(t, 60.0, b.SeverityMinutes["Down"], 0.01)
+		assert.InDelta(t, 60.0, b.SeverityMinutes["Degraded"], 0.01)
+		assert.NotContains(t, b.SeverityMinutes, "Suspected")
+		assert.Empty(t, buckets[0].SeverityMinutes)
+	})
+}
+
+func TestBuildHistoryBuckets_Granularity(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 30, 0, 0, time.UTC)
+
+	t.Run("day buckets follow the requested time zone", func(t *testing.T) {
+		window, errMsg := parseHistoryWindow(url.Values{"days": {"2"}, "tz": {"America/New_York"}}, now)
+		require.Empty(t, errMsg)
+
+		// 03:00-05:00 UTC on Jan 10 is 22:00-00:00 on Jan 9 in New York.
+		outages := []types.Outage{
+			{
+				Severity:  types.SeverityDown,
+				StartTime: time.Date(2024, 1, 10, 3, 0, 0, 0, time.UTC),
+				EndTime:   sql.NullTime{Time: time.Date(2024, 1, 10, 5, 0, 0, 0, time.UTC), Valid: true},
+			},
+		}
+
+		buckets := buildHistoryBuckets(outages, window, now)
+		require.Len(t, buckets, 2)
+		assert.Equal(t, "2024-01-09", buckets[0].Date)
+		assert.Equal(t, time.Date(2024, 1, 9, 5, 0, 0, 0, time.UTC), buckets[0].Start.UTC())
+		assert.InDelta(t, 120.0, buckets[0].TotalOutageMinutes, 0.01)
+		assert.Equal(t, "2024-01-10", buckets[1].Date)
+		assert.Equal(t, 0, buckets[1].OutageCount)
+	})
+
+	t.Run("hour buckets", func(t *testing.T) {
+		window, errMsg := parseHistoryWindow(url.Values{"days": {"1"}, "granularity": {"hour"}}, now)
+		require.Empty(t, errMsg)
+
+		outages := []types.Outage{
+			{
+				Severity:  types.SeverityDegraded,
+				StartTime: time.Date(2024, 1, 10, 10, 45, 0, 0, time.UTC),
+				EndTime:   sql.NullTime{Valid: false},
+			},
+		}
+
+		buckets := buildHistoryBuckets(outages, window, now)
+		require.Len(t, buckets, 24)
+		assert.Equal(t, time.Date(2024, 1, 9, 13, 0, 0, 0, time.UTC), buckets[0].Start)
+		last := buckets[len(buckets)-1]
+		assert.Equal(t, time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC), last.Start)
+		assert.InDelta(t, 30.0, last.TotalOutageMinutes, 0.01)
+		assert.InDelta(t, 60.0, buckets[len(buckets)-2].TotalOutageMinutes, 0.01)
+		assert.InDelta(t, 15.0, buckets[len(buckets)-3].TotalOutageMinutes, 0.01)
+	})
+
+	t.Run("week buckets start on Monday", func(t *testing.T) {
+		window, errMsg := parseHistoryWindow(url.Values{"days": {"14"}, "granularity": {"week"}}, now)
+		require.Empty(t, errMsg)
+
+		buckets := buildHistoryBuckets(nil, window, now)
+		require.Len(t, buckets, 2)
+		assert.Equal(t, "2024-01-01", buckets[0].Date)
+		assert.Equal(t, "2024-01-08", buckets[1].Date)
+	})
+
+	t.Run("explicit range stops counting at end", func(t *testing.T) {
+		window, errMsg := parseHistoryWindow(url.Values{
+			"start": {"2024-01-05T00:00:00Z"},
+			"end":   {"2024-01-06T06:00:00Z"},
+		}, now)
+		require.Empty(t, errMsg)
+
+		outages := []types.Outage{
+			{
+				Severity:  types.SeverityDown,
+				StartTime: time.Date(2024, 1, 6, 5, 0, 0, 0, time.UTC),
+				EndTime:   sql.NullTime{Valid: false},
+			},
+		}
+
+		buckets := buildHistoryBuckets(outages, window, now)
+		require.Len(t, buckets, 2)
+		assert.InDelta(t, 60.0, buckets[1].TotalOutageMinutes, 0.01)
+	})
+}
+
+func TestParseHistoryWindow(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		query   url.Values
+		wantErr string
+	}{
+		{name: "defaults", query: url.Values{}},
+		{name: "invalid tz", query: url.Values{"tz": {"Mars/Olympus"}}, wantErr: "invalid tz: Mars/Olympus"},
+		{name: "invalid granularity", query: url.Values{"granularity": {"month"}}, wantErr: "granularity must be one of: hour, day, week"},
+		{name: "non-numeric days", query: url.Values{"days": {"x"}}, wantErr: "days must be a positive integer"},
+		{name: "days too large", query: url.Values{"days": {"366"}}, wantErr: "days must not exceed 365"},
+		{
+			name:    "days with start",
+			query:   url.Values{"days": {"3"}, "start": {"2024-01-01T00:00:00Z"}},
+			wantErr: "days cannot be combined with start or end",
+		},
+		{name: "invalid start", query: url.Values{"start": {"yesterday"}}, wantErr: "invalid start time"},
+		{
+			name:    "start after end",
+			query:   url.Values{"start": {"2024-01-05T00:00:00Z"}, "end": {"2024-01-04T00:00:00Z"}},
+			wantErr: "start must be before end",
+		},
+		{
+			name:    "too many hourly buckets",
+			query:   url.Values{"days": {"90"}, "granularity": {"hour"}},
+			wantErr: "range produces more than 744 buckets, use a coarser granularity or a shorter range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errMsg := parseHistoryWindow(tt.query, now)
+			assert.Equal(t, tt.wantErr, errMsg)
+		})
+	}
 }
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader is set on responses replayed from an earlier request with the same key.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+
+	idempotencyOutcomeReplayed   = "replayed"
+	idempotencyOutcomeInProgress = "in_progress"
+	idempotencyOutcomeMismatch   = "mismatch"
+)
+
+var idempotencyKeyConflicts = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "ship_status_idempotency_key_retries_total",
+	Help: "Number of requests reusing an Idempotency-Key, by whether they were replayed, found the original in progress, or did not match it.",
+}, []string{"outcome"})
+
+func init() {
+	prometheus.MustRegister(idempotencyKeyConflicts)
+}
+
+// IdempotencyKeys makes create routes safe to retry. The response to a request with an Idempotency-Key header is
+// stored with the key, and later requests from the same caller with the key get that response instead of creating
+// another resource. Only successful responses are stored, so failed requests can be retried with the same key.
+type IdempotencyKeys struct {
+	repo   repositories.IdempotencyKeyRepository
+	ttl    time.Duration
+	logger *logrus.Logger
+	now    func() time.Time
+}
+
+// NewIdempotencyKeys creates IdempotencyKeys keeping each key for ttl.
+func NewIdempotencyKeys(repo repositories.IdempotencyKeyRepository, ttl time.Duration, logger *logrus.Logger) *IdempotencyKeys {
+	return &IdempotencyKeys{repo: repo, ttl: ttl, logger: logger, now: time.Now}
+}
+
+// Wrap returns next honoring the Idempotency-Key header. It must run after authentication, since keys belong to
+// the authenticated caller.
+func (k *IdempotencyKeys) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			respondWithError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+		user, ok := GetUserFromContext(r.Context())
+		if !ok {
+			respondWithError(w, http.StatusUnauthorized, "no active user found")
+			return
+		}
+		delegator, _ := GetDelegatorFromContext(r.Context())
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.Sum256(body)
+
+		logger := k.logger.WithFields(logrus.Fields{
+			"method":          r.Method,
+			"path":            r.URL.Path,
+			"user":            user,
+			"idempotency_key": key,
+		})
+
+		record := &types.IdempotencyKey{
+			Key:         key,
+			User:        user,
+			Delegator:   delegator,
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			RequestHash: hex.EncodeToString(hash[:]),
+		}
+		existing, err := k.repo.ReserveKey(record, k.now().Add(-k.ttl))
+		if err != nil {
+			logger.WithField("error", err).Error("Failed to reserve idempotency key")
+			respondWithError(w, http.StatusInternalServerError, "Failed to check Idempotency-Key")
+			return
+		}
+		if existing != nil {
+			k.replay(w, record, existing, logger)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			// Without a stored response, a retry must be able to make the request again.
+			if err := k.repo.ReleaseKey(record.ID); err != nil {
+				logger.WithField("error", err).Error("Failed to release idempotency key")
+			}
+		}()
+
+		next(recorder, r)
+
+		status := recorder.statusCode()
+		if status < 200 || status >= 300 {
+			return
+		}
+		// The key is kept whatever happens to storing the response, since the resource now exists and must
+		// not be created twice.
+		completed = true
+		if err := k.repo.CompleteKey(record.ID, status, recorder.body.Bytes()); err != nil {
+			logger.WithField("error", err).Error("Failed to store response for idempotency key")
+		}
+	}
+}
+
+// replay responds to a request whose key the caller already used, with the stored response if it matches.
+func (k *IdempotencyKeys) replay(w http.ResponseWriter, record, existing *types.IdempotencyKey, logger *logrus.Entry) {
+	switch {
+	case existing.Method != record.Method || existing.Path != record.Path || existing.RequestHash != record.RequestHash:
+		idempotencyKeyConflicts.WithLabelValues(idempotencyOutcomeMismatch).Inc()
+		respondWithError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+	case !existing.Completed():
+		idempotencyKeyConflicts.WithLabelValues(idempotencyOutcomeInProgress).Inc()
+		respondWithError(w, http.StatusConflict, "A request with this Idempotency-Key is still in progress")
+	default:
+		idempotencyKeyConflicts.WithLabelValues(idempotencyOutcomeReplayed).Inc()
+		logger.Info("Replaying response for idempotency key")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(idempotentReplayedHeader, "true")
+		w.WriteHeader(existing.StatusCode)
+		_, _ = w.Write(existing.ResponseBody)
+	}
+}
+
+// responseRecorder passes a response through while keeping a copy of its status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// IdempotencyKeyPruner deletes idempotency keys once they can no longer be replayed.
+type IdempotencyKeyPruner struct {
+	repo          repositories.IdempotencyKeyRepository
+	ttl           time.Duration
+	checkInterval time.Duration
+	logger        *logrus.Logger
+}
+
+// NewIdempotencyKeyPruner creates a new IdempotencyKeyPruner.
+func NewIdempotencyKeyPruner(repo repositories.IdempotencyKeyRepository, ttl, checkInterval time.Duration, logger *logrus.Logger) *IdempotencyKeyPruner {
+	return &IdempotencyKeyPruner{
+		repo:          repo,
+		ttl:           ttl,
+		checkInterval: checkInterval,
+		logger:        logger,
+	}
+}
+
+// Start prunes expired keys once, then on every check interval until ctx is done.
+func (p *IdempotencyKeyPruner) Start(ctx context.Context) {
+	p.logger.WithFields(logrus.Fields{
+		"ttl":            p.ttl,
+		"check_interval": p.checkInterval,
+	}).Info("Starting idempotency key pruner")
+	p.prune(time.Now())
+
+	ticker := time.NewTicker(p.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("Stopping idempotency key pruner")
+			return
+		case now := <-ticker.C:
+			p.prune(now)
+		}
+	}
+}
+
+// prune deletes the keys created more than the TTL before now.
+func (p *IdempotencyKeyPruner) prune(now time.Time) {
+	deleted, err := p.repo.DeleteKeysBefore(now.Add(-p.ttl))
+	if err != nil {
+		p.logger.WithField("error", err).Error("Failed to prune idempotency keys")
+		return
+	}
+	if deleted > 0 {
+		p.logger.WithField("deleted", deleted).Info("Pruned expired idempotency keys")
+	}
+}
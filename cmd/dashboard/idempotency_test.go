@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	type call struct {
+		user       string
+		key        string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantReplay bool
+	}
+	tests := []struct {
+		name      string
+		seed      []types.IdempotencyKey
+		failFirst bool
+		calls     []call
+		wantCalls int
+	}{
+		{
+			name: "requests without a key are not deduplicated",
+			calls: []call{
+				{user: "alice", body: `{"a":1}`, wantStatus: http.StatusCreated, wantBody: `{"id":1}`},
+				{user: "alice", body: `{"a":1}`, wantStatus: http.StatusCreated, wantBody: `{"id":2}`},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "retry gets the original response",
+			calls: []call{
+				{user: "alice", key: "k1", body: `{"a":1}`, wantStatus: http.StatusCreated, wantBody: `{"id":1}`},
+				{user: "alice", key: "k1", body: `{"a":1}`, wantStatus: http.StatusCreated, wantBody: `{"id":1}`, wantReplay: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "keys belong to their caller",
+			calls: []call{
+				{user: "alice", key: "k1", body: `{"a":1}`, wantStatus: http.StatusCreated, wantBody: `{"id":1}`},
+				{user: "bob", key: "k1", body: `{"a":1}`, wantStatus: http.StatusCreated, wantBody: `{"id":2}`},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "key reused for a different request",
+			calls: []call{
+				{user: "alice", key: "k1", body: `{"a":1}`, wantStatus: http.StatusCreated, wantBody: `{"id":1}`},
+				{user: "alice", key: "k1", body: `{"a":2}`, wantStatus: http.StatusUnprocessableEntity},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "expired key is used again",
+			seed: []types.IdempotencyKey{{
+				Model: gorm.Model{ID: 1, CreatedAt: now.Add(-25 * time.Hour)},
+				Key:   "k1", User: "alice", Method: http.MethodPost, Path: "/api/things",
+				StatusCode: http.StatusCreated, ResponseBody: []byte(`{"id":0}`),
+			}},
+			calls: []call{
+				{user: "alice", key: "k1", body: `{"a":1}`, wantStatus: http.StatusCreated, wantBody: `{"id":1}`},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:      "failed request can be retried with its key",
+			failFirst: true,
+			calls: []call{
+				{user: "alice", key: "k1", body: `{"a":1}`, wantStatus: http.StatusInternalServerError},
+				{user: "alice", key: "k1", body: `{"a":1}`, wantStatus: http.StatusCreated, wantBody: `{"id":2}`},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "key too long",
+			calls: []call{
+				{user: "alice", key: strings.Repeat("k", 256), body: `{"a":1}`, wantStatus: http.StatusBadRequest},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repositories.MockIdempotencyKeyRepository{Keys: tt.seed}
+			keys := NewIdempotencyKeys(repo, 24*time.Hour, logger)
+			keys.now = func() time.Time { return now }
+
+			handlerCalls := 0
+			handler := keys.Wrap(func(w http.ResponseWriter, r *http.Request) {
+				handlerCalls++
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.NotEmpty(t, body, "the handler still reads the request body")
+				if tt.failFirst && handlerCalls == 1 {
+					respondWithError(w, http.StatusInternalServerError, "Failed")
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprintf(w, `{"id":%d}`, handlerCalls)
+			})
+
+			for i, c := range tt.calls {
+				req := withUser(httptest.NewRequest(http.MethodPost, "/api/things", strings.NewReader(c.body)), c.user)
+				if c.key != "" {
+					req.Header.Set(idempotencyKeyHeader, c.key)
+				}
+				rec := httptest.NewRecorder()
+				handler(rec, req)
+				assert.Equal(t, c.wantStatus, rec.Code, "call %d: %s", i, rec.Body.String())
+				if c.wantBody != "" {
+					assert.Equal(t, c.wantBody, rec.Body.String(), "call %d", i)
+				}
+				assert.Equal(t, c.wantReplay, rec.Header().Get(idempotentReplayedHeader) == "true", "call %d", i)
+			}
+			assert.Equal(t, tt.wantCalls, handlerCalls)
+		})
+	}
+}
+
+func TestIdempotencyKeys_InProgress(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	repo := &repositories.MockIdempotencyKeyRepository{}
+	keys := NewIdempotencyKeys(repo, 24*time.Hour, logger)
+
+	var retry *httptest.ResponseRecorder
+	handler := keys.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		if retry == nil {
+			// A retry arriving while the original request is still being handled.
+			retry = httptest.NewRecorder()
+			req := withUser(httptest.NewRequest(http.MethodPost, "/api/things", strings.NewReader(`{}`)), "alice")
+			req.Header.Set(idempotencyKeyHeader, "k1")
+			keys.Wrap(func(http.ResponseWriter, *http.Request) {
+				t.Error("the retry must not be handled")
+			})(retry, req)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req := withUser(httptest.NewRequest(http.MethodPost, "/api/things", strings.NewReader(`{}`)), "alice")
+	req.Header.Set(idempotencyKeyHeader, "k1")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	require.NotNil(t, retry)
+	assert.Equal(t, http.StatusConflict, retry.Code)
+	require.Len(t, repo.Keys, 1)
+	assert.Equal(t, http.StatusCreated, repo.Keys[0].StatusCode)
+}
+
+func TestIdempotencyKeyPruner(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &repositories.MockIdempotencyKeyRepository{Keys: []types.IdempotencyKey{
+		{Model: gorm.Model{ID: 1, CreatedAt: now.Add(-25 * time.Hour)}, Key: "old"},
+		{Model: gorm.Model{ID: 2, CreatedAt: now.Add(-time.Hour)}, Key: "new"},
+	}}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	NewIdempotencyKeyPruner(repo, 24*time.Hour, time.Hour, logger).prune(now)
+	require.Len(t, repo.Keys, 1)
+	assert.Equal(t, "new", repo.Keys[0].Key)
+}
+
+func TestRouteIdempotency(t *testing.T) {
+	s := &Server{handlers: newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})}
+	idempotent := map[string]bool{}
+	for _, r := range s.routes() {
+		if r.idempotent {
+			assert.True(t, r.protected, "%s %s", r.method, r.path)
+			idempotent[r.method+" "+r.path] = true
+		}
+	}
+	assert.Equal(t, map[string]bool{
+		"POST /api/components/{componentName}/{subComponentName}/outages":                                true,
+		"POST /api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/triage-notes": true,
+		"POST /api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/links":        true,
+		"POST /api/components/{componentName}/{subComponentName}/outages/report-suspected":               true,
+	}, idempotent)
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"net/http"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -37,10 +42,55 @@ type Options struct {
 	HMACSecretFile            string
 	CORSOrigin                string
 	KubeconfigPath            string
+	AuthMode                  string
+	OIDCIssuerURL             string
+	OIDCClientID              string
+	OIDCClientSecretFile      string
+	OIDCRedirectURL           string
+	OIDCScopes                string
+	OIDCUsernameClaim         string
+	OIDCGroupsClaim           string
+	OIDCSessionSecretFile     string
+	OIDCSessionTTL            time.Duration
+	GroupSource               string
+	GroupFile                 string
+	GroupRefreshInterval      time.Duration
+	GroupMaxStaleness         time.Duration
+	LDAPURL                   string
+	LDAPBindDN                string
+	LDAPBindPasswordFile      string
+	LDAPBaseDN                string
+	LDAPGroupFilter           string
+	LDAPMemberAttribute       string
+	LDAPUserAttribute         string
+	TokenReview               bool
+	TokenReviewAudiences      string
+	TokenReviewCacheTTL       time.Duration
 	AbsentReportCheckInterval time.Duration
 	ConfigUpdatePollInterval  time.Duration
+	SecurityEventRetention    time.Duration
+	TrustedProxies            string
+	APITokenUseRetention      time.Duration
+	IdempotencyKeyTTL         time.Duration
 	SlackBaseURL              string
 	SlackWorkspaceURL         string
+	SlackIdentityEmailDomain  string
+	UserEmailDomain           string
+	WatchWebhookHosts         string
+	SlackCoalesceWindow       time.Duration
+	SlackChannelInterval      time.Duration
+	SlackMaxRetries           int
+	SMTPAddress               string
+	SMTPFrom                  string
+	SMTPUsername              string
+	SMTPPasswordFile          string
+	SMTPRequireTLS            bool
+	PagerDutyRoutingKeysFile  string
+	PagerDutyAPITokenFile     string
+	JiraURL                   string
+	JiraUsername              string
+	JiraTokenFile             string
+	JiraSyncInterval          time.Duration
 }
 
 // NewOptions parses command-line flags and returns a new Options instance.
@@ -53,10 +103,55 @@ func NewOptions() *Options {
 	flag.StringVar(&opts.HMACSecretFile, "hmac-secret-file", "", "File containing HMAC secret")
 	flag.StringVar(&opts.CORSOrigin, "cors-origin", "*", "CORS allowed origin")
 	flag.StringVar(&opts.KubeconfigPath, "kubeconfig", "", "Path to kubeconfig file (empty string uses in-cluster config)")
+	flag.StringVar(&opts.AuthMode, "auth-mode", authModeHMAC, "How protected requests are authenticated: hmac (signed by oauth-proxy) or oidc (the dashboard's own OpenID Connect login)")
+	flag.StringVar(&opts.OIDCIssuerURL, "oidc-issuer-url", "", "OpenID Connect issuer URL. Required if auth-mode is oidc.")
+	flag.StringVar(&opts.OIDCClientID, "oidc-client-id", "", "OpenID Connect client ID. Required if auth-mode is oidc.")
+	flag.StringVar(&opts.OIDCClientSecretFile, "oidc-client-secret-file", "", "File containing the OpenID Connect client secret. Required if auth-mode is oidc.")
+	flag.StringVar(&opts.OIDCRedirectURL, "oidc-redirect-url", "", "Absolute URL of the dashboard's "+auth.OIDCCallbackPath+" endpoint, as registered with the provider. Required if auth-mode is oidc.")
+	flag.StringVar(&opts.OIDCScopes, "oidc-scopes", "profile,email,groups", "Comma-separated scopes requested besides openid")
+	flag.StringVar(&opts.OIDCUsernameClaim, "oidc-username-claim", auth.DefaultOIDCUsernameClaim, "ID token claim used as the username")
+	flag.StringVar(&opts.OIDCGroupsClaim, "oidc-groups-claim", auth.DefaultOIDCGroupsClaim, "ID token claim listing the user's groups, which count as rover_group membership")
+	flag.StringVar(&opts.OIDCSessionSecretFile, "oidc-session-secret-file", "", "File containing at least 32 bytes used to sign session cookies. Required if auth-mode is oidc.")
+	flag.DurationVar(&opts.OIDCSessionTTL, "oidc-session-ttl", 12*time.Hour, "How long a login lasts")
+	flag.StringVar(&opts.GroupSource, "group-source", "openshift", "Where rover_group members are looked up: openshift, ldap, static, or none to rely on OIDC group claims")
+	flag.StringVar(&opts.GroupFile, "group-file", "", "YAML file mapping group names to members. Required if group-source is static.")
+	flag.DurationVar(&opts.GroupRefreshInterval, "group-refresh-interval", 10*time.Minute, "Interval for refreshing group membership in the background. 0 refreshes only on startup and config reload.")
+	flag.DurationVar(&opts.GroupMaxStaleness, "group-max-staleness", 24*time.Hour, "How long group members are kept while their group fails to refresh. 0 keeps them until the next successful refresh.")
+	flag.StringVar(&opts.LDAPURL, "ldap-url", "", "LDAP server URL, e.g. ldaps://ldap.example.com. Required if group-source is ldap.")
+	flag.StringVar(&opts.LDAPBindDN, "ldap-bind-dn", "", "DN to bind as for group lookups. Lookups are anonymous when empty.")
+	flag.StringVar(&opts.LDAPBindPasswordFile, "ldap-bind-password-file", "", "File containing the password for ldap-bind-dn. Required if ldap-bind-dn is set.")
+	flag.StringVar(&opts.LDAPBaseDN, "ldap-base-dn", "", "Base DN groups are searched under. Required if group-source is ldap.")
+	flag.StringVar(&opts.LDAPGroupFilter, "ldap-group-filter", auth.DefaultLDAPGroupFilter, "LDAP search filter for a group, with %s standing for the group name")
+	flag.StringVar(&opts.LDAPMemberAttribute, "ldap-member-attribute", auth.DefaultLDAPMemberAttribute, "Group attribute listing its members, as DNs or usernames")
+	flag.StringVar(&opts.LDAPUserAttribute, "ldap-user-attribute", auth.DefaultLDAPUserAttribute, "Attribute of member DNs holding the username")
+	flag.BoolVar(&opts.TokenReview, "token-review", false, "Authenticate protected requests carrying a ServiceAccount bearer token through the Kubernetes TokenReview API, so monitors can report without oauth-proxy")
+	flag.StringVar(&opts.TokenReviewAudiences, "token-review-audiences", "", "Comma-separated audiences tokens must be issued for, such as ship-status-dash. Required with --token-review.")
+	flag.DurationVar(&opts.TokenReviewCacheTTL, "token-review-cache-ttl", auth.DefaultTokenReviewCacheTTL, "How long a successful TokenReview is reused. 0 reviews every request.")
 	flag.DurationVar(&opts.AbsentReportCheckInterval, "absent-report-check-interval", 5*time.Minute, "Interval for checking absent monitored component reports")
 	flag.DurationVar(&opts.ConfigUpdatePollInterval, "config-update-poll-interval", config.DefaultPollInterval, "Interval for polling config file for changes")
+	flag.DurationVar(&opts.SecurityEventRetention, "security-event-retention", 90*24*time.Hour, "How long security events are kept. 0 keeps them forever.")
+	flag.StringVar(&opts.TrustedProxies, "trusted-proxies", "127.0.0.1/32,::1/128", "Comma-separated CIDRs of the proxies in front of the dashboard, such as oauth-proxy and the router. Authentication failures are limited per client address, which is read from X-Forwarded-For on requests from these proxies.")
+	flag.DurationVar(&opts.APITokenUseRetention, "api-token-use-retention", 90*24*time.Hour, "How long the record of each API token use is kept. 0 keeps them forever.")
+	flag.DurationVar(&opts.IdempotencyKeyTTL, "idempotency-key-ttl", 24*time.Hour, "How long the response to a create request with an Idempotency-Key is replayed to retries. 0 ignores the header.")
 	flag.StringVar(&opts.SlackBaseURL, "slack-base-url", "", "Base URL for building outage links in Slack messages. Required if slack reporting is enabled.")
 	flag.StringVar(&opts.SlackWorkspaceURL, "slack-workspace-url", "https://rhsandbox.slack.com/", "Slack workspace URL for constructing thread links. Required if slack reporting is enabled.")
+	flag.StringVar(&opts.SlackIdentityEmailDomain, "slack-identity-email-domain", "", "Email domain used to map Slack users to dashboard users (alice@domain acts as alice). Required if SLACK_SIGNING_SECRET is set.")
+	flag.StringVar(&opts.UserEmailDomain, "user-email-domain", "", "Email domain of dashboard users (alice's address is alice@domain). Users can only have watch notifications emailed to their own address, so email watch notifications are off when empty. Defaults to slack-identity-email-domain.")
+	flag.StringVar(&opts.WatchWebhookHosts, "watch-webhook-hosts", "", "Comma-separated hosts watch notification webhooks may point at. An entry starting with . allows its subdomains. Webhook watch notifications are off when empty.")
+	flag.DurationVar(&opts.SlackCoalesceWindow, "slack-coalesce-window", 20*time.Second, "How long a new outage waits for others bound for the same Slack channel, which are then announced in one grouped message. 0 disables grouping.")
+	flag.DurationVar(&opts.SlackChannelInterval, "slack-channel-interval", time.Second, "Minimum time between Slack posts to the same channel")
+	flag.IntVar(&opts.SlackMaxRetries, "slack-max-retries", 3, "How many times a Slack post rejected by rate limiting is retried after its Retry-After")
+	flag.StringVar(&opts.SMTPAddress, "smtp-address", "", "SMTP relay (host:port) for email notifications. Email notifications are disabled when empty.")
+	flag.StringVar(&opts.SMTPFrom, "smtp-from", "", "Sender address for email notifications. Required if smtp-address is set.")
+	flag.StringVar(&opts.SMTPUsername, "smtp-username", "", "Username for SMTP AUTH PLAIN. Authentication is skipped when empty.")
+	flag.StringVar(&opts.SMTPPasswordFile, "smtp-password-file", "", "File containing the SMTP password. Required if smtp-username is set.")
+	flag.BoolVar(&opts.SMTPRequireTLS, "smtp-require-tls", false, "Fail email delivery when the SMTP relay does not offer STARTTLS. STARTTLS is always used when offered.")
+	flag.StringVar(&opts.PagerDutyRoutingKeysFile, "pagerduty-routing-keys-file", "", "YAML file mapping paging routing_key_ref names to PagerDuty Events API v2 routing keys. Paging is disabled when empty.")
+	flag.StringVar(&opts.PagerDutyAPITokenFile, "pagerduty-api-token-file", "", "File containing a read-only PagerDuty REST API token, used to link outages to their PagerDuty incidents.")
+	flag.StringVar(&opts.JiraURL, "jira-url", "", "Base URL of the Jira server issues are filed on for components with a jira block. Jira integration is disabled when empty.")
+	flag.StringVar(&opts.JiraUsername, "jira-username", "", "Jira username for basic auth. When empty, the token is sent as a bearer personal access token.")
+	flag.StringVar(&opts.JiraTokenFile, "jira-token-file", "", "File containing the Jira API token or personal access token. Required if jira-url is set.")
+	flag.DurationVar(&opts.JiraSyncInterval, "jira-sync-interval", 10*time.Minute, "Interval for syncing the status of linked Jira issues")
 	flag.Parse()
 
 	return opts
@@ -80,12 +175,92 @@ func (o *Options) Validate() error {
 		errs = append(errs, errors.New("database DSN is required (use --dsn flag)"))
 	}
 
-	if os.Getenv("SKIP_AUTH") != "1" {
-		if o.HMACSecretFile == "" {
-			errs = append(errs, errors.New("hmac secret file is required (use --hmac-secret-file flag)"))
-		} else if _, err := os.Stat(o.HMACSecretFile); os.IsNotExist(err) {
-			errs = append(errs, errors.New("hmac secret file does not exist: "+o.HMACSecretFile))
+	switch o.AuthMode {
+	case authModeHMAC:
+		if os.Getenv("SKIP_AUTH") != "1" {
+			if o.HMACSecretFile == "" {
+				errs = append(errs, errors.New("hmac secret file is required (use --hmac-secret-file flag)"))
+			} else if _, err := os.Stat(o.HMACSecretFile); os.IsNotExist(err) {
+				errs = append(errs, errors.New("hmac secret file does not exist: "+o.HMACSecretFile))
+			}
+		}
+	case authModeOIDC:
+		if o.OIDCIssuerURL == "" {
+			errs = append(errs, errors.New("oidc-issuer-url is required when auth-mode is oidc (use --oidc-issuer-url flag)"))
+		}
+		if o.OIDCClientID == "" {
+			errs = append(errs, errors.New("oidc-client-id is required when auth-mode is oidc (use --oidc-client-id flag)"))
+		}
+		if o.OIDCClientSecretFile == "" {
+			errs = append(errs, errors.New("oidc-client-secret-file is required when auth-mode is oidc (use --oidc-client-secret-file flag)"))
+		} else if _, err := os.Stat(o.OIDCClientSecretFile); os.IsNotExist(err) {
+			errs = append(errs, errors.New("oidc client secret file does not exist: "+o.OIDCClientSecretFile))
+		}
+		if redirectURL, err := url.Parse(o.OIDCRedirectURL); err != nil || !redirectURL.IsAbs() || redirectURL.Path != auth.OIDCCallbackPath {
+			errs = append(errs, fmt.Errorf("oidc-redirect-url must be an absolute URL ending in %s (use --oidc-redirect-url flag)", auth.OIDCCallbackPath))
 		}
+		if o.OIDCSessionSecretFile == "" {
+			errs = append(errs, errors.New("oidc-session-secret-file is required when auth-mode is oidc (use --oidc-session-secret-file flag)"))
+		} else if _, err := os.Stat(o.OIDCSessionSecretFile); os.IsNotExist(err) {
+			errs = append(errs, errors.New("oidc session secret file does not exist: "+o.OIDCSessionSecretFile))
+		}
+		if o.OIDCSessionTTL <= 0 {
+			errs = append(errs, errors.New("oidc-session-ttl must be positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("auth-mode must be one of %s, %s", authModeHMAC, authModeOIDC))
+	}
+
+	switch o.GroupSource {
+	case groupSourceOpenShift, groupSourceNone:
+	case groupSourceStatic:
+		if o.GroupFile == "" {
+			errs = append(errs, errors.New("group-file is required when group-source is static (use --group-file flag)"))
+		} else if _, err := os.Stat(o.GroupFile); os.IsNotExist(err) {
+			errs = append(errs, errors.New("group file does not exist: "+o.GroupFile))
+		}
+	case groupSourceLDAP:
+		if o.LDAPURL == "" {
+			errs = append(errs, errors.New("ldap-url is required when group-source is ldap (use --ldap-url flag)"))
+		}
+		if o.LDAPBaseDN == "" {
+			errs = append(errs, errors.New("ldap-base-dn is required when group-source is ldap (use --ldap-base-dn flag)"))
+		}
+		if o.LDAPBindDN != "" {
+			if o.LDAPBindPasswordFile == "" {
+				errs = append(errs, errors.New("ldap-bind-password-file is required when ldap-bind-dn is set (use --ldap-bind-password-file flag)"))
+			} else if _, err := os.Stat(o.LDAPBindPasswordFile); os.IsNotExist(err) {
+				errs = append(errs, errors.New("ldap bind password file does not exist: "+o.LDAPBindPasswordFile))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("group-source must be one of %s, %s, %s, %s", groupSourceOpenShift, groupSourceLDAP, groupSourceStatic, groupSourceNone))
+	}
+	if o.GroupRefreshInterval < 0 {
+		errs = append(errs, errors.New("group-refresh-interval must not be negative"))
+	}
+	if o.GroupMaxStaleness < 0 {
+		errs = append(errs, errors.New("group-max-staleness must not be negative"))
+	} else if o.GroupMaxStaleness > 0 && o.GroupRefreshInterval > 0 && o.GroupMaxStaleness <= o.GroupRefreshInterval {
+		errs = append(errs, errors.New("group-max-staleness must be longer than group-refresh-interval"))
+	}
+	if o.TokenReview && len(o.tokenReviewAudiences()) == 0 {
+		errs = append(errs, errors.New("token-review-audiences is required with --token-review"))
+	}
+	if o.TokenReviewCacheTTL < 0 {
+		errs = append(errs, errors.New("token-review-cache-ttl must not be negative"))
+	}
+	if o.SecurityEventRetention < 0 {
+		errs = append(errs, errors.New("security-event-retention must not be negative"))
+	}
+	if _, err := o.trustedProxies(); err != nil {
+		errs = append(errs, err)
+	}
+	if o.APITokenUseRetention < 0 {
+		errs = append(errs, errors.New("api-token-use-retention must not be negative"))
+	}
+	if o.IdempotencyKeyTTL < 0 {
+		errs = append(errs, errors.New("idempotency-key-ttl must not be negative"))
 	}
 
 	if os.Getenv("SLACK_BOT_TOKEN") != "" {
@@ -97,9 +272,94 @@ func (o *Options) Validate() error {
 		}
 	}
 
+	if os.Getenv("SLACK_SIGNING_SECRET") != "" {
+		if os.Getenv("SLACK_BOT_TOKEN") == "" {
+			errs = append(errs, errors.New("SLACK_BOT_TOKEN is required when SLACK_SIGNING_SECRET is set"))
+		}
+		if o.SlackIdentityEmailDomain == "" {
+			errs = append(errs, errors.New("slack-identity-email-domain is required when SLACK_SIGNING_SECRET is set (use --slack-identity-email-domain flag)"))
+		}
+	}
+
+	if o.SMTPAddress != "" && o.SMTPFrom == "" {
+		errs = append(errs, errors.New("smtp-from is required when smtp-address is set (use --smtp-from flag)"))
+	}
+	if o.SMTPUsername != "" {
+		if o.SMTPPasswordFile == "" {
+			errs = append(errs, errors.New("smtp-password-file is required when smtp-username is set (use --smtp-password-file flag)"))
+		} else if _, err := os.Stat(o.SMTPPasswordFile); os.IsNotExist(err) {
+			errs = append(errs, errors.New("smtp password file does not exist: "+o.SMTPPasswordFile))
+		}
+	}
+
+	if o.PagerDutyRoutingKeysFile != "" {
+		if _, err := os.Stat(o.PagerDutyRoutingKeysFile); os.IsNotExist(err) {
+			errs = append(errs, errors.New("pagerduty routing keys file does not exist: "+o.PagerDutyRoutingKeysFile))
+		}
+	}
+	if o.PagerDutyAPITokenFile != "" {
+		if o.PagerDutyRoutingKeysFile == "" {
+			errs = append(errs, errors.New("pagerduty-routing-keys-file is required when pagerduty-api-token-file is set (use --pagerduty-routing-keys-file flag)"))
+		}
+		if _, err := os.Stat(o.PagerDutyAPITokenFile); os.IsNotExist(err) {
+			errs = append(errs, errors.New("pagerduty api token file does not exist: "+o.PagerDutyAPITokenFile))
+		}
+	}
+
+	if o.JiraURL != "" {
+		if !isHTTPURL(o.JiraURL) {
+			errs = append(errs, errors.New("jira-url must be an http or https url"))
+		}
+		if o.JiraTokenFile == "" {
+			errs = append(errs, errors.New("jira-token-file is required when jira-url is set (use --jira-token-file flag)"))
+		} else if _, err := os.Stat(o.JiraTokenFile); os.IsNotExist(err) {
+			errs = append(errs, errors.New("jira token file does not exist: "+o.JiraTokenFile))
+		}
+		if o.JiraSyncInterval <= 0 {
+			errs = append(errs, errors.New("jira-sync-interval must be positive"))
+		}
+	}
+
 	return apimachineryerrors.NewAggregate(errs)
 }
 
+// userEmailDomain returns the domain of users' email addresses, falling back to the Slack identity domain.
+func (o *Options) userEmailDomain() string {
+	if o.UserEmailDomain != "" {
+		return o.UserEmailDomain
+	}
+	return o.SlackIdentityEmailDomain
+}
+
+// tokenReviewAudiences splits the --token-review-audiences list.
+func (o *Options) tokenReviewAudiences() []string {
+	return splitList(o.TokenReviewAudiences)
+}
+
+// trustedProxies parses the --trusted-proxies list.
+func (o *Options) trustedProxies() ([]netip.Prefix, error) {
+	var proxies []netip.Prefix
+	for _, item := range splitList(o.TrustedProxies) {
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, fmt.Errorf("trusted-proxies must be a list of CIDRs: %w", err)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func setupLogger() *logrus.Logger {
 	log := logrus.New()
 	log.SetLevel(logrus.InfoLevel)
@@ -126,6 +386,12 @@ func loadAndValidateConfig(log *logrus.Logger, configPath string) (*types.Dashbo
 		if len(component.Owners) == 0 {
 			return nil, fmt.Errorf("component must have at least one owner: %s", component.Name)
 		}
+		if err := validateOwnerRoles(component.Name, component.Owners); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateGlobalRoles(cfg.GlobalRoles); err != nil {
+		return nil, err
 	}
 
 	for _, component := range cfg.Components {
@@ -159,10 +425,295 @@ func loadAndValidateConfig(log *logrus.Logger, configPath string) (*types.Dashbo
 		}
 	}
 
+	for _, component := range cfg.Components {
+		if err := validateNotifierReporting(component.Name, component.TeamsReporting, component.WebhookReporting); err != nil {
+			return nil, err
+		}
+		if err := validateEmailReporting(component.Name, component.EmailReporting); err != nil {
+			return nil, err
+		}
+		if err := validatePaging(component.Name, component.Paging); err != nil {
+			return nil, err
+		}
+		if err := validateEscalation(component.Name, component.Escalation); err != nil {
+			return nil, err
+		}
+		if err := validateJira(component.Name, component.Jira); err != nil {
+			return nil, err
+		}
+		for _, sub := range component.Subcomponents {
+			owner := component.Name + "/" + sub.Name
+			if err := validateNotifierReporting(owner, sub.TeamsReporting, sub.WebhookReporting); err != nil {
+				return nil, err
+			}
+			if err := validateEmailReporting(owner, sub.EmailReporting); err != nil {
+				return nil, err
+			}
+			if err := validatePaging(owner, sub.Paging); err != nil {
+				return nil, err
+			}
+			if err := validateEscalation(owner, sub.Escalation); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := validateSlackDigests(&cfg); err != nil {
+		return nil, err
+	}
+	if err := validateSlackStatusSync(&cfg); err != nil {
+		return nil, err
+	}
+	if err := validateSlackReportingVisibility(&cfg); err != nil {
+		return nil, err
+	}
+	if err := validateDelegationRules(&cfg); err != nil {
+		return nil, err
+	}
+	if err := validateRateLimits(cfg.RateLimits); err != nil {
+		return nil, err
+	}
+
 	log.Infof("Loaded configuration with %d components", len(cfg.Components))
 	return &cfg, nil
 }
 
+// validateOwnerRoles checks that every owner of component with a role names a known one.
+func validateOwnerRoles(component string, owners []types.Owner) error {
+	for i, owner := range owners {
+		if owner.Role != "" && !types.IsValidRole(string(owner.Role)) {
+			return fmt.Errorf("owners[%d] on %s has unknown role %q", i, component, owner.Role)
+		}
+	}
+	return nil
+}
+
+// validateGlobalRoles checks that every global_roles entry names an identity and a known role. Unlike owners,
+// global roles have no default, so that a missing role never grants admin on every component.
+func validateGlobalRoles(globalRoles []types.Owner) error {
+	for i, entry := range globalRoles {
+		if entry.User == "" && entry.ServiceAccount == "" && entry.RoverGroup == "" {
+			return fmt.Errorf("global_roles[%d] must set user, service_account or rover_group", i)
+		}
+		if !types.IsValidRole(string(entry.Role)) {
+			return fmt.Errorf("global_roles[%d] has unknown role %q", i, entry.Role)
+		}
+	}
+	return nil
+}
+
+// validateDelegationRules checks that every delegation_rules entry names a delegator not also in
+// trusted_delegators, where it would be unrestricted, and only known permissions, components and tags.
+func validateDelegationRules(cfg *types.DashboardConfig) error {
+	seen := map[string]bool{}
+	for i, rule := range cfg.DelegationRules {
+		if rule.Delegator == "" {
+			return fmt.Errorf("delegation_rules[%d] must set delegator", i)
+		}
+		if seen[rule.Delegator] {
+			return fmt.Errorf("delegation_rules[%d] repeats delegator %q", i, rule.Delegator)
+		}
+		seen[rule.Delegator] = true
+		if slices.Contains(cfg.TrustedDelegators, rule.Delegator) {
+			return fmt.Errorf("delegation_rules[%d] delegator %q is also in trusted_delegators", i, rule.Delegator)
+		}
+		if len(rule.Permissions) == 0 {
+			return fmt.Errorf("delegation_rules[%d] must list at least one permission", i)
+		}
+		for _, permission := range rule.Permissions {
+			if !types.IsValidPermission(string(permission)) {
+				return fmt.Errorf("delegation_rules[%d] has unknown permission %q", i, permission)
+			}
+		}
+		for _, name := range rule.Components {
+			if cfg.GetComponentBySlug(utils.Slugify(name)) == nil {
+				return fmt.Errorf("delegation_rules[%d] references unknown component %q", i, name)
+			}
+		}
+		for _, tag := range rule.Tags {
+			if !slices.ContainsFunc(cfg.Tags, func(t types.Tag) bool { return utils.Slugify(t.Name) == utils.Slugify(tag) }) {
+				return fmt.Errorf("delegation_rules[%d] references unknown tag %q", i, tag)
+			}
+		}
+	}
+	return nil
+}
+
+// validateRateLimits checks that every rate_limits entry is for a known route class, with a positive period and
+// sub_components only on reports.
+func validateRateLimits(rateLimits map[types.RateLimitClass]types.RateLimit) error {
+	for class, limit := range rateLimits {
+		if _, ok := types.DefaultRateLimits[class]; !ok {
+			return fmt.Errorf("rate_limits has unknown route class %q", class)
+		}
+		if limit.Requests < 0 || limit.SubComponents < 0 {
+			return fmt.Errorf("rate_limits.%s cannot be negative", class)
+		}
+		if limit.Requests > 0 && limit.PeriodDuration() == 0 {
+			return fmt.Errorf("rate_limits.%s has invalid period %q", class, limit.Period)
+		}
+		if limit.SubComponents > 0 && class != types.RateLimitReports {
+			return fmt.Errorf("rate_limits.%s cannot limit sub_components", class)
+		}
+	}
+	return nil
+}
+
+// validateSlackDigests checks that every slack_digests entry has a channel, a valid schedule and known components.
+func validateSlackDigests(cfg *types.DashboardConfig) error {
+	for i, digest := range cfg.SlackDigests {
+		if digest.Channel == "" {
+			return fmt.Errorf("slack_digests[%d] must set channel", i)
+		}
+		if _, err := digest.LastScheduledRun(time.Now()); err != nil {
+			return fmt.Errorf("slack_digests[%d]: %w", i, err)
+		}
+		for _, name := range digest.Components {
+			if cfg.GetComponentBySlug(utils.Slugify(name)) == nil {
+				return fmt.Errorf("slack_digests[%d] references unknown component %q", i, name)
+			}
+		}
+		if digest.Visibility != "" && !types.IsValidVisibility(string(digest.Visibility)) {
+			return fmt.Errorf("slack_digests[%d] has unknown visibility %q", i, digest.Visibility)
+		}
+	}
+	return nil
+}
+
+// validateSlackStatusSync checks that status_sync is a known mode and that every slack_reporting entry for a
+// channel agrees on it.
+func validateSlackStatusSync(cfg *types.DashboardConfig) error {
+	modes := map[string]types.SlackStatusSync{}
+	for _, component := range cfg.Components {
+		for i := range component.Subcomponents {
+			for _, reporting := range types.GetSlackReporting(component, &component.Subcomponents[i]) {
+				switch reporting.StatusSync {
+				case "":
+					continue
+				case types.SlackStatusSyncTopic, types.SlackStatusSyncPinned:
+				default:
+					return fmt.Errorf("slack_reporting for %s on %s has unknown status_sync %q", reporting.Channel, component.Name, reporting.StatusSync)
+				}
+				if mode, ok := modes[reporting.Channel]; ok && mode != reporting.StatusSync {
+					return fmt.Errorf("slack_reporting for %s sets both status_sync %q and %q", reporting.Channel, mode, reporting.StatusSync)
+				}
+				modes[reporting.Channel] = reporting.StatusSync
+			}
+		}
+	}
+	return nil
+}
+
+// validateSlackReportingVisibility checks that every slack_reporting entry with a visibility names a known one.
+func validateSlackReportingVisibility(cfg *types.DashboardConfig) error {
+	for _, component := range cfg.Components {
+		for i := range component.Subcomponents {
+			for _, reporting := range types.GetSlackReporting(component, &component.Subcomponents[i]) {
+				if reporting.Visibility != "" && !types.IsValidVisibility(string(reporting.Visibility)) {
+					return fmt.Errorf("slack_reporting for %s on %s has unknown visibility %q", reporting.Channel, component.Name, reporting.Visibility)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateNotifierReporting checks the teams_reporting and webhook_reporting entries configured on owner.
+// Errors never include the URLs, which are credentials.
+func validateNotifierReporting(owner string, teams []types.TeamsReportingConfig, webhooks []types.WebhookReportingConfig) error {
+	for i, reporting := range teams {
+		if !isHTTPURL(reporting.WebhookURL) {
+			return fmt.Errorf("teams_reporting[%d] on %s must have an http or https webhook_url", i, owner)
+		}
+		if reporting.Visibility != "" && !types.IsValidVisibility(string(reporting.Visibility)) {
+			return fmt.Errorf("teams_reporting[%d] on %s has unknown visibility %q", i, owner, reporting.Visibility)
+		}
+	}
+	for i, reporting := range webhooks {
+		if !isHTTPURL(reporting.URL) {
+			return fmt.Errorf("webhook_reporting[%d] on %s must have an http or https url", i, owner)
+		}
+		switch reporting.Flavor {
+		case "", types.WebhookFlavorMattermost, types.WebhookFlavorGoogleChat:
+		default:
+			return fmt.Errorf("webhook_reporting[%d] on %s has unknown flavor %q", i, owner, reporting.Flavor)
+		}
+		if reporting.Visibility != "" && !types.IsValidVisibility(string(reporting.Visibility)) {
+			return fmt.Errorf("webhook_reporting[%d] on %s has unknown visibility %q", i, owner, reporting.Visibility)
+		}
+	}
+	return nil
+}
+
+// validateEmailReporting checks that every email_reporting entry on owner lists valid recipient addresses.
+func validateEmailReporting(owner string, reporting []types.EmailReportingConfig) error {
+	for i, entry := range reporting {
+		if len(entry.Recipients) == 0 {
+			return fmt.Errorf("email_reporting[%d] on %s must list at least one recipient", i, owner)
+		}
+		for _, recipient := range entry.Recipients {
+			if address, err := mail.ParseAddress(recipient); err != nil || address.Address != recipient {
+				return fmt.Errorf("email_reporting[%d] on %s has invalid recipient %q, expected a plain email address", i, owner, recipient)
+			}
+		}
+		if entry.Visibility != "" && !types.IsValidVisibility(string(entry.Visibility)) {
+			return fmt.Errorf("email_reporting[%d] on %s has unknown visibility %q", i, owner, entry.Visibility)
+		}
+	}
+	return nil
+}
+
+// validatePaging checks that every paging entry on owner names a routing key and, if set, a known severity.
+func validatePaging(owner string, paging []types.PagingConfig) error {
+	for i, entry := range paging {
+		if entry.RoutingKeyRef == "" {
+			return fmt.Errorf("paging[%d] on %s must set routing_key_ref", i, owner)
+		}
+		if entry.Severity != nil && !types.IsValidSeverity(string(*entry.Severity)) {
+			return fmt.Errorf("paging[%d] on %s has unknown severity %q", i, owner, *entry.Severity)
+		}
+		if entry.Visibility != "" && !types.IsValidVisibility(string(entry.Visibility)) {
+			return fmt.Errorf("paging[%d] on %s has unknown visibility %q", i, owner, entry.Visibility)
+		}
+	}
+	return nil
+}
+
+// validateEscalation checks that every escalation step on owner has a positive delay and, if set, a known severity.
+func validateEscalation(owner string, steps []types.EscalationStep) error {
+	for i, step := range steps {
+		if after, err := time.ParseDuration(step.After); err != nil || after <= 0 {
+			return fmt.Errorf("escalation[%d] on %s must set after to a positive duration such as 15m", i, owner)
+		}
+		if step.Severity != nil && !types.IsValidSeverity(string(*step.Severity)) {
+			return fmt.Errorf("escalation[%d] on %s has unknown severity %q", i, owner, *step.Severity)
+		}
+	}
+	return nil
+}
+
+// validateJira checks that a component's jira block names a project and, if set, a known severity.
+func validateJira(owner string, jira *types.JiraConfig) error {
+	if jira == nil {
+		return nil
+	}
+	if jira.Project == "" {
+		return fmt.Errorf("jira on %s must set project", owner)
+	}
+	if jira.Severity != nil && !types.IsValidSeverity(string(*jira.Severity)) {
+		return fmt.Errorf("jira on %s has unknown severity %q", owner, *jira.Severity)
+	}
+	if jira.Visibility != "" && !types.IsValidVisibility(string(jira.Visibility)) {
+		return fmt.Errorf("jira on %s has unknown visibility %q", owner, jira.Visibility)
+	}
+	return nil
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
 func connectDatabase(log *logrus.Logger, dsn string) *gorm.DB {
 	log.Info("Connecting to PostgreSQL database")
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
@@ -197,27 +748,239 @@ func extractRoverGroups(config *types.DashboardConfig) []string {
 			}
 		}
 	}
+	for _, owner := range config.GlobalRoles {
+		if owner.RoverGroup != "" {
+			groupSet.Insert(owner.RoverGroup)
+		}
+	}
+	for _, rule := range config.DelegationRules {
+		if rule.RoverGroup != "" {
+			groupSet.Insert(rule.RoverGroup)
+		}
+	}
 
 	return groupSet.List()
 }
 
-func loadGroupMembership(log *logrus.Logger, config *types.DashboardConfig, kubeconfigPath string) *auth.GroupMembershipCache {
+const (
+	authModeHMAC = "hmac"
+	authModeOIDC = "oidc"
+
+	groupSourceOpenShift = "openshift"
+	groupSourceLDAP      = "ldap"
+	groupSourceStatic    = "static"
+	groupSourceNone      = "none"
+)
+
+// newGroupSource returns the backend rover_group members are looked up in, or nil when there is none.
+func newGroupSource(opts *Options) (auth.GroupSource, error) {
+	switch opts.GroupSource {
+	case groupSourceNone:
+		return nil, nil
+	case groupSourceStatic:
+		return auth.NewStaticGroupSource(opts.GroupFile)
+	case groupSourceLDAP:
+		ldapConfig := auth.LDAPConfig{
+			URL:             opts.LDAPURL,
+			BindDN:          opts.LDAPBindDN,
+			BaseDN:          opts.LDAPBaseDN,
+			GroupFilter:     opts.LDAPGroupFilter,
+			MemberAttribute: opts.LDAPMemberAttribute,
+			UserAttribute:   opts.LDAPUserAttribute,
+		}
+		if opts.LDAPBindPasswordFile != "" {
+			password, err := os.ReadFile(opts.LDAPBindPasswordFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read LDAP bind password file: %w", err)
+			}
+			ldapConfig.BindPassword = strings.TrimSpace(string(password))
+		}
+		return auth.NewLDAPGroupSource(ldapConfig)
+	default:
+		return auth.NewOpenShiftGroupSource(opts.KubeconfigPath), nil
+	}
+}
+
+// loadGroupMembership creates the group cache and loads the configured rover_groups. Groups that cannot be
+// fetched are logged and retried on the next refresh, so their members lack access until then.
+func loadGroupMembership(log *logrus.Logger, config *types.DashboardConfig, source auth.GroupSource) *auth.GroupMembershipCache {
+	cache := auth.NewGroupMembershipCache(source, log)
+	if source == nil {
+		log.Info("No group source configured, rover_group members come only from OIDC group claims")
+		return cache
+	}
 	groupNames := extractRoverGroups(config)
 	if len(groupNames) == 0 {
 		log.Info("No rover_groups configured, skipping group membership loading")
-		return auth.NewGroupMembershipCache(log)
+		return cache
 	}
 
-	log.WithField("group_count", len(groupNames)).Info("Loading group membership from OpenShift")
-	cache := auth.NewGroupMembershipCache(log)
-	if err := cache.LoadGroups(groupNames, kubeconfigPath); err != nil {
-		log.WithField("error", err).Fatal("Failed to load group membership")
+	log.WithFields(logrus.Fields{
+		"group_count": len(groupNames),
+		"source":      source.Name(),
+	}).Info("Loading group membership")
+	if err := cache.LoadGroups(groupNames); err != nil {
+		log.WithField("error", err).Error("Failed to load some groups, continuing without their members")
+		return cache
 	}
 
 	log.Info("Successfully loaded group membership")
 	return cache
 }
 
+// newOIDCAuthenticator sets up the built-in OIDC login. Group claims are recorded in claimGroups, keeping only the
+// rover_groups in the current config.
+func newOIDCAuthenticator(ctx context.Context, log *logrus.Logger, opts *Options, configManager *config.Manager[types.DashboardConfig], claimGroups *auth.ClaimGroupMembership) (*auth.OIDCAuthenticator, error) {
+	clientSecret, err := os.ReadFile(opts.OIDCClientSecretFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OIDC client secret file: %w", err)
+	}
+	sessionSecret, err := os.ReadFile(opts.OIDCSessionSecretFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OIDC session secret file: %w", err)
+	}
+	secure := strings.HasPrefix(opts.OIDCRedirectURL, "https://")
+	sessions, err := auth.NewSessionManager(bytes.TrimSpace(sessionSecret), opts.OIDCSessionTTL, secure, claimGroups)
+	if err != nil {
+		return nil, err
+	}
+	return auth.NewOIDCAuthenticator(ctx, auth.OIDCConfig{
+		IssuerURL:     opts.OIDCIssuerURL,
+		ClientID:      opts.OIDCClientID,
+		ClientSecret:  strings.TrimSpace(string(clientSecret)),
+		RedirectURL:   opts.OIDCRedirectURL,
+		Scopes:        splitList(opts.OIDCScopes),
+		UsernameClaim: opts.OIDCUsernameClaim,
+		GroupsClaim:   opts.OIDCGroupsClaim,
+		RelevantGroups: func() []string {
+			return extractRoverGroups(configManager.Get())
+		},
+	}, sessions, log)
+}
+
+// newSMTPMailer returns the mailer for email notifications, or nil when no SMTP relay is configured.
+func newSMTPMailer(log *logrus.Logger, opts *Options) *outage.SMTPMailer {
+	if opts.SMTPAddress == "" {
+		log.Info("Email notifications disabled (--smtp-address not set)")
+		return nil
+	}
+	cfg := outage.SMTPConfig{
+		Address:    opts.SMTPAddress,
+		From:       opts.SMTPFrom,
+		Username:   opts.SMTPUsername,
+		RequireTLS: opts.SMTPRequireTLS,
+	}
+	if opts.SMTPPasswordFile != "" {
+		password, err := os.ReadFile(opts.SMTPPasswordFile)
+		if err != nil {
+			log.WithField("error", err).Fatal("Failed to read SMTP password file")
+		}
+		cfg.Password = strings.TrimSpace(string(password))
+	}
+	log.Info("Email notifications enabled")
+	return outage.NewSMTPMailer(cfg)
+}
+
+// newPagerDutyNotifier returns the notifier for paging, or nil when no routing keys file is configured.
+func newPagerDutyNotifier(log *logrus.Logger, opts *Options, configManager *config.Manager[types.DashboardConfig], linkRepo repositories.OutageLinkRepository) *outage.PagerDutyNotifier {
+	if opts.PagerDutyRoutingKeysFile == "" {
+		log.Info("PagerDuty paging disabled (--pagerduty-routing-keys-file not set)")
+		return nil
+	}
+	data, err := os.ReadFile(opts.PagerDutyRoutingKeysFile)
+	if err != nil {
+		log.WithField("error", err).Fatal("Failed to read PagerDuty routing keys file")
+	}
+	cfg := outage.PagerDutyConfig{}
+	if err := yaml.Unmarshal(data, &cfg.RoutingKeys); err != nil {
+		log.WithField("error", err).Fatal("Failed to parse PagerDuty routing keys file")
+	}
+	if opts.PagerDutyAPITokenFile != "" {
+		token, err := os.ReadFile(opts.PagerDutyAPITokenFile)
+		if err != nil {
+			log.WithField("error", err).Fatal("Failed to read PagerDuty API token file")
+		}
+		cfg.APIToken = strings.TrimSpace(string(token))
+	} else {
+		log.Info("PagerDuty incident links disabled (--pagerduty-api-token-file not set)")
+	}
+
+	warnUnknownRoutingKeyRefs(log, configManager.Get(), cfg.RoutingKeys)
+	configManager.OnUpdate(func(newConfig *types.DashboardConfig) {
+		warnUnknownRoutingKeyRefs(log, newConfig, cfg.RoutingKeys)
+	})
+	log.WithField("routing_keys", len(cfg.RoutingKeys)).Info("PagerDuty paging enabled")
+	return outage.NewPagerDutyNotifier(cfg, linkRepo, configManager, opts.SlackBaseURL, log)
+}
+
+// newJiraNotifier returns the notifier for Jira issues, or nil when no Jira server is configured.
+func newJiraNotifier(log *logrus.Logger, opts *Options, configManager *config.Manager[types.DashboardConfig], linkRepo repositories.OutageLinkRepository) *outage.JiraNotifier {
+	if opts.JiraURL == "" {
+		log.Info("Jira integration disabled (--jira-url not set)")
+		return nil
+	}
+	token, err := os.ReadFile(opts.JiraTokenFile)
+	if err != nil {
+		log.WithField("error", err).Fatal("Failed to read Jira token file")
+	}
+	cfg := outage.JiraConfig{
+		URL:          opts.JiraURL,
+		Username:     opts.JiraUsername,
+		Token:        strings.TrimSpace(string(token)),
+		SyncInterval: opts.JiraSyncInterval,
+	}
+	log.WithField("jira_url", opts.JiraURL).Info("Jira integration enabled")
+	return outage.NewJiraNotifier(cfg, linkRepo, configManager, opts.SlackBaseURL, log)
+}
+
+// warnUnknownRoutingKeyRefs logs paging entries whose routing_key_ref is missing from the routing keys file.
+// Such entries cannot page, so the problem should be visible before an outage happens.
+func warnUnknownRoutingKeyRefs(log *logrus.Logger, cfg *types.DashboardConfig, routingKeys map[string]string) {
+	for _, component := range cfg.Components {
+		owners := map[string][]types.PagingConfig{component.Name: component.Paging}
+		for _, sub := range component.Subcomponents {
+			owners[component.Name+"/"+sub.Name] = sub.Paging
+		}
+		for owner, paging := range owners {
+			for _, entry := range paging {
+				if _, ok := routingKeys[entry.RoutingKeyRef]; !ok {
+					log.WithFields(logrus.Fields{
+						"owner":           owner,
+						"routing_key_ref": entry.RoutingKeyRef,
+					}).Warn("Paging routing_key_ref not found in PagerDuty routing keys file")
+				}
+			}
+		}
+	}
+}
+
+// watchSenders returns the delivery channels available for watch notifications.
+// Webhooks are always available; Slack DMs and email depend on their integrations being configured.
+func watchSenders(slackClient *slack.Client, mailer *outage.SMTPMailer) map[types.NotificationChannelType]outage.WatchSender {
+	senders := map[types.NotificationChannelType]outage.WatchSender{
+		types.NotificationChannelWebhook: outage.NewWebhookSender(5 * time.Second),
+	}
+	if slackClient != nil {
+		senders[types.NotificationChannelSlackDM] = outage.NewSlackDMSender(slackClient)
+	}
+	if mailer != nil {
+		senders[types.NotificationChannelEmail] = outage.NewEmailSender(mailer)
+	}
+	return senders
+}
+
+// watchDestinationPolicy limits Slack DMs and email to the user's own account and webhooks to the allowed hosts.
+func watchDestinationPolicy(opts *Options, slackClient *slack.Client) outage.WatchDestinationPolicy {
+	policy := outage.WatchDestinationPolicy{
+		EmailDomain:  strings.TrimPrefix(opts.userEmailDomain(), "@"),
+		WebhookHosts: splitList(opts.WatchWebhookHosts),
+	}
+	if slackClient != nil && policy.EmailDomain != "" {
+		policy.SlackUser = NewSlackEmailIdentityResolver(slackClient, policy.EmailDomain).DashboardUser
+	}
+	return policy
+}
+
 func main() {
 	log := setupLogger()
 	opts := NewOptions()
@@ -243,18 +1006,38 @@ func main() {
 	}
 
 	db := connectDatabase(log, opts.DatabaseDSN)
-	hmacSecret := getHMACSecret(log, opts.HMACSecretFile)
-	groupCache := loadGroupMembership(log, configManager.Get(), opts.KubeconfigPath)
-
-	configManager.OnUpdate(func(newConfig *types.DashboardConfig) {
-		log.Info("Config updated, reloading group membership")
-		newGroups := extractRoverGroups(newConfig)
-		if err := groupCache.LoadGroups(newGroups, opts.KubeconfigPath); err != nil {
-			log.WithField("error", err).Error("Failed to reload group membership")
-		} else {
-			log.Info("Successfully reloaded group membership")
+	var hmacSecret []byte
+	if opts.AuthMode == authModeHMAC {
+		hmacSecret = getHMACSecret(log, opts.HMACSecretFile)
+	}
+	groupSource, err := newGroupSource(opts)
+	if err != nil {
+		log.WithField("error", err).Fatal("Failed to set up group source")
+	}
+	groupCache := loadGroupMembership(log, configManager.Get(), groupSource)
+	if groupSource != nil {
+		if opts.GroupRefreshInterval > 0 {
+			// Without the background refresh, members would expire between config reloads.
+			groupCache.SetMaxStaleness(opts.GroupMaxStaleness)
+			go groupCache.Start(ctx, opts.GroupRefreshInterval)
 		}
-	})
+
+		configManager.OnUpdate(func(newConfig *types.DashboardConfig) {
+			log.Info("Config updated, reloading group membership")
+			newGroups := extractRoverGroups(newConfig)
+			if err := groupCache.LoadGroups(newGroups); err != nil {
+				log.WithField("error", err).Error("Failed to reload group membership")
+			} else {
+				log.Info("Successfully reloaded group membership")
+			}
+		})
+	}
+	var groups auth.GroupMembershipProvider = groupCache
+	var claimGroups *auth.ClaimGroupMembership
+	if opts.AuthMode == authModeOIDC {
+		claimGroups = auth.NewClaimGroupMembership(groupCache)
+		groups = claimGroups
+	}
 
 	var slackClient *slack.Client
 	slackToken := os.Getenv("SLACK_BOT_TOKEN")
@@ -286,7 +1069,63 @@ func main() {
 	pingRepo := repositories.NewGORMComponentPingRepository(db)
 	triageNoteRepo := repositories.NewGORMTriageNoteRepository(db)
 	outageLinkRepo := repositories.NewGORMOutageLinkRepository(db)
-	server := NewServer(configManager, log, opts.CORSOrigin, hmacSecret, groupCache, outageManager, pingRepo, triageNoteRepo, outageLinkRepo)
+	watchRepo := repositories.NewGORMWatchSubscriptionRepository(db)
+	apiTokenRepo := repositories.NewGORMAPITokenRepository(db)
+	securityEventRepo := repositories.NewGORMSecurityEventRepository(db)
+	mailer := newSMTPMailer(log, opts)
+	if mailer != nil {
+		outageManager.AddNotifier(outage.NewEmailNotifier(mailer, configManager, opts.SlackBaseURL, log))
+	}
+	if pagerDuty := newPagerDutyNotifier(log, opts, configManager, outageLinkRepo); pagerDuty != nil {
+		outageManager.AddNotifier(pagerDuty)
+	}
+	if jira := newJiraNotifier(log, opts, configManager, outageLinkRepo); jira != nil {
+		outageManager.AddNotifier(jira)
+		go jira.Start(ctx)
+	}
+	watchDestinations := watchDestinationPolicy(opts, slackClient)
+	outageManager.AddNotifier(outage.NewWatchNotifier(watchRepo, configManager, watchSenders(slackClient, mailer), watchDestinations, opts.SlackBaseURL, log))
+	server := NewServer(configManager, log, opts.CORSOrigin, hmacSecret, groups, outageManager, pingRepo, triageNoteRepo, outageLinkRepo, watchRepo, apiTokenRepo, securityEventRepo)
+	server.SetWatchDestinations(watchDestinations)
+	// Validate has already rejected a malformed list.
+	trustedProxies, _ := opts.trustedProxies()
+	server.SetTrustedProxies(trustedProxies)
+	if opts.AuthMode == authModeOIDC {
+		oidcAuthenticator, err := newOIDCAuthenticator(ctx, log, opts, configManager, claimGroups)
+		if err != nil {
+			log.WithField("error", err).Fatal("Failed to set up OIDC login")
+		}
+		server.EnableOIDC(oidcAuthenticator)
+		log.WithField("issuer", opts.OIDCIssuerURL).Info("OIDC login enabled")
+	}
+	if opts.TokenReview {
+		tokenReviewer, err := auth.NewTokenReviewAuthenticatorForConfig(opts.KubeconfigPath, opts.tokenReviewAudiences(), opts.TokenReviewCacheTTL)
+		if err != nil {
+			log.WithField("error", err).Fatal("Failed to create TokenReview authenticator")
+		}
+		server.EnableTokenReview(tokenReviewer)
+		log.Info("TokenReview authentication enabled")
+	}
+	var idempotencyKeyRepo repositories.IdempotencyKeyRepository
+	if opts.IdempotencyKeyTTL > 0 {
+		idempotencyKeyRepo = repositories.NewGORMIdempotencyKeyRepository(db)
+		server.EnableIdempotencyKeys(NewIdempotencyKeys(idempotencyKeyRepo, opts.IdempotencyKeyTTL, log))
+	}
+	if signingSecret := os.Getenv("SLACK_SIGNING_SECRET"); signingSecret != "" && slackClient != nil {
+		identities := NewSlackEmailIdentityResolver(slackClient, opts.SlackIdentityEmailDomain)
+		slackThreadRepo := repositories.NewGORMSlackThreadRepository(db)
+		server.EnableSlackInteractions(slackClient, slackThreadRepo, signingSecret, identities)
+		server.EnableSlackEvents(slackThreadRepo, signingSecret, identities)
+		outageManager.EnableSlackActions()
+		log.Info("Slack interactivity and events enabled")
+	}
+	if slackClient != nil {
+		outageManager.QueueSlackNotifications(ctx, outage.SlackQueueConfig{
+			CoalesceWindow:  opts.SlackCoalesceWindow,
+			ChannelInterval: opts.SlackChannelInterval,
+			MaxRetries:      opts.SlackMaxRetries,
+		})
+	}
 
 	absentReportChecker := NewAbsentMonitoredComponentReportChecker(configManager, outageManager, pingRepo, opts.AbsentReportCheckInterval, log)
 	go absentReportChecker.Start(ctx)
@@ -294,6 +1133,36 @@ func main() {
 	suspectedExpiryChecker := NewSuspectedOutageExpiryChecker(outageManager, 30*time.Minute, log)
 	go suspectedExpiryChecker.Start(ctx)
 
+	if opts.SecurityEventRetention > 0 {
+		securityEventPruner := NewSecurityEventPruner(securityEventRepo, opts.SecurityEventRetention, time.Hour, log)
+		go securityEventPruner.Start(ctx)
+	}
+
+	if opts.APITokenUseRetention > 0 {
+		apiTokenUsePruner := NewAPITokenUsePruner(apiTokenRepo, opts.APITokenUseRetention, time.Hour, log)
+		go apiTokenUsePruner.Start(ctx)
+	}
+
+	if idempotencyKeyRepo != nil {
+		idempotencyKeyPruner := NewIdempotencyKeyPruner(idempotencyKeyRepo, opts.IdempotencyKeyTTL, time.Hour, log)
+		go idempotencyKeyPruner.Start(ctx)
+	}
+
+	if slackClient != nil {
+		digestScheduler := NewSlackDigestScheduler(configManager, outageManager, pingRepo, slackClient, opts.SlackBaseURL, time.Minute, log)
+		go digestScheduler.Start(ctx)
+
+		statusSyncer := NewSlackChannelStatusSyncer(configManager, outageManager, repositories.NewGORMSlackChannelStatusRepository(db), slackClient, opts.SlackBaseURL, time.Minute, log)
+		outageManager.AddNotifier(statusSyncer)
+		go statusSyncer.Start(ctx)
+
+		escalationChecker := NewEscalationChecker(configManager, outageManager, repositories.NewGORMEscalationRepository(db), repositories.NewGORMSlackThreadRepository(db), slackClient, opts.SlackBaseURL, time.Minute, log)
+		go escalationChecker.Start(ctx)
+	}
+
+	// After every AddNotifier above, so that all notifiers are delivered from the background.
+	outageManager.QueueNotifications(ctx, outage.DefaultNotifierQueueSize)
+
 	addr := ":" + opts.Port
 	go func() {
 		if err := server.Start(addr); err != nil && err != http.ErrServerClosed {
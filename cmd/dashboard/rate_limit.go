@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/types"
+)
+
+const (
+	rateLimitReasonRequests      = "requests"
+	rateLimitReasonSubComponents = "sub_components"
+
+	// rateLimitSweepInterval is how often windows of idle callers are dropped.
+	rateLimitSweepInterval = time.Minute
+)
+
+var (
+	rateLimitedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ship_status_rate_limited_requests_total",
+		Help: "Number of requests rejected with 429 by the rate limiter, by route class and the limit they exceeded.",
+	}, []string{"class", "reason"})
+	rateLimitAllowedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ship_status_rate_limit_allowed_requests_total",
+		Help: "Number of requests counted against a rate limit and allowed, by route class.",
+	}, []string{"class"})
+)
+
+func init() {
+	prometheus.MustRegister(rateLimitedRequests, rateLimitAllowedRequests)
+}
+
+// RateLimiter limits how many requests each caller may make per route class, using the rate_limits of the current
+// config. Callers are identified by the delegator of delegated requests, so a bot cannot get around its limit by
+// acting for many users, and by the authenticated user otherwise.
+type RateLimiter struct {
+	configManager *config.Manager[types.DashboardConfig]
+	logger        *logrus.Logger
+
+	mu        sync.Mutex
+	windows   map[rateLimitKey]*rateLimitWindow
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type rateLimitKey struct {
+	class  types.RateLimitClass
+	caller string
+}
+
+// rateLimitWindow holds a caller's requests of one class within the period.
+type rateLimitWindow struct {
+	requests []time.Time
+	// subComponents holds the last request for each sub-component.
+	subComponents map[string]time.Time
+}
+
+// NewRateLimiter creates a rate limiter reading its limits from configManager.
+func NewRateLimiter(configManager *config.Manager[types.DashboardConfig], logger *logrus.Logger) *RateLimiter {
+	return &RateLimiter{
+		configManager: configManager,
+		logger:        logger,
+		windows:       make(map[rateLimitKey]*rateLimitWindow),
+		now:           time.Now,
+	}
+}
+
+// Limit rejects requests to next with 429 and a Retry-After header once the caller has used up the limit of class.
+func (l *RateLimiter) Limit(class types.RateLimitClass, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := GetDelegatorFromContext(r.Context())
+		if !ok {
+			caller, _ = GetUserFromContext(r.Context())
+		}
+		var subComponent string
+		if vars := mux.Vars(r); vars["subComponentName"] != "" {
+			subComponent = vars["componentName"] + "/" + vars["subComponentName"]
+		}
+
+		retryAfter, reason := l.allow(class, caller, subComponent)
+		if retryAfter > 0 {
+			rateLimitedRequests.WithLabelValues(string(class), reason).Inc()
+			l.logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"path":        r.URL.Path,
+				"caller":      caller,
+				"class":       class,
+				"reason":      reason,
+				"retry_after": retryAfter,
+			}).Warn("Request rate limited")
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			respondWithError(w, http.StatusTooManyRequests, fmt.Sprintf("Rate limit exceeded, retry in %d seconds", seconds))
+			return
+		}
+		rateLimitAllowedRequests.WithLabelValues(string(class)).Inc()
+		next(w, r)
+	}
+}
+
+// allow counts a request by caller for subComponent (empty for routes without one) against the limit of class.
+// When the limit is used up, the request is not counted, and allow returns how long the caller has to wait and
+// which limit was exceeded.
+func (l *RateLimiter) allow(class types.RateLimitClass, caller, subComponent string) (time.Duration, string) {
+	cfg := l.configManager.Get()
+	limit := cfg.RateLimitFor(class)
+	period := limit.PeriodDuration()
+	if limit.Requests <= 0 || period == 0 {
+		return 0, ""
+	}
+
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now, cfg)
+
+	key := rateLimitKey{class: class, caller: caller}
+	window, ok := l.windows[key]
+	if !ok {
+		window = &rateLimitWindow{subComponents: make(map[string]time.Time)}
+		l.windows[key] = window
+	}
+	window.expire(now.Add(-period))
+
+	if len(window.requests) >= limit.Requests {
+		return window.requests[0].Add(period).Sub(now), rateLimitReasonRequests
+	}
+	if _, seen := window.subComponents[subComponent]; limit.SubComponents > 0 && subComponent != "" && !seen && len(window.subComponents) >= limit.SubComponents {
+		oldest := now
+		for _, last := range window.subComponents {
+			if last.Before(oldest) {
+				oldest = last
+			}
+		}
+		return oldest.Add(period).Sub(now), rateLimitReasonSubComponents
+	}
+
+	window.requests = append(window.requests, now)
+	if subComponent != "" {
+		window.subComponents[subComponent] = now
+	}
+	return 0, ""
+}
+
+// sweep drops the windows of callers without requests in the period of their class, at most once per
+// rateLimitSweepInterval.
+func (l *RateLimiter) sweep(now time.Time, cfg *types.DashboardConfig) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, window := range l.windows {
+		window.expire(now.Add(-cfg.RateLimitFor(key.class).PeriodDuration()))
+		if len(window.requests) == 0 {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// expire forgets the requests made before cutoff.
+func (w *rateLimitWindow) expire(cutoff time.Time) {
+	expired := 0
+	for expired < len(w.requests) && !w.requests[expired].After(cutoff) {
+		expired++
+	}
+	w.requests = w.requests[expired:]
+	for subComponent, last := range w.subComponents {
+		if !last.After(cutoff) {
+			delete(w.subComponents, subComponent)
+		}
+	}
+}
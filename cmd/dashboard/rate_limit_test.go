@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/types"
+)
+
+func newTestRateLimiter(t *testing.T, rateLimits map[types.RateLimitClass]types.RateLimit) (*RateLimiter, *time.Time) {
+	t.Helper()
+	cfg := minimalDashboardConfig()
+	cfg.RateLimits = rateLimits
+	h := newTestHandlers(t, cfg, &outage.MockOutageManager{})
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	limiter := NewRateLimiter(h.configManager, logger)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestRateLimiter_Requests(t *testing.T) {
+	limiter, now := newTestRateLimiter(t, map[types.RateLimitClass]types.RateLimit{
+		types.RateLimitOutages: {Requests: 2, Period: "10m"},
+	})
+	handler := limiter.Limit(types.RateLimitOutages, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	send := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler(rec, mux.SetURLVars(req, map[string]string{"componentName": "alpha", "subComponentName": "one"}))
+		return rec
+	}
+	newRequest := func() *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/api/components/alpha/one/outages", nil)
+	}
+
+	assert.Equal(t, http.StatusCreated, send(withUser(newRequest(), "alice")).Code)
+	*now = now.Add(time.Minute)
+	assert.Equal(t, http.StatusCreated, send(withUser(newRequest(), "alice")).Code)
+
+	rec := send(withUser(newRequest(), "alice"))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "540", rec.Header().Get("Retry-After"), "the first request leaves the window in 9 minutes")
+	assert.Equal(t, http.StatusCreated, send(withUser(newRequest(), "bob")).Code, "callers have separate limits")
+
+	// Delegated requests count against the delegator, whoever they act for.
+	assert.Equal(t, http.StatusCreated, send(withDelegation(newRequest(), chatBotServiceAccount, "carol")).Code)
+	assert.Equal(t, http.StatusCreated, send(withDelegation(newRequest(), chatBotServiceAccount, "dave")).Code)
+	assert.Equal(t, http.StatusTooManyRequests, send(withDelegation(newRequest(), chatBotServiceAccount, "erin")).Code)
+
+	*now = now.Add(9 * time.Minute)
+	assert.Equal(t, http.StatusCreated, send(withUser(newRequest(), "alice")).Code, "the first request has left the window")
+	assert.Equal(t, http.StatusTooManyRequests, send(withUser(newRequest(), "alice")).Code)
+}
+
+func TestRateLimiter_ReportSubComponents(t *testing.T) {
+	limiter, now := newTestRateLimiter(t, map[types.RateLimitClass]types.RateLimit{
+		types.RateLimitReports: {Requests: 10, Period: "1h", SubComponents: 2},
+	})
+	handler := limiter.Limit(types.RateLimitReports, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	report := func(subComponent string) *httptest.ResponseRecorder {
+		req := withUser(httptest.NewRequest(http.MethodPost, "/api/components/alpha/"+subComponent+"/outages/report-suspected", nil), "alice")
+		rec := httptest.NewRecorder()
+		handler(rec, mux.SetURLVars(req, map[string]string{"componentName": "alpha", "subComponentName": subComponent}))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, report("one").Code)
+	assert.Equal(t, http.StatusOK, report("one").Code, "reporting a sub-component again is not a new one")
+	*now = now.Add(10 * time.Minute)
+	assert.Equal(t, http.StatusOK, report("two").Code)
+
+	rec := report("three")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "3000", rec.Header().Get("Retry-After"), "one leaves the window in 50 minutes")
+
+	*now = now.Add(50 * time.Minute)
+	assert.Equal(t, http.StatusOK, report("three").Code)
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	limiter, _ := newTestRateLimiter(t, map[types.RateLimitClass]types.RateLimit{
+		types.RateLimitTriageNotes: {Requests: 0},
+	})
+	for range 100 {
+		retryAfter, _ := limiter.allow(types.RateLimitTriageNotes, "alice", "")
+		assert.Zero(t, retryAfter)
+	}
+	for range types.DefaultRateLimits[types.RateLimitLinks].Requests {
+		retryAfter, _ := limiter.allow(types.RateLimitLinks, "alice", "")
+		assert.Zero(t, retryAfter)
+	}
+	retryAfter, reason := limiter.allow(types.RateLimitLinks, "alice", "")
+	assert.Positive(t, retryAfter, "classes missing from rate_limits use the defaults")
+	assert.Equal(t, rateLimitReasonRequests, reason)
+}
+
+func TestRouteRateLimits(t *testing.T) {
+	s := &Server{handlers: newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})}
+	for _, r := range s.routes() {
+		// Component monitors report on a schedule, and are limited by their monitoring frequency instead.
+		if r.protected && r.method != http.MethodGet && r.path != "/api/component-monitor/report" {
+			assert.NotEmpty(t, r.rateLimit, "%s %s must be rate limited", r.method, r.path)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+const (
+	// authFailureEventsPerAddress is how many authentication failures from one client address are stored per
+	// authFailureEventWindow. Anonymous requests can fail authentication as fast as they are sent, and each
+	// stored event is a database insert.
+	authFailureEventsPerAddress = 10
+	authFailureEventWindow      = time.Minute
+)
+
+var (
+	securityEventsRecorded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ship_status_security_events_total",
+		Help: "Number of security events, by type, including those that failed to be stored.",
+	}, []string{"type"})
+	securityEventsSuppressed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ship_status_security_events_suppressed_total",
+		Help: "Number of authentication failures not stored because their client address exceeded its limit.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(securityEventsRecorded, securityEventsSuppressed)
+}
+
+// SecurityEventRecorder stores authentication failures, denials, delegated requests and admin actions in the
+// security event log, so they can be looked up after the transient log lines are gone.
+type SecurityEventRecorder struct {
+	repo   repositories.SecurityEventRepository
+	logger *logrus.Logger
+
+	// trustedProxies are the proxies whose X-Forwarded-For header gives the client address of a request.
+	trustedProxies []netip.Prefix
+
+	mu sync.Mutex
+	// authFailures counts the authentication failures stored for each client address in the current window.
+	authFailures      map[string]int
+	authFailuresSince time.Time
+	now               func() time.Time
+}
+
+// NewSecurityEventRecorder creates a SecurityEventRecorder storing events in repo.
+func NewSecurityEventRecorder(repo repositories.SecurityEventRepository, logger *logrus.Logger) *SecurityEventRecorder {
+	return &SecurityEventRecorder{repo: repo, logger: logger, authFailures: make(map[string]int), now: time.Now}
+}
+
+// Record stores event for request r. The request's method, path, remote address and component slugs, and the
+// user and delegator in its context, fill in the fields event leaves empty. Failing to store the event is logged
+// and does not change the response, so the log cannot be used to turn rejected requests into errors.
+func (rec *SecurityEventRecorder) Record(r *http.Request, event types.SecurityEvent) {
+	event.Method = r.Method
+	event.Path = r.URL.Path
+	event.RemoteAddr = r.RemoteAddr
+	vars := mux.Vars(r)
+	if event.ComponentName == "" {
+		event.ComponentName = vars["componentName"]
+	}
+	if event.SubComponentName == "" {
+		event.SubComponentName = vars["subComponentName"]
+	}
+	if event.User == "" {
+		event.User, _ = GetUserFromContext(r.Context())
+	}
+	if event.Delegator == "" {
+		event.Delegator, _ = GetDelegatorFromContext(r.Context())
+	}
+
+	securityEventsRecorded.WithLabelValues(string(event.Type)).Inc()
+	if event.Type == types.SecurityEventAuthFailure {
+		if client := rec.clientAddress(r); !rec.allowAuthFailure(client) {
+			securityEventsSuppressed.Inc()
+			rec.logger.WithFields(logrus.Fields{
+				"method":      event.Method,
+				"path":        event.Path,
+				"remote_addr": event.RemoteAddr,
+				"client_addr": client,
+			}).Debug("Not storing authentication failure from address over its limit")
+			return
+		}
+	}
+	if err := rec.repo.RecordEvent(&event); err != nil {
+		rec.logger.WithFields(logrus.Fields{
+			"type":   event.Type,
+			"method": event.Method,
+			"path":   event.Path,
+			"user":   event.User,
+			"error":  err,
+		}).Error("Failed to record security event")
+	}
+}
+
+// clientAddress returns the address of the client that sent r, without its port. Requests from a trusted proxy
+// are attributed to the last address in X-Forwarded-For that is not a trusted proxy, since each proxy appends the
+// address it received the request from and anything before that can be set by the client.
+func (rec *SecurityEventRecorder) clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !rec.trustedProxy(host) {
+		return host
+	}
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwarded[i])
+		if _, err := netip.ParseAddr(addr); err != nil {
+			break
+		}
+		host = addr
+		if !rec.trustedProxy(addr) {
+			break
+		}
+	}
+	return host
+}
+
+func (rec *SecurityEventRecorder) trustedProxy(host string) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range rec.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowAuthFailure reports whether another authentication failure from client may be stored in the current
+// window, and counts it when it may.
+func (rec *SecurityEventRecorder) allowAuthFailure(client string) bool {
+	now := rec.now()
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if now.Sub(rec.authFailuresSince) >= authFailureEventWindow {
+		clear(rec.authFailures)
+		rec.authFailuresSince = now
+	}
+	if rec.authFailures[client] >= authFailureEventsPerAddress {
+		return false
+	}
+	rec.authFailures[client]++
+	return true
+}
+
+// respondForbidden records the denial of permission (empty for checks that are not about one) and responds
+// with 403 and message.
+func (h *Handlers) respondForbidden(w http.ResponseWriter, r *http.Request, permission types.Permission, message string) {
+	h.securityEvents.Record(r, types.SecurityEvent{
+		Type:       types.SecurityEventAccessDenied,
+		Permission: permission,
+		Reason:     message,
+	})
+	respondWithError(w, http.StatusForbidden, message)
+}
+
+// SecurityEventPruner deletes security events older than the retention period.
+type SecurityEventPruner struct {
+	repo          repositories.SecurityEventRepository
+	retention     time.Duration
+	checkInterval time.Duration
+	logger        *logrus.Logger
+}
+
+// NewSecurityEventPruner creates a new SecurityEventPruner.
+func NewSecurityEventPruner(repo repositories.SecurityEventRepository, retention, checkInterval time.Duration, logger *logrus.Logger) *SecurityEventPruner {
+	return &SecurityEventPruner{
+		repo:          repo,
+		retention:     retention,
+		checkInterval: checkInterval,
+		logger:        logger,
+	}
+}
+
+// Start prunes expired events once, then on every check interval until ctx is done.
+func (p *SecurityEventPruner) Start(ctx context.Context) {
+	p.logger.WithFields(logrus.Fields{
+		"retention":      p.retention,
+		"check_interval": p.checkInterval,
+	}).Info("Starting security event pruner")
+	p.prune(time.Now())
+
+	ticker := time.NewTicker(p.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("Stopping security event pruner")
+			return
+		case now := <-ticker.C:
+			p.prune(now)
+		}
+	}
+}
+
+// prune deletes the events created more than the retention period before now.
+func (p *SecurityEventPruner) prune(now time.Time) {
+	deleted, err := p.repo.DeleteEventsBefore(now.Add(-p.retention))
+	if err != nil {
+		p.logger.WithField("error", err).Error("Failed to prune security events")
+		return
+	}
+	if deleted > 0 {
+		p.logger.WithField("deleted", deleted).Info("Pruned expired security events")
+	}
+}
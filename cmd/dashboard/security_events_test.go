@@ -0,0 +1,310 @@
+package main
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/18F/hmacauth"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/auth"
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+func TestAuthMiddleware_SecurityEvents(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.TrustedDelegators = []string{mcpServiceAccount}
+	hmacAuth := hmacauth.NewHmacAuth(crypto.SHA256, []byte("secret"), auth.GAPSignatureHeader, auth.OAuthSignatureHeaders)
+
+	tests := []struct {
+		name       string
+		method     string
+		headers    map[string]string
+		sign       bool
+		wantStatus int
+		wantEvent  *types.SecurityEvent
+	}{
+		{
+			name:       "missing X-Forwarded-User",
+			method:     http.MethodPost,
+			wantStatus: http.StatusUnauthorized,
+			wantEvent:  &types.SecurityEvent{Type: types.SecurityEventAuthFailure, Reason: "Missing X-Forwarded-User header"},
+		},
+		{
+			name:       "missing signature",
+			method:     http.MethodPost,
+			headers:    map[string]string{"X-Forwarded-User": "mallory"},
+			wantStatus: http.StatusUnauthorized,
+			wantEvent:  &types.SecurityEvent{Type: types.SecurityEventAuthFailure, User: "mallory", Reason: "Missing GAP-Signature header"},
+		},
+		{
+			name:       "invalid signature",
+			method:     http.MethodPost,
+			headers:    map[string]string{"X-Forwarded-User": "mallory", auth.GAPSignatureHeader: "sha256 aW52YWxpZA=="},
+			wantStatus: http.StatusUnauthorized,
+			wantEvent:  &types.SecurityEvent{Type: types.SecurityEventAuthFailure, User: "mallory", Reason: "Invalid signature"},
+		},
+		{
+			name:       "delegator without acting for",
+			method:     http.MethodPost,
+			headers:    map[string]string{"X-Forwarded-User": mcpServiceAccount},
+			sign:       true,
+			wantStatus: http.StatusBadRequest,
+			wantEvent:  &types.SecurityEvent{Type: types.SecurityEventAuthFailure, Delegator: mcpServiceAccount, Reason: "acting_for is required for delegated requests"},
+		},
+		{
+			name:       "delegated write",
+			method:     http.MethodPost,
+			headers:    map[string]string{"X-Forwarded-User": mcpServiceAccount, actingForHeader: "alice"},
+			sign:       true,
+			wantStatus: http.StatusOK,
+			wantEvent:  &types.SecurityEvent{Type: types.SecurityEventDelegation, User: "alice", Delegator: mcpServiceAccount},
+		},
+		{
+			name:       "delegated reads are not recorded",
+			method:     http.MethodGet,
+			headers:    map[string]string{"X-Forwarded-User": mcpServiceAccount, actingForHeader: "alice"},
+			sign:       true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "authenticated requests are not recorded",
+			method:     http.MethodPost,
+			headers:    map[string]string{"X-Forwarded-User": "alice"},
+			sign:       true,
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandlers(t, cfg, &outage.MockOutageManager{})
+			logger := logrus.New()
+			logger.SetLevel(logrus.PanicLevel)
+			middleware := authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}), logger, hmacAuth, h.configManager, h.apiTokenRepo, h.securityEvents, nil, nil)
+
+			req := httptest.NewRequest(tt.method, "/api/components/alpha/one/outages", nil)
+			for header, value := range tt.headers {
+				req.Header.Set(header, value)
+			}
+			if tt.sign {
+				hmacAuth.SignRequest(req)
+			}
+			req = mux.SetURLVars(req, map[string]string{"componentName": "alpha", "subComponentName": "one"})
+			rec := httptest.NewRecorder()
+			middleware.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+
+			events := h.securityEventRepo.(*repositories.MockSecurityEventRepository).Events
+			if tt.wantEvent == nil {
+				assert.Empty(t, events)
+				return
+			}
+			require.Len(t, events, 1)
+			assert.Equal(t, tt.wantEvent.Type, events[0].Type)
+			assert.Equal(t, tt.wantEvent.User, events[0].User)
+			assert.Equal(t, tt.wantEvent.Delegator, events[0].Delegator)
+			assert.Equal(t, tt.wantEvent.Reason, events[0].Reason)
+			assert.Equal(t, tt.method, events[0].Method)
+			assert.Equal(t, "/api/components/alpha/one/outages", events[0].Path)
+			assert.Equal(t, "alpha", events[0].ComponentName)
+			assert.Equal(t, "one", events[0].SubComponentName)
+		})
+	}
+}
+
+func TestSecurityEventRecorder_LimitsAuthFailuresPerAddress(t *testing.T) {
+	repo := &repositories.MockSecurityEventRepository{}
+	recorder := NewSecurityEventRecorder(repo, logrus.New())
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	recorder.now = func() time.Time { return now }
+
+	fail := func(remoteAddr string, eventType types.SecurityEventType) {
+		req := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+		req.RemoteAddr = remoteAddr
+		recorder.Record(req, types.SecurityEvent{Type: eventType, Reason: "Invalid signature"})
+	}
+	for i := range authFailureEventsPerAddress + 5 {
+		// Each request comes from a new connection, so from a new port.
+		fail(fmt.Sprintf("203.0.113.7:%d", 40000+i), types.SecurityEventAuthFailure)
+	}
+	assert.Len(t, repo.Events, authFailureEventsPerAddress, "failures over the limit are not stored")
+
+	fail("198.51.100.2:40000", types.SecurityEventAuthFailure)
+	fail("203.0.113.7:50000", types.SecurityEventAccessDenied)
+	assert.Len(t, repo.Events, authFailureEventsPerAddress+2, "other addresses and other event types are stored")
+
+	now = now.Add(authFailureEventWindow)
+	fail("203.0.113.7:50001", types.SecurityEventAuthFailure)
+	assert.Len(t, repo.Events, authFailureEventsPerAddress+3, "the limit starts over in the next window")
+}
+
+func TestSecurityEventRecorder_ClientAddress(t *testing.T) {
+	recorder := NewSecurityEventRecorder(&repositories.MockSecurityEventRepository{}, logrus.New())
+	recorder.trustedProxies = []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32"), netip.MustParsePrefix("10.128.0.0/14")}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		wantClient   string
+	}{
+		{name: "direct request", remoteAddr: "203.0.113.7:40000", wantClient: "203.0.113.7"},
+		{name: "forwarded header from an untrusted address is ignored", remoteAddr: "203.0.113.7:40000", forwardedFor: []string{"198.51.100.2"}, wantClient: "203.0.113.7"},
+		{name: "sidecar proxy", remoteAddr: "127.0.0.1:40000", forwardedFor: []string{"203.0.113.7"}, wantClient: "203.0.113.7"},
+		{name: "router and sidecar proxy", remoteAddr: "127.0.0.1:40000", forwardedFor: []string{"203.0.113.7, 10.128.2.5"}, wantClient: "203.0.113.7"},
+		{name: "addresses set by the client are skipped", remoteAddr: "127.0.0.1:40000", forwardedFor: []string{"192.0.2.1", "203.0.113.7"}, wantClient: "203.0.113.7"},
+		{name: "trusted proxy without a forwarded header", remoteAddr: "127.0.0.1:40000", wantClient: "127.0.0.1"},
+		{name: "malformed forwarded entry", remoteAddr: "127.0.0.1:40000", forwardedFor: []string{"203.0.113.7, not-an-ip"}, wantClient: "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			assert.Equal(t, tt.wantClient, recorder.clientAddress(req))
+		})
+	}
+}
+
+func TestSecurityEventRecorder_LimitsAuthFailuresPerClientBehindProxy(t *testing.T) {
+	repo := &repositories.MockSecurityEventRepository{}
+	recorder := NewSecurityEventRecorder(repo, logrus.New())
+	recorder.trustedProxies = []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")}
+
+	fail := func(client string) {
+		req := httptest.NewRequest(http.MethodGet, "/api/user", nil)
+		req.RemoteAddr = "127.0.0.1:40000"
+		req.Header.Set("X-Forwarded-For", client)
+		recorder.Record(req, types.SecurityEvent{Type: types.SecurityEventAuthFailure, Reason: "Invalid signature"})
+	}
+	for range authFailureEventsPerAddress + 5 {
+		fail("203.0.113.7")
+	}
+	fail("198.51.100.2")
+	require.Len(t, repo.Events, authFailureEventsPerAddress+1, "one client over its limit does not stop failures from others behind the same proxy")
+	assert.Equal(t, "127.0.0.1:40000", repo.Events[authFailureEventsPerAddress].RemoteAddr)
+}
+
+func TestSecurityEvents_AccessDenied(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.Components[0].Owners = []types.Owner{{User: "alice", Role: types.RoleResponder}}
+	cfg.GlobalRoles = []types.Owner{{User: "admin", Role: types.RoleAdmin}}
+	h := newTestHandlers(t, cfg, &outage.MockOutageManager{})
+	repo := h.securityEventRepo.(*repositories.MockSecurityEventRepository)
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	req := withUser(httptest.NewRequest(http.MethodPost, "/api/components/alpha/one/outages", nil), "mallory")
+	req = mux.SetURLVars(req, map[string]string{"componentName": "alpha", "subComponentName": "one"})
+	rec := httptest.NewRecorder()
+	h.requireComponentPermission(types.PermissionCreateOutage, ok)(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	require.Len(t, repo.Events, 1)
+	assert.Equal(t, types.SecurityEventAccessDenied, repo.Events[0].Type)
+	assert.Equal(t, "mallory", repo.Events[0].User)
+	assert.Equal(t, "alpha", repo.Events[0].ComponentName)
+	assert.Equal(t, types.PermissionCreateOutage, repo.Events[0].Permission)
+
+	rec = httptest.NewRecorder()
+	h.requireComponentPermission(types.PermissionCreateOutage, ok)(rec, mux.SetURLVars(
+		withUser(httptest.NewRequest(http.MethodPost, "/api/components/alpha/one/outages", nil), "alice"),
+		map[string]string{"componentName": "alpha", "subComponentName": "one"}))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, repo.Events, 1, "allowed requests are not recorded")
+
+	rec = httptest.NewRecorder()
+	h.requireGlobalAdmin(ok)(rec, withUser(httptest.NewRequest(http.MethodGet, "/api/admin/groups", nil), "alice"))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	require.Len(t, repo.Events, 2)
+	assert.Equal(t, types.SecurityEventAccessDenied, repo.Events[1].Type)
+	assert.Equal(t, "Admin role required", repo.Events[1].Reason)
+
+	rec = httptest.NewRecorder()
+	h.requireGlobalAdmin(ok)(rec, withUser(httptest.NewRequest(http.MethodGet, "/api/admin/groups", nil), "admin"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, repo.Events, 3)
+	assert.Equal(t, types.SecurityEventAdminAction, repo.Events[2].Type)
+	assert.Equal(t, "admin", repo.Events[2].User)
+	assert.Equal(t, "/api/admin/groups", repo.Events[2].Path)
+}
+
+func TestGetSecurityEventsJSON(t *testing.T) {
+	h := newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})
+	repo := h.securityEventRepo.(*repositories.MockSecurityEventRepository)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo.Events = []types.SecurityEvent{
+		{Type: types.SecurityEventAccessDenied, User: "mallory", ComponentName: "alpha"},
+		{Type: types.SecurityEventAuthFailure, User: "mallory"},
+		{Type: types.SecurityEventDelegation, User: "alice", Delegator: mcpServiceAccount, ComponentName: "alpha"},
+		{Type: types.SecurityEventAccessDenied, User: "bob", ComponentName: "beta"},
+	}
+	for i := range repo.Events {
+		repo.Events[i].ID = uint(i + 1)
+		repo.Events[i].CreatedAt = base.Add(time.Duration(i) * time.Hour)
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []uint
+	}{
+		{name: "all events, newest first", wantStatus: http.StatusOK, wantIDs: []uint{4, 3, 2, 1}},
+		{name: "by type", query: "type=access_denied", wantStatus: http.StatusOK, wantIDs: []uint{4, 1}},
+		{name: "by user and component", query: "user=mallory&componentName=alpha", wantStatus: http.StatusOK, wantIDs: []uint{1}},
+		{name: "by delegator", query: "delegator=" + mcpServiceAccount, wantStatus: http.StatusOK, wantIDs: []uint{3}},
+		{name: "by time", query: "start=2026-03-01T13:00:00Z&end=2026-03-01T14:00:00Z", wantStatus: http.StatusOK, wantIDs: []uint{3, 2}},
+		{name: "limit", query: "limit=1", wantStatus: http.StatusOK, wantIDs: []uint{4}},
+		{name: "no match", query: "user=nobody", wantStatus: http.StatusOK, wantIDs: []uint{}},
+		{name: "unknown type", query: "type=login", wantStatus: http.StatusBadRequest},
+		{name: "invalid start", query: "start=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "start after end", query: "start=2026-03-02T00:00:00Z&end=2026-03-01T00:00:00Z", wantStatus: http.StatusBadRequest},
+		{name: "limit too large", query: "limit=1001", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.GetSecurityEventsJSON(rec, withUser(httptest.NewRequest(http.MethodGet, "/api/admin/security-events?"+tt.query, nil), "admin"))
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var events []types.SecurityEvent
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &events))
+			ids := []uint{}
+			for _, event := range events {
+				ids = append(ids, event.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestSecurityEventPruner(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &repositories.MockSecurityEventRepository{Events: []types.SecurityEvent{
+		{Model: gorm.Model{ID: 1, CreatedAt: now.Add(-100 * 24 * time.Hour)}, Type: types.SecurityEventAuthFailure},
+		{Model: gorm.Model{ID: 2, CreatedAt: now.Add(-time.Hour)}, Type: types.SecurityEventAuthFailure},
+	}}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	NewSecurityEventPruner(repo, 90*24*time.Hour, time.Hour, logger).prune(now)
+	require.Len(t, repo.Events, 1)
+	assert.Equal(t, uint(2), repo.Events[0].ID)
+}
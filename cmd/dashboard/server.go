@@ -3,13 +3,16 @@ package main
 import (
 	"context"
 	"net/http"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
 
 	"ship-status-dash/pkg/auth"
 	"ship-status-dash/pkg/config"
@@ -26,27 +29,97 @@ type Server struct {
 	corsOrigin    string
 	hmacSecret    []byte
 	httpServer    *http.Server
+	rateLimiter   *RateLimiter
+	// slackInteractions is nil unless Slack interactivity is enabled.
+	slackInteractions *SlackInteractionHandler
+	// slackEvents is nil unless the Slack Events API endpoint is enabled.
+	slackEvents *SlackEventHandler
+	// tokenReviewer is nil unless bearer tokens are validated with the TokenReview API.
+	tokenReviewer *auth.TokenReviewAuthenticator
+	// oidc is nil unless users log in through the built-in OIDC flow instead of oauth-proxy.
+	oidc *auth.OIDCAuthenticator
+	// idempotencyKeys is nil unless the Idempotency-Key header is honored on create routes.
+	idempotencyKeys *IdempotencyKeys
 }
 
 // NewServer creates a new Server instance
-func NewServer(configManager *config.Manager[types.DashboardConfig], logger *logrus.Logger, corsOrigin string, hmacSecret []byte, groupCache auth.GroupMembershipProvider, outageManager outage.OutageManager, pingRepo repositories.ComponentPingRepository, triageNoteRepo repositories.TriageNoteRepository, outageLinkRepo repositories.OutageLinkRepository) *Server {
+func NewServer(configManager *config.Manager[types.DashboardConfig], logger *logrus.Logger, corsOrigin string, hmacSecret []byte, groupCache auth.GroupMembershipProvider, outageManager outage.OutageManager, pingRepo repositories.ComponentPingRepository, triageNoteRepo repositories.TriageNoteRepository, outageLinkRepo repositories.OutageLinkRepository, watchRepo repositories.WatchSubscriptionRepository, apiTokenRepo repositories.APITokenRepository, securityEventRepo repositories.SecurityEventRepository) *Server {
 	return &Server{
 		logger:        logger,
 		configManager: configManager,
-		handlers:      NewHandlers(logger, configManager, outageManager, pingRepo, triageNoteRepo, outageLinkRepo, groupCache),
+		handlers:      NewHandlers(logger, configManager, outageManager, pingRepo, triageNoteRepo, outageLinkRepo, watchRepo, apiTokenRepo, securityEventRepo, groupCache),
 		corsOrigin:    corsOrigin,
 		hmacSecret:    hmacSecret,
+		rateLimiter:   NewRateLimiter(configManager, logger),
 	}
 }
 
+// EnableTokenReview authenticates protected requests carrying a Kubernetes bearer token, without an oauth-proxy in front.
+func (s *Server) EnableTokenReview(tokenReviewer *auth.TokenReviewAuthenticator) {
+	s.tokenReviewer = tokenReviewer
+}
+
+// EnableOIDC serves the OIDC login endpoints and authenticates protected requests by their session cookie
+// instead of the oauth-proxy HMAC signature.
+func (s *Server) EnableOIDC(oidc *auth.OIDCAuthenticator) {
+	s.oidc = oidc
+}
+
+// EnableIdempotencyKeys honors the Idempotency-Key header on create routes, replaying the stored response to retries.
+func (s *Server) EnableIdempotencyKeys(idempotencyKeys *IdempotencyKeys) {
+	s.idempotencyKeys = idempotencyKeys
+}
+
+// EnableSlackInteractions serves the Slack interactivity endpoint for the actions on outage messages.
+func (s *Server) EnableSlackInteractions(slackClient *slack.Client, slackThreadRepo repositories.SlackThreadRepository, signingSecret string, identities SlackIdentityResolver) {
+	s.slackInteractions = NewSlackInteractionHandler(s.handlers, slackClient, slackThreadRepo, signingSecret, identities, s.logger)
+}
+
+// EnableSlackEvents serves the Slack Events API endpoint that mirrors outage thread replies into triage notes.
+func (s *Server) EnableSlackEvents(slackThreadRepo repositories.SlackThreadRepository, signingSecret string, identities SlackIdentityResolver) {
+	s.slackEvents = NewSlackEventHandler(s.handlers, slackThreadRepo, signingSecret, identities, s.logger)
+}
+
+// SetTrustedProxies sets the proxies whose X-Forwarded-For header is used to attribute authentication failures
+// to their client.
+func (s *Server) SetTrustedProxies(proxies []netip.Prefix) {
+	s.handlers.securityEvents.trustedProxies = proxies
+}
+
+// SetWatchDestinations sets where users may have their watch notifications sent. Until it is called, every
+// notification destination is refused.
+func (s *Server) SetWatchDestinations(policy outage.WatchDestinationPolicy) {
+	s.handlers.watchDestinations = policy
+}
+
 type route struct {
 	path      string
 	method    string
 	handler   func(http.ResponseWriter, *http.Request)
 	protected bool
+	// permission is required on the component in the path of a protected route. Without one, any
+	// authenticated user may call the route and the handler does its own checks.
+	permission types.Permission
+	// admin restricts a protected route to users with the admin role in global_roles.
+	admin bool
+	// checksDelegation marks a protected route without a permission whose handler applies delegation rules
+	// and API token scopes itself. Other routes without a permission are closed to delegators with a delegation
+	// rule, and to scoped API tokens unless tokenScope is set.
+	checksDelegation bool
+	// tokenScope opens a protected route without a permission to API tokens scoped for it. The handler still
+	// authorizes the user itself.
+	tokenScope types.Permission
+	// rateLimit counts requests to a protected route against the caller's rate limit for the class.
+	rateLimit types.RateLimitClass
+	// optionalAuth authenticates requests to an unprotected route that carry credentials, so that the handler
+	// can show internal items to authenticated users. Requests without credentials are served anonymously.
+	optionalAuth bool
+	// idempotent marks a protected create route that replays its response to retries with the same Idempotency-Key.
+	idempotent bool
 }
 
-func (s *Server) setupRoutes() http.Handler {
+// routes returns every API route served by the dashboard.
+func (s *Server) routes() []route {
 	routes := []route{
 		{
 			path:      "/health",
@@ -55,22 +128,31 @@ func (s *Server) setupRoutes() http.Handler {
 			protected: false,
 		},
 		{
-			path:      "/api/status",
+			path:      "/metrics",
 			method:    http.MethodGet,
-			handler:   s.handlers.GetAllComponentsStatusJSON,
+			handler:   promhttp.Handler().ServeHTTP,
 			protected: false,
 		},
 		{
-			path:      "/api/status/{componentName}",
-			method:    http.MethodGet,
-			handler:   s.handlers.GetComponentStatusJSON,
-			protected: false,
+			path:         "/api/status",
+			method:       http.MethodGet,
+			handler:      s.handlers.GetAllComponentsStatusJSON,
+			protected:    false,
+			optionalAuth: true,
 		},
 		{
-			path:      "/api/status/{componentName}/{subComponentName}",
-			method:    http.MethodGet,
-			handler:   s.handlers.GetSubComponentStatusJSON,
-			protected: false,
+			path:         "/api/status/{componentName}",
+			method:       http.MethodGet,
+			handler:      s.handlers.GetComponentStatusJSON,
+			protected:    false,
+			optionalAuth: true,
+		},
+		{
+			path:         "/api/status/{componentName}/{subComponentName}",
+			method:       http.MethodGet,
+			handler:      s.handlers.GetSubComponentStatusJSON,
+			protected:    false,
+			optionalAuth: true,
 		},
 		{
 			path:      "/api/components",
@@ -91,9 +173,23 @@ func (s *Server) setupRoutes() http.Handler {
 			protected: false,
 		},
 		{
-			path:      "/api/outages/during",
+			path:         "/api/outages/during",
+			method:       http.MethodGet,
+			handler:      s.handlers.GetOutagesDuringJSON,
+			protected:    false,
+			optionalAuth: true,
+		},
+		{
+			path:         "/api/audit-logs",
+			method:       http.MethodGet,
+			handler:      s.handlers.GetAuditLogsJSON,
+			protected:    false,
+			optionalAuth: true,
+		},
+		{
+			path:      "/api/outage-history",
 			method:    http.MethodGet,
-			handler:   s.handlers.GetOutagesDuringJSON,
+			handler:   s.handlers.GetOutageHistoryJSON,
 			protected: false,
 		},
 		{
@@ -103,10 +199,11 @@ func (s *Server) setupRoutes() http.Handler {
 			protected: false,
 		},
 		{
-			path:      "/api/components/{componentName}/outages",
-			method:    http.MethodGet,
-			handler:   s.handlers.GetOutagesJSON,
-			protected: false,
+			path:         "/api/components/{componentName}/outages",
+			method:       http.MethodGet,
+			handler:      s.handlers.GetOutagesJSON,
+			protected:    false,
+			optionalAuth: true,
 		},
 		{
 			path:      "/api/components/{componentName}/{subComponentName}/outage-history",
@@ -115,106 +212,209 @@ func (s *Server) setupRoutes() http.Handler {
 			protected: false,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}",
-			method:    http.MethodGet,
-			handler:   s.handlers.GetOutageJSON,
-			protected: false,
+			path:         "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}",
+			method:       http.MethodGet,
+			handler:      s.handlers.GetOutageJSON,
+			protected:    false,
+			optionalAuth: true,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages",
-			method:    http.MethodGet,
-			handler:   s.handlers.GetSubComponentOutagesJSON,
-			protected: false,
+			path:         "/api/components/{componentName}/{subComponentName}/outages",
+			method:       http.MethodGet,
+			handler:      s.handlers.GetSubComponentOutagesJSON,
+			protected:    false,
+			optionalAuth: true,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/audit-logs",
-			method:    http.MethodGet,
-			handler:   s.handlers.GetOutageAuditLogsJSON,
-			protected: false,
+			path:         "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/audit-logs",
+			method:       http.MethodGet,
+			handler:      s.handlers.GetOutageAuditLogsJSON,
+			protected:    false,
+			optionalAuth: true,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/triage-notes",
-			method:    http.MethodGet,
-			handler:   s.handlers.GetTriageNotesJSON,
-			protected: false,
+			path:         "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/triage-notes",
+			method:       http.MethodGet,
+			handler:      s.handlers.GetTriageNotesJSON,
+			protected:    false,
+			optionalAuth: true,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/links",
-			method:    http.MethodGet,
-			handler:   s.handlers.GetOutageLinksJSON,
-			protected: false,
+			path:         "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/links",
+			method:       http.MethodGet,
+			handler:      s.handlers.GetOutageLinksJSON,
+			protected:    false,
+			optionalAuth: true,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}",
-			method:    http.MethodPatch,
-			handler:   s.handlers.UpdateOutageJSON,
-			protected: true,
+			path:       "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}",
+			method:     http.MethodPatch,
+			handler:    s.handlers.UpdateOutageJSON,
+			protected:  true,
+			permission: types.PermissionUpdateOutage,
+			rateLimit:  types.RateLimitOutages,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}",
-			method:    http.MethodDelete,
-			handler:   s.handlers.DeleteOutage,
+			path:       "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}",
+			method:     http.MethodDelete,
+			handler:    s.handlers.DeleteOutage,
+			protected:  true,
+			permission: types.PermissionDeleteOutage,
+			rateLimit:  types.RateLimitOutages,
+		},
+		{
+			path:       "/api/components/{componentName}/{subComponentName}/outages",
+			method:     http.MethodPost,
+			handler:    s.handlers.CreateOutageJSON,
+			protected:  true,
+			permission: types.PermissionCreateOutage,
+			rateLimit:  types.RateLimitOutages,
+			idempotent: true,
+		},
+		{
+			path:       "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/triage-notes",
+			method:     http.MethodPost,
+			handler:    s.handlers.AddTriageNoteJSON,
+			protected:  true,
+			permission: types.PermissionAddTriageNote,
+			rateLimit:  types.RateLimitTriageNotes,
+			idempotent: true,
+		},
+		{
+			path:             "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/triage-notes/{noteId:[0-9]+}",
+			method:           http.MethodPatch,
+			handler:          s.handlers.UpdateTriageNoteJSON,
+			protected:        true,
+			checksDelegation: true,
+			rateLimit:        types.RateLimitTriageNotes,
+		},
+		{
+			path:             "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/triage-notes/{noteId:[0-9]+}",
+			method:           http.MethodDelete,
+			handler:          s.handlers.DeleteTriageNoteJSON,
+			protected:        true,
+			checksDelegation: true,
+			rateLimit:        types.RateLimitTriageNotes,
+		},
+		{
+			path:       "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/links",
+			method:     http.MethodPost,
+			handler:    s.handlers.AddOutageLinkJSON,
+			protected:  true,
+			permission: types.PermissionWriteLink,
+			rateLimit:  types.RateLimitLinks,
+			idempotent: true,
+		},
+		{
+			path:       "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/links/{linkId:[0-9]+}",
+			method:     http.MethodPatch,
+			handler:    s.handlers.UpdateOutageLinkJSON,
+			protected:  true,
+			permission: types.PermissionWriteLink,
+			rateLimit:  types.RateLimitLinks,
+		},
+		{
+			path:       "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/links/{linkId:[0-9]+}",
+			method:     http.MethodDelete,
+			handler:    s.handlers.DeleteOutageLinkJSON,
+			protected:  true,
+			permission: types.PermissionDeleteLink,
+			rateLimit:  types.RateLimitLinks,
+		},
+		{
+			path:      "/api/user",
+			method:    http.MethodGet,
+			handler:   s.handlers.GetAuthenticatedUserJSON,
 			protected: true,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages",
-			method:    http.MethodPost,
-			handler:   s.handlers.CreateOutageJSON,
+			path:      "/api/user/activity",
+			method:    http.MethodGet,
+			handler:   s.handlers.GetUserActivityJSON,
 			protected: true,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/triage-notes",
-			method:    http.MethodPost,
-			handler:   s.handlers.AddTriageNoteJSON,
+			path:      "/api/user/subscriptions",
+			method:    http.MethodGet,
+			handler:   s.handlers.ListWatchSubscriptionsJSON,
 			protected: true,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/triage-notes/{noteId:[0-9]+}",
-			method:    http.MethodPatch,
-			handler:   s.handlers.UpdateTriageNoteJSON,
+			path:      "/api/user/subscriptions",
+			method:    http.MethodPost,
+			handler:   s.handlers.CreateWatchSubscriptionJSON,
 			protected: true,
+			rateLimit: types.RateLimitAccount,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/triage-notes/{noteId:[0-9]+}",
+			path:      "/api/user/subscriptions/{subscriptionId:[0-9]+}",
 			method:    http.MethodDelete,
-			handler:   s.handlers.DeleteTriageNoteJSON,
+			handler:   s.handlers.DeleteWatchSubscriptionJSON,
 			protected: true,
+			rateLimit: types.RateLimitAccount,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/links",
-			method:    http.MethodPost,
-			handler:   s.handlers.AddOutageLinkJSON,
+			path:      "/api/user/tokens",
+			method:    http.MethodGet,
+			handler:   s.handlers.ListAPITokensJSON,
 			protected: true,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/links/{linkId:[0-9]+}",
-			method:    http.MethodPatch,
-			handler:   s.handlers.UpdateOutageLinkJSON,
+			path:      "/api/user/tokens",
+			method:    http.MethodPost,
+			handler:   s.handlers.CreateAPITokenJSON,
 			protected: true,
+			rateLimit: types.RateLimitAccount,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages/{outageId:[0-9]+}/links/{linkId:[0-9]+}",
+			path:      "/api/user/tokens/{tokenId:[0-9]+}",
 			method:    http.MethodDelete,
-			handler:   s.handlers.DeleteOutageLinkJSON,
+			handler:   s.handlers.RevokeAPITokenJSON,
 			protected: true,
+			rateLimit: types.RateLimitAccount,
 		},
 		{
-			path:      "/api/user",
+			path:      "/api/user/notification-preferences",
 			method:    http.MethodGet,
-			handler:   s.handlers.GetAuthenticatedUserJSON,
+			handler:   s.handlers.GetNotificationPreferenceJSON,
 			protected: true,
 		},
 		{
-			path:      "/api/components/{componentName}/{subComponentName}/outages/report-suspected",
-			method:    http.MethodPost,
-			handler:   s.handlers.ReportSuspectedOutageJSON,
+			path:      "/api/user/notification-preferences",
+			method:    http.MethodPut,
+			handler:   s.handlers.PutNotificationPreferenceJSON,
 			protected: true,
+			rateLimit: types.RateLimitAccount,
 		},
 		{
-			path:      "/api/component-monitor/report",
-			method:    http.MethodPost,
-			handler:   s.handlers.PostComponentMonitorReportJSON,
+			path:      "/api/admin/groups",
+			method:    http.MethodGet,
+			handler:   s.handlers.GetGroupMembershipJSON,
+			protected: true,
+			admin:     true,
+		},
+		{
+			path:      "/api/admin/security-events",
+			method:    http.MethodGet,
+			handler:   s.handlers.GetSecurityEventsJSON,
 			protected: true,
+			admin:     true,
+		},
+		{
+			path:       "/api/components/{componentName}/{subComponentName}/outages/report-suspected",
+			method:     http.MethodPost,
+			handler:    s.handlers.ReportSuspectedOutageJSON,
+			protected:  true,
+			tokenScope: types.PermissionCreateOutage,
+			rateLimit:  types.RateLimitReports,
+			idempotent: true,
+		},
+		{
+			path:       "/api/component-monitor/report",
+			method:     http.MethodPost,
+			handler:    s.handlers.PostComponentMonitorReportJSON,
+			protected:  true,
+			tokenScope: types.PermissionReportStatus,
 		},
 		{
 			path:      "/api/external-pages/{pageSlug}",
@@ -224,15 +424,64 @@ func (s *Server) setupRoutes() http.Handler {
 		},
 	}
 
+	if s.oidc != nil {
+		routes = append(routes,
+			route{
+				path:      auth.OIDCStartPath,
+				method:    http.MethodGet,
+				handler:   s.oidc.StartLogin,
+				protected: false,
+			},
+			route{
+				path:      auth.OIDCCallbackPath,
+				method:    http.MethodGet,
+				handler:   s.oidc.Callback,
+				protected: false,
+			},
+			route{
+				path:      auth.OIDCSignOutPath,
+				method:    http.MethodGet,
+				handler:   s.oidc.SignOut,
+				protected: false,
+			},
+		)
+	}
+	if s.slackInteractions != nil {
+		// Slack signs these requests itself, so they bypass the HMAC auth middleware.
+		routes = append(routes, route{
+			path:      "/api/slack/interactions",
+			method:    http.MethodPost,
+			handler:   s.slackInteractions.HandleInteraction,
+			protected: false,
+		})
+	}
+	if s.slackEvents != nil {
+		routes = append(routes, route{
+			path:      "/api/slack/events",
+			method:    http.MethodPost,
+			handler:   s.slackEvents.HandleEvent,
+			protected: false,
+		})
+	}
+	return routes
+}
+
+func (s *Server) setupRoutes() http.Handler {
 	router := mux.NewRouter()
 	protectedRouter := router.Name("protected").Subrouter()
+	var sessions *auth.SessionManager
+	if s.oidc != nil {
+		sessions = s.oidc.Sessions()
+	}
 	protectedRouter.Use(func(next http.Handler) http.Handler {
-		return newAuthMiddleware(s.logger, s.hmacSecret, s.configManager, next)
+		return newAuthMiddleware(s.logger, s.hmacSecret, s.configManager, s.handlers.apiTokenRepo, s.handlers.securityEvents, s.tokenReviewer, sessions, next)
 	})
 
-	for _, route := range routes {
+	for _, route := range s.routes() {
 		if route.protected {
-			protectedRouter.HandleFunc(route.path, route.handler).Methods(route.method)
+			protectedRouter.HandleFunc(route.path, s.protectedHandler(route)).Methods(route.method)
+		} else if route.optionalAuth {
+			router.Handle(route.path, newOptionalAuthMiddleware(s.logger, s.hmacSecret, s.configManager, s.handlers.apiTokenRepo, s.handlers.securityEvents, s.tokenReviewer, sessions, http.HandlerFunc(route.handler))).Methods(route.method)
 		} else {
 			router.HandleFunc(route.path, route.handler).Methods(route.method)
 		}
@@ -245,7 +494,7 @@ func (s *Server) setupRoutes() http.Handler {
 	corsHandler := handlers.CORS(
 		handlers.AllowedOrigins([]string{s.corsOrigin}),
 		handlers.AllowedMethods([]string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "X-Forwarded-User", "X-Acting-For", "GAP-Signature"}),
+		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "X-Forwarded-User", "X-Acting-For", "GAP-Signature", idempotencyKeyHeader}),
 		handlers.AllowCredentials(),
 	)(router)
 
@@ -254,6 +503,28 @@ func (s *Server) setupRoutes() http.Handler {
 	return handler
 }
 
+// protectedHandler wraps the handler of a protected route with its authorization checks and rate limit.
+func (s *Server) protectedHandler(route route) http.HandlerFunc {
+	handler := route.handler
+	// Retries are replayed only once they pass the same permission checks and rate limits.
+	if route.idempotent && s.idempotencyKeys != nil {
+		handler = s.idempotencyKeys.Wrap(handler)
+	}
+	if route.permission != "" {
+		handler = s.handlers.requireComponentPermission(route.permission, handler)
+	} else if !route.checksDelegation {
+		handler = s.handlers.rejectScopedDelegators(handler)
+		handler = s.handlers.requireTokenScope(route.tokenScope, handler)
+	}
+	if route.admin {
+		handler = s.handlers.requireGlobalAdmin(handler)
+	}
+	if route.rateLimit != "" {
+		handler = s.rateLimiter.Limit(route.rateLimit, handler)
+	}
+	return handler
+}
+
 // spaHandler implements the http.Handler interface for serving a Single Page Application.
 // It serves static files if they exist, otherwise serves index.html to allow
 // client-side routing to work.
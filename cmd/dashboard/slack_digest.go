@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+	"ship-status-dash/pkg/utils"
+)
+
+// SlackDigestScheduler posts the slack_digests configured in DashboardConfig when they are due.
+type SlackDigestScheduler struct {
+	configManager *config.Manager[types.DashboardConfig]
+	outageManager outage.OutageManager
+	pingRepo      repositories.ComponentPingRepository
+	slackClient   *slack.Client
+	baseURL       string
+	checkInterval time.Duration
+	logger        *logrus.Logger
+	// lastRun records the scheduled run each digest was last posted for, keyed by digestKey.
+	lastRun map[string]time.Time
+}
+
+// NewSlackDigestScheduler creates a new SlackDigestScheduler. Outages in digests link to baseURL.
+func NewSlackDigestScheduler(configManager *config.Manager[types.DashboardConfig], outageManager outage.OutageManager, pingRepo repositories.ComponentPingRepository, slackClient *slack.Client, baseURL string, checkInterval time.Duration, logger *logrus.Logger) *SlackDigestScheduler {
+	return &SlackDigestScheduler{
+		configManager: configManager,
+		outageManager: outageManager,
+		pingRepo:      pingRepo,
+		slackClient:   slackClient,
+		baseURL:       baseURL,
+		checkInterval: checkInterval,
+		logger:        logger,
+		lastRun:       map[string]time.Time{},
+	}
+}
+
+// Start checks for due digests every check interval until ctx is done.
+func (s *SlackDigestScheduler) Start(ctx context.Context) {
+	s.logger.WithField("check_interval", s.checkInterval).Info("Starting Slack digest scheduler")
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	s.postDueDigests(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Stopping Slack digest scheduler")
+			return
+		case now := <-ticker.C:
+			s.postDueDigests(now)
+		}
+	}
+}
+
+func digestKey(digest types.SlackDigestConfig) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s", digest.Channel, digest.Schedule, digest.Time, digest.Weekday, digest.Timezone)
+}
+
+// postDueDigests posts each digest whose scheduled run has passed since it was last posted. A digest seen for
+// the first time, at startup or after a config reload, waits for its next run, so runs missed while the
+// dashboard was down are skipped rather than posted late.
+func (s *SlackDigestScheduler) postDueDigests(now time.Time) {
+	for _, digest := range s.configManager.Get().SlackDigests {
+		logger := s.logger.WithFields(logrus.Fields{
+			"channel":  digest.Channel,
+			"schedule": digest.Schedule,
+		})
+		run, err := digest.LastScheduledRun(now)
+		if err != nil {
+			logger.WithField("error", err).Error("Invalid Slack digest schedule")
+			continue
+		}
+		key := digestKey(digest)
+		last, seen := s.lastRun[key]
+		s.lastRun[key] = run
+		if !seen || !run.After(last) {
+			continue
+		}
+
+		summary, err := s.collect(digest, run)
+		if err != nil {
+			logger.WithField("error", err).Error("Failed to collect Slack digest")
+			continue
+		}
+		if !digest.Visibility.IsInternal() {
+			summary = summary.PublicView()
+		}
+		message := outage.FormatSlackDigest(summary, s.configManager, s.baseURL)
+		if _, _, err := s.slackClient.PostMessage(digest.Channel, slack.MsgOptionText(message, false), slack.MsgOptionAsUser(true)); err != nil {
+			logger.WithField("error", err).Error("Failed to post Slack digest")
+			continue
+		}
+		logger.Info("Posted Slack digest")
+	}
+}
+
+// digestComponents returns the components a digest covers.
+func (s *SlackDigestScheduler) digestComponents(digest types.SlackDigestConfig) []*types.Component {
+	components := s.configManager.Get().Components
+	if len(digest.Components) == 0 {
+		return components
+	}
+	var selected []*types.Component
+	for _, component := range components {
+		for _, name := range digest.Components {
+			if utils.Slugify(name) == component.Slug {
+				selected = append(selected, component)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// collect gathers the activity for the digest period ending at end.
+func (s *SlackDigestScheduler) collect(digest types.SlackDigestConfig, end time.Time) (*outage.SlackDigest, error) {
+	summary := &outage.SlackDigest{
+		Schedule:    digest.Schedule,
+		PeriodStart: end.Add(-digest.Period()),
+		PeriodEnd:   end,
+	}
+
+	var refs []types.SubComponentRef
+	for _, component := range s.digestComponents(digest) {
+		active, err := s.outageManager.GetActiveOutagesForComponent(component.Slug)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get active outages for %s: %w", component.Slug, err)
+		}
+		for _, o := range active {
+			if o.ConfirmedAt.Valid {
+				summary.Open = append(summary.Open, o)
+			} else {
+				summary.Unconfirmed = append(summary.Unconfirmed, o)
+			}
+		}
+		suspected, err := s.outageManager.GetActiveSuspectedOutagesForComponent(component.Slug)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get suspected outages for %s: %w", component.Slug, err)
+		}
+		summary.Suspected = append(summary.Suspected, suspected...)
+
+		for _, subComponent := range component.Subcomponents {
+			refs = append(refs, types.SubComponentRef{ComponentSlug: component.Slug, SubSlug: subComponent.Slug})
+			if subComponent.Monitoring == nil {
+				continue
+			}
+			frequency, err := time.ParseDuration(subComponent.Monitoring.Frequency)
+			if err != nil {
+				continue
+			}
+			lastPingTime, err := s.pingRepo.GetLastPingTime(component.Slug, subComponent.Slug)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get last ping time for %s/%s: %w", component.Slug, subComponent.Slug, err)
+			}
+			if reason, absent := absentReportReason(lastPingTime, frequency, end); absent {
+				summary.AbsentReports = append(summary.AbsentReports, outage.AbsentMonitorReport{
+					ComponentSlug:    component.Slug,
+					SubComponentSlug: subComponent.Slug,
+					Reason:           reason,
+				})
+			}
+		}
+	}
+
+	during, err := s.outageManager.GetOutagesDuring(summary.PeriodStart, end, refs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outages during digest period: %w", err)
+	}
+	for _, o := range during {
+		if !o.StartTime.Before(summary.PeriodStart) {
+			summary.Opened = append(summary.Opened, o)
+		}
+		if o.EndTime.Valid && !o.EndTime.Time.After(end) {
+			summary.Resolved = append(summary.Resolved, o)
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+func TestSlackDigestScheduler_postDueDigests(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.Components[0].Subcomponents[0].Monitoring = &types.Monitoring{Frequency: "1m"}
+	cfg.Components = append(cfg.Components, &types.Component{
+		Name:          "Beta",
+		Slug:          "beta",
+		Subcomponents: []types.SubComponent{{Name: "Two", Slug: "two"}},
+	})
+	cfg.SlackDigests = []types.SlackDigestConfig{
+		{Channel: "#alpha-digest", Schedule: types.DigestScheduleDaily, Time: "09:00", Components: []string{"Alpha"}},
+		{Channel: "#broken-digest", Schedule: "hourly", Time: "09:00"},
+	}
+
+	runStart := time.Date(2024, 1, 17, 9, 0, 0, 0, time.UTC)
+	outageAt := func(id uint, severity types.Severity, start time.Time) types.Outage {
+		return types.Outage{Model: gorm.Model{ID: id}, ComponentName: "alpha", SubComponentName: "one", Severity: severity, StartTime: start, CreatedBy: "alice"}
+	}
+	confirmed := outageAt(1, types.SeverityDown, runStart.Add(-30*time.Hour))
+	confirmed.ConfirmedAt = sql.NullTime{Time: confirmed.StartTime, Valid: true}
+	unconfirmed := outageAt(2, types.SeverityDegraded, runStart.Add(-time.Hour))
+	resolved := outageAt(3, types.SeverityDegraded, runStart.Add(-5*time.Hour))
+	resolved.EndTime = sql.NullTime{Time: runStart.Add(-4 * time.Hour), Valid: true}
+
+	var queriedComponents []string
+	om := &outage.MockOutageManager{
+		GetActiveOutagesForComponentFn: func(componentSlug string) ([]types.Outage, error) {
+			queriedComponents = append(queriedComponents, componentSlug)
+			return []types.Outage{confirmed, unconfirmed}, nil
+		},
+		GetActiveSuspectedOutagesForComponentFn: func(string) ([]types.Outage, error) {
+			return []types.Outage{outageAt(4, types.SeveritySuspected, runStart.Add(-2*time.Hour))}, nil
+		},
+		GetOutagesDuringFn: func(time.Time, time.Time, []types.SubComponentRef) ([]types.Outage, error) {
+			return []types.Outage{unconfirmed, resolved, confirmed}, nil
+		},
+	}
+	lastPing := runStart.Add(-10 * time.Minute)
+	pingRepo := &repositories.MockComponentPingRepository{LastPingTimes: map[string]*time.Time{"alpha/one": &lastPing}}
+	slackServer := outage.NewMockSlackServer(t)
+	defer slackServer.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	scheduler := NewSlackDigestScheduler(newTestHandlers(t, cfg, om).configManager, om, pingRepo, slackServer.Client(), "https://dashboard.example.com", time.Minute, logger)
+
+	// The first check only records the schedule, so a restart does not repost the last digest.
+	scheduler.postDueDigests(runStart.Add(-time.Minute))
+	assert.Empty(t, slackServer.PostedMessages())
+
+	scheduler.postDueDigests(runStart.Add(30 * time.Second))
+	posted := slackServer.PostedMessages()
+	require.Len(t, posted, 1)
+	assert.Equal(t, "#alpha-digest", posted[0].Channel)
+	assert.Equal(t, []string{"alpha"}, queriedComponents, "only the configured components are summarized")
+	assert.Equal(t, runStart.Add(-24*time.Hour), om.LastGetOutagesDuringQueryStart)
+	assert.Equal(t, runStart, om.LastGetOutagesDuringQueryEnd)
+	assert.Equal(t, []types.SubComponentRef{{ComponentSlug: "alpha", SubSlug: "one"}}, om.LastGetOutagesDuringRefs)
+	for _, want := range []string{
+		"📰 Daily Outage Digest: Tue Jan 16 09:00 UTC to Wed Jan 17 09:00 UTC",
+		"*Open outages (1)*\n• <https://dashboard.example.com/alpha/one/outages/1|Alpha/One> `Down` for 1d6h",
+		"*Awaiting confirmation (1)*\n• <https://dashboard.example.com/alpha/one/outages/2|Alpha/One>",
+		"*Suspected reports (1)*\n• <https://dashboard.example.com/alpha/one/outages/4|Alpha/One>",
+		"*Missing monitor reports (1)*\n• Alpha/One: Last report from component-monitor was 10m0s ago, exceeding threshold of 5m0s",
+		"*Opened (2)*\n• <https://dashboard.example.com/alpha/one/outages/2|Alpha/One>",
+		"*Resolved (1)*\n• <https://dashboard.example.com/alpha/one/outages/3|Alpha/One> `Degraded`, lasted 1h0m",
+	} {
+		assert.Contains(t, posted[0].Text, want)
+	}
+
+	scheduler.postDueDigests(runStart.Add(time.Minute))
+	assert.Len(t, slackServer.PostedMessages(), 1, "a digest is posted once per scheduled run")
+
+	scheduler.postDueDigests(runStart.Add(24*time.Hour + time.Minute))
+	assert.Len(t, slackServer.PostedMessages(), 2)
+}
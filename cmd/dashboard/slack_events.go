@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+// slackEventEnvelope is the outer payload of a Slack Events API request.
+type slackEventEnvelope struct {
+	Type      string          `json:"type"`
+	Challenge string          `json:"challenge"`
+	Event     json.RawMessage `json:"event"`
+}
+
+// slackMessageEvent holds the fields of a message event needed to mirror thread replies.
+type slackMessageEvent struct {
+	Type            string `json:"type"`
+	SubType         string `json:"subtype"`
+	Channel         string `json:"channel"`
+	User            string `json:"user"`
+	BotID           string `json:"bot_id"`
+	Text            string `json:"text"`
+	Timestamp       string `json:"ts"`
+	ThreadTimestamp string `json:"thread_ts"`
+	// Message is the edited message of a message_changed event.
+	Message *slackMessageEvent `json:"message"`
+}
+
+// SlackEventHandler serves the Slack Events API endpoint. It mirrors human replies in outage threads
+// into triage notes for channels configured with mirror_thread_replies.
+type SlackEventHandler struct {
+	handlers        *Handlers
+	slackThreadRepo repositories.SlackThreadRepository
+	signingSecret   string
+	identities      SlackIdentityResolver
+	logger          *logrus.Logger
+}
+
+// NewSlackEventHandler creates a SlackEventHandler. Requests are verified with signingSecret, and reply authors
+// are mapped to dashboard users through identities.
+func NewSlackEventHandler(handlers *Handlers, slackThreadRepo repositories.SlackThreadRepository, signingSecret string, identities SlackIdentityResolver, logger *logrus.Logger) *SlackEventHandler {
+	return &SlackEventHandler{
+		handlers:        handlers,
+		slackThreadRepo: slackThreadRepo,
+		signingSecret:   signingSecret,
+		identities:      identities,
+		logger:          logger,
+	}
+}
+
+// HandleEvent answers the URL verification challenge and handles message events.
+func (s *SlackEventHandler) HandleEvent(w http.ResponseWriter, r *http.Request) {
+	body, err := verifiedSlackBody(r, s.signingSecret)
+	if err != nil {
+		s.logger.WithField("error", err).Warn("Rejected Slack event with invalid signature")
+		respondWithError(w, http.StatusUnauthorized, "Invalid Slack signature")
+		return
+	}
+	var envelope slackEventEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	switch envelope.Type {
+	case "url_verification":
+		respondWithJSON(w, http.StatusOK, map[string]string{"challenge": envelope.Challenge})
+		return
+	case "event_callback":
+		var event slackMessageEvent
+		if err := json.Unmarshal(envelope.Event, &event); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if event.Type == "message" {
+			// A failed event is retried by Slack; mirrored replies are deduplicated by message timestamp.
+			if err := s.mirrorThreadReply(&event); err != nil {
+				s.logger.WithFields(logrus.Fields{
+					"channel_id": event.Channel,
+					"error":      err,
+				}).Error("Failed to mirror Slack thread reply")
+				respondWithError(w, http.StatusInternalServerError, "Failed to handle event")
+				return
+			}
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// mirrorThreadReply stores a reply in an outage thread as a triage note, or updates the mirrored note when the reply is edited.
+// Bot messages, thread parents, replies from users without a dashboard identity or without permission to add triage
+// notes to the component, and threads in channels that do not mirror replies are ignored.
+func (s *SlackEventHandler) mirrorThreadReply(event *slackMessageEvent) error {
+	message := event
+	switch event.SubType {
+	case "", "thread_broadcast":
+	case "message_changed":
+		if event.Message == nil {
+			return nil
+		}
+		message = event.Message
+	default:
+		// Joins, deletions, bot messages and other housekeeping events.
+		return nil
+	}
+	body := strings.TrimSpace(message.Text)
+	if message.BotID != "" || message.User == "" || body == "" ||
+		message.ThreadTimestamp == "" || message.ThreadTimestamp == message.Timestamp {
+		return nil
+	}
+
+	thread, err := s.slackThreadRepo.GetThreadByTimestamp(event.Channel, message.ThreadTimestamp)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	// Replies under a grouped message cannot be attributed to one of its outages.
+	if thread.Grouped {
+		return nil
+	}
+	component, reporting := s.handlers.slackReporting(thread.ComponentName, thread.SubComponentName, thread.Channel)
+	if reporting == nil || !reporting.MirrorThreadReplies {
+		return nil
+	}
+	logger := s.logger.WithFields(logrus.Fields{
+		"outage_id":  thread.OutageID,
+		"channel":    thread.Channel,
+		"slack_user": message.User,
+		"message_ts": message.Timestamp,
+	})
+
+	user, err := s.identities.DashboardUser(message.User)
+	if err != nil {
+		logger.WithField("error", err).Info("Not mirroring Slack reply from user without a dashboard identity")
+		return nil
+	}
+	logger = logger.WithField("active_user", user)
+	if !s.handlers.HasComponentPermission(user, component, types.PermissionAddTriageNote) {
+		logger.Warn("Not mirroring Slack reply from user not authorized to add triage notes")
+		return nil
+	}
+
+	existing, err := s.handlers.triageNoteRepo.GetTriageNoteBySlackMessage(thread.OutageID, message.Timestamp)
+	switch {
+	case err == nil:
+		if existing.Body == body {
+			return nil
+		}
+		if _, err := s.handlers.outageManager.UpdateTriageNote(thread.OutageID, existing.ID, body, "", user); err != nil {
+			return err
+		}
+		logger.Info("Updated triage note from edited Slack reply")
+		return nil
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	case message != event:
+		// Edits of replies that were never mirrored, or whose note was deleted, are not mirrored.
+		return nil
+	}
+
+	timestamp := message.Timestamp
+	// Anyone in the channel can read a reply, but it only becomes public when the channel is configured as public.
+	note := &types.TriageNote{OutageID: thread.OutageID, Body: body, Author: user, Visibility: slackNoteVisibility(reporting), SlackMessageTS: &timestamp}
+	if err := s.handlers.outageManager.AddTriageNote(note); err != nil {
+		return err
+	}
+	logger.Info("Mirrored Slack reply into triage note")
+	return nil
+}
+
+// slackReporting returns the component and the slack_reporting entry for channel of the sub-component. The entry
+// is nil when it is not, or no longer, configured.
+func (h *Handlers) slackReporting(componentSlug, subComponentSlug, channel string) (*types.Component, *types.SlackReportingConfig) {
+	component := h.config().GetComponentBySlug(componentSlug)
+	if component == nil {
+		return nil, nil
+	}
+	subComponent := component.GetSubComponentBySlug(subComponentSlug)
+	if subComponent == nil {
+		return nil, nil
+	}
+	for _, reporting := range types.GetSlackReporting(component, subComponent) {
+		if reporting.Channel == channel {
+			return component, &reporting
+		}
+	}
+	return nil, nil
+}
+
+// slackNoteVisibility returns the visibility of triage notes written in a channel with reporting, which is
+// internal unless the channel is configured as public.
+func slackNoteVisibility(reporting *types.SlackReportingConfig) types.Visibility {
+	if reporting != nil && reporting.Visibility == types.VisibilityPublic {
+		return types.VisibilityPublic
+	}
+	return types.VisibilityInternal
+}
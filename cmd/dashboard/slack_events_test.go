@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+// signedSlackEvent builds an Events API request carrying body, signed the way Slack signs it.
+func signedSlackEvent(t *testing.T, body any, secret string) *http.Request {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := signSlackRequest(httptest.NewRequest(http.MethodPost, "/api/slack/events", strings.NewReader(string(raw))), secret)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func threadReplyEvent(event map[string]any) map[string]any {
+	message := map[string]any{
+		"type":      "message",
+		"channel":   "C_ALERTS",
+		"user":      "U_ALICE",
+		"text":      "  rolled back the deploy  ",
+		"ts":        "1700000100.000200",
+		"thread_ts": "1700000000.000100",
+	}
+	for k, v := range event {
+		message[k] = v
+	}
+	return map[string]any{"type": "event_callback", "event": message}
+}
+
+func TestSlackEventHandler(t *testing.T) {
+	mirroredTS := "1700000100.000200"
+	tests := []struct {
+		name              string
+		body              map[string]any
+		secret            string
+		existingNote      *types.TriageNote
+		threadLookupError error
+		wantStatus        int
+		wantBody          string
+		wantAdded         []*types.TriageNote
+		wantUpdated       []*types.TriageNote
+	}{
+		{
+			name:       "URL verification challenge",
+			body:       map[string]any{"type": "url_verification", "challenge": "abc123"},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"challenge":"abc123"}`,
+		},
+		{
+			name:       "invalid signature is rejected",
+			body:       threadReplyEvent(nil),
+			secret:     "wrong-secret",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "reply is mirrored into a triage note",
+			body:       threadReplyEvent(nil),
+			wantStatus: http.StatusOK,
+			wantAdded:  []*types.TriageNote{{OutageID: 7, Body: "rolled back the deploy", Author: "alice", Visibility: types.VisibilityPublic, SlackMessageTS: &mirroredTS}},
+		},
+		{
+			name:       "broadcast reply is mirrored",
+			body:       threadReplyEvent(map[string]any{"subtype": "thread_broadcast"}),
+			wantStatus: http.StatusOK,
+			wantAdded:  []*types.TriageNote{{OutageID: 7, Body: "rolled back the deploy", Author: "alice", Visibility: types.VisibilityPublic, SlackMessageTS: &mirroredTS}},
+		},
+		{
+			name:       "reply in an internal channel is mirrored as an internal note",
+			body:       threadReplyEvent(map[string]any{"channel": "C_INTERNAL"}),
+			wantStatus: http.StatusOK,
+			wantAdded:  []*types.TriageNote{{OutageID: 7, Body: "rolled back the deploy", Author: "alice", Visibility: types.VisibilityInternal, SlackMessageTS: &mirroredTS}},
+		},
+		{
+			name:       "reply in a channel without a visibility is mirrored as an internal note",
+			body:       threadReplyEvent(map[string]any{"channel": "C_UNSET"}),
+			wantStatus: http.StatusOK,
+			wantAdded:  []*types.TriageNote{{OutageID: 7, Body: "rolled back the deploy", Author: "alice", Visibility: types.VisibilityInternal, SlackMessageTS: &mirroredTS}},
+		},
+		{
+			name:       "reply from a user not authorized to add triage notes",
+			body:       threadReplyEvent(map[string]any{"user": "U_BOB"}),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:         "redelivered reply is not duplicated",
+			body:         threadReplyEvent(nil),
+			existingNote: &types.TriageNote{Model: gorm.Model{ID: 4}, OutageID: 7, Body: "rolled back the deploy", Author: "alice", SlackMessageTS: &mirroredTS},
+			wantStatus:   http.StatusOK,
+		},
+		{
+			name: "edited reply updates the mirrored note",
+			body: threadReplyEvent(map[string]any{
+				"subtype": "message_changed",
+				"user":    nil,
+				"ts":      "1700000200.000300",
+				"message": map[string]any{
+					"type":      "message",
+					"user":      "U_ALICE",
+					"text":      "rolled back the deploy to v1.2",
+					"ts":        "1700000100.000200",
+					"thread_ts": "1700000000.000100",
+				},
+			}),
+			existingNote: &types.TriageNote{Model: gorm.Model{ID: 4}, OutageID: 7, Body: "rolled back the deploy", Author: "alice", SlackMessageTS: &mirroredTS},
+			wantStatus:   http.StatusOK,
+			wantUpdated:  []*types.TriageNote{{Model: gorm.Model{ID: 4}, OutageID: 7, Body: "rolled back the deploy to v1.2", Author: "alice"}},
+		},
+		{
+			name: "edit of a reply that was not mirrored is ignored",
+			body: threadReplyEvent(map[string]any{
+				"subtype": "message_changed",
+				"message": map[string]any{
+					"type":      "message",
+					"user":      "U_ALICE",
+					"text":      "rolled back the deploy to v1.2",
+					"ts":        "1700000100.000200",
+					"thread_ts": "1700000000.000100",
+				},
+			}),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "bot messages are ignored",
+			body:       threadReplyEvent(map[string]any{"bot_id": "B1"}),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "thread parent is ignored",
+			body:       threadReplyEvent(map[string]any{"ts": "1700000000.000100"}),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "deleted messages are ignored",
+			body:       threadReplyEvent(map[string]any{"subtype": "message_deleted"}),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "reply in a thread that is not an outage thread",
+			body:       threadReplyEvent(map[string]any{"thread_ts": "1600000000.000100"}),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "reply in a channel that does not mirror replies",
+			body:       threadReplyEvent(map[string]any{"channel": "C_OTHER"}),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "reply from a Slack user without a dashboard identity",
+			body:       threadReplyEvent(map[string]any{"user": "U_EXTERNAL"}),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:              "lookup failure is reported so Slack retries",
+			body:              threadReplyEvent(nil),
+			threadLookupError: errors.New("database error"),
+			wantStatus:        http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := minimalDashboardConfig()
+			cfg.Components[0].Owners = []types.Owner{{User: "alice", Role: types.RoleTriager}}
+			cfg.Components[0].SlackReporting = []types.SlackReportingConfig{
+				{Channel: "#alerts", MirrorThreadReplies: true, Visibility: types.VisibilityPublic},
+				{Channel: "#other"},
+				{Channel: "#internal", MirrorThreadReplies: true, Visibility: types.VisibilityInternal},
+				{Channel: "#unset", MirrorThreadReplies: true},
+			}
+			om := &outage.MockOutageManager{}
+			h := newTestHandlers(t, cfg, om)
+			h.triageNoteRepo.(*repositories.MockTriageNoteRepository).GetTriageNoteBySlackMessageFn = func(outageID uint, messageTS string) (*types.TriageNote, error) {
+				if tt.existingNote == nil || outageID != tt.existingNote.OutageID || messageTS != *tt.existingNote.SlackMessageTS {
+					return nil, gorm.ErrRecordNotFound
+				}
+				return tt.existingNote, nil
+			}
+			threads := &repositories.MockSlackThreadRepository{
+				GetThreadError: tt.threadLookupError,
+				ThreadsByTimestamp: []types.OutageSlackThread{
+					{
+						SlackThread:      types.SlackThread{OutageID: 7, Channel: "#alerts", ChannelID: "C_ALERTS", ThreadTimestamp: "1700000000.000100"},
+						ComponentName:    "alpha",
+						SubComponentName: "one",
+					},
+					{
+						SlackThread:      types.SlackThread{OutageID: 7, Channel: "#other", ChannelID: "C_OTHER", ThreadTimestamp: "1700000000.000100"},
+						ComponentName:    "alpha",
+						SubComponentName: "one",
+					},
+					{
+						SlackThread:      types.SlackThread{OutageID: 7, Channel: "#internal", ChannelID: "C_INTERNAL", ThreadTimestamp: "1700000000.000100"},
+						ComponentName:    "alpha",
+						SubComponentName: "one",
+					},
+					{
+						SlackThread:      types.SlackThread{OutageID: 7, Channel: "#unset", ChannelID: "C_UNSET", ThreadTimestamp: "1700000000.000100"},
+						ComponentName:    "alpha",
+						SubComponentName: "one",
+					},
+				},
+			}
+			slackServer := outage.NewMockSlackServer(t)
+			defer slackServer.Close()
+			slackServer.AddUser("U_ALICE", "alice@example.com")
+			slackServer.AddUser("U_EXTERNAL", "alice@partner.example.org")
+			slackServer.AddUser("U_BOB", "bob@example.com")
+
+			handler := NewSlackEventHandler(h, threads, testSlackSigningSecret, NewSlackEmailIdentityResolver(slackServer.Client(), "example.com"), logrus.New())
+
+			secret := tt.secret
+			if secret == "" {
+				secret = testSlackSigningSecret
+			}
+			rr := httptest.NewRecorder()
+			handler.HandleEvent(rr, signedSlackEvent(t, tt.body, secret))
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rr.Body.String())
+			}
+			assert.Equal(t, tt.wantAdded, om.AddedTriageNotes)
+			assert.Equal(t, tt.wantUpdated, om.UpdatedTriageNotes)
+		})
+	}
+}
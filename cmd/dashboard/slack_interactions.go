@@ -0,0 +1,319 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+// maxSlackPayloadBytes bounds interaction and event payloads, which Slack keeps well below this size.
+const maxSlackPayloadBytes = 1 << 20
+
+// SlackIdentityResolver maps a Slack user to the dashboard identity used for authorization.
+type SlackIdentityResolver interface {
+	DashboardUser(slackUserID string) (string, error)
+}
+
+// slackEmailIdentityResolver maps Slack users to dashboard users through their Slack profile email:
+// alice@example.com maps to alice when example.com is the configured domain.
+type slackEmailIdentityResolver struct {
+	client *slack.Client
+	domain string
+}
+
+// NewSlackEmailIdentityResolver creates a SlackIdentityResolver that accepts profile emails in domain.
+// The bot token needs the users:read.email scope.
+func NewSlackEmailIdentityResolver(client *slack.Client, domain string) SlackIdentityResolver {
+	return &slackEmailIdentityResolver{client: client, domain: strings.ToLower(strings.TrimPrefix(domain, "@"))}
+}
+
+func (r *slackEmailIdentityResolver) DashboardUser(slackUserID string) (string, error) {
+	user, err := r.client.GetUserInfo(slackUserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up Slack user: %w", err)
+	}
+	if user.IsBot || user.Deleted {
+		return "", errors.New("Slack user is a bot or deactivated")
+	}
+	local, domain, ok := strings.Cut(strings.ToLower(user.Profile.Email), "@")
+	if !ok || local == "" || domain != r.domain {
+		return "", fmt.Errorf("Slack user has no %s email address", r.domain)
+	}
+	return local, nil
+}
+
+// SlackInteractionHandler serves the Slack interactivity endpoint used by the actions on outage messages.
+type SlackInteractionHandler struct {
+	handlers        *Handlers
+	slackClient     *slack.Client
+	slackThreadRepo repositories.SlackThreadRepository
+	signingSecret   string
+	identities      SlackIdentityResolver
+	logger          *logrus.Logger
+	// actions tracks the block actions still running after their request was acknowledged.
+	actions sync.WaitGroup
+}
+
+// NewSlackInteractionHandler creates a SlackInteractionHandler. Requests are verified with signingSecret, and
+// actions are authorized like the corresponding API calls through handlers. slackThreadRepo maps outage messages
+// back to the channel they were posted for, which decides the visibility of triage notes added from them.
+func NewSlackInteractionHandler(handlers *Handlers, slackClient *slack.Client, slackThreadRepo repositories.SlackThreadRepository, signingSecret string, identities SlackIdentityResolver, logger *logrus.Logger) *SlackInteractionHandler {
+	return &SlackInteractionHandler{
+		handlers:        handlers,
+		slackClient:     slackClient,
+		slackThreadRepo: slackThreadRepo,
+		signingSecret:   signingSecret,
+		identities:      identities,
+		logger:          logger,
+	}
+}
+
+// verifiedSlackBody reads the request body and checks Slack's request signature over it.
+func verifiedSlackBody(r *http.Request, signingSecret string) ([]byte, error) {
+	verifier, err := slack.NewSecretsVerifier(r.Header, signingSecret)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSlackPayloadBytes))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := verifier.Write(body); err != nil {
+		return nil, err
+	}
+	if err := verifier.Ensure(); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// HandleInteraction handles block actions on outage messages and submissions of the triage note modal.
+func (s *SlackInteractionHandler) HandleInteraction(w http.ResponseWriter, r *http.Request) {
+	body, err := verifiedSlackBody(r, s.signingSecret)
+	if err != nil {
+		s.logger.WithField("error", err).Warn("Rejected Slack interaction with invalid signature")
+		respondWithError(w, http.StatusUnauthorized, "Invalid Slack signature")
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	var callback slack.InteractionCallback
+	if err := json.Unmarshal([]byte(form.Get("payload")), &callback); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	switch callback.Type {
+	case slack.InteractionTypeBlockActions:
+		// Slack gives up on interactions that are not acknowledged within 3 seconds, so the action runs afterwards
+		// and reports failures to the user in an ephemeral message.
+		w.WriteHeader(http.StatusOK)
+		s.actions.Add(1)
+		go func() {
+			defer s.actions.Done()
+			s.handleBlockActions(&callback)
+		}()
+	case slack.InteractionTypeViewSubmission:
+		s.handleViewSubmission(w, &callback)
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// authorize resolves the Slack user and checks that their role on the referenced outage's component grants permission.
+// The returned message explains a failure to the Slack user.
+func (s *SlackInteractionHandler) authorize(slackUserID string, ref outage.SlackOutageRef, permission types.Permission, logger *logrus.Entry) (user string, component *types.Component, message string) {
+	user, err := s.identities.DashboardUser(slackUserID)
+	if err != nil {
+		logger.WithField("error", err).Warn("Failed to map Slack user to a dashboard identity")
+		return "", nil, "Your Slack account could not be mapped to a dashboard identity."
+	}
+	component = s.handlers.config().GetComponentBySlug(ref.ComponentSlug)
+	if component == nil || component.GetSubComponentBySlug(ref.SubComponentSlug) == nil {
+		return "", nil, "This outage's component no longer exists."
+	}
+	if !s.handlers.HasComponentPermission(user, component, permission) {
+		logger.WithField("active_user", user).Warn("User not authorized for Slack outage action")
+		return "", nil, "You are not authorized to perform this action on this component."
+	}
+	return user, component, ""
+}
+
+func (s *SlackInteractionHandler) handleBlockActions(callback *slack.InteractionCallback) {
+	if len(callback.ActionCallback.BlockActions) == 0 {
+		return
+	}
+	action := callback.ActionCallback.BlockActions[0]
+	ref, err := outage.ParseSlackOutageRef(action.BlockID)
+	if err != nil {
+		return
+	}
+	logger := s.logger.WithFields(logrus.Fields{
+		"outage_id":     ref.OutageID,
+		"component":     ref.ComponentSlug,
+		"sub_component": ref.SubComponentSlug,
+		"slack_user":    callback.User.ID,
+		"action":        action.ActionID,
+	})
+	reply := func(message string) {
+		if _, err := s.slackClient.PostEphemeral(callback.Channel.ID, callback.User.ID, slack.MsgOptionText(message, false)); err != nil {
+			logger.WithField("error", err).Warn("Failed to send ephemeral Slack reply")
+		}
+	}
+
+	permission := types.PermissionUpdateOutage
+	if action.ActionID == outage.SlackActionAddNote {
+		permission = types.PermissionAddTriageNote
+	}
+	user, component, message := s.authorize(callback.User.ID, ref, permission, logger)
+	if message != "" {
+		reply(message)
+		return
+	}
+	logger = logger.WithField("active_user", user)
+
+	o, err := s.handlers.outageManager.GetOutageByID(ref.ComponentSlug, ref.SubComponentSlug, ref.OutageID)
+	if err != nil || o == nil {
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.WithField("error", err).Error("Failed to query outage from database")
+		}
+		reply("This outage could not be found.")
+		return
+	}
+
+	switch action.ActionID {
+	case outage.SlackActionAddNote:
+		subComponent := component.GetSubComponentBySlug(ref.SubComponentSlug)
+		title := fmt.Sprintf("*%s/%s* outage #%d", component.Name, subComponent.Name, o.ID)
+		modal := outage.BuildTriageNoteModal(ref, title, s.noteVisibility(callback, ref, logger))
+		if _, err := s.slackClient.OpenView(callback.TriggerID, modal); err != nil {
+			logger.WithField("error", err).Error("Failed to open triage note modal")
+			reply("Failed to open the triage note form.")
+		}
+		return
+	case outage.SlackActionResolve:
+		if o.EndTime.Valid {
+			reply("This outage is already resolved.")
+			return
+		}
+		o.EndTime = sql.NullTime{Time: time.Now(), Valid: true}
+	case outage.SlackActionChangeSeverity:
+		severity := action.SelectedOption.Value
+		if !types.IsValidSeverity(severity) {
+			reply("Unknown severity.")
+			return
+		}
+		if o.Severity == types.Severity(severity) {
+			return
+		}
+		o.Severity = types.Severity(severity)
+	case outage.SlackActionConfirm:
+		if o.ConfirmedAt.Valid {
+			reply("This outage is already confirmed.")
+			return
+		}
+		o.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	default:
+		return
+	}
+
+	if message, valid := o.Validate(); !valid {
+		reply(message)
+		return
+	}
+	// The update is announced in the outage thread by the Slack reporter, so success needs no reply.
+	if err := s.handlers.outageManager.UpdateOutage(o, user); err != nil {
+		logger.WithField("error", err).Error("Failed to update outage from Slack")
+		reply("Failed to update the outage.")
+		return
+	}
+	logger.Info("Updated outage from Slack")
+}
+
+// noteVisibility returns the visibility of triage notes added from the outage message callback acts on, following
+// the slack_reporting entry of the channel it was posted to. Messages that cannot be traced to an entry add
+// internal notes.
+func (s *SlackInteractionHandler) noteVisibility(callback *slack.InteractionCallback, ref outage.SlackOutageRef, logger *logrus.Entry) types.Visibility {
+	timestamp := callback.Message.ThreadTimestamp
+	if timestamp == "" {
+		timestamp = callback.Message.Timestamp
+	}
+	thread, err := s.slackThreadRepo.GetThreadByTimestamp(callback.Channel.ID, timestamp)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.WithField("error", err).Error("Failed to look up the Slack thread of an outage message")
+		}
+		return types.VisibilityInternal
+	}
+	_, reporting := s.handlers.slackReporting(ref.ComponentSlug, ref.SubComponentSlug, thread.Channel)
+	return slackNoteVisibility(reporting)
+}
+
+func (s *SlackInteractionHandler) handleViewSubmission(w http.ResponseWriter, callback *slack.InteractionCallback) {
+	if callback.View.CallbackID != outage.SlackViewTriageNote {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	fail := func(message string) {
+		respondWithJSON(w, http.StatusOK, slack.NewErrorsViewSubmissionResponse(map[string]string{outage.SlackTriageNoteBlockID: message}))
+	}
+
+	ref, visibility, err := outage.ParseTriageNoteModalMetadata(callback.View.PrivateMetadata)
+	if err != nil {
+		fail("This form no longer refers to an outage.")
+		return
+	}
+	logger := s.logger.WithFields(logrus.Fields{
+		"outage_id":     ref.OutageID,
+		"component":     ref.ComponentSlug,
+		"sub_component": ref.SubComponentSlug,
+		"slack_user":    callback.User.ID,
+	})
+
+	var body string
+	if callback.View.State != nil {
+		body = strings.TrimSpace(callback.View.State.Values[outage.SlackTriageNoteBlockID][outage.SlackTriageNoteActionID].Value)
+	}
+	if body == "" {
+		fail("Body is required")
+		return
+	}
+
+	user, _, message := s.authorize(callback.User.ID, ref, types.PermissionAddTriageNote, logger)
+	if message != "" {
+		fail(message)
+		return
+	}
+	if o, err := s.handlers.outageManager.GetOutageByID(ref.ComponentSlug, ref.SubComponentSlug, ref.OutageID); err != nil || o == nil {
+		fail("This outage could not be found.")
+		return
+	}
+
+	note := &types.TriageNote{OutageID: ref.OutageID, Body: body, Author: user, Visibility: visibility}
+	if err := s.handlers.outageManager.AddTriageNote(note); err != nil {
+		logger.WithField("error", err).Error("Failed to add triage note from Slack")
+		fail("Failed to add triage note")
+		return
+	}
+	logger.WithField("active_user", user).Info("Added triage note from Slack")
+	// An empty 200 response closes the modal.
+	w.WriteHeader(http.StatusOK)
+}
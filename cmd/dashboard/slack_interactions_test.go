@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+const testSlackSigningSecret = "signing-secret"
+
+// signedSlackRequest builds an interactivity request carrying payload, signed the way Slack signs it.
+func signedSlackRequest(t *testing.T, payload any, secret string) *http.Request {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	require.NoError(t, err)
+	req := signSlackRequest(httptest.NewRequest(http.MethodPost, "/api/slack/interactions", strings.NewReader(url.Values{"payload": {string(raw)}}.Encode())), secret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// signSlackRequest adds Slack's signature headers for the request body.
+func signSlackRequest(req *http.Request, secret string) *http.Request {
+	body, _ := io.ReadAll(req.Body)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + string(body)))
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func blockActionPayload(slackUser, actionID string, extra map[string]any) map[string]any {
+	action := map[string]any{"action_id": actionID, "block_id": "outage:alpha/one/7", "type": "button"}
+	for k, v := range extra {
+		action[k] = v
+	}
+	return map[string]any{
+		"type":       "block_actions",
+		"user":       map[string]any{"id": slackUser},
+		"channel":    map[string]any{"id": "C1"},
+		"message":    map[string]any{"ts": "1700000000.000100"},
+		"trigger_id": "trigger-1",
+		"actions":    []any{action},
+	}
+}
+
+// withSlackChannel moves a block action payload to channelID.
+func withSlackChannel(payload map[string]any, channelID string) map[string]any {
+	payload["channel"] = map[string]any{"id": channelID}
+	return payload
+}
+
+func triageNoteSubmissionPayload(slackUser, body, metadata string) map[string]any {
+	return map[string]any{
+		"type": "view_submission",
+		"user": map[string]any{"id": slackUser},
+		"view": map[string]any{
+			"callback_id":      outage.SlackViewTriageNote,
+			"private_metadata": metadata,
+			"state": map[string]any{"values": map[string]any{
+				outage.SlackTriageNoteBlockID: map[string]any{
+					outage.SlackTriageNoteActionID: map[string]any{"type": "plain_text_input", "value": body},
+				},
+			}},
+		},
+	}
+}
+
+func TestSlackInteractionHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		payload     map[string]any
+		secret      string
+		wantStatus  int
+		wantBody    string
+		wantReplies []string
+		verify      func(t *testing.T, om *outage.MockOutageManager, slackServer *outage.MockSlackServer)
+	}{
+		{
+			name:       "invalid signature is rejected",
+			payload:    blockActionPayload("U_ALICE", outage.SlackActionResolve, nil),
+			secret:     "wrong-secret",
+			wantStatus: http.StatusUnauthorized,
+			verify: func(t *testing.T, om *outage.MockOutageManager, _ *outage.MockSlackServer) {
+				assert.Empty(t, om.UpdatedOutages)
+			},
+		},
+		{
+			name:       "resolve",
+			payload:    blockActionPayload("U_ALICE", outage.SlackActionResolve, nil),
+			wantStatus: http.StatusOK,
+			verify: func(t *testing.T, om *outage.MockOutageManager, _ *outage.MockSlackServer) {
+				require.Len(t, om.UpdatedOutages, 1)
+				assert.True(t, om.UpdatedOutages[0].EndTime.Valid)
+			},
+		},
+		{
+			name: "change severity",
+			payload: blockActionPayload("U_ALICE", outage.SlackActionChangeSeverity, map[string]any{
+				"type":            "static_select",
+				"selected_option": map[string]any{"value": "Down"},
+			}),
+			wantStatus: http.StatusOK,
+			verify: func(t *testing.T, om *outage.MockOutageManager, _ *outage.MockSlackServer) {
+				require.Len(t, om.UpdatedOutages, 1)
+				assert.Equal(t, types.SeverityDown, om.UpdatedOutages[0].Severity)
+			},
+		},
+		{
+			name:       "confirm",
+			payload:    blockActionPayload("U_ALICE", outage.SlackActionConfirm, nil),
+			wantStatus: http.StatusOK,
+			verify: func(t *testing.T, om *outage.MockOutageManager, _ *outage.MockSlackServer) {
+				require.Len(t, om.UpdatedOutages, 1)
+				assert.True(t, om.UpdatedOutages[0].ConfirmedAt.Valid)
+			},
+		},
+		{
+			name:       "add triage note opens the modal",
+			payload:    blockActionPayload("U_ALICE", outage.SlackActionAddNote, nil),
+			wantStatus: http.StatusOK,
+			verify: func(t *testing.T, om *outage.MockOutageManager, slackServer *outage.MockSlackServer) {
+				assert.Empty(t, om.UpdatedOutages)
+				views := slackServer.OpenedViews()
+				require.Len(t, views, 1)
+				assert.Equal(t, "trigger-1", views[0].TriggerID)
+				assert.Equal(t, outage.SlackViewTriageNote, views[0].View.CallbackID)
+				assert.Equal(t, "outage:alpha/one/7 internal", views[0].View.PrivateMetadata, "C1 is an internal channel")
+			},
+		},
+		{
+			name:       "add triage note from a public channel",
+			payload:    withSlackChannel(blockActionPayload("U_ALICE", outage.SlackActionAddNote, nil), "C_PUBLIC"),
+			wantStatus: http.StatusOK,
+			verify: func(t *testing.T, _ *outage.MockOutageManager, slackServer *outage.MockSlackServer) {
+				views := slackServer.OpenedViews()
+				require.Len(t, views, 1)
+				assert.Equal(t, "outage:alpha/one/7 public", views[0].View.PrivateMetadata)
+			},
+		},
+		{
+			name:       "add triage note from an untracked message",
+			payload:    withSlackChannel(blockActionPayload("U_ALICE", outage.SlackActionAddNote, nil), "C_UNKNOWN"),
+			wantStatus: http.StatusOK,
+			verify: func(t *testing.T, _ *outage.MockOutageManager, slackServer *outage.MockSlackServer) {
+				views := slackServer.OpenedViews()
+				require.Len(t, views, 1)
+				assert.Equal(t, "outage:alpha/one/7 internal", views[0].View.PrivateMetadata)
+			},
+		},
+		{
+			name:        "user without access to the component",
+			payload:     blockActionPayload("U_MALLORY", outage.SlackActionResolve, nil),
+			wantStatus:  http.StatusOK,
+			wantReplies: []string{"You are not authorized to perform this action on this component."},
+			verify: func(t *testing.T, om *outage.MockOutageManager, _ *outage.MockSlackServer) {
+				assert.Empty(t, om.UpdatedOutages)
+			},
+		},
+		{
+			name:        "triager cannot resolve",
+			payload:     blockActionPayload("U_TRIAGER", outage.SlackActionResolve, nil),
+			wantStatus:  http.StatusOK,
+			wantReplies: []string{"You are not authorized to perform this action on this component."},
+			verify: func(t *testing.T, om *outage.MockOutageManager, _ *outage.MockSlackServer) {
+				assert.Empty(t, om.UpdatedOutages)
+			},
+		},
+		{
+			name:       "triager can open the triage note modal",
+			payload:    blockActionPayload("U_TRIAGER", outage.SlackActionAddNote, nil),
+			wantStatus: http.StatusOK,
+			verify: func(t *testing.T, _ *outage.MockOutageManager, slackServer *outage.MockSlackServer) {
+				assert.Len(t, slackServer.OpenedViews(), 1)
+			},
+		},
+		{
+			name:        "Slack user outside the identity domain",
+			payload:     blockActionPayload("U_EXTERNAL", outage.SlackActionResolve, nil),
+			wantStatus:  http.StatusOK,
+			wantReplies: []string{"Your Slack account could not be mapped to a dashboard identity."},
+			verify: func(t *testing.T, om *outage.MockOutageManager, _ *outage.MockSlackServer) {
+				assert.Empty(t, om.UpdatedOutages)
+			},
+		},
+		{
+			name:       "triage note submission",
+			payload:    triageNoteSubmissionPayload("U_ALICE", "  rolled back the deploy  ", "outage:alpha/one/7 internal"),
+			wantStatus: http.StatusOK,
+			verify: func(t *testing.T, om *outage.MockOutageManager, _ *outage.MockSlackServer) {
+				require.Len(t, om.AddedTriageNotes, 1)
+				assert.Equal(t, types.TriageNote{OutageID: 7, Body: "rolled back the deploy", Author: "alice", Visibility: types.VisibilityInternal}, *om.AddedTriageNotes[0])
+			},
+		},
+		{
+			name:       "triage note submission from a public channel",
+			payload:    triageNoteSubmissionPayload("U_ALICE", "rolled back the deploy", "outage:alpha/one/7 public"),
+			wantStatus: http.StatusOK,
+			verify: func(t *testing.T, om *outage.MockOutageManager, _ *outage.MockSlackServer) {
+				require.Len(t, om.AddedTriageNotes, 1)
+				assert.Equal(t, types.VisibilityPublic, om.AddedTriageNotes[0].Visibility)
+			},
+		},
+		{
+			name:       "triage note submission from a modal without a visibility",
+			payload:    triageNoteSubmissionPayload("U_ALICE", "rolled back the deploy", "outage:alpha/one/7"),
+			wantStatus: http.StatusOK,
+			verify: func(t *testing.T, om *outage.MockOutageManager, _ *outage.MockSlackServer) {
+				require.Len(t, om.AddedTriageNotes, 1)
+				assert.Equal(t, types.VisibilityInternal, om.AddedTriageNotes[0].Visibility)
+			},
+		},
+		{
+			name:       "empty triage note is rejected in the modal",
+			payload:    triageNoteSubmissionPayload("U_ALICE", "   ", "outage:alpha/one/7 internal"),
+			wantStatus: http.StatusOK,
+			wantBody:   `{"response_action":"errors","errors":{"triage_note":"Body is required"}}`,
+			verify: func(t *testing.T, om *outage.MockOutageManager, _ *outage.MockSlackServer) {
+				assert.Empty(t, om.AddedTriageNotes)
+			},
+		},
+		{
+			name:       "triage note from unauthorized user is rejected in the modal",
+			payload:    triageNoteSubmissionPayload("U_MALLORY", "hello", "outage:alpha/one/7 internal"),
+			wantStatus: http.StatusOK,
+			wantBody:   `{"response_action":"errors","errors":{"triage_note":"You are not authorized to perform this action on this component."}}`,
+			verify: func(t *testing.T, om *outage.MockOutageManager, _ *outage.MockSlackServer) {
+				assert.Empty(t, om.AddedTriageNotes)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := minimalDashboardConfig()
+			cfg.Components[0].Owners = []types.Owner{{User: "alice"}, {User: "trina", Role: types.RoleTriager}}
+			cfg.Components[0].Subcomponents[0].RequiresConfirmation = true
+			cfg.Components[0].SlackReporting = []types.SlackReportingConfig{
+				{Channel: "#alerts", Visibility: types.VisibilityPublic},
+				{Channel: "#team"},
+			}
+			threads := &repositories.MockSlackThreadRepository{ThreadsByTimestamp: []types.OutageSlackThread{
+				{
+					SlackThread:      types.SlackThread{OutageID: 7, Channel: "#alerts", ChannelID: "C_PUBLIC", ThreadTimestamp: "1700000000.000100"},
+					ComponentName:    "alpha",
+					SubComponentName: "one",
+				},
+				{
+					SlackThread:      types.SlackThread{OutageID: 7, Channel: "#team", ChannelID: "C1", ThreadTimestamp: "1700000000.000100"},
+					ComponentName:    "alpha",
+					SubComponentName: "one",
+				},
+			}}
+			om := &outage.MockOutageManager{
+				GetOutageByIDFn: func(componentSlug, subComponentSlug string, outageID uint) (*types.Outage, error) {
+					return &types.Outage{
+						Model:            gorm.Model{ID: outageID},
+						ComponentName:    componentSlug,
+						SubComponentName: subComponentSlug,
+						Severity:         types.SeverityDegraded,
+						Description:      "Builds are failing",
+						StartTime:        time.Now().Add(-time.Hour),
+						DiscoveredFrom:   "frontend",
+						CreatedBy:        "alice",
+					}, nil
+				},
+			}
+			slackServer := outage.NewMockSlackServer(t)
+			defer slackServer.Close()
+			slackServer.AddUser("U_ALICE", "alice@example.com")
+			slackServer.AddUser("U_MALLORY", "mallory@example.com")
+			slackServer.AddUser("U_TRIAGER", "trina@example.com")
+			slackServer.AddUser("U_EXTERNAL", "alice@partner.example.org")
+
+			h := NewSlackInteractionHandler(newTestHandlers(t, cfg, om), slackServer.Client(), threads, testSlackSigningSecret,
+				NewSlackEmailIdentityResolver(slackServer.Client(), "example.com"), logrus.New())
+
+			secret := tt.secret
+			if secret == "" {
+				secret = testSlackSigningSecret
+			}
+			rr := httptest.NewRecorder()
+			h.HandleInteraction(rr, signedSlackRequest(t, tt.payload, secret))
+			h.actions.Wait()
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rr.Body.String())
+			}
+			var replies []string
+			for _, msg := range slackServer.EphemeralMessages() {
+				assert.Equal(t, "C1", msg.Channel)
+				replies = append(replies, msg.Text)
+			}
+			assert.Equal(t, tt.wantReplies, replies)
+			tt.verify(t, om, slackServer)
+		})
+	}
+}
+
+func TestSlackInteractionHandler_AcknowledgesBeforeAction(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.Components[0].Owners = []types.Owner{{User: "alice"}}
+	release := make(chan struct{})
+	om := &outage.MockOutageManager{
+		GetOutageByIDFn: func(componentSlug, subComponentSlug string, outageID uint) (*types.Outage, error) {
+			<-release
+			return nil, errors.New("database error")
+		},
+	}
+	slackServer := outage.NewMockSlackServer(t)
+	defer slackServer.Close()
+	slackServer.AddUser("U_ALICE", "alice@example.com")
+	h := NewSlackInteractionHandler(newTestHandlers(t, cfg, om), slackServer.Client(), &repositories.MockSlackThreadRepository{}, testSlackSigningSecret,
+		NewSlackEmailIdentityResolver(slackServer.Client(), "example.com"), logrus.New())
+
+	rr := httptest.NewRecorder()
+	h.HandleInteraction(rr, signedSlackRequest(t, blockActionPayload("U_ALICE", outage.SlackActionResolve, nil), testSlackSigningSecret))
+	assert.Equal(t, http.StatusOK, rr.Code, "the interaction is acknowledged while the action is still running")
+
+	close(release)
+	h.actions.Wait()
+	require.Len(t, slackServer.EphemeralMessages(), 1)
+	assert.Equal(t, "This outage could not be found.", slackServer.EphemeralMessages()[0].Text)
+}
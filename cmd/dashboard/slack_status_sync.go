@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+// syncedSlackChannel is a channel with status_sync and the sub-components reported to it.
+type syncedSlackChannel struct {
+	mode       types.SlackStatusSync
+	components []*types.Component
+	refs       []types.SubComponentRef
+}
+
+// SlackChannelStatusSyncer keeps the topic or pinned status message of channels with status_sync up to date.
+// It implements outage.Notifier so that outage changes are published right away; a periodic sync catches
+// everything else, such as suspected outages and config reloads.
+type SlackChannelStatusSyncer struct {
+	configManager *config.Manager[types.DashboardConfig]
+	outageManager outage.OutageManager
+	statusRepo    repositories.SlackChannelStatusRepository
+	slackClient   *slack.Client
+	baseURL       string
+	checkInterval time.Duration
+	logger        *logrus.Logger
+	trigger       chan struct{}
+}
+
+// NewSlackChannelStatusSyncer creates a new SlackChannelStatusSyncer. Statuses link to the dashboard at baseURL.
+func NewSlackChannelStatusSyncer(configManager *config.Manager[types.DashboardConfig], outageManager outage.OutageManager, statusRepo repositories.SlackChannelStatusRepository, slackClient *slack.Client, baseURL string, checkInterval time.Duration, logger *logrus.Logger) *SlackChannelStatusSyncer {
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &SlackChannelStatusSyncer{
+		configManager: configManager,
+		outageManager: outageManager,
+		statusRepo:    statusRepo,
+		slackClient:   slackClient,
+		baseURL:       baseURL,
+		checkInterval: checkInterval,
+		logger:        logger,
+		trigger:       make(chan struct{}, 1),
+	}
+}
+
+func (s *SlackChannelStatusSyncer) Name() string {
+	return "slack-status-sync"
+}
+
+// OutageCreated implements outage.Notifier by scheduling a sync.
+func (s *SlackChannelStatusSyncer) OutageCreated(*types.Outage) error {
+	s.requestSync()
+	return nil
+}
+
+// OutageUpdated implements outage.Notifier by scheduling a sync.
+func (s *SlackChannelStatusSyncer) OutageUpdated(_, _ *types.Outage) error {
+	s.requestSync()
+	return nil
+}
+
+// OutageResolved implements outage.Notifier by scheduling a sync.
+func (s *SlackChannelStatusSyncer) OutageResolved(_, _ *types.Outage) error {
+	s.requestSync()
+	return nil
+}
+
+func (s *SlackChannelStatusSyncer) requestSync() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+		// A sync is already pending and will see this change.
+	}
+}
+
+// Start syncs channel statuses on every outage change and every check interval until ctx is done.
+func (s *SlackChannelStatusSyncer) Start(ctx context.Context) {
+	s.logger.WithField("check_interval", s.checkInterval).Info("Starting Slack channel status syncer")
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	s.syncChannels()
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Stopping Slack channel status syncer")
+			return
+		case <-ticker.C:
+			s.syncChannels()
+		case <-s.trigger:
+			s.syncChannels()
+		}
+	}
+}
+
+// syncedChannels groups the sub-components of every slack_reporting entry with status_sync by channel.
+func (s *SlackChannelStatusSyncer) syncedChannels() map[string]*syncedSlackChannel {
+	channels := map[string]*syncedSlackChannel{}
+	for _, component := range s.configManager.Get().Components {
+		for i := range component.Subcomponents {
+			subComponent := &component.Subcomponents[i]
+			for _, reporting := range types.GetSlackReporting(component, subComponent) {
+				if reporting.StatusSync == "" {
+					continue
+				}
+				channel, ok := channels[reporting.Channel]
+				if !ok {
+					channel = &syncedSlackChannel{mode: reporting.StatusSync}
+					channels[reporting.Channel] = channel
+				}
+				if len(channel.components) == 0 || channel.components[len(channel.components)-1] != component {
+					channel.components = append(channel.components, component)
+				}
+				channel.refs = append(channel.refs, types.SubComponentRef{ComponentSlug: component.Slug, SubSlug: subComponent.Slug})
+			}
+		}
+	}
+	return channels
+}
+
+func (s *SlackChannelStatusSyncer) syncChannels() {
+	channels := s.syncedChannels()
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	statuses := map[types.SubComponentRef]types.Status{}
+	loaded := map[string]bool{}
+	for _, name := range names {
+		channel := channels[name]
+		logger := s.logger.WithFields(logrus.Fields{
+			"channel":     name,
+			"status_sync": channel.mode,
+		})
+		summary, err := s.summarize(channel, statuses, loaded)
+		if err != nil {
+			logger.WithField("error", err).Error("Failed to get channel status")
+			continue
+		}
+		if err := s.publish(name, channel.mode, summary); err != nil {
+			logger.WithField("error", err).Error("Failed to sync Slack channel status")
+		}
+	}
+}
+
+// summarize derives the status of each sub-component reported to channel. statuses and loaded cache the
+// component outages across the channels of one sync.
+func (s *SlackChannelStatusSyncer) summarize(channel *syncedSlackChannel, statuses map[types.SubComponentRef]types.Status, loaded map[string]bool) (*outage.SlackChannelStatusSummary, error) {
+	for _, component := range channel.components {
+		if loaded[component.Slug] {
+			continue
+		}
+		confirmed, err := s.outageManager.GetActiveOutagesForComponent(component.Slug)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get active outages for %s: %w", component.Slug, err)
+		}
+		suspected, err := s.outageManager.GetActiveSuspectedOutagesForComponent(component.Slug)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get suspected outages for %s: %w", component.Slug, err)
+		}
+		confirmedBySub := map[string][]types.Outage{}
+		for _, o := range confirmed {
+			confirmedBySub[o.SubComponentName] = append(confirmedBySub[o.SubComponentName], o)
+		}
+		suspectedBySub := map[string][]types.Outage{}
+		for _, o := range suspected {
+			suspectedBySub[o.SubComponentName] = append(suspectedBySub[o.SubComponentName], o)
+		}
+		for _, subComponent := range component.Subcomponents {
+			ref := types.SubComponentRef{ComponentSlug: component.Slug, SubSlug: subComponent.Slug}
+			statuses[ref] = types.StatusFromActiveOutages(confirmedBySub[subComponent.Slug], suspectedBySub[subComponent.Slug])
+		}
+		loaded[component.Slug] = true
+	}
+
+	summary := &outage.SlackChannelStatusSummary{Label: "Status", URL: s.baseURL}
+	if len(channel.components) == 1 {
+		summary.Label = channel.components[0].Name
+		summary.URL = s.baseURL + channel.components[0].Slug
+	}
+	for _, ref := range channel.refs {
+		if status := statuses[ref]; status != types.StatusHealthy {
+			summary.Unhealthy = append(summary.Unhealthy, outage.SubComponentStatus{ComponentSlug: ref.ComponentSlug, SubComponentSlug: ref.SubSlug, Status: status})
+		}
+	}
+	return summary, nil
+}
+
+// publish updates channel when its status text differs from what was last published. A topic or pinned message
+// that the dashboard never set is left alone while everything is healthy.
+func (s *SlackChannelStatusSyncer) publish(channel string, mode types.SlackStatusSync, summary *outage.SlackChannelStatusSummary) error {
+	previous, err := s.statusRepo.GetChannelStatus(channel)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		previous = &types.SlackChannelStatus{Channel: channel}
+	} else if err != nil {
+		return err
+	}
+
+	var text string
+	if mode == types.SlackStatusSyncPinned {
+		text = outage.FormatSlackChannelPinnedStatus(summary, s.configManager)
+	} else {
+		text = outage.FormatSlackChannelTopic(summary)
+	}
+	if text == previous.Text {
+		return nil
+	}
+
+	if mode == types.SlackStatusSyncPinned {
+		err = s.publishPinned(previous, text)
+	} else {
+		err = s.publishTopic(previous, text)
+	}
+	if err != nil {
+		return err
+	}
+	previous.Text = text
+	if err := s.statusRepo.SaveChannelStatus(previous); err != nil {
+		return err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"channel":     channel,
+		"status_sync": mode,
+		"cleared":     text == "",
+	}).Info("Synced Slack channel status")
+	return nil
+}
+
+func (s *SlackChannelStatusSyncer) publishTopic(status *types.SlackChannelStatus, topic string) error {
+	if status.ChannelID == "" {
+		channelID, err := s.resolveChannelID(status.Channel)
+		if err != nil {
+			return err
+		}
+		status.ChannelID = channelID
+	}
+	_, err := s.slackClient.SetTopicOfConversation(status.ChannelID, topic)
+	return err
+}
+
+// publishPinned posts and pins the status message, edits it in place, or unpins and deletes it once text is empty.
+func (s *SlackChannelStatusSyncer) publishPinned(status *types.SlackChannelStatus, text string) error {
+	switch {
+	case text == "":
+		if status.MessageTS == "" {
+			return nil
+		}
+		if err := s.slackClient.RemovePin(status.ChannelID, slack.NewRefToMessage(status.ChannelID, status.MessageTS)); err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"channel": status.Channel,
+				"error":   err,
+			}).Warn("Failed to unpin Slack status message")
+		}
+		if _, _, err := s.slackClient.DeleteMessage(status.ChannelID, status.MessageTS); err != nil {
+			return err
+		}
+		status.MessageTS = ""
+		return nil
+	case status.MessageTS != "":
+		_, _, _, err := s.slackClient.UpdateMessage(status.ChannelID, status.MessageTS, slack.MsgOptionText(text, false))
+		var slackErr slack.SlackErrorResponse
+		if !errors.As(err, &slackErr) || slackErr.Err != "message_not_found" {
+			return err
+		}
+		// Someone deleted the status message, so post a new one.
+		fallthrough
+	default:
+		channelID, timestamp, err := s.slackClient.PostMessage(status.Channel, slack.MsgOptionText(text, false), slack.MsgOptionAsUser(true))
+		if err != nil {
+			return err
+		}
+		status.ChannelID = channelID
+		status.MessageTS = timestamp
+		// The message is saved even when it cannot be pinned, so that it is edited rather than posted again on
+		// every sync.
+		if err := s.slackClient.AddPin(channelID, slack.NewRefToMessage(channelID, timestamp)); err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"channel": status.Channel,
+				"error":   err,
+			}).Warn("Failed to pin Slack status message")
+		}
+		return nil
+	}
+}
+
+// resolveChannelID looks up the ID of a channel configured by name, such as "#build-farm". Names that are
+// already IDs are returned as they are.
+func (s *SlackChannelStatusSyncer) resolveChannelID(channel string) (string, error) {
+	name, isName := strings.CutPrefix(channel, "#")
+	if !isName {
+		return channel, nil
+	}
+	params := &slack.GetConversationsParameters{
+		ExcludeArchived: true,
+		Limit:           1000,
+		Types:           []string{"public_channel", "private_channel"},
+	}
+	for {
+		conversations, cursor, err := s.slackClient.GetConversations(params)
+		if err != nil {
+			return "", err
+		}
+		for _, conversation := range conversations {
+			if conversation.Name == name {
+				return conversation.ID, nil
+			}
+		}
+		if cursor == "" {
+			return "", fmt.Errorf("slack channel %s not found", channel)
+		}
+		params.Cursor = cursor
+	}
+}
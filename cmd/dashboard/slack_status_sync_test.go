@@ -0,0 +1,129 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+func TestSlackChannelStatusSyncer_syncChannels(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.Components[0].Subcomponents = append(cfg.Components[0].Subcomponents, types.SubComponent{Name: "Two", Slug: "two"})
+	cfg.Components[0].SlackReporting = []types.SlackReportingConfig{
+		{Channel: "#alpha", StatusSync: types.SlackStatusSyncTopic},
+		{Channel: "#alpha-status", StatusSync: types.SlackStatusSyncPinned},
+		{Channel: "#alpha-quiet"},
+	}
+
+	confirmedAt := sql.NullTime{Time: time.Now(), Valid: true}
+	active := []types.Outage{
+		{Model: gorm.Model{ID: 1}, ComponentName: "alpha", SubComponentName: "one", Severity: types.SeverityDown, ConfirmedAt: confirmedAt},
+		{Model: gorm.Model{ID: 2}, ComponentName: "alpha", SubComponentName: "two", Severity: types.SeverityDegraded, ConfirmedAt: confirmedAt},
+	}
+	om := &outage.MockOutageManager{
+		GetActiveOutagesForComponentFn: func(string) ([]types.Outage, error) {
+			return active, nil
+		},
+		GetActiveSuspectedOutagesForComponentFn: func(string) ([]types.Outage, error) {
+			return nil, nil
+		},
+	}
+	statusRepo := &repositories.MockSlackChannelStatusRepository{}
+	slackServer := outage.NewMockSlackServer(t)
+	defer slackServer.Close()
+	slackServer.AddChannel("alpha", "C0ALPHA")
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	syncer := NewSlackChannelStatusSyncer(newTestHandlers(t, cfg, om).configManager, om, statusRepo, slackServer.Client(), "https://dashboard.example.com", time.Minute, logger)
+
+	syncer.syncChannels()
+	assert.Equal(t, []outage.TopicChange{{Channel: "C0ALPHA", Topic: "Alpha: 1 Down, 1 Degraded — https://dashboard.example.com/alpha"}}, slackServer.TopicChanges())
+	posted := slackServer.PostedMessages()
+	require.Len(t, posted, 1)
+	assert.Equal(t, "#alpha-status", posted[0].Channel)
+	assert.Contains(t, posted[0].Text, "📌 *Alpha status: 1 Down, 1 Degraded*")
+	assert.Equal(t, []outage.PinChange{{Channel: "#alpha-status", Timestamp: posted[0].ResponseTS, Pinned: true}}, slackServer.PinChanges())
+	assert.NotContains(t, statusRepo.Statuses, "#alpha-quiet", "channels without status_sync are left alone")
+
+	syncer.syncChannels()
+	assert.Len(t, slackServer.TopicChanges(), 1, "an unchanged status is not republished")
+	assert.Len(t, slackServer.PostedMessages(), 1)
+
+	active = active[:1]
+	syncer.syncChannels()
+	assert.Equal(t, "Alpha: 1 Down — https://dashboard.example.com/alpha", slackServer.TopicChanges()[1].Topic)
+	edited := slackServer.EditedMessages()
+	require.Len(t, edited, 1)
+	assert.Equal(t, posted[0].ResponseTS, edited[0].Timestamp)
+	assert.Contains(t, edited[0].Text, "📌 *Alpha status: 1 Down*")
+	assert.False(t, edited[0].Deleted)
+
+	active = nil
+	syncer.syncChannels()
+	assert.Equal(t, outage.TopicChange{Channel: "C0ALPHA", Topic: ""}, slackServer.TopicChanges()[2], "the topic is cleared once healthy")
+	assert.Equal(t, outage.PinChange{Channel: "#alpha-status", Timestamp: posted[0].ResponseTS, Pinned: false}, slackServer.PinChanges()[1])
+	assert.True(t, slackServer.EditedMessages()[1].Deleted)
+	assert.Empty(t, statusRepo.Statuses["#alpha-status"].MessageTS)
+}
+
+func TestSlackChannelStatusSyncer_PinFailure(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.Components[0].SlackReporting = []types.SlackReportingConfig{{Channel: "#alpha-status", StatusSync: types.SlackStatusSyncPinned}}
+	active := []types.Outage{
+		{Model: gorm.Model{ID: 1}, ComponentName: "alpha", SubComponentName: "one", Severity: types.SeverityDown, ConfirmedAt: sql.NullTime{Time: time.Now(), Valid: true}},
+	}
+	om := &outage.MockOutageManager{
+		GetActiveOutagesForComponentFn: func(string) ([]types.Outage, error) {
+			return active, nil
+		},
+		GetActiveSuspectedOutagesForComponentFn: func(string) ([]types.Outage, error) {
+			return nil, nil
+		},
+	}
+	statusRepo := &repositories.MockSlackChannelStatusRepository{}
+	slackServer := outage.NewMockSlackServer(t)
+	defer slackServer.Close()
+	slackServer.FailPins("not_in_channel")
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	syncer := NewSlackChannelStatusSyncer(newTestHandlers(t, cfg, om).configManager, om, statusRepo, slackServer.Client(), "https://dashboard.example.com", time.Minute, logger)
+
+	syncer.syncChannels()
+	posted := slackServer.PostedMessages()
+	require.Len(t, posted, 1)
+	assert.Empty(t, slackServer.PinChanges())
+	require.Contains(t, statusRepo.Statuses, "#alpha-status")
+	assert.Equal(t, posted[0].ResponseTS, statusRepo.Statuses["#alpha-status"].MessageTS, "the message is recorded although it could not be pinned")
+
+	syncer.syncChannels()
+	assert.Len(t, slackServer.PostedMessages(), 1, "an unpinned status message is not posted again")
+}
+
+func TestSlackChannelStatusSyncer_LeavesUntouchedChannelsWhenHealthy(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.Components[0].SlackReporting = []types.SlackReportingConfig{{Channel: "#alpha", StatusSync: types.SlackStatusSyncTopic}}
+	om := &outage.MockOutageManager{
+		GetActiveOutagesForComponentFn:          func(string) ([]types.Outage, error) { return nil, nil },
+		GetActiveSuspectedOutagesForComponentFn: func(string) ([]types.Outage, error) { return nil, nil },
+	}
+	slackServer := outage.NewMockSlackServer(t)
+	defer slackServer.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	syncer := NewSlackChannelStatusSyncer(newTestHandlers(t, cfg, om).configManager, om, &repositories.MockSlackChannelStatusRepository{}, slackServer.Client(), "https://dashboard.example.com", time.Minute, logger)
+
+	syncer.syncChannels()
+	assert.Empty(t, slackServer.TopicChanges(), "a topic the dashboard never set is not cleared")
+}
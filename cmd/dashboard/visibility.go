@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+
+	"ship-status-dash/pkg/types"
+)
+
+const invalidVisibilityMessage = "Invalid visibility. Must be one of: public, internal"
+
+// parseVisibility returns the visibility named by raw, or fallback when raw is empty. It returns false for
+// unknown values.
+func parseVisibility(raw string, fallback types.Visibility) (types.Visibility, bool) {
+	if raw == "" {
+		return fallback, true
+	}
+	if !types.IsValidVisibility(raw) {
+		return "", false
+	}
+	return types.Visibility(raw), true
+}
+
+// viewsInternal reports whether the request may see the internal items of outages on subComponentSlug of
+// componentSlug. That takes an authenticated user, an API token scoped for view if one was used, and a delegation
+// rule covering view on the sub-component if the request was delegated.
+func (h *Handlers) viewsInternal(r *http.Request, componentSlug, subComponentSlug string) bool {
+	if _, ok := GetUserFromContext(r.Context()); !ok {
+		return false
+	}
+	if !apiTokenAllows(r, types.PermissionView) {
+		return false
+	}
+	component := h.config().GetComponentBySlug(componentSlug)
+	if component == nil {
+		// No delegation rule can cover a component that is no longer configured.
+		_, delegated := GetDelegatorFromContext(r.Context())
+		return !delegated
+	}
+	return h.delegatedWithin(r, component, component.GetSubComponentBySlug(subComponentSlug), types.PermissionView)
+}
+
+// visibleOutage returns outage whole when the request may see its internal items, and its public view otherwise.
+func (h *Handlers) visibleOutage(r *http.Request, outage types.Outage) types.Outage {
+	if h.viewsInternal(r, outage.ComponentName, outage.SubComponentName) {
+		return outage
+	}
+	return outage.PublicView()
+}
+
+// visibleOutages applies visibleOutage to each of outages.
+func (h *Handlers) visibleOutages(r *http.Request, outages []types.Outage) []types.Outage {
+	if outages == nil {
+		return nil
+	}
+	visible := make([]types.Outage, len(outages))
+	for i, outage := range outages {
+		visible[i] = h.visibleOutage(r, outage)
+	}
+	return visible
+}
+
+// visibleAuditLogEntries returns entries with the public view of those whose internal items the request may not
+// see. Entries that only changed internal items are left out for such requests.
+func (h *Handlers) visibleAuditLogEntries(r *http.Request, entries []types.AuditLogEntry) []types.AuditLogEntry {
+	visible := make([]types.AuditLogEntry, 0, len(entries))
+	for _, entry := range entries {
+		if h.viewsInternal(r, entry.ComponentName, entry.SubComponentName) {
+			visible = append(visible, entry)
+			continue
+		}
+		if public, ok := entry.PublicView(); ok {
+			visible = append(visible, public)
+		}
+	}
+	return visible
+}
+
+// visibleAuditLogs returns the first filter.Limit entries of the audit log feed that the request may see. Entries
+// left out by visibleAuditLogEntries are made up for from further pages, so callers who may not see internal items
+// still get full pages while the feed has more entries.
+func (h *Handlers) visibleAuditLogs(r *http.Request, filter types.AuditLogFilter) ([]types.AuditLogEntry, error) {
+	limit := filter.Limit
+	visible := []types.AuditLogEntry{}
+	seen := map[uint]bool{}
+	for {
+		entries, err := h.outageManager.GetAuditLogs(filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range h.visibleAuditLogEntries(r, entries) {
+			// Entries written between pages shift the offsets and can repeat an entry already returned.
+			if !seen[entry.ID] {
+				seen[entry.ID] = true
+				visible = append(visible, entry)
+			}
+		}
+		if limit <= 0 || len(visible) >= limit || len(entries) < filter.Limit {
+			break
+		}
+		filter.Offset += len(entries)
+		// Further pages only make up for left out entries, so they are read in large batches.
+		filter.Limit = maxAuditLogLimit
+	}
+	if limit > 0 && len(visible) > limit {
+		visible = visible[:limit]
+	}
+	return visible, nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"crypto"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/18F/hmacauth"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/auth"
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+func TestGetOutageJSON_Visibility(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.DelegationRules = []types.DelegationRule{{Delegator: chatBotServiceAccount, Permissions: []types.Permission{types.PermissionCreateOutage}}}
+	om := &outage.MockOutageManager{
+		GetOutageByIDFn: func(componentSlug, subComponentSlug string, outageID uint) (*types.Outage, error) {
+			return &types.Outage{
+				Model:                 gorm.Model{ID: outageID},
+				ComponentName:         "alpha",
+				SubComponentName:      "one",
+				Description:           "bad deploy of the auth proxy",
+				DescriptionVisibility: types.VisibilityInternal,
+				TriageNotes: []types.TriageNote{
+					{Body: "rolled back", Visibility: types.VisibilityPublic},
+					{Body: "customer X is affected", Visibility: types.VisibilityInternal},
+				},
+			}, nil
+		},
+	}
+	h := newTestHandlers(t, cfg, om)
+
+	tests := []struct {
+		name            string
+		authenticate    func(*http.Request) *http.Request
+		wantDescription string
+		wantNotes       int
+	}{
+		{
+			name:      "anonymous readers see public items only",
+			wantNotes: 1,
+		},
+		{
+			name:            "authenticated users see everything",
+			authenticate:    func(r *http.Request) *http.Request { return withUser(r, "alice") },
+			wantDescription: "bad deploy of the auth proxy",
+			wantNotes:       2,
+		},
+		{
+			name: "tokens without the view scope see public items only",
+			authenticate: func(r *http.Request) *http.Request {
+				return withAPIToken(r, &types.APIToken{User: "alice", Scopes: []types.Permission{types.PermissionCreateOutage}})
+			},
+			wantNotes: 1,
+		},
+		{
+			name: "delegators without a view rule see public items only",
+			authenticate: func(r *http.Request) *http.Request {
+				return withDelegation(r, chatBotServiceAccount, "alice")
+			},
+			wantNotes: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/components/alpha/one/outages/3", nil)
+			if tt.authenticate != nil {
+				req = tt.authenticate(req)
+			}
+			req = mux.SetURLVars(req, map[string]string{"componentName": "alpha", "subComponentName": "one", "outageId": "3"})
+			rec := httptest.NewRecorder()
+			h.GetOutageJSON(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			var got types.Outage
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tt.wantDescription, got.Description)
+			assert.Len(t, got.TriageNotes, tt.wantNotes)
+		})
+	}
+}
+
+func TestGetTriageNotesJSON_Visibility(t *testing.T) {
+	om := &outage.MockOutageManager{
+		GetOutageByIDFn: func(componentSlug, subComponentSlug string, outageID uint) (*types.Outage, error) {
+			return &types.Outage{Model: gorm.Model{ID: outageID}}, nil
+		},
+	}
+	h := newTestHandlers(t, minimalDashboardConfig(), om)
+	h.triageNoteRepo.(*repositories.MockTriageNoteRepository).ListTriageNotesFn = func(uint) ([]types.TriageNote, error) {
+		return []types.TriageNote{
+			{Body: "rolled back", Visibility: types.VisibilityPublic},
+			{Body: "customer X is affected", Visibility: types.VisibilityInternal},
+		}, nil
+	}
+	get := func(req *http.Request) []types.TriageNote {
+		req = mux.SetURLVars(req, map[string]string{"componentName": "alpha", "subComponentName": "one", "outageId": "3"})
+		rec := httptest.NewRecorder()
+		h.GetTriageNotesJSON(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var notes []types.TriageNote
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &notes))
+		return notes
+	}
+
+	anonymous := get(httptest.NewRequest(http.MethodGet, "/api/components/alpha/one/outages/3/triage-notes", nil))
+	require.Len(t, anonymous, 1)
+	assert.Equal(t, "rolled back", anonymous[0].Body)
+
+	authenticated := get(withUser(httptest.NewRequest(http.MethodGet, "/api/components/alpha/one/outages/3/triage-notes", nil), "alice"))
+	assert.Len(t, authenticated, 2)
+}
+
+func TestAddTriageNoteJSON_Visibility(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		wantStatus     int
+		wantVisibility types.Visibility
+	}{
+		{name: "defaults to public", body: `{"body":"rolled back"}`, wantStatus: http.StatusCreated, wantVisibility: types.VisibilityPublic},
+		{name: "internal", body: `{"body":"rolled back","visibility":"internal"}`, wantStatus: http.StatusCreated, wantVisibility: types.VisibilityInternal},
+		{name: "unknown visibility", body: `{"body":"rolled back","visibility":"secret"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			om := &outage.MockOutageManager{
+				GetOutageByIDFn: func(componentSlug, subComponentSlug string, outageID uint) (*types.Outage, error) {
+					return &types.Outage{Model: gorm.Model{ID: outageID}}, nil
+				},
+			}
+			h := newTestHandlers(t, minimalDashboardConfig(), om)
+			req := withUser(httptest.NewRequest(http.MethodPost, "/api/components/alpha/one/outages/3/triage-notes", strings.NewReader(tt.body)), "alice")
+			req = mux.SetURLVars(req, map[string]string{"componentName": "alpha", "subComponentName": "one", "outageId": "3"})
+			rec := httptest.NewRecorder()
+			h.AddTriageNoteJSON(rec, req)
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus != http.StatusCreated {
+				assert.Contains(t, rec.Body.String(), invalidVisibilityMessage)
+				assert.Empty(t, om.AddedTriageNotes)
+				return
+			}
+			require.Len(t, om.AddedTriageNotes, 1)
+			assert.Equal(t, tt.wantVisibility, om.AddedTriageNotes[0].Visibility)
+		})
+	}
+}
+
+func TestGetAuditLogsJSON_InternalEntriesFillFirstPage(t *testing.T) {
+	// The feed holds 30 entries that only add internal notes, newest first, followed by 15 public severity changes.
+	var feed []types.AuditLogEntry
+	for id := uint(45); id > 0; id-- {
+		log := types.OutageAuditLog{Model: gorm.Model{ID: id}, OutageID: 3, User: "alice", Operation: "UPDATE"}
+		if id > 15 {
+			log.Old = []byte(`{"triage_notes":[]}`)
+			log.New = []byte(`{"triage_notes":[{"ID":1,"body":"customer X is affected","visibility":"internal"}]}`)
+		} else {
+			log.Old = []byte(`{"severity":"Down"}`)
+			log.New = []byte(`{"severity":"Degraded"}`)
+		}
+		entry := types.NewAuditLogEntry(log)
+		entry.ComponentName, entry.SubComponentName = "alpha", "one"
+		feed = append(feed, entry)
+	}
+	var queries int
+	om := &outage.MockOutageManager{
+		GetAuditLogsFn: func(filter types.AuditLogFilter) ([]types.AuditLogEntry, error) {
+			queries++
+			start := min(filter.Offset, len(feed))
+			return feed[start:min(start+filter.Limit, len(feed))], nil
+		},
+	}
+	h := newTestHandlers(t, minimalDashboardConfig(), om)
+	get := func(req *http.Request) []types.AuditLogEntry {
+		rec := httptest.NewRecorder()
+		h.GetAuditLogsJSON(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var entries []types.AuditLogEntry
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &entries))
+		return entries
+	}
+	ids := func(entries []types.AuditLogEntry) []uint {
+		var ids []uint
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		return ids
+	}
+
+	anonymous := get(httptest.NewRequest(http.MethodGet, "/api/audit-logs?limit=10", nil))
+	assert.Equal(t, []uint{15, 14, 13, 12, 11, 10, 9, 8, 7, 6}, ids(anonymous), "public entries past the internal ones fill the page")
+
+	queries = 0
+	authenticated := get(withUser(httptest.NewRequest(http.MethodGet, "/api/audit-logs?limit=10", nil), "alice"))
+	assert.Equal(t, []uint{45, 44, 43, 42, 41, 40, 39, 38, 37, 36}, ids(authenticated))
+	assert.Equal(t, 1, queries, "a full first page needs no further queries")
+
+	all := get(httptest.NewRequest(http.MethodGet, "/api/audit-logs", nil))
+	assert.Len(t, all, 15, "a short page means the feed has no more visible entries")
+}
+
+func TestOptionalAuthMiddleware(t *testing.T) {
+	hmacAuth := hmacauth.NewHmacAuth(crypto.SHA256, []byte("secret"), auth.GAPSignatureHeader, auth.OAuthSignatureHeaders)
+	h := newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	var gotUser string
+	var served bool
+	middleware := optionalAuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = true
+		gotUser, _ = GetUserFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}), logger, hmacAuth, h.configManager, h.apiTokenRepo, h.securityEvents, nil, nil)
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		sign       bool
+		wantStatus int
+		wantUser   string
+	}{
+		{
+			name:       "no credentials is served anonymously",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "a user header alone is ignored",
+			headers:    map[string]string{"X-Forwarded-User": "alice"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "signed request is authenticated",
+			headers:    map[string]string{"X-Forwarded-User": "alice"},
+			sign:       true,
+			wantStatus: http.StatusOK,
+			wantUser:   "alice",
+		},
+		{
+			name:       "invalid signature is rejected",
+			headers:    map[string]string{"X-Forwarded-User": "mallory", auth.GAPSignatureHeader: "sha256 aW52YWxpZA=="},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			served, gotUser = false, ""
+			req := httptest.NewRequest(http.MethodGet, "/api/components/alpha/one/outages", nil)
+			for header, value := range tt.headers {
+				req.Header.Set(header, value)
+			}
+			if tt.sign {
+				hmacAuth.SignRequest(req)
+			}
+			rec := httptest.NewRecorder()
+			middleware.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			assert.Equal(t, tt.wantStatus == http.StatusOK, served)
+			assert.Equal(t, tt.wantUser, gotUser)
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/types"
+)
+
+// slackUserIDPattern matches Slack user IDs, which are the destinations of slack_dm notifications.
+var slackUserIDPattern = regexp.MustCompile(`^[UW][A-Z0-9]+$`)
+
+// maxWatchSubscriptionsPerUser bounds how many subscriptions each user can have.
+const maxWatchSubscriptionsPerUser = 100
+
+// validateNotificationDestination checks that destination is usable for channel and that the watch destination
+// policy lets user send notifications to it. It returns a client-facing message and false when it is not.
+func (h *Handlers) validateNotificationDestination(user string, channel types.NotificationChannelType, destination string) (string, bool) {
+	if destination == "" {
+		return "destination is required when channel is set", false
+	}
+	switch channel {
+	case types.NotificationChannelSlackDM:
+		if !slackUserIDPattern.MatchString(destination) {
+			return "slack_dm destination must be a Slack user ID (e.g. U012AB3CD)", false
+		}
+	case types.NotificationChannelEmail:
+		address, err := mail.ParseAddress(destination)
+		if err != nil || address.Address != destination {
+			return "email destination must be a plain email address", false
+		}
+	case types.NotificationChannelWebhook:
+		if !isHTTPURL(destination) {
+			return "webhook destination must be an http or https URL", false
+		}
+	default:
+		return "channel must be one of slack_dm, email, webhook", false
+	}
+	if err := h.watchDestinations.Check(user, channel, destination); err != nil {
+		return err.Error(), false
+	}
+	return "", true
+}
+
+// validateWatchTarget checks that the item a subscription request targets exists.
+// It returns the HTTP status and message to respond with when it does not.
+func (h *Handlers) validateWatchTarget(req *types.WatchSubscriptionRequest) (int, string, bool) {
+	cfg := h.config()
+	targetType := types.WatchTargetType(req.TargetType)
+
+	if targetType == types.WatchTargetTag {
+		if req.Tag == "" {
+			return http.StatusBadRequest, "tag is required for tag subscriptions", false
+		}
+		for _, tag := range cfg.Tags {
+			if tag.Name == req.Tag {
+				return 0, "", true
+			}
+		}
+		return http.StatusNotFound, "Tag not found", false
+	}
+
+	if req.ComponentName == "" {
+		return http.StatusBadRequest, "component_name is required", false
+	}
+	component := cfg.GetComponentBySlug(req.ComponentName)
+	if component == nil {
+		return http.StatusNotFound, "Component not found", false
+	}
+	if targetType == types.WatchTargetComponent {
+		return 0, "", true
+	}
+
+	if req.SubComponentName == "" {
+		return http.StatusBadRequest, "sub_component_name is required", false
+	}
+	if component.GetSubComponentBySlug(req.SubComponentName) == nil {
+		return http.StatusNotFound, "Sub-component not found", false
+	}
+	if targetType == types.WatchTargetSubComponent {
+		return 0, "", true
+	}
+
+	if req.OutageID == 0 {
+		return http.StatusBadRequest, "outage_id is required for outage subscriptions", false
+	}
+	if _, err := h.outageManager.GetOutageByID(req.ComponentName, req.SubComponentName, req.OutageID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, "Outage not found", false
+		}
+		h.logger.WithField("error", err).Error("Failed to query outage from database")
+		return http.StatusInternalServerError, "Failed to get outage", false
+	}
+	return 0, "", true
+}
+
+// ListWatchSubscriptionsJSON returns the authenticated user's watch subscriptions.
+func (h *Handlers) ListWatchSubscriptionsJSON(w http.ResponseWriter, r *http.Request) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "no active user found")
+		return
+	}
+
+	subscriptions, err := h.watchRepo.ListSubscriptions(user)
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"active_user": user,
+			"error":       err,
+		}).Error("Failed to list watch subscriptions")
+		respondWithError(w, http.StatusInternalServerError, "Failed to list subscriptions")
+		return
+	}
+	if subscriptions == nil {
+		subscriptions = []types.WatchSubscription{}
+	}
+
+	respondWithJSON(w, http.StatusOK, subscriptions)
+}
+
+// CreateWatchSubscriptionJSON subscribes the authenticated user to a component, sub-component, tag, or outage.
+func (h *Handlers) CreateWatchSubscriptionJSON(w http.ResponseWriter, r *http.Request) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "no active user found")
+		return
+	}
+
+	logger := h.logger.WithField("active_user", user)
+
+	var req types.WatchSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if !types.IsValidWatchTargetType(req.TargetType) {
+		respondWithError(w, http.StatusBadRequest, "target_type must be one of component, sub_component, tag, outage")
+		return
+	}
+	if status, msg, ok := h.validateWatchTarget(&req); !ok {
+		respondWithError(w, status, msg)
+		return
+	}
+
+	channel := types.NotificationChannelType(req.Channel)
+	if channel != "" {
+		if msg, ok := h.validateNotificationDestination(user, channel, req.Destination); !ok {
+			respondWithError(w, http.StatusBadRequest, msg)
+			return
+		}
+	} else if req.Destination != "" {
+		respondWithError(w, http.StatusBadRequest, "destination requires a channel")
+		return
+	}
+
+	count, err := h.watchRepo.CountSubscriptions(user)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to count watch subscriptions")
+		respondWithError(w, http.StatusInternalServerError, "Failed to create subscription")
+		return
+	}
+	if count >= maxWatchSubscriptionsPerUser {
+		respondWithError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Subscription limit of %d reached", maxWatchSubscriptionsPerUser))
+		return
+	}
+
+	subscription := &types.WatchSubscription{
+		User:        user,
+		TargetType:  types.WatchTargetType(req.TargetType),
+		Channel:     channel,
+		Destination: req.Destination,
+	}
+	switch subscription.TargetType {
+	case types.WatchTargetTag:
+		subscription.Tag = req.Tag
+	case types.WatchTargetComponent:
+		subscription.ComponentName = req.ComponentName
+	case types.WatchTargetSubComponent:
+		subscription.ComponentName = req.ComponentName
+		subscription.SubComponentName = req.SubComponentName
+	case types.WatchTargetOutage:
+		outageID := req.OutageID
+		subscription.ComponentName = req.ComponentName
+		subscription.SubComponentName = req.SubComponentName
+		subscription.OutageID = &outageID
+	}
+
+	if err := h.watchRepo.CreateSubscription(subscription); err != nil {
+		logger.WithField("error", err).Error("Failed to create watch subscription")
+		respondWithError(w, http.StatusInternalServerError, "Failed to create subscription")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"subscription_id": subscription.ID,
+		"target_type":     subscription.TargetType,
+	}).Info("Created watch subscription")
+	respondWithJSON(w, http.StatusCreated, subscription)
+}
+
+// DeleteWatchSubscriptionJSON removes one of the authenticated user's watch subscriptions.
+func (h *Handlers) DeleteWatchSubscriptionJSON(w http.ResponseWriter, r *http.Request) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "no active user found")
+		return
+	}
+
+	subscriptionIDStr := mux.Vars(r)["subscriptionId"]
+	logger := h.logger.WithFields(logrus.Fields{
+		"active_user":     user,
+		"subscription_id": subscriptionIDStr,
+	})
+
+	subscriptionID, err := strconv.ParseUint(subscriptionIDStr, 10, 32)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid subscription ID")
+		return
+	}
+
+	if err := h.watchRepo.DeleteSubscription(user, uint(subscriptionID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondWithError(w, http.StatusNotFound, "Subscription not found")
+			return
+		}
+		logger.WithField("error", err).Error("Failed to delete watch subscription")
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete subscription")
+		return
+	}
+
+	logger.Info("Deleted watch subscription")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetNotificationPreferenceJSON returns the authenticated user's default notification channel.
+func (h *Handlers) GetNotificationPreferenceJSON(w http.ResponseWriter, r *http.Request) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "no active user found")
+		return
+	}
+
+	preference, err := h.watchRepo.GetPreference(user)
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"active_user": user,
+			"error":       err,
+		}).Error("Failed to get notification preference")
+		respondWithError(w, http.StatusInternalServerError, "Failed to get notification preference")
+		return
+	}
+	if preference == nil {
+		respondWithError(w, http.StatusNotFound, "Notification preference not set")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, preference)
+}
+
+// PutNotificationPreferenceJSON sets the authenticated user's default notification channel.
+func (h *Handlers) PutNotificationPreferenceJSON(w http.ResponseWriter, r *http.Request) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "no active user found")
+		return
+	}
+
+	logger := h.logger.WithField("active_user", user)
+
+	var req types.NotificationPreferenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if !types.IsValidNotificationChannelType(req.Channel) {
+		respondWithError(w, http.StatusBadRequest, "channel must be one of slack_dm, email, webhook")
+		return
+	}
+	channel := types.NotificationChannelType(req.Channel)
+	if msg, ok := h.validateNotificationDestination(user, channel, req.Destination); !ok {
+		respondWithError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	preference := &types.NotificationPreference{
+		User:                  user,
+		Channel:               channel,
+		Destination:           req.Destination,
+		NotifyReportedOutages: req.NotifyReportedOutages == nil || *req.NotifyReportedOutages,
+	}
+	if err := h.watchRepo.UpsertPreference(preference); err != nil {
+		logger.WithField("error", err).Error("Failed to save notification preference")
+		respondWithError(w, http.StatusInternalServerError, "Failed to save notification preference")
+		return
+	}
+
+	logger.WithField("channel", channel).Info("Saved notification preference")
+	respondWithJSON(w, http.StatusOK, preference)
+}
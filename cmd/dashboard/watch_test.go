@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/outage"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+func withUser(req *http.Request, user string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), userContextKey, user))
+}
+
+// testWatchDestinations lets alice use U012AB3CD, alice@example.com and webhooks on hooks.example.com.
+var testWatchDestinations = outage.WatchDestinationPolicy{
+	SlackUser: func(slackUserID string) (string, error) {
+		if slackUserID == "U012AB3CD" {
+			return "alice", nil
+		}
+		return "bob", nil
+	},
+	EmailDomain:  "example.com",
+	WebhookHosts: []string{"hooks.example.com"},
+}
+
+func TestCreateWatchSubscriptionJSON(t *testing.T) {
+	cfg := minimalDashboardConfig()
+	cfg.Tags = []types.Tag{{Name: "ci"}}
+	om := &outage.MockOutageManager{
+		GetOutageByIDFn: func(componentSlug, subComponentSlug string, outageID uint) (*types.Outage, error) {
+			if outageID == 5 {
+				return &types.Outage{Model: gorm.Model{ID: 5}}, nil
+			}
+			return nil, gorm.ErrRecordNotFound
+		},
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "component with default channel", body: `{"target_type":"component","component_name":"alpha"}`, wantStatus: http.StatusCreated},
+		{name: "sub-component with slack dm", body: `{"target_type":"sub_component","component_name":"alpha","sub_component_name":"one","channel":"slack_dm","destination":"U012AB3CD"}`, wantStatus: http.StatusCreated},
+		{name: "tag with email", body: `{"target_type":"tag","tag":"ci","channel":"email","destination":"alice@example.com"}`, wantStatus: http.StatusCreated},
+		{name: "outage with webhook", body: `{"target_type":"outage","component_name":"alpha","sub_component_name":"one","outage_id":5,"channel":"webhook","destination":"https://hooks.example.com/x"}`, wantStatus: http.StatusCreated},
+		{name: "invalid body", body: `{`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "invalid target type", body: `{"target_type":"team"}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown component", body: `{"target_type":"component","component_name":"nope"}`, wantStatus: http.StatusNotFound, wantError: "Component not found"},
+		{name: "unknown sub-component", body: `{"target_type":"sub_component","component_name":"alpha","sub_component_name":"nope"}`, wantStatus: http.StatusNotFound, wantError: "Sub-component not found"},
+		{name: "unknown tag", body: `{"target_type":"tag","tag":"nope"}`, wantStatus: http.StatusNotFound, wantError: "Tag not found"},
+		{name: "unknown outage", body: `{"target_type":"outage","component_name":"alpha","sub_component_name":"one","outage_id":6}`, wantStatus: http.StatusNotFound, wantError: "Outage not found"},
+		{name: "missing outage id", body: `{"target_type":"outage","component_name":"alpha","sub_component_name":"one"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid slack user", body: `{"target_type":"component","component_name":"alpha","channel":"slack_dm","destination":"#channel"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid email", body: `{"target_type":"component","component_name":"alpha","channel":"email","destination":"Alice <alice@example.com>"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid webhook", body: `{"target_type":"component","component_name":"alpha","channel":"webhook","destination":"ftp://example.com"}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown channel", body: `{"target_type":"component","component_name":"alpha","channel":"sms","destination":"123"}`, wantStatus: http.StatusBadRequest},
+		{name: "another user's slack id", body: `{"target_type":"component","component_name":"alpha","channel":"slack_dm","destination":"U999"}`, wantStatus: http.StatusBadRequest, wantError: "slack_dm destination must be your own Slack user ID"},
+		{name: "another user's email", body: `{"target_type":"component","component_name":"alpha","channel":"email","destination":"bob@example.com"}`, wantStatus: http.StatusBadRequest, wantError: "email destination must be your own address, alice@example.com"},
+		{name: "webhook host not allowed", body: `{"target_type":"component","component_name":"alpha","channel":"webhook","destination":"http://10.0.0.1/x"}`, wantStatus: http.StatusBadRequest, wantError: "webhook destination host is not allowed"},
+		{name: "destination without channel", body: `{"target_type":"component","component_name":"alpha","destination":"U012AB3CD"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandlers(t, cfg, om)
+			h.watchDestinations = testWatchDestinations
+			req := withUser(httptest.NewRequest(http.MethodPost, "/api/user/subscriptions", strings.NewReader(tt.body)), "alice")
+			rec := httptest.NewRecorder()
+			h.CreateWatchSubscriptionJSON(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantError != "" {
+				var resp map[string]string
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				assert.Equal(t, tt.wantError, resp["error"])
+			}
+			if tt.wantStatus == http.StatusCreated {
+				var subscription types.WatchSubscription
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&subscription))
+				assert.Equal(t, "alice", subscription.User)
+				assert.NotZero(t, subscription.ID)
+			}
+		})
+	}
+}
+
+func TestCreateWatchSubscriptionJSON_Limit(t *testing.T) {
+	h := newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})
+	repo := &repositories.MockWatchSubscriptionRepository{}
+	for i := 0; i < maxWatchSubscriptionsPerUser; i++ {
+		repo.Subscriptions = append(repo.Subscriptions, types.WatchSubscription{User: "alice"})
+	}
+	h.watchRepo = repo
+
+	create := func(user string) int {
+		req := withUser(httptest.NewRequest(http.MethodPost, "/api/user/subscriptions", strings.NewReader(`{"target_type":"component","component_name":"alpha"}`)), user)
+		rec := httptest.NewRecorder()
+		h.CreateWatchSubscriptionJSON(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusUnprocessableEntity, create("alice"))
+	assert.Equal(t, http.StatusCreated, create("bob"), "the limit is per user")
+}
+
+func TestWatchSubscriptionLifecycle(t *testing.T) {
+	h := newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})
+	repo := &repositories.MockWatchSubscriptionRepository{
+		Subscriptions: []types.WatchSubscription{
+			{Model: gorm.Model{ID: 1}, User: "alice", TargetType: types.WatchTargetComponent, ComponentName: "alpha"},
+			{Model: gorm.Model{ID: 2}, User: "bob", TargetType: types.WatchTargetComponent, ComponentName: "alpha"},
+		},
+	}
+	h.watchRepo = repo
+
+	rec := httptest.NewRecorder()
+	h.ListWatchSubscriptionsJSON(rec, withUser(httptest.NewRequest(http.MethodGet, "/api/user/subscriptions", nil), "alice"))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var subscriptions []types.WatchSubscription
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&subscriptions))
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, uint(1), subscriptions[0].ID)
+
+	deleteSubscription := func(id string) int {
+		req := withUser(httptest.NewRequest(http.MethodDelete, "/api/user/subscriptions/"+id, nil), "alice")
+		req = mux.SetURLVars(req, map[string]string{"subscriptionId": id})
+		rec := httptest.NewRecorder()
+		h.DeleteWatchSubscriptionJSON(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusNotFound, deleteSubscription("2"), "users cannot delete other users' subscriptions")
+	assert.Equal(t, http.StatusNoContent, deleteSubscription("1"))
+	assert.Equal(t, http.StatusNotFound, deleteSubscription("1"))
+	assert.Len(t, repo.Subscriptions, 1)
+
+	rec = httptest.NewRecorder()
+	h.ListWatchSubscriptionsJSON(rec, httptest.NewRequest(http.MethodGet, "/api/user/subscriptions", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestNotificationPreferenceJSON(t *testing.T) {
+	h := newTestHandlers(t, minimalDashboardConfig(), &outage.MockOutageManager{})
+	h.watchDestinations = testWatchDestinations
+
+	rec := httptest.NewRecorder()
+	h.GetNotificationPreferenceJSON(rec, withUser(httptest.NewRequest(http.MethodGet, "/api/user/notification-preferences", nil), "alice"))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.PutNotificationPreferenceJSON(rec, withUser(httptest.NewRequest(http.MethodPut, "/api/user/notification-preferences", strings.NewReader(body)), "alice"))
+		return rec
+	}
+	assert.Equal(t, http.StatusBadRequest, put(`{"channel":"","destination":"U1"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, put(`{"channel":"email","destination":"not-an-email"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, put(`{"channel":"email","destination":"bob@example.com"}`).Code)
+
+	rec = put(`{"channel":"slack_dm","destination":"U012AB3CD"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	h.GetNotificationPreferenceJSON(rec, withUser(httptest.NewRequest(http.MethodGet, "/api/user/notification-preferences", nil), "alice"))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var preference types.NotificationPreference
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&preference))
+	assert.Equal(t, types.NotificationChannelSlackDM, preference.Channel)
+	assert.Equal(t, "U012AB3CD", preference.Destination)
+	assert.True(t, preference.NotifyReportedOutages, "notify_reported_outages defaults to true")
+
+	require.Equal(t, http.StatusOK, put(`{"channel":"email","destination":"alice@example.com","notify_reported_outages":false}`).Code)
+	rec = httptest.NewRecorder()
+	h.GetNotificationPreferenceJSON(rec, withUser(httptest.NewRequest(http.MethodGet, "/api/user/notification-preferences", nil), "alice"))
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&preference))
+	assert.Equal(t, types.NotificationChannelEmail, preference.Channel)
+	assert.False(t, preference.NotifyReportedOutages)
+}
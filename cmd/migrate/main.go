@@ -121,6 +121,38 @@ func main() {
 		log.WithField("error", err).Fatal("Failed to migrate OutageReport table")
 	}
 
+	if err = db.AutoMigrate(&types.WatchSubscription{}); err != nil {
+		log.WithField("error", err).Fatal("Failed to migrate WatchSubscription table")
+	}
+
+	if err = db.AutoMigrate(&types.NotificationPreference{}); err != nil {
+		log.WithField("error", err).Fatal("Failed to migrate NotificationPreference table")
+	}
+
+	if err = db.AutoMigrate(&types.OutageEscalation{}); err != nil {
+		log.WithField("error", err).Fatal("Failed to migrate OutageEscalation table")
+	}
+
+	if err = db.AutoMigrate(&types.SlackChannelStatus{}); err != nil {
+		log.WithField("error", err).Fatal("Failed to migrate SlackChannelStatus table")
+	}
+
+	if err = db.AutoMigrate(&types.APIToken{}); err != nil {
+		log.WithField("error", err).Fatal("Failed to migrate APIToken table")
+	}
+
+	if err = db.AutoMigrate(&types.APITokenUse{}); err != nil {
+		log.WithField("error", err).Fatal("Failed to migrate APITokenUse table")
+	}
+
+	if err = db.AutoMigrate(&types.SecurityEvent{}); err != nil {
+		log.WithField("error", err).Fatal("Failed to migrate SecurityEvent table")
+	}
+
+	if err = db.AutoMigrate(&types.IdempotencyKey{}); err != nil {
+		log.WithField("error", err).Fatal("Failed to migrate IdempotencyKey table")
+	}
+
 	db.Exec("DROP INDEX IF EXISTS idx_one_active_suspected_per_subcomponent")
 	if err = db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_one_active_suspected_per_subcomponent
 		ON outages (component_name, sub_component_name)
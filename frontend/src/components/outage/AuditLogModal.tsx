@@ -219,6 +219,7 @@ const AuditLogModal = ({
                   />
                   <Typography variant="body2" color="text.secondary">
                     {log.user}
+                    {log.delegator && ` (via ${log.delegator})`}
                   </Typography>
                   <Typography variant="caption" color="text.secondary">
                     {formatDateTime(log.CreatedAt)}
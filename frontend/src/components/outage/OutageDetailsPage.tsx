@@ -30,6 +30,7 @@ import { useCallback, useEffect, useRef, useState } from 'react'
 import { useNavigate, useParams } from 'react-router'
 
 import { useAuth } from '../../contexts/AuthContext'
+import type { Permission } from '../../contexts/AuthContext'
 import useIntervalRefresh from '../../hooks/useIntervalRefresh'
 import type { Outage, OutageLink, TriageNote } from '../../types'
 import { deferMountFetch } from '../../utils/deferMountFetch'
@@ -233,8 +234,9 @@ const OutageDetailsPage = () => {
     lastAuditableUpdateRef.current = lastAuditableUpdate
   }, [lastAuditableUpdate])
 
-  const { user, isComponentAdmin } = useAuth()
-  const isAdmin = outage ? isComponentAdmin(outage.component_name) : false
+  const { user, hasPermission } = useAuth()
+  const can = (permission: Permission) =>
+    outage ? hasPermission(outage.component_name, permission) : false
   const currentUser = user?.username ?? ''
 
   const validationError =
@@ -601,7 +603,8 @@ const OutageDetailsPage = () => {
           <Section icon={<Notes />} title="Triage Notes">
             <TriageNotesSection
               notes={outage.triage_notes ?? []}
-              isAdmin={isAdmin}
+              canAddNotes={can('triage_note:create')}
+              canManageNotes={can('triage_note:manage')}
               currentUser={currentUser}
               componentName={componentName}
               subComponentName={subComponentName}
@@ -617,7 +620,8 @@ const OutageDetailsPage = () => {
         <FullWidthGridItem>
           <OutageLinksSection
             links={outage.links ?? []}
-            isAdmin={isAdmin}
+            canWriteLinks={can('link:write')}
+            canDeleteLinks={can('link:delete')}
             componentName={componentName}
             subComponentName={subComponentName}
             outageId={outage.ID}
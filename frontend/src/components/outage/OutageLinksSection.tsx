@@ -23,7 +23,8 @@ import Section from './OutageDetailsSection'
 
 interface OutageLinksSectionProps {
   links: OutageLink[]
-  isAdmin: boolean
+  canWriteLinks: boolean
+  canDeleteLinks: boolean
   componentName: string
   subComponentName: string
   outageId: number
@@ -65,10 +66,13 @@ const AddLinkRow = styled(Box)(({ theme }) => ({
 const LINK_TYPE_OPTIONS = [
   { value: 'incident_channel_thread', label: 'Incident Channel/Thread' },
   { value: 'rca', label: 'RCA' },
+  { value: 'pagerduty_incident', label: 'PagerDuty Incident' },
+  { value: 'jira_issue', label: 'Jira Issue' },
   { value: 'other', label: 'Other' },
 ] as const
 
 const getLinkLabel = (link: OutageLink): string => {
+  if (link.link_type === 'jira_issue' && link.description) return link.description
   const option = LINK_TYPE_OPTIONS.find((o) => o.value === link.link_type)
   if (option && link.link_type !== 'other') return option.label
   return link.description || link.url
@@ -76,7 +80,8 @@ const getLinkLabel = (link: OutageLink): string => {
 
 const OutageLinksSection = ({
   links,
-  isAdmin,
+  canWriteLinks,
+  canDeleteLinks,
   componentName,
   subComponentName,
   outageId,
@@ -203,7 +208,7 @@ const OutageLinksSection = ({
 
   return (
     <Section icon={<OpenInNew />} title="Links">
-      {links.length === 0 && !isAdmin && (
+      {links.length === 0 && !canWriteLinks && (
         <Typography variant="body2" color="text.secondary" sx={{ fontStyle: 'italic' }}>
           No links yet.
         </Typography>
@@ -288,6 +293,11 @@ const OutageLinksSection = ({
                 >
                   {getLinkLabel(link)}
                 </Link>
+                {link.status && (
+                  <Typography component="span" variant="caption" color="text.secondary" sx={{ ml: 1 }}>
+                    {link.status}
+                  </Typography>
+                )}
                 {getLinkLabel(link) !== link.url && (
                   <Typography
                     variant="caption"
@@ -300,33 +310,29 @@ const OutageLinksSection = ({
                 )}
               </LinkContent>
             )}
-            {isAdmin && !isEditing && (
-              <>
-                <Tooltip title="Edit link">
-                  <IconButton
-                    size="small"
-                    onClick={() => startLinkEdit(link)}
-                    aria-label="edit link"
-                  >
-                    <Edit fontSize="small" />
-                  </IconButton>
-                </Tooltip>
-                <Tooltip title="Remove link">
-                  <IconButton
-                    size="small"
-                    onClick={() => handleDeleteLink(link.ID)}
-                    aria-label="remove link"
-                  >
-                    <Delete fontSize="small" />
-                  </IconButton>
-                </Tooltip>
-              </>
+            {canWriteLinks && !isEditing && (
+              <Tooltip title="Edit link">
+                <IconButton size="small" onClick={() => startLinkEdit(link)} aria-label="edit link">
+                  <Edit fontSize="small" />
+                </IconButton>
+              </Tooltip>
+            )}
+            {canDeleteLinks && !isEditing && (
+              <Tooltip title="Remove link">
+                <IconButton
+                  size="small"
+                  onClick={() => handleDeleteLink(link.ID)}
+                  aria-label="remove link"
+                >
+                  <Delete fontSize="small" />
+                </IconButton>
+              </Tooltip>
             )}
           </LinkRow>
         )
       })}
 
-      {isAdmin && (
+      {canWriteLinks && (
         <>
           {links.length > 0 && <Divider sx={{ my: 1.5 }} />}
           {linkError && (
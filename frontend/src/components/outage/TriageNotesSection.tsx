@@ -87,7 +87,8 @@ const EmptyNotice = styled(Typography)(({ theme }) => ({
 
 interface TriageNotesSectionProps {
   notes: TriageNote[]
-  isAdmin: boolean
+  canAddNotes: boolean
+  canManageNotes: boolean
   currentUser: string
   componentName: string
   subComponentName: string
@@ -100,7 +101,8 @@ interface TriageNotesSectionProps {
 
 const TriageNotesSection = ({
   notes,
-  isAdmin,
+  canAddNotes,
+  canManageNotes,
   currentUser,
   componentName,
   subComponentName,
@@ -230,14 +232,14 @@ const TriageNotesSection = ({
         </Alert>
       )}
 
-      {notes.length === 0 && !isAdmin && (
+      {notes.length === 0 && !canAddNotes && (
         <EmptyNotice variant="body2">No triage notes yet.</EmptyNotice>
       )}
 
       {notes.length > 0 && (
         <NoteList>
           {notes.map((note) => {
-            const canModify = isAdmin || note.author === currentUser
+            const canModify = canManageNotes || note.author === currentUser
             const isEditing = editingNoteId === note.ID
 
             return (
@@ -246,6 +248,7 @@ const TriageNotesSection = ({
                   <NoteAuthor variant="body2">{note.author}</NoteAuthor>
                   <NoteTimestamp variant="caption">
                     {relativeTime(new Date(note.CreatedAt), new Date())}
+                    {note.slack_message_ts && ' via Slack'}
                   </NoteTimestamp>
                   {canModify && !isEditing && (
                     <NoteActions>
@@ -314,7 +317,7 @@ const TriageNotesSection = ({
         </NoteList>
       )}
 
-      {isAdmin && (
+      {canAddNotes && (
         <>
           {notes.length > 0 && <Divider sx={{ my: 2 }} />}
           <ComposeArea>
@@ -18,13 +18,14 @@ interface OutageActionsProps {
 }
 
 const OutageActions = ({ outage, onSuccess, onError }: OutageActionsProps) => {
-  const { isComponentAdmin } = useAuth()
+  const { hasPermission } = useAuth()
   const [anchorEl, setAnchorEl] = useState<null | HTMLElement>(null)
   const [updateDialogOpen, setUpdateDialogOpen] = useState(false)
 
-  const isAdmin = isComponentAdmin(outage.component_name)
+  const canUpdate = hasPermission(outage.component_name, 'outage:update')
+  const canDelete = hasPermission(outage.component_name, 'outage:delete')
 
-  if (!isAdmin) {
+  if (!canUpdate) {
     return null
   }
 
@@ -84,9 +85,11 @@ const OutageActions = ({ outage, onSuccess, onError }: OutageActionsProps) => {
             <EndOutage outage={outage} onEndSuccess={onSuccess} onError={onError} />
           </MenuItem>
         )}
-        <MenuItem>
-          <DeleteOutage outage={outage} onDeleteSuccess={onSuccess} onError={onError} />
-        </MenuItem>
+        {canDelete && (
+          <MenuItem>
+            <DeleteOutage outage={outage} onDeleteSuccess={onSuccess} onError={onError} />
+          </MenuItem>
+        )}
       </Menu>
 
       <UpsertOutageModal
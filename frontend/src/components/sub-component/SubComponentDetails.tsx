@@ -218,7 +218,7 @@ const SubComponentDetails = () => {
     componentSlug: string
     subComponentSlug: string
   }>()
-  const { user, isComponentAdmin } = useAuth()
+  const { user, hasPermission } = useAuth()
   const { getTag } = useTags()
   const [outages, setOutages] = useState<Outage[]>([])
   const [error, setError] = useState<string | null>(null)
@@ -238,7 +238,8 @@ const SubComponentDetails = () => {
 
   const componentName = componentSlug ? deslugify(componentSlug) : ''
   const subComponentName = subComponentSlug ? deslugify(subComponentSlug) : ''
-  const isAdmin = isComponentAdmin(componentSlug || '')
+  const canCreateOutage = hasPermission(componentSlug || '', 'outage:create')
+  const canUpdateOutages = hasPermission(componentSlug || '', 'outage:update')
   const hasUserReported = !!(
     user && subComponentStatus?.suspected_outage?.reporters?.includes(user.username)
   )
@@ -559,7 +560,7 @@ const SubComponentDetails = () => {
         return <OutageDetailsButton outage={outage} />
       },
     },
-    ...(isAdmin
+    ...(canUpdateOutages
       ? [
           {
             field: 'actions',
@@ -657,7 +658,7 @@ const SubComponentDetails = () => {
             )}
           </Box>
           <HeaderActionsRow>
-            {isAdmin && (
+            {canCreateOutage && (
               <ReportOutageButton
                 variant="contained"
                 startIcon={<ReportProblem />}
@@ -667,7 +668,7 @@ const SubComponentDetails = () => {
                 Report Outage
               </ReportOutageButton>
             )}
-            {user && !isAdmin && subComponentStatus && !subComponentStatus.suspected_outage && (
+            {user && !canCreateOutage && subComponentStatus && !subComponentStatus.suspected_outage && (
               <Button
                 variant="outlined"
                 startIcon={<ReportProblem />}
@@ -19,8 +19,8 @@ const SuspectedReportsBanner = ({
   hasUserReported,
   onReportClick,
 }: SuspectedReportsBannerProps) => {
-  const { user, isComponentAdmin } = useAuth()
-  const isNonAdmin = !!user && !isComponentAdmin(componentSlug)
+  const { user, hasPermission } = useAuth()
+  const isNonAdmin = !!user && !hasPermission(componentSlug, 'outage:create')
 
   const reportCount = suspected.report_count
   const reportLabel = reportCount === 1 ? 'report' : 'reports'
@@ -3,15 +3,27 @@ import { createContext, useContext, useEffect, useState } from 'react'
 
 import { getUserEndpoint } from '../utils/endpoints'
 
+export type Permission =
+  | 'view'
+  | 'triage_note:create'
+  | 'link:write'
+  | 'outage:create'
+  | 'outage:update'
+  | 'link:delete'
+  | 'status:report'
+  | 'outage:delete'
+  | 'triage_note:manage'
+
 interface AuthenticatedUser {
   username: string
   components: string[]
+  permissions: Record<string, Permission[]>
 }
 
 interface AuthContextType {
   user: AuthenticatedUser | null
   loading: boolean
-  isComponentAdmin: (componentSlug: string) => boolean
+  hasPermission: (componentSlug: string, permission: Permission) => boolean
 }
 
 const AuthContext = createContext<AuthContextType | undefined>(undefined)
@@ -43,15 +55,15 @@ export const AuthProvider = ({ children }: { children: ReactNode }) => {
       })
   }, [])
 
-  const isComponentAdmin = (componentSlug: string): boolean => {
+  const hasPermission = (componentSlug: string, permission: Permission): boolean => {
     if (!user) {
       return false
     }
-    return user.components.includes(componentSlug)
+    return user.permissions?.[componentSlug]?.includes(permission) ?? false
   }
 
   return (
-    <AuthContext.Provider value={{ user, loading, isComponentAdmin: isComponentAdmin }}>
+    <AuthContext.Provider value={{ user, loading, hasPermission }}>
       {children}
     </AuthContext.Provider>
   )
@@ -26,8 +26,11 @@ export interface ComponentStatus {
 
 export interface OutageDayBucket {
   date: string // YYYY-MM-DD
+  start?: string
+  end?: string
   highest_severity: Status | null
   total_outage_minutes: number
+  severity_minutes?: Partial<Record<Status, number>>
   outage_count: number
 }
 
@@ -45,12 +48,16 @@ export interface SlackThread {
   thread_url: string
 }
 
+export type Visibility = 'public' | 'internal'
+
 export interface TriageNote {
   ID: number
   CreatedAt: string
   outage_id: number
   body: string
   author: string
+  visibility?: Visibility
+  slack_message_ts?: string
 }
 
 export interface OutageLink {
@@ -58,8 +65,10 @@ export interface OutageLink {
   CreatedAt: string
   outage_id: number
   url: string
-  link_type: 'incident_channel_thread' | 'rca' | 'other'
+  link_type: 'incident_channel_thread' | 'rca' | 'pagerduty_incident' | 'jira_issue' | 'other'
   description?: string
+  status?: string
+  visibility?: Visibility
 }
 
 export interface OutageAuditLog {
@@ -68,9 +77,17 @@ export interface OutageAuditLog {
   UpdatedAt: string
   outage_id: number
   user: string
+  delegator?: string
   operation: string
   old?: string
   new?: string
+  changes?: AuditFieldChange[]
+}
+
+export interface AuditFieldChange {
+  field: string
+  old: unknown
+  new: unknown
 }
 
 export interface Outage {
@@ -88,6 +105,7 @@ export interface Outage {
   }
   auto_resolve: boolean
   description?: string
+  description_visibility?: Visibility
   discovered_from?: string
   created_by?: string
   resolved_by?: string
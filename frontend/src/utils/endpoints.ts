@@ -94,7 +94,7 @@ export const getSubComponentHistoryEndpoint = (
   subComponentName: string,
   days: number,
 ) =>
-  `${getPublicDomain()}/api/components/${slugify(componentName)}/${slugify(subComponentName)}/outage-history?days=${days}`
+  `${getPublicDomain()}/api/components/${slugify(componentName)}/${slugify(subComponentName)}/outage-history?days=${days}&tz=${encodeURIComponent(Intl.DateTimeFormat().resolvedOptions().timeZone)}`
 
 export const getReportSuspectedOutageEndpoint = (componentName: string, subComponentName: string) =>
   `${getProtectedDomain()}/api/components/${slugify(componentName)}/${slugify(subComponentName)}/outages/report-suspected`
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APITokenPrefix starts every personal access token, so bearer tokens meant for the dashboard can be told
+// apart from other Authorization headers and recognized by secret scanners.
+const APITokenPrefix = "ssd_"
+
+// apiTokenDisplayLength is how much of a token is kept in the clear to identify it in listings.
+const apiTokenDisplayLength = len(APITokenPrefix) + 6
+
+// GenerateAPIToken returns a new random personal access token, its hash for storage, and its display prefix.
+func GenerateAPIToken() (token, hash, prefix string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token = APITokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return token, HashAPIToken(token), token[:apiTokenDisplayLength], nil
+}
+
+// HashAPIToken returns the hex SHA-256 of token. Tokens carry 256 bits of randomness, so a fast unsalted hash
+// is enough to make the stored value useless without the token.
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// BearerToken returns the token in an "Authorization: Bearer" header value.
+func BearerToken(authorization string) (string, bool) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// BearerAPIToken returns the personal access token in an "Authorization: Bearer" header value, if it holds one.
+func BearerAPIToken(authorization string) (string, bool) {
+	token, ok := BearerToken(authorization)
+	return token, ok && strings.HasPrefix(token, APITokenPrefix)
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateAPIToken(t *testing.T) {
+	token, hash, prefix, err := GenerateAPIToken()
+	if err != nil {
+		t.Fatalf("GenerateAPIToken returned error: %v", err)
+	}
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		t.Errorf("token %q does not start with %q", token, APITokenPrefix)
+	}
+	if hash != HashAPIToken(token) {
+		t.Errorf("hash %q does not match HashAPIToken(token)", hash)
+	}
+	if strings.Contains(hash, token) {
+		t.Error("hash contains the token")
+	}
+	if !strings.HasPrefix(token, prefix) || len(prefix) >= len(token) {
+		t.Errorf("prefix %q is not a proper prefix of the token", prefix)
+	}
+
+	other, _, _, err := GenerateAPIToken()
+	if err != nil {
+		t.Fatalf("GenerateAPIToken returned error: %v", err)
+	}
+	if other == token {
+		t.Error("GenerateAPIToken returned the same token twice")
+	}
+}
+
+func TestBearerAPIToken(t *testing.T) {
+	tests := []struct {
+		authorization string
+		wantToken     string
+		wantOK        bool
+	}{
+		{authorization: "Bearer ssd_abc", wantToken: "ssd_abc", wantOK: true},
+		{authorization: "bearer  ssd_abc ", wantToken: "ssd_abc", wantOK: true},
+		{authorization: "Bearer eyJhbGciOi", wantToken: "eyJhbGciOi"},
+		{authorization: "Basic ssd_abc"},
+		{authorization: "ssd_abc"},
+		{authorization: ""},
+	}
+	for _, tt := range tests {
+		token, ok := BearerAPIToken(tt.authorization)
+		if ok != tt.wantOK || token != tt.wantToken {
+			t.Errorf("BearerAPIToken(%q) = %q, %v; want %q, %v", tt.authorization, token, ok, tt.wantToken, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ClaimGroupMembership adds the groups claimed in users' OIDC ID tokens to the members from a group source, so a
+// rover_group can be satisfied by the identity provider alone. A user's claims count until their session expires.
+type ClaimGroupMembership struct {
+	base GroupMembershipProvider
+
+	mu    sync.RWMutex
+	users map[string]claimedGroups
+	now   func() time.Time
+}
+
+type claimedGroups struct {
+	groups  []string
+	expires time.Time
+}
+
+// NewClaimGroupMembership creates a provider combining base with the recorded claims.
+func NewClaimGroupMembership(base GroupMembershipProvider) *ClaimGroupMembership {
+	return &ClaimGroupMembership{
+		base:  base,
+		users: make(map[string]claimedGroups),
+		now:   time.Now,
+	}
+}
+
+// Record replaces the claimed groups of user, valid until expires.
+func (c *ClaimGroupMembership) Record(user string, groups []string, expires time.Time) {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, claimed := range c.users {
+		if !now.Before(claimed.expires) {
+			delete(c.users, name)
+		}
+	}
+	c.users[user] = claimedGroups{groups: slices.Clone(groups), expires: expires}
+}
+
+// GetGroupMembers returns the members of groupName from the group source followed by the users claiming it.
+func (c *ClaimGroupMembership) GetGroupMembers(groupName string) []string {
+	members := slices.Clone(c.base.GetGroupMembers(groupName))
+	for _, user := range c.claimingUsers(groupName) {
+		if !slices.Contains(members, user) {
+			members = append(members, user)
+		}
+	}
+	return members
+}
+
+// GroupStatuses reports the groups of the group source with the claiming users added to their members, and the
+// groups only known from claims.
+func (c *ClaimGroupMembership) GroupStatuses() []GroupStatus {
+	statuses := c.base.GroupStatuses()
+	known := make(map[string]int, len(statuses))
+	for i := range statuses {
+		known[statuses[i].Name] = i
+	}
+
+	for _, groupName := range c.claimedGroupNames() {
+		i, ok := known[groupName]
+		if !ok {
+			statuses = append(statuses, GroupStatus{Name: groupName, Source: "oidc", Members: []string{}})
+			i = len(statuses) - 1
+		}
+		for _, user := range c.claimingUsers(groupName) {
+			if !slices.Contains(statuses[i].Members, user) {
+				statuses[i].Members = append(statuses[i].Members, user)
+			}
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// claimingUsers returns the users with an unexpired claim to groupName, sorted.
+func (c *ClaimGroupMembership) claimingUsers(groupName string) []string {
+	now := c.now()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var users []string
+	for user, claimed := range c.users {
+		if now.Before(claimed.expires) && slices.Contains(claimed.groups, groupName) {
+			users = append(users, user)
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+func (c *ClaimGroupMembership) claimedGroupNames() []string {
+	now := c.now()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var names []string
+	for _, claimed := range c.users {
+		if !now.Before(claimed.expires) {
+			continue
+		}
+		for _, groupName := range claimed.groups {
+			if !slices.Contains(names, groupName) {
+				names = append(names, groupName)
+			}
+		}
+	}
+	return names
+}
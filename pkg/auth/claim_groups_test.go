@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestClaimGroupMembership(t *testing.T) {
+	base := &MockGroupMembershipProvider{Groups: map[string][]string{"ship-admins": {"bob"}}}
+	groups := NewClaimGroupMembership(base)
+	now := time.Now()
+	groups.now = func() time.Time { return now }
+
+	groups.Record("alice", []string{"ship-admins", "oncall"}, now.Add(time.Hour))
+	groups.Record("carol", []string{"oncall"}, now.Add(time.Minute))
+
+	if members := groups.GetGroupMembers("ship-admins"); !slices.Equal(members, []string{"bob", "alice"}) {
+		t.Errorf("ship-admins members = %v, want the group source's followed by the claimed", members)
+	}
+	if members := groups.GetGroupMembers("oncall"); !slices.Equal(members, []string{"alice", "carol"}) {
+		t.Errorf("oncall members = %v, want alice and carol", members)
+	}
+	if members := base.GetGroupMembers("ship-admins"); !slices.Equal(members, []string{"bob"}) {
+		t.Errorf("the group source's members were modified: %v", members)
+	}
+
+	statuses := groups.GroupStatuses()
+	if len(statuses) != 2 || statuses[0].Name != "oncall" || statuses[0].Source != "oidc" || statuses[1].Source != "mock" {
+		t.Fatalf("GroupStatuses() = %+v, want oncall from claims and ship-admins from the source", statuses)
+	}
+	if !slices.Equal(statuses[1].Members, []string{"bob", "alice"}) {
+		t.Errorf("ship-admins status members = %v", statuses[1].Members)
+	}
+
+	// A newer login replaces the claims, and expired sessions stop counting.
+	groups.Record("alice", []string{"oncall"}, now.Add(time.Hour))
+	now = now.Add(2 * time.Minute)
+	if members := groups.GetGroupMembers("ship-admins"); !slices.Equal(members, []string{"bob"}) {
+		t.Errorf("ship-admins members = %v, want alice's claim replaced", members)
+	}
+	if members := groups.GetGroupMembers("oncall"); !slices.Equal(members, []string{"alice"}) {
+		t.Errorf("oncall members = %v, want carol's expired claim dropped", members)
+	}
+}
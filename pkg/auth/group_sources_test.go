@@ -0,0 +1,330 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeGroupSource serves groups from a map and fails the groups listed in failing.
+type fakeGroupSource struct {
+	groups  map[string][]string
+	failing map[string]bool
+}
+
+func (s *fakeGroupSource) Name() string {
+	return "fake"
+}
+
+func (s *fakeGroupSource) FetchGroupMembers(ctx context.Context, groupName string) ([]string, error) {
+	if s.failing[groupName] {
+		return nil, errors.New("backend unavailable")
+	}
+	members, ok := s.groups[groupName]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return members, nil
+}
+
+func TestGroupMembershipCache_LoadGroupsDegradesGracefully(t *testing.T) {
+	source := &fakeGroupSource{
+		groups:  map[string][]string{"admins": {"alice"}, "oncall": {"bob"}},
+		failing: map[string]bool{"oncall": true},
+	}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	cache := NewGroupMembershipCache(source, logger)
+
+	err := cache.LoadGroups([]string{"oncall", "admins"})
+	if err == nil || !strings.Contains(err.Error(), "oncall") {
+		t.Fatalf("LoadGroups error = %v, want a failure for oncall", err)
+	}
+	if !cache.IsUserInGroup("alice", "admins") {
+		t.Error("admins should load even though oncall failed")
+	}
+	if cache.IsUserInGroup("bob", "oncall") {
+		t.Error("oncall never loaded, so bob should not be a member")
+	}
+
+	statuses := cache.GroupStatuses()
+	if len(statuses) != 2 || statuses[0].Name != "admins" || statuses[1].Name != "oncall" {
+		t.Fatalf("GroupStatuses() = %+v, want admins and oncall", statuses)
+	}
+	if statuses[0].Stale || statuses[0].LastRefreshed == nil || statuses[0].Source != "fake" {
+		t.Errorf("admins status = %+v, want fresh", statuses[0])
+	}
+	if !statuses[1].Stale || statuses[1].LastRefreshed != nil || statuses[1].LastError == "" {
+		t.Errorf("oncall status = %+v, want stale with an error", statuses[1])
+	}
+
+	// Once the backend recovers, the background refresh picks the group up.
+	source.failing = map[string]bool{"admins": true}
+	source.groups["admins"] = []string{"carol"}
+	if err := cache.Refresh(); err == nil {
+		t.Error("Refresh should report the admins failure")
+	}
+	if !cache.IsUserInGroup("bob", "oncall") {
+		t.Error("oncall should load on refresh")
+	}
+	if !cache.IsUserInGroup("alice", "admins") {
+		t.Error("admins should keep its previous members when its refresh fails")
+	}
+	if statuses := cache.GroupStatuses(); !statuses[0].Stale || statuses[0].LastRefreshed == nil {
+		t.Errorf("admins status = %+v, want stale with its last refresh time", statuses[0])
+	}
+
+	// Groups dropped from the config are forgotten.
+	if err := cache.LoadGroups([]string{"oncall"}); err != nil {
+		t.Fatalf("LoadGroups returned error: %v", err)
+	}
+	if cache.GetGroupMembers("admins") != nil {
+		t.Error("admins should be dropped")
+	}
+	if statuses := cache.GroupStatuses(); len(statuses) != 1 {
+		t.Errorf("GroupStatuses() = %+v, want only oncall", statuses)
+	}
+}
+
+func TestGroupMembershipCache_MaxStaleness(t *testing.T) {
+	source := &fakeGroupSource{groups: map[string][]string{"admins": {"alice"}}}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	cache := NewGroupMembershipCache(source, logger)
+	cache.SetMaxStaleness(time.Hour)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	if err := cache.LoadGroups([]string{"admins"}); err != nil {
+		t.Fatalf("LoadGroups returned error: %v", err)
+	}
+	source.failing = map[string]bool{"admins": true}
+
+	now = now.Add(30 * time.Minute)
+	if err := cache.Refresh(); err == nil {
+		t.Error("Refresh should report the admins failure")
+	}
+	if !cache.IsUserInGroup("alice", "admins") {
+		t.Error("admins should keep its members within the max staleness")
+	}
+
+	// Members are dropped once too old, even before the next refresh attempt.
+	now = now.Add(31 * time.Minute)
+	if cache.IsUserInGroup("alice", "admins") || cache.GetGroupMembers("admins") != nil {
+		t.Error("admins should lose its members past the max staleness")
+	}
+	if statuses := cache.GroupStatuses(); !statuses[0].Stale || len(statuses[0].Members) != 0 {
+		t.Errorf("admins status = %+v, want stale without members", statuses[0])
+	}
+	if err := cache.Refresh(); err == nil {
+		t.Error("Refresh should report the admins failure")
+	}
+	if _, cached := cache.groups["admins"]; cached {
+		t.Error("a failed refresh past the max staleness should drop the cached members")
+	}
+
+	source.failing = nil
+	if err := cache.Refresh(); err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+	if !cache.IsUserInGroup("alice", "admins") {
+		t.Error("admins should be back once it refreshes")
+	}
+}
+
+func TestStaticGroupSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "groups.yaml")
+	if err := os.WriteFile(path, []byte("groups:\n  admins: [alice, bob]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	source, err := NewStaticGroupSource(path)
+	if err != nil {
+		t.Fatalf("NewStaticGroupSource returned error: %v", err)
+	}
+
+	members, err := source.FetchGroupMembers(context.Background(), "admins")
+	if err != nil || !slices.Equal(members, []string{"alice", "bob"}) {
+		t.Errorf("FetchGroupMembers(admins) = %v, %v", members, err)
+	}
+	if _, err := source.FetchGroupMembers(context.Background(), "missing"); err == nil {
+		t.Error("FetchGroupMembers(missing) should fail")
+	}
+
+	// Edits are picked up without a restart.
+	if err := os.WriteFile(path, []byte("groups:\n  admins: [carol]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	members, err = source.FetchGroupMembers(context.Background(), "admins")
+	if err != nil || !slices.Equal(members, []string{"carol"}) {
+		t.Errorf("FetchGroupMembers(admins) after edit = %v, %v", members, err)
+	}
+
+	if err := os.WriteFile(path, []byte("groups: [\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewStaticGroupSource(path); err == nil {
+		t.Error("NewStaticGroupSource should reject an invalid file")
+	}
+}
+
+// fakeLDAPServer is a minimal LDAPv3 server answering simple binds and searches, standing in for a directory.
+// entries maps a search filter, as decompiled by go-ldap, to the member values of the matching group.
+type fakeLDAPServer struct {
+	listener net.Listener
+	bindDN   string
+	password string
+	entries  map[string][]string
+}
+
+func newFakeLDAPServer(t *testing.T, bindDN, password string, entries map[string][]string) *fakeLDAPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeLDAPServer{listener: listener, bindDN: bindDN, password: password, entries: entries}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeLDAPServer) url() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *fakeLDAPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		request := packet.Children[1]
+		switch request.Tag {
+		case ldap.ApplicationBindRequest:
+			code := ldap.LDAPResultSuccess
+			if request.Children[1].Value.(string) != s.bindDN || request.Children[2].Data.String() != s.password {
+				code = ldap.LDAPResultInvalidCredentials
+			}
+			_, _ = conn.Write(ldapResponse(messageID, ldap.ApplicationBindResponse, code).Bytes())
+		case ldap.ApplicationSearchRequest:
+			filter, err := ldap.DecompileFilter(request.Children[6])
+			if err != nil {
+				return
+			}
+			if members, ok := s.entries[filter]; ok {
+				_, _ = conn.Write(ldapSearchEntry(messageID, "cn=group,ou=groups,dc=example,dc=com", members).Bytes())
+			}
+			_, _ = conn.Write(ldapResponse(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess).Bytes())
+		default:
+			return
+		}
+	}
+}
+
+func ldapEnvelope(messageID int64, operation *ber.Packet) *ber.Packet {
+	envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	envelope.AppendChild(operation)
+	return envelope
+}
+
+func ldapResponse(messageID int64, tag ber.Tag, code int) *ber.Packet {
+	operation := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	operation.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	operation.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	operation.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return ldapEnvelope(messageID, operation)
+}
+
+func ldapSearchEntry(messageID int64, dn string, members []string) *ber.Packet {
+	values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+	for _, member := range members {
+		values.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, member, "Value"))
+	}
+	attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+	attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, DefaultLDAPMemberAttribute, "Type"))
+	attribute.AppendChild(values)
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	attributes.AppendChild(attribute)
+
+	operation := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	operation.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "Object Name"))
+	operation.AppendChild(attributes)
+	return ldapEnvelope(messageID, operation)
+}
+
+func TestLDAPGroupSource(t *testing.T) {
+	server := newFakeLDAPServer(t, "cn=reader,dc=example,dc=com", "secret", map[string][]string{
+		"(&(objectClass=groupOfUniqueNames)(cn=ship-admins))": {
+			"uid=alice,ou=users,dc=example,dc=com",
+			"UID=bob,ou=users,dc=example,dc=com",
+			"cn=nested-group,ou=groups,dc=example,dc=com",
+		},
+		`(&(objectClass=groupOfUniqueNames)(cn=a\2a))`: {"uid=carol,ou=users,dc=example,dc=com"},
+	})
+	config := LDAPConfig{
+		URL:          server.url(),
+		BindDN:       "cn=reader,dc=example,dc=com",
+		BindPassword: "secret",
+		BaseDN:       "ou=groups,dc=example,dc=com",
+	}
+	source, err := NewLDAPGroupSource(config)
+	if err != nil {
+		t.Fatalf("NewLDAPGroupSource returned error: %v", err)
+	}
+
+	members, err := source.FetchGroupMembers(context.Background(), "ship-admins")
+	if err != nil || !slices.Equal(members, []string{"alice", "bob"}) {
+		t.Errorf("FetchGroupMembers(ship-admins) = %v, %v; want alice and bob", members, err)
+	}
+
+	members, err = source.FetchGroupMembers(context.Background(), "a*")
+	if err != nil || !slices.Equal(members, []string{"carol"}) {
+		t.Errorf("FetchGroupMembers(a*) = %v, %v; the group name should be escaped in the filter", members, err)
+	}
+
+	if _, err := source.FetchGroupMembers(context.Background(), "missing"); err == nil {
+		t.Error("FetchGroupMembers(missing) should fail")
+	}
+
+	config.BindPassword = "wrong"
+	badSource, err := NewLDAPGroupSource(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := badSource.FetchGroupMembers(context.Background(), "ship-admins"); err == nil || !strings.Contains(err.Error(), "bind") {
+		t.Errorf("FetchGroupMembers with a wrong password = %v, want a bind error", err)
+	}
+}
+
+func TestNewLDAPGroupSource(t *testing.T) {
+	if _, err := NewLDAPGroupSource(LDAPConfig{BaseDN: "dc=example,dc=com"}); err == nil {
+		t.Error("a URL should be required")
+	}
+	if _, err := NewLDAPGroupSource(LDAPConfig{URL: "ldap://localhost"}); err == nil {
+		t.Error("a base DN should be required")
+	}
+	if _, err := NewLDAPGroupSource(LDAPConfig{URL: "ldap://localhost", BaseDN: "dc=example,dc=com", GroupFilter: "(cn=admins)"}); err == nil {
+		t.Error("a filter without a group name placeholder should be rejected")
+	}
+}
@@ -2,87 +2,225 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
+	"sort"
 	"sync"
+	"time"
 
-	userv1client "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
 	"github.com/sirupsen/logrus"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 // GroupMembershipProvider resolves group names to member lists.
 type GroupMembershipProvider interface {
 	GetGroupMembers(groupName string) []string
+	// GroupStatuses reports the state of every group the provider knows about, sorted by name.
+	GroupStatuses() []GroupStatus
 }
 
-// GroupMembershipCache stores the mapping of rover_group names to their member users.
+// GroupSource looks up the members of a group in a membership backend such as OpenShift, LDAP or a static file.
+type GroupSource interface {
+	// Name identifies the backend in logs and the admin view.
+	Name() string
+	FetchGroupMembers(ctx context.Context, groupName string) ([]string, error)
+}
+
+// GroupStatus describes a cached group for the admin view.
+type GroupStatus struct {
+	Name    string   `json:"name"`
+	Source  string   `json:"source"`
+	Members []string `json:"members"`
+	// LastRefreshed is when the members were last fetched successfully; nil if they never were.
+	LastRefreshed *time.Time `json:"last_refreshed,omitempty"`
+	LastAttempt   *time.Time `json:"last_attempt,omitempty"`
+	// LastError is set when the last fetch failed. The members from the last successful fetch are kept until
+	// they are older than the cache's max staleness.
+	LastError string `json:"last_error,omitempty"`
+	Stale     bool   `json:"stale"`
+}
+
+// groupState tracks when a group was fetched.
+type groupState struct {
+	lastRefreshed time.Time
+	lastAttempt   time.Time
+	lastError     error
+}
+
+// GroupMembershipCache stores the mapping of rover_group names to their member users, as fetched from a GroupSource.
+// A group that fails to refresh keeps its previous members and is reported as stale, so one unreachable group
+// neither blocks startup nor revokes access that was already granted. Once the members are older than the max
+// staleness they are dropped, so that people removed from the group while it was unreachable lose access.
 type GroupMembershipCache struct {
 	mu     sync.RWMutex
 	groups map[string][]string
-	logger *logrus.Logger
+	states map[string]*groupState
+	// names are the groups requested by the last LoadGroups call, refreshed in the background.
+	names        []string
+	source       GroupSource
+	maxStaleness time.Duration
+	logger       *logrus.Logger
+	now          func() time.Time
 }
 
-// NewGroupMembershipCache creates a new empty group membership cache.
-func NewGroupMembershipCache(logger *logrus.Logger) *GroupMembershipCache {
+// NewGroupMembershipCache creates a new empty group membership cache that loads groups from source.
+func NewGroupMembershipCache(source GroupSource, logger *logrus.Logger) *GroupMembershipCache {
 	return &GroupMembershipCache{
 		groups: make(map[string][]string),
+		states: make(map[string]*groupState),
+		source: source,
 		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetMaxStaleness sets how long the members of a group are kept after it was last fetched successfully. 0, the
+// default, keeps them until the next successful fetch. It must be longer than the refresh interval.
+func (c *GroupMembershipCache) SetMaxStaleness(maxStaleness time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxStaleness = maxStaleness
+}
+
+// expired reports whether the members of groupName were fetched more than the max staleness before now. The
+// caller holds c.mu.
+func (c *GroupMembershipCache) expired(groupName string, now time.Time) bool {
+	state, ok := c.states[groupName]
+	return ok && c.maxStaleness > 0 && !state.lastRefreshed.IsZero() && now.Sub(state.lastRefreshed) > c.maxStaleness
+}
+
+// members returns the cached members of groupName, leaving out those that are too stale.
+func (c *GroupMembershipCache) members(groupName string) ([]string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.expired(groupName, c.now()) {
+		return nil, false
 	}
+	users, exists := c.groups[groupName]
+	return users, exists
 }
 
-// LoadGroups queries OpenShift Groups API for the specified group names and populates the cache.
-func (c *GroupMembershipCache) LoadGroups(groupNames []string, kubeconfigPath string) error {
-	var config *rest.Config
-	var err error
+// LoadGroups fetches the specified groups from the source and makes them the set of cached groups. Groups that
+// are no longer requested are dropped. Every group is attempted; the returned error joins the failures.
+func (c *GroupMembershipCache) LoadGroups(groupNames []string) error {
+	names := slices.Clone(groupNames)
+	sort.Strings(names)
 
-	if kubeconfigPath == "" {
-		// In-cluster config uses the service account token automatically
-		config, err = rest.InClusterConfig()
-		if err != nil {
-			return fmt.Errorf("failed to build in-cluster config: %w", err)
+	c.mu.Lock()
+	c.names = names
+	for groupName := range c.groups {
+		if !slices.Contains(names, groupName) {
+			delete(c.groups, groupName)
 		}
-	} else {
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to build kubeconfig: %w", err)
+	}
+	for groupName := range c.states {
+		if !slices.Contains(names, groupName) {
+			delete(c.states, groupName)
+			forgetGroupMetrics(groupName)
 		}
 	}
+	c.mu.Unlock()
 
-	userClient, err := userv1client.NewForConfig(config)
-	if err != nil {
-		return fmt.Errorf("failed to create user client: %w", err)
+	return c.fetchGroups(names)
+}
+
+// Refresh fetches the groups requested by the last LoadGroups call again.
+func (c *GroupMembershipCache) Refresh() error {
+	c.mu.RLock()
+	names := c.names
+	c.mu.RUnlock()
+	return c.fetchGroups(names)
+}
+
+// Start refreshes the cached groups every interval until ctx is cancelled.
+func (c *GroupMembershipCache) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Refresh(); err != nil {
+				c.logger.WithField("error", err).Warn("Some groups failed to refresh, keeping their previous members")
+			}
+		}
 	}
+}
 
-	ctx := context.Background()
+func (c *GroupMembershipCache) fetchGroups(groupNames []string) error {
+	if c.source == nil {
+		if len(groupNames) == 0 {
+			return nil
+		}
+		return errors.New("no group source configured")
+	}
+
+	var errs []error
 	for _, groupName := range groupNames {
-		group, err := userClient.Groups().Get(ctx, groupName, metav1.GetOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to get group %s: %w", groupName, err)
+		if err := c.fetchGroup(groupName); err != nil {
+			errs = append(errs, err)
 		}
+	}
+	return errors.Join(errs...)
+}
 
-		users := make([]string, len(group.Users))
-		copy(users, group.Users)
-		c.mu.Lock()
-		c.groups[groupName] = users
-		c.mu.Unlock()
+func (c *GroupMembershipCache) fetchGroup(groupName string) error {
+	members, err := c.source.FetchGroupMembers(context.Background(), groupName)
+	now := c.now()
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !slices.Contains(c.names, groupName) {
+		// The group was dropped by a config reload while it was being fetched.
+		return nil
+	}
+	state, ok := c.states[groupName]
+	if !ok {
+		state = &groupState{}
+		c.states[groupName] = state
+	}
+	state.lastAttempt = now
+	if err != nil {
+		state.lastError = err
+		groupRefreshFailures.WithLabelValues(groupName).Inc()
+		groupStale.WithLabelValues(groupName).Set(1)
 		c.logger.WithFields(logrus.Fields{
-			"group":      groupName,
-			"user_count": len(users),
-		}).Info("Loaded group membership")
+			"group":  groupName,
+			"source": c.source.Name(),
+			"error":  err,
+		}).Error("Failed to load group membership")
+		if _, cached := c.groups[groupName]; cached && c.expired(groupName, now) {
+			delete(c.groups, groupName)
+			groupMembers.WithLabelValues(groupName).Set(0)
+			c.logger.WithFields(logrus.Fields{
+				"group":          groupName,
+				"last_refreshed": state.lastRefreshed,
+				"max_staleness":  c.maxStaleness,
+			}).Error("Dropped group members that could not be refreshed within the max staleness")
+		}
+		return fmt.Errorf("failed to get group %s: %w", groupName, err)
 	}
 
+	users := make([]string, len(members))
+	copy(users, members)
+	c.groups[groupName] = users
+	state.lastRefreshed = now
+	state.lastError = nil
+	groupMembers.WithLabelValues(groupName).Set(float64(len(users)))
+	groupLastRefreshed.WithLabelValues(groupName).Set(float64(now.Unix()))
+	groupStale.WithLabelValues(groupName).Set(0)
+	c.logger.WithFields(logrus.Fields{
+		"group":      groupName,
+		"source":     c.source.Name(),
+		"user_count": len(users),
+	}).Debug("Loaded group membership")
 	return nil
 }
 
 // IsUserInGroup checks if a user is a member of the specified group.
 func (c *GroupMembershipCache) IsUserInGroup(user, groupName string) bool {
-	c.mu.RLock()
-	users, exists := c.groups[groupName]
-	c.mu.RUnlock()
+	users, exists := c.members(groupName)
 	if !exists {
 		return false
 	}
@@ -92,11 +230,49 @@ func (c *GroupMembershipCache) IsUserInGroup(user, groupName string) bool {
 
 // GetGroupMembers returns the list of users in the specified group.
 func (c *GroupMembershipCache) GetGroupMembers(groupName string) []string {
-	c.mu.RLock()
-	users, exists := c.groups[groupName]
-	c.mu.RUnlock()
+	users, exists := c.members(groupName)
 	if !exists {
 		return nil
 	}
 	return users
 }
+
+// GroupStatuses reports every requested group with its members and refresh state, sorted by name.
+func (c *GroupMembershipCache) GroupStatuses() []GroupStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	sourceName := ""
+	if c.source != nil {
+		sourceName = c.source.Name()
+	}
+	now := c.now()
+	statuses := make([]GroupStatus, 0, len(c.names))
+	for _, groupName := range c.names {
+		status := GroupStatus{
+			Name:   groupName,
+			Source: sourceName,
+			Stale:  true,
+		}
+		if !c.expired(groupName, now) {
+			status.Members = slices.Clone(c.groups[groupName])
+		}
+		if status.Members == nil {
+			status.Members = []string{}
+		}
+		if state, ok := c.states[groupName]; ok {
+			if !state.lastRefreshed.IsZero() {
+				lastRefreshed := state.lastRefreshed
+				status.LastRefreshed = &lastRefreshed
+			}
+			lastAttempt := state.lastAttempt
+			status.LastAttempt = &lastAttempt
+			if state.lastError != nil {
+				status.LastError = state.lastError.Error()
+			}
+			status.Stale = state.lastError != nil || c.expired(groupName, now)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -8,7 +8,7 @@ import (
 
 func TestNewGroupMembershipCache(t *testing.T) {
 	logger := logrus.New()
-	cache := NewGroupMembershipCache(logger)
+	cache := NewGroupMembershipCache(nil, logger)
 
 	if cache == nil {
 		t.Fatal("NewGroupMembershipCache returned nil")
@@ -23,7 +23,7 @@ func TestNewGroupMembershipCache(t *testing.T) {
 
 func TestIsUserInGroup(t *testing.T) {
 	logger := logrus.New()
-	cache := NewGroupMembershipCache(logger)
+	cache := NewGroupMembershipCache(nil, logger)
 
 	cache.groups["group1"] = []string{"user1", "user2", "user3"}
 	cache.groups["group2"] = []string{"user2", "user4"}
@@ -91,7 +91,7 @@ func TestIsUserInGroup(t *testing.T) {
 
 func TestGetGroupMembers(t *testing.T) {
 	logger := logrus.New()
-	cache := NewGroupMembershipCache(logger)
+	cache := NewGroupMembershipCache(nil, logger)
 
 	cache.groups["group1"] = []string{"user1", "user2", "user3"}
 	cache.groups["empty-group"] = []string{}
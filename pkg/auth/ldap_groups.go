@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	// DefaultLDAPGroupFilter finds a group by its common name; %s is replaced by the escaped group name.
+	DefaultLDAPGroupFilter = "(&(objectClass=groupOfUniqueNames)(cn=%s))"
+	// DefaultLDAPMemberAttribute is the group attribute listing its members.
+	DefaultLDAPMemberAttribute = "uniqueMember"
+	// DefaultLDAPUserAttribute is the attribute of a member DN holding the username.
+	DefaultLDAPUserAttribute = "uid"
+
+	ldapTimeout = 30 * time.Second
+)
+
+// LDAPConfig configures the LDAP group source.
+type LDAPConfig struct {
+	// URL of the server, e.g. ldaps://ldap.example.com.
+	URL string
+	// BindDN and BindPassword authenticate the lookups. Lookups are anonymous when BindDN is empty.
+	BindDN       string
+	BindPassword string
+	// BaseDN is where groups are searched for.
+	BaseDN string
+	// GroupFilter is the search filter for a group, with %s standing for the group name.
+	GroupFilter string
+	// MemberAttribute lists the members of a group, either as DNs (member, uniqueMember) or as usernames (memberUid).
+	MemberAttribute string
+	// UserAttribute is the RDN attribute of member DNs that holds the username.
+	UserAttribute string
+}
+
+// LDAPGroupSource looks up group members in an LDAP directory, such as the one backing Rover groups.
+// It opens a connection per lookup, since refreshes are minutes apart.
+type LDAPGroupSource struct {
+	config LDAPConfig
+}
+
+// NewLDAPGroupSource creates a source for config, filling in the defaults for unset search settings.
+func NewLDAPGroupSource(config LDAPConfig) (*LDAPGroupSource, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("LDAP URL is required")
+	}
+	if config.BaseDN == "" {
+		return nil, fmt.Errorf("LDAP base DN is required")
+	}
+	if config.GroupFilter == "" {
+		config.GroupFilter = DefaultLDAPGroupFilter
+	}
+	if !strings.Contains(config.GroupFilter, "%s") {
+		return nil, fmt.Errorf("LDAP group filter %q must contain %%s for the group name", config.GroupFilter)
+	}
+	if config.MemberAttribute == "" {
+		config.MemberAttribute = DefaultLDAPMemberAttribute
+	}
+	if config.UserAttribute == "" {
+		config.UserAttribute = DefaultLDAPUserAttribute
+	}
+	return &LDAPGroupSource{config: config}, nil
+}
+
+func (s *LDAPGroupSource) Name() string {
+	return "ldap"
+}
+
+func (s *LDAPGroupSource) FetchGroupMembers(ctx context.Context, groupName string) ([]string, error) {
+	conn, err := ldap.DialURL(s.config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP: %w", err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(ldapTimeout)
+
+	if s.config.BindDN != "" {
+		if err := conn.Bind(s.config.BindDN, s.config.BindPassword); err != nil {
+			return nil, fmt.Errorf("failed to bind to LDAP: %w", err)
+		}
+	}
+
+	request := ldap.NewSearchRequest(
+		s.config.BaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(ldapTimeout.Seconds()), false,
+		fmt.Sprintf(s.config.GroupFilter, ldap.EscapeFilter(groupName)),
+		[]string{s.config.MemberAttribute},
+		nil,
+	)
+	result, err := conn.Search(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search LDAP: %w", err)
+	}
+	switch len(result.Entries) {
+	case 0:
+		return nil, fmt.Errorf("group %s not found in LDAP", groupName)
+	case 1:
+	default:
+		return nil, fmt.Errorf("group %s matches %d LDAP entries", groupName, len(result.Entries))
+	}
+
+	values := result.Entries[0].GetAttributeValues(s.config.MemberAttribute)
+	members := make([]string, 0, len(values))
+	for _, value := range values {
+		if member := s.memberName(value); member != "" {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+// memberName returns the username in a member value. DNs are reduced to the value of their first RDN when it is
+// the user attribute, and skipped otherwise (e.g. nested groups); plain values are usernames already.
+func (s *LDAPGroupSource) memberName(value string) string {
+	if !strings.Contains(value, "=") {
+		return value
+	}
+	dn, err := ldap.ParseDN(value)
+	if err != nil || len(dn.RDNs) == 0 {
+		return ""
+	}
+	for _, attribute := range dn.RDNs[0].Attributes {
+		if strings.EqualFold(attribute.Type, s.config.UserAttribute) {
+			return attribute.Value
+		}
+	}
+	return ""
+}
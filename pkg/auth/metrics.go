@@ -0,0 +1,34 @@
+package auth
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	groupMembers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ship_status_group_members",
+		Help: "Number of members of a group as of its last successful refresh.",
+	}, []string{"group"})
+	groupLastRefreshed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ship_status_group_last_refresh_timestamp_seconds",
+		Help: "Unix time of the last successful refresh of a group's members.",
+	}, []string{"group"})
+	groupStale = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ship_status_group_stale",
+		Help: "1 when the last refresh of a group failed and its previous members are still in use.",
+	}, []string{"group"})
+	groupRefreshFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ship_status_group_refresh_failures_total",
+		Help: "Number of failed refreshes of a group's members.",
+	}, []string{"group"})
+)
+
+func init() {
+	prometheus.MustRegister(groupMembers, groupLastRefreshed, groupStale, groupRefreshFailures)
+}
+
+// forgetGroupMetrics removes the series of a group that is no longer configured.
+func forgetGroupMetrics(groupName string) {
+	groupMembers.DeleteLabelValues(groupName)
+	groupLastRefreshed.DeleteLabelValues(groupName)
+	groupStale.DeleteLabelValues(groupName)
+	groupRefreshFailures.DeleteLabelValues(groupName)
+}
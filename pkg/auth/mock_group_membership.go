@@ -1,5 +1,7 @@
 package auth
 
+import "sort"
+
 // MockGroupMembershipProvider is a mock implementation of GroupMembershipProvider for testing.
 type MockGroupMembershipProvider struct {
 	GetGroupMembersFn func(groupName string) []string
@@ -12,3 +14,14 @@ func (m *MockGroupMembershipProvider) GetGroupMembers(groupName string) []string
 	}
 	return m.Groups[groupName]
 }
+
+func (m *MockGroupMembershipProvider) GroupStatuses() []GroupStatus {
+	statuses := make([]GroupStatus, 0, len(m.Groups))
+	for name, members := range m.Groups {
+		statuses = append(statuses, GroupStatus{Name: name, Source: "mock", Members: members})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// The login endpoints use the oauth-proxy paths, so the frontend's login button works in both modes.
+	OIDCStartPath    = "/oauth/start"
+	OIDCCallbackPath = "/oauth/callback"
+	OIDCSignOutPath  = "/oauth/sign_out"
+
+	// DefaultOIDCUsernameClaim is the ID token claim used as the dashboard username.
+	DefaultOIDCUsernameClaim = "preferred_username"
+	// DefaultOIDCGroupsClaim is the ID token claim listing the user's groups.
+	DefaultOIDCGroupsClaim = "groups"
+
+	loginCookieName = "ship_status_oidc_login"
+	loginTimeout    = 10 * time.Minute
+)
+
+// OIDCConfig configures the dashboard's built-in OpenID Connect login.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the dashboard's OIDCCallbackPath as registered with the provider.
+	RedirectURL string
+	// Scopes are requested besides openid.
+	Scopes        []string
+	UsernameClaim string
+	GroupsClaim   string
+	// RelevantGroups, when set, returns the groups worth keeping from the groups claim. Users can belong to far
+	// more groups than fit in a session cookie, and only the configured rover_groups matter.
+	RelevantGroups func() []string
+}
+
+// OIDCAuthenticator logs users in with the authorization code flow (with PKCE) and keeps them logged in with a
+// session cookie.
+type OIDCAuthenticator struct {
+	config   OIDCConfig
+	oauth2   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	sessions *SessionManager
+	logger   *logrus.Logger
+	now      func() time.Time
+}
+
+// oidcLogin is kept in a short-lived cookie between the redirect to the provider and the callback.
+type oidcLogin struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Redirect string `json:"redirect"`
+	Expires  int64  `json:"expires"`
+}
+
+// NewOIDCAuthenticator discovers the provider at config.IssuerURL and creates an authenticator issuing sessions
+// through sessions.
+func NewOIDCAuthenticator(ctx context.Context, config OIDCConfig, sessions *SessionManager, logger *logrus.Logger) (*OIDCAuthenticator, error) {
+	if config.IssuerURL == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, fmt.Errorf("OIDC issuer URL, client ID and redirect URL are required")
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = DefaultOIDCUsernameClaim
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = DefaultOIDCGroupsClaim
+	}
+
+	provider, err := oidc.NewProvider(ctx, config.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+	return &OIDCAuthenticator{
+		config: config,
+		oauth2: oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  config.RedirectURL,
+			Scopes:       append([]string{oidc.ScopeOpenID}, config.Scopes...),
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: config.ClientID}),
+		sessions: sessions,
+		logger:   logger,
+		now:      time.Now,
+	}, nil
+}
+
+// Sessions returns the session manager logins are issued through.
+func (a *OIDCAuthenticator) Sessions() *SessionManager {
+	return a.sessions
+}
+
+// StartLogin redirects to the provider. The rd query parameter names the dashboard path to return to.
+func (a *OIDCAuthenticator) StartLogin(w http.ResponseWriter, r *http.Request) {
+	login := oidcLogin{
+		State:    randomToken(),
+		Nonce:    randomToken(),
+		Verifier: oauth2.GenerateVerifier(),
+		Redirect: localRedirect(r.URL.Query().Get("rd")),
+		Expires:  a.now().Add(loginTimeout).Unix(),
+	}
+	value, err := a.sessions.sign(loginCookieName, login)
+	if err != nil {
+		a.logger.WithField("error", err).Error("Failed to start OIDC login")
+		http.Error(w, "Failed to start login", http.StatusInternalServerError)
+		return
+	}
+	a.sessions.setCookie(w, loginCookieName, value, time.Unix(login.Expires, 0))
+	http.Redirect(w, r, a.oauth2.AuthCodeURL(login.State, oidc.Nonce(login.Nonce), oauth2.S256ChallengeOption(login.Verifier)), http.StatusFound)
+}
+
+// Callback completes the login started by StartLogin and starts the user's session.
+func (a *OIDCAuthenticator) Callback(w http.ResponseWriter, r *http.Request) {
+	callbackLogger := a.logger.WithField("path", r.URL.Path)
+
+	var login oidcLogin
+	cookie, err := r.Cookie(loginCookieName)
+	if err == nil {
+		err = a.sessions.verify(loginCookieName, cookie.Value, &login)
+	}
+	if err != nil || !a.now().Before(time.Unix(login.Expires, 0)) {
+		callbackLogger.Warn("OIDC callback without a pending login")
+		http.Error(w, "Login expired, please try again", http.StatusBadRequest)
+		return
+	}
+	a.sessions.setCookie(w, loginCookieName, "", time.Unix(0, 0))
+
+	query := r.URL.Query()
+	if providerError := query.Get("error"); providerError != "" {
+		callbackLogger.WithFields(logrus.Fields{
+			"error":       providerError,
+			"description": query.Get("error_description"),
+		}).Warn("OIDC provider rejected login")
+		http.Error(w, "Login failed: "+providerError, http.StatusUnauthorized)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(login.State)) != 1 {
+		callbackLogger.Warn("OIDC callback state mismatch")
+		http.Error(w, "Invalid login state", http.StatusBadRequest)
+		return
+	}
+
+	user, groups, err := a.exchange(r.Context(), query.Get("code"), login)
+	if err != nil {
+		callbackLogger.WithField("error", err).Warn("OIDC login failed")
+		http.Error(w, "Login failed", http.StatusUnauthorized)
+		return
+	}
+	if err := a.sessions.Issue(w, user, groups); err != nil {
+		callbackLogger.WithField("error", err).Error("Failed to issue session")
+		http.Error(w, "Failed to start session", http.StatusInternalServerError)
+		return
+	}
+	callbackLogger.WithFields(logrus.Fields{
+		"user":   user,
+		"groups": groups,
+	}).Info("User logged in through OIDC")
+	http.Redirect(w, r, login.Redirect, http.StatusFound)
+}
+
+// SignOut ends the session and redirects to the rd query parameter, or the root.
+func (a *OIDCAuthenticator) SignOut(w http.ResponseWriter, r *http.Request) {
+	a.sessions.Clear(w)
+	http.Redirect(w, r, localRedirect(r.URL.Query().Get("rd")), http.StatusFound)
+}
+
+// exchange redeems code and returns the username and relevant groups from the verified ID token.
+func (a *OIDCAuthenticator) exchange(ctx context.Context, code string, login oidcLogin) (string, []string, error) {
+	token, err := a.oauth2.Exchange(ctx, code, oauth2.VerifierOption(login.Verifier))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return "", nil, fmt.Errorf("token response has no id_token")
+	}
+	idToken, err := a.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to verify ID token: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(login.Nonce)) != 1 {
+		return "", nil, fmt.Errorf("ID token nonce mismatch")
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return "", nil, fmt.Errorf("failed to decode ID token claims: %w", err)
+	}
+	user, _ := claims[a.config.UsernameClaim].(string)
+	if user == "" {
+		return "", nil, fmt.Errorf("ID token has no %s claim", a.config.UsernameClaim)
+	}
+
+	var relevant []string
+	if a.config.RelevantGroups != nil {
+		relevant = a.config.RelevantGroups()
+	}
+	var groups []string
+	claimed, _ := claims[a.config.GroupsClaim].([]any)
+	for _, group := range claimed {
+		name, ok := group.(string)
+		if !ok || name == "" {
+			continue
+		}
+		if a.config.RelevantGroups == nil || slices.Contains(relevant, name) {
+			groups = append(groups, name)
+		}
+	}
+	return user, groups, nil
+}
+
+// localRedirect returns target if it is a path on the dashboard, and the root otherwise, so the login endpoints
+// cannot be used to redirect to other sites. Browsers drop tabs and newlines from URLs and treat backslashes as
+// slashes, so targets containing them are refused as well.
+func localRedirect(target string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.Contains(target, `\`) ||
+		strings.ContainsFunc(target, unicode.IsControl) {
+		return "/"
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.User != nil {
+		return "/"
+	}
+	return target
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
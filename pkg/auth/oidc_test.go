@@ -0,0 +1,336 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeOIDCProvider is an OpenID provider issuing ID tokens with fixed claims for any authorization code whose
+// PKCE verifier matches the challenge it was issued for.
+type fakeOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	claims map[string]any
+	// codes maps issued authorization codes to their nonce and PKCE challenge.
+	codes map[string][2]string
+}
+
+func newFakeOIDCProvider(t *testing.T, claims map[string]any) *fakeOIDCProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &fakeOIDCProvider{key: key, claims: claims, codes: map[string][2]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                p.server.URL,
+			"authorization_endpoint":                p.server.URL + "/authorize",
+			"token_endpoint":                        p.server.URL + "/token",
+			"jwks_uri":                              p.server.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "test", Algorithm: "RS256", Use: "sig"}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		issued, ok := p.codes[r.FormValue("code")]
+		verifier := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != issued[1] {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     p.idToken(t, issued[0]),
+		})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *fakeOIDCProvider) idToken(t *testing.T, nonce string) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: p.key}, (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := map[string]any{
+		"iss":   p.server.URL,
+		"aud":   "dashboard",
+		"sub":   "1234",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": nonce,
+	}
+	for name, value := range p.claims {
+		claims[name] = value
+	}
+	payload, _ := json.Marshal(claims)
+	signed, err := signer.Sign(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := signed.CompactSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// authorize stands in for the user approving the login at the provider, returning the callback URL.
+func (p *fakeOIDCProvider) authorize(t *testing.T, authURL string) *url.URL {
+	t.Helper()
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("login does not use PKCE: %s", authURL)
+	}
+	code := "code-" + query.Get("state")
+	p.codes[code] = [2]string{query.Get("nonce"), query.Get("code_challenge")}
+	callback, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback.RawQuery = url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+	return callback
+}
+
+func newTestOIDCAuthenticator(t *testing.T, provider *fakeOIDCProvider, groups *ClaimGroupMembership) *OIDCAuthenticator {
+	t.Helper()
+	sessions, err := NewSessionManager([]byte(strings.Repeat("s", 32)), time.Hour, false, groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	authenticator, err := NewOIDCAuthenticator(context.Background(), OIDCConfig{
+		IssuerURL:      provider.server.URL,
+		ClientID:       "dashboard",
+		ClientSecret:   "secret",
+		RedirectURL:    "http://dashboard.example.com" + OIDCCallbackPath,
+		RelevantGroups: func() []string { return []string{"ship-admins"} },
+	}, sessions, logger)
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator returned error: %v", err)
+	}
+	return authenticator
+}
+
+// addCookies copies the cookies set on rec to req.
+func addCookies(req *http.Request, rec *httptest.ResponseRecorder) {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.MaxAge >= 0 {
+			req.AddCookie(cookie)
+		}
+	}
+}
+
+func TestOIDCAuthenticator_Login(t *testing.T) {
+	provider := newFakeOIDCProvider(t, map[string]any{
+		"preferred_username": "alice",
+		"groups":             []string{"ship-admins", "unrelated"},
+	})
+	groups := NewClaimGroupMembership(&MockGroupMembershipProvider{})
+	authenticator := newTestOIDCAuthenticator(t, provider, groups)
+
+	start := httptest.NewRecorder()
+	authenticator.StartLogin(start, httptest.NewRequest(http.MethodGet, OIDCStartPath+"?rd=/components/alpha", nil))
+	if start.Code != http.StatusFound {
+		t.Fatalf("StartLogin status = %d, want a redirect", start.Code)
+	}
+
+	callbackURL := provider.authorize(t, start.Header().Get("Location"))
+	callback := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, callbackURL.String(), nil)
+	addCookies(req, start)
+	authenticator.Callback(callback, req)
+	if callback.Code != http.StatusFound || callback.Header().Get("Location") != "/components/alpha" {
+		t.Fatalf("Callback = %d to %q: %s", callback.Code, callback.Header().Get("Location"), callback.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/user", nil)
+	addCookies(req, callback)
+	user, err := authenticator.Sessions().Authenticate(req)
+	if err != nil || user != "alice" {
+		t.Errorf("Authenticate() = %q, %v; want alice", user, err)
+	}
+	if members := groups.GetGroupMembers("ship-admins"); !slices.Equal(members, []string{"alice"}) {
+		t.Errorf("ship-admins members = %v, want the claimed alice", members)
+	}
+	if members := groups.GetGroupMembers("unrelated"); len(members) != 0 {
+		t.Errorf("unrelated members = %v, groups outside the config should not be kept", members)
+	}
+
+	// The callback clears the login cookie, so it cannot complete a second login.
+	replay := httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, callbackURL.String(), nil)
+	addCookies(req, callback)
+	authenticator.Callback(replay, req)
+	if replay.Code != http.StatusBadRequest {
+		t.Errorf("replayed callback status = %d, want %d", replay.Code, http.StatusBadRequest)
+	}
+}
+
+func TestOIDCAuthenticator_CallbackRejects(t *testing.T) {
+	provider := newFakeOIDCProvider(t, map[string]any{"preferred_username": "alice"})
+	authenticator := newTestOIDCAuthenticator(t, provider, nil)
+
+	tests := []struct {
+		name       string
+		tamper     func(callback *url.URL)
+		wantStatus int
+	}{
+		{
+			name: "state mismatch",
+			tamper: func(callback *url.URL) {
+				query := callback.Query()
+				query.Set("state", "forged")
+				callback.RawQuery = query.Encode()
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "unknown code",
+			tamper: func(callback *url.URL) {
+				query := callback.Query()
+				query.Set("code", "forged")
+				callback.RawQuery = query.Encode()
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "provider error",
+			tamper: func(callback *url.URL) {
+				callback.RawQuery = url.Values{"error": {"access_denied"}, "state": {callback.Query().Get("state")}}.Encode()
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := httptest.NewRecorder()
+			authenticator.StartLogin(start, httptest.NewRequest(http.MethodGet, OIDCStartPath, nil))
+			callbackURL := provider.authorize(t, start.Header().Get("Location"))
+			tt.tamper(callbackURL)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, callbackURL.String(), nil)
+			addCookies(req, start)
+			authenticator.Callback(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Callback status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			for _, cookie := range rec.Result().Cookies() {
+				if cookie.Name == SessionCookieName && cookie.MaxAge >= 0 {
+					t.Error("a failed login should not start a session")
+				}
+			}
+		})
+	}
+}
+
+func TestSessionManager(t *testing.T) {
+	if _, err := NewSessionManager([]byte("short"), time.Hour, true, nil); err == nil {
+		t.Error("a short secret should be rejected")
+	}
+
+	sessions, err := NewSessionManager([]byte(strings.Repeat("s", 32)), time.Hour, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	sessions.now = func() time.Time { return now }
+
+	rec := httptest.NewRecorder()
+	if err := sessions.Issue(rec, "alice", nil); err != nil {
+		t.Fatal(err)
+	}
+	cookie := rec.Result().Cookies()[0]
+	if !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("session cookie = %+v, want HttpOnly, Secure and SameSite=Lax", cookie)
+	}
+
+	authenticate := func(value string) (string, error) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: value})
+		return sessions.Authenticate(req)
+	}
+	if user, err := authenticate(cookie.Value); err != nil || user != "alice" {
+		t.Errorf("Authenticate() = %q, %v; want alice", user, err)
+	}
+
+	payload, signature, _ := strings.Cut(cookie.Value, ".")
+	forged, _ := json.Marshal(session{User: "mallory", Expires: now.Add(time.Hour).Unix()})
+	if _, err := authenticate(base64.RawURLEncoding.EncodeToString(forged) + "." + signature); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("a session with a forged payload: got %v, want ErrInvalidSession", err)
+	}
+	login, _ := sessions.sign(loginCookieName, session{User: "mallory", Expires: now.Add(time.Hour).Unix()})
+	if _, err := authenticate(login); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("a value signed for another cookie: got %v, want ErrInvalidSession", err)
+	}
+	if _, err := authenticate(payload); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("an unsigned session: got %v, want ErrInvalidSession", err)
+	}
+	if _, err := authenticate(payload + ".%%%"); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("a session with an undecodable signature: got %v, want ErrInvalidSession", err)
+	}
+	if _, err := sessions.Authenticate(httptest.NewRequest(http.MethodGet, "/", nil)); !errors.Is(err, ErrNoSession) {
+		t.Errorf("a request without a session: got %v, want ErrNoSession", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := authenticate(cookie.Value); !errors.Is(err, ErrNoSession) {
+		t.Errorf("an expired session: got %v, want ErrNoSession", err)
+	}
+}
+
+func TestLocalRedirect(t *testing.T) {
+	tests := map[string]string{
+		"":                         "/",
+		"/components/alpha?tab=1":  "/components/alpha?tab=1",
+		"https://evil.example.com": "/",
+		"//evil.example.com":       "/",
+		`/\evil.example.com`:       "/",
+		"/\t/evil.example.com":     "/",
+		"/\n/evil.example.com":     "/",
+		"/\r\n/evil.example.com":   "/",
+		"/%09/evil.example.com":    "/%09/evil.example.com",
+		"/outages/1#notes":         "/outages/1#notes",
+	}
+	for target, want := range tests {
+		if got := localRedirect(target); got != want {
+			t.Errorf("localRedirect(%q) = %q, want %q", target, got, want)
+		}
+	}
+
+	// The rd parameter arrives percent-encoded, so an encoded tab reaches localRedirect as a tab.
+	r := httptest.NewRequest(http.MethodGet, OIDCStartPath+"?rd=/%09/evil.example.com", nil)
+	if got := localRedirect(r.URL.Query().Get("rd")); got != "/" {
+		t.Errorf("localRedirect of an encoded tab = %q, want /", got)
+	}
+}
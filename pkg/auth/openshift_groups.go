@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	userv1client "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// OpenShiftGroupSource reads group members from the OpenShift Groups API, where Rover groups are synced.
+// The client is created on the first lookup, so a dashboard without rover_groups does not need cluster access.
+type OpenShiftGroupSource struct {
+	kubeconfigPath string
+
+	mu         sync.Mutex
+	userClient userv1client.UserV1Interface
+}
+
+// NewOpenShiftGroupSource creates a source using kubeconfigPath, or the in-cluster config when it is empty.
+func NewOpenShiftGroupSource(kubeconfigPath string) *OpenShiftGroupSource {
+	return &OpenShiftGroupSource{kubeconfigPath: kubeconfigPath}
+}
+
+func (s *OpenShiftGroupSource) Name() string {
+	return "openshift"
+}
+
+func (s *OpenShiftGroupSource) FetchGroupMembers(ctx context.Context, groupName string) ([]string, error) {
+	userClient, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	group, err := userClient.Groups().Get(ctx, groupName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return group.Users, nil
+}
+
+// client returns the user client, creating it if an earlier attempt has not succeeded yet.
+func (s *OpenShiftGroupSource) client() (userv1client.UserV1Interface, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.userClient != nil {
+		return s.userClient, nil
+	}
+
+	config, err := restConfig(s.kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	userClient, err := userv1client.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user client: %w", err)
+	}
+	s.userClient = userClient
+	return userClient, nil
+}
+
+// restConfig loads kubeconfigPath, or the in-cluster config when it is empty.
+func restConfig(kubeconfigPath string) (*rest.Config, error) {
+	if kubeconfigPath == "" {
+		// In-cluster config uses the service account token automatically
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build in-cluster config: %w", err)
+		}
+		return config, nil
+	}
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubeconfig: %w", err)
+	}
+	return config, nil
+}
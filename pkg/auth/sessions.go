@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SessionCookieName is the cookie holding the signed session of a user logged in through OIDC.
+const SessionCookieName = "ship_status_session"
+
+// ErrNoSession is returned for requests without a session cookie, or whose session has expired.
+var ErrNoSession = errors.New("no valid session")
+
+// ErrInvalidSession is returned for session cookies that are malformed or not signed by the dashboard.
+var ErrInvalidSession = errors.New("invalid session cookie")
+
+// SessionManager issues and checks the session cookies of users logged in through the dashboard's own OIDC flow.
+// Sessions are kept entirely in the signed cookie, so they survive restarts and work across replicas.
+type SessionManager struct {
+	secret []byte
+	ttl    time.Duration
+	secure bool
+	// groups, when set, learns the groups of every session it sees.
+	groups *ClaimGroupMembership
+	now    func() time.Time
+}
+
+type session struct {
+	User    string   `json:"user"`
+	Groups  []string `json:"groups,omitempty"`
+	Expires int64    `json:"expires"`
+}
+
+// NewSessionManager creates a manager signing cookies with secret. Sessions last ttl, and secure cookies are only
+// sent over HTTPS. The groups of each session are recorded in groups when it is set.
+func NewSessionManager(secret []byte, ttl time.Duration, secure bool, groups *ClaimGroupMembership) (*SessionManager, error) {
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("session secret must be at least 32 bytes, got %d", len(secret))
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("session TTL must be positive")
+	}
+	return &SessionManager{secret: secret, ttl: ttl, secure: secure, groups: groups, now: time.Now}, nil
+}
+
+// Issue starts a session for user with the groups from their ID token.
+func (m *SessionManager) Issue(w http.ResponseWriter, user string, groups []string) error {
+	expires := m.now().Add(m.ttl)
+	value, err := m.sign(SessionCookieName, session{User: user, Groups: groups, Expires: expires.Unix()})
+	if err != nil {
+		return err
+	}
+	m.setCookie(w, SessionCookieName, value, expires)
+	if m.groups != nil {
+		m.groups.Record(user, groups, expires)
+	}
+	return nil
+}
+
+// Authenticate returns the user of the session cookie on r.
+func (m *SessionManager) Authenticate(r *http.Request) (string, error) {
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		return "", ErrNoSession
+	}
+	var s session
+	if err := m.verify(SessionCookieName, cookie.Value, &s); err != nil {
+		return "", err
+	}
+	expires := time.Unix(s.Expires, 0)
+	if !m.now().Before(expires) || s.User == "" {
+		return "", ErrNoSession
+	}
+	if m.groups != nil {
+		// The claims are recorded on every request, since the dashboard may have restarted since the login.
+		m.groups.Record(s.User, s.Groups, expires)
+	}
+	return s.User, nil
+}
+
+// Clear ends the session.
+func (m *SessionManager) Clear(w http.ResponseWriter) {
+	m.setCookie(w, SessionCookieName, "", time.Unix(0, 0))
+}
+
+func (m *SessionManager) setCookie(w http.ResponseWriter, name, value string, expires time.Time) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   m.secure,
+		// Lax keeps the cookie off cross-site POSTs while still sending it on the redirect back from the provider.
+		SameSite: http.SameSiteLaxMode,
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+}
+
+// sign encodes v as a cookie value for the cookie called name. The name is part of the signature, so a value
+// cannot be replayed in another cookie.
+func (m *SessionManager) sign(name string, v any) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s cookie: %w", name, err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(m.mac(name, encoded)), nil
+}
+
+// verify decodes value, signed for the cookie called name, into v. It returns ErrInvalidSession when the value was
+// not signed by sign.
+func (m *SessionManager) verify(name, value string, v any) error {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return ErrInvalidSession
+	}
+	got, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(got, m.mac(name, encoded)) {
+		return ErrInvalidSession
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalidSession
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrInvalidSession
+	}
+	return nil
+}
+
+func (m *SessionManager) mac(name, encoded string) []byte {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(name + "." + encoded))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StaticGroupSource reads group members from a YAML file mapping group names to users, for local development:
+//
+//	groups:
+//	  ship-admins: [alice, bob]
+//
+// The file is read on every fetch, so edits are picked up by the next refresh.
+type StaticGroupSource struct {
+	path string
+}
+
+type staticGroupFile struct {
+	Groups map[string][]string `yaml:"groups"`
+}
+
+// NewStaticGroupSource creates a source reading path, which must be a valid group file.
+func NewStaticGroupSource(path string) (*StaticGroupSource, error) {
+	source := &StaticGroupSource{path: path}
+	if _, err := source.load(); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+func (s *StaticGroupSource) Name() string {
+	return "static"
+}
+
+func (s *StaticGroupSource) FetchGroupMembers(ctx context.Context, groupName string) ([]string, error) {
+	groups, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	members, ok := groups[groupName]
+	if !ok {
+		return nil, fmt.Errorf("group %s is not defined in %s", groupName, s.path)
+	}
+	return members, nil
+}
+
+func (s *StaticGroupSource) load() (map[string][]string, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read group file: %w", err)
+	}
+	var file staticGroupFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse group file %s: %w", s.path, err)
+	}
+	return file.Groups, nil
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+)
+
+const (
+	// ServiceAccountUsernamePrefix starts the username Kubernetes assigns to service account tokens,
+	// which continues with namespace:name.
+	ServiceAccountUsernamePrefix = "system:serviceaccount:"
+
+	// DefaultTokenReviewCacheTTL is how long a successful review is reused, sparing the API server a
+	// TokenReview for every status report.
+	DefaultTokenReviewCacheTTL = time.Minute
+)
+
+// ErrTokenNotAuthenticated is returned for tokens the API server does not accept.
+var ErrTokenNotAuthenticated = errors.New("token not authenticated")
+
+// TokenReviewAuthenticator validates ServiceAccount bearer tokens with the Kubernetes TokenReview API, doing in the
+// dashboard what oauth-proxy does for the protected route.
+type TokenReviewAuthenticator struct {
+	client    authenticationv1client.TokenReviewsGetter
+	audiences []string
+	cacheTTL  time.Duration
+
+	mu sync.Mutex
+	// cache maps token hashes to reviewed identities, so raw tokens are not kept in memory.
+	cache map[string]reviewedToken
+	now   func() time.Time
+}
+
+type reviewedToken struct {
+	identity string
+	expires  time.Time
+}
+
+// NewTokenReviewAuthenticator creates an authenticator that reviews tokens through client. Tokens must be issued
+// for one of audiences, which is required: without it any token for the API server, such as one a workload sends
+// to another service, would be accepted. Successful reviews are cached for cacheTTL.
+func NewTokenReviewAuthenticator(client authenticationv1client.TokenReviewsGetter, audiences []string, cacheTTL time.Duration) (*TokenReviewAuthenticator, error) {
+	if len(audiences) == 0 {
+		return nil, errors.New("at least one token audience is required")
+	}
+	return &TokenReviewAuthenticator{
+		client:    client,
+		audiences: audiences,
+		cacheTTL:  cacheTTL,
+		cache:     make(map[string]reviewedToken),
+		now:       time.Now,
+	}, nil
+}
+
+// NewTokenReviewAuthenticatorForConfig creates an authenticator that reviews tokens against the cluster in
+// kubeconfigPath, or the cluster the dashboard runs in when it is empty.
+func NewTokenReviewAuthenticatorForConfig(kubeconfigPath string, audiences []string, cacheTTL time.Duration) (*TokenReviewAuthenticator, error) {
+	config, err := restConfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	client, err := authenticationv1client.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authentication client: %w", err)
+	}
+	return NewTokenReviewAuthenticator(client, audiences, cacheTTL)
+}
+
+// Authenticate returns the system:serviceaccount:namespace:name identity of token. Tokens of regular users are
+// rejected, since only component monitors are expected to authenticate this way.
+func (a *TokenReviewAuthenticator) Authenticate(ctx context.Context, token string) (string, error) {
+	key := HashAPIToken(token)
+	if identity, ok := a.cached(key); ok {
+		return identity, nil
+	}
+
+	review, err := a.client.TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token, Audiences: a.audiences},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to review token: %w", err)
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return "", fmt.Errorf("%w: %s", ErrTokenNotAuthenticated, review.Status.Error)
+		}
+		return "", ErrTokenNotAuthenticated
+	}
+
+	// An API server that ignores the requested audiences answers with none, which means the token was only
+	// checked against the API server's own audience.
+	if !slices.ContainsFunc(review.Status.Audiences, func(audience string) bool { return slices.Contains(a.audiences, audience) }) {
+		return "", fmt.Errorf("%w: token is not issued for the dashboard's audiences", ErrTokenNotAuthenticated)
+	}
+
+	identity := review.Status.User.Username
+	if !IsServiceAccountUsername(identity) {
+		return "", fmt.Errorf("%w: %s is not a service account", ErrTokenNotAuthenticated, identity)
+	}
+
+	if a.cacheTTL > 0 {
+		now := a.now()
+		a.mu.Lock()
+		for cachedKey, entry := range a.cache {
+			if now.After(entry.expires) {
+				delete(a.cache, cachedKey)
+			}
+		}
+		a.cache[key] = reviewedToken{identity: identity, expires: now.Add(a.cacheTTL)}
+		a.mu.Unlock()
+	}
+	return identity, nil
+}
+
+func (a *TokenReviewAuthenticator) cached(key string) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entry, ok := a.cache[key]
+	if !ok {
+		return "", false
+	}
+	if a.now().After(entry.expires) {
+		delete(a.cache, key)
+		return "", false
+	}
+	return entry.identity, true
+}
+
+// IsServiceAccountUsername reports whether username has the system:serviceaccount:namespace:name form.
+func IsServiceAccountUsername(username string) bool {
+	serviceAccount, ok := strings.CutPrefix(username, ServiceAccountUsernamePrefix)
+	if !ok {
+		return false
+	}
+	namespace, name, ok := strings.Cut(serviceAccount, ":")
+	return ok && namespace != "" && name != "" && !strings.Contains(name, ":")
+}
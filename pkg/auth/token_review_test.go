@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	authenticationv1fake "k8s.io/client-go/kubernetes/typed/authentication/v1/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// newFakeTokenReviewClient answers TokenReviews with the usernames in tokens, counting the reviews it serves.
+func newFakeTokenReviewClient(tokens map[string]string, reviews *int) *authenticationv1fake.FakeAuthenticationV1 {
+	client := &authenticationv1fake.FakeAuthenticationV1{Fake: &clienttesting.Fake{}}
+	client.AddReactor("create", "tokenreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		*reviews++
+		review := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenReview).DeepCopy()
+		if review.Spec.Token == "broken" {
+			return true, nil, errors.New("apiserver unavailable")
+		}
+		if !slices.Equal(review.Spec.Audiences, []string{"ship-status"}) {
+			review.Status.Error = "unexpected audiences"
+			return true, review, nil
+		}
+		if username, ok := tokens[review.Spec.Token]; ok {
+			review.Status.Authenticated = true
+			review.Status.User.Username = username
+			// Tokens for the API server alone are authenticated without a matching audience.
+			if review.Spec.Token != "apiserver-token" {
+				review.Status.Audiences = review.Spec.Audiences
+			}
+		}
+		return true, review, nil
+	})
+	return client
+}
+
+func TestTokenReviewAuthenticator(t *testing.T) {
+	var reviews int
+	client := newFakeTokenReviewClient(map[string]string{
+		"monitor-token":   "system:serviceaccount:ship-status:component-monitor",
+		"user-token":      "alice",
+		"apiserver-token": "system:serviceaccount:ship-status:component-monitor",
+	}, &reviews)
+	authenticator, err := NewTokenReviewAuthenticator(client, []string{"ship-status"}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		token        string
+		wantIdentity string
+		wantNotAuthn bool
+	}{
+		{name: "service account token", token: "monitor-token", wantIdentity: "system:serviceaccount:ship-status:component-monitor"},
+		{name: "user token", token: "user-token", wantNotAuthn: true},
+		{name: "unknown token", token: "garbage", wantNotAuthn: true},
+		{name: "token without the dashboard audience", token: "apiserver-token", wantNotAuthn: true},
+		{name: "review fails", token: "broken"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := authenticator.Authenticate(context.Background(), tt.token)
+			if tt.wantIdentity != "" {
+				if err != nil || identity != tt.wantIdentity {
+					t.Errorf("Authenticate() = %q, %v; want %q", identity, err, tt.wantIdentity)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Authenticate() = %q, want an error", identity)
+			}
+			if errors.Is(err, ErrTokenNotAuthenticated) != tt.wantNotAuthn {
+				t.Errorf("Authenticate() error = %v, ErrTokenNotAuthenticated expected: %v", err, tt.wantNotAuthn)
+			}
+		})
+	}
+}
+
+func TestNewTokenReviewAuthenticator_RequiresAudiences(t *testing.T) {
+	var reviews int
+	if _, err := NewTokenReviewAuthenticator(newFakeTokenReviewClient(nil, &reviews), nil, time.Minute); err == nil {
+		t.Error("NewTokenReviewAuthenticator() without audiences should fail")
+	}
+}
+
+func TestTokenReviewAuthenticator_CachesSuccessfulReviews(t *testing.T) {
+	var reviews int
+	client := newFakeTokenReviewClient(map[string]string{"monitor-token": "system:serviceaccount:ship-status:component-monitor"}, &reviews)
+	authenticator, err := NewTokenReviewAuthenticator(client, []string{"ship-status"}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	authenticator.now = func() time.Time { return now }
+
+	for range 3 {
+		if _, err := authenticator.Authenticate(context.Background(), "monitor-token"); err != nil {
+			t.Fatalf("Authenticate() returned error: %v", err)
+		}
+	}
+	if reviews != 1 {
+		t.Errorf("reviews = %d, want 1 while the review is cached", reviews)
+	}
+
+	for range 2 {
+		_, _ = authenticator.Authenticate(context.Background(), "garbage")
+	}
+	if reviews != 3 {
+		t.Errorf("reviews = %d, want failed reviews not to be cached", reviews)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := authenticator.Authenticate(context.Background(), "monitor-token"); err != nil {
+		t.Fatalf("Authenticate() returned error: %v", err)
+	}
+	if reviews != 4 {
+		t.Errorf("reviews = %d, want the token reviewed again once the cache entry expires", reviews)
+	}
+}
+
+func TestIsServiceAccountUsername(t *testing.T) {
+	tests := map[string]bool{
+		"system:serviceaccount:ship-status:component-monitor": true,
+		"system:serviceaccount:ship-status":                   false,
+		"system:serviceaccount::component-monitor":            false,
+		"system:serviceaccount:ship-status:a:b":               false,
+		"alice":                                               false,
+	}
+	for username, want := range tests {
+		if got := IsServiceAccountUsername(username); got != want {
+			t.Errorf("IsServiceAccountUsername(%q) = %v, want %v", username, got, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package outage
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/types"
+)
+
+// ChatWebhookNotifier posts outage notifications as {"text": ...} payloads to generic chat webhooks,
+// such as Mattermost or Google Chat, configured with webhook_reporting.
+type ChatWebhookNotifier struct {
+	client        *http.Client
+	configManager *config.Manager[types.DashboardConfig]
+	baseURL       string
+	logger        *logrus.Logger
+}
+
+// NewChatWebhookNotifier creates a ChatWebhookNotifier. baseURL is used to link back to the outage.
+func NewChatWebhookNotifier(configManager *config.Manager[types.DashboardConfig], baseURL string, logger *logrus.Logger) *ChatWebhookNotifier {
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &ChatWebhookNotifier{
+		client:        &http.Client{Timeout: notifierHTTPTimeout},
+		configManager: configManager,
+		baseURL:       baseURL,
+		logger:        logger,
+	}
+}
+
+func (n *ChatWebhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (n *ChatWebhookNotifier) OutageCreated(outage *types.Outage) error {
+	return n.notify(outage, nil)
+}
+
+func (n *ChatWebhookNotifier) OutageUpdated(outage, oldOutage *types.Outage) error {
+	return n.notify(outage, oldOutage)
+}
+
+func (n *ChatWebhookNotifier) OutageResolved(outage, oldOutage *types.Outage) error {
+	return n.OutageUpdated(outage, oldOutage)
+}
+
+// targets returns the configured webhooks whose severity threshold is met by the outage or its previous state.
+func (n *ChatWebhookNotifier) targets(outage, oldOutage *types.Outage) []types.WebhookReportingConfig {
+	component := n.configManager.Get().GetComponentBySlug(outage.ComponentName)
+	if component == nil {
+		return nil
+	}
+	var targets []types.WebhookReportingConfig
+	for _, reporting := range types.GetWebhookReporting(component, component.GetSubComponentBySlug(outage.SubComponentName)) {
+		if meetsSeverityThreshold(outage.Severity, reporting.Severity) ||
+			(oldOutage != nil && meetsSeverityThreshold(oldOutage.Severity, reporting.Severity)) {
+			targets = append(targets, reporting)
+		}
+	}
+	return targets
+}
+
+// notify posts the outage to each target, without internal items unless the target is internal.
+func (n *ChatWebhookNotifier) notify(outage, oldOutage *types.Outage) error {
+	var lastErr error
+	for _, target := range n.targets(outage, oldOutage) {
+		view, oldView, ok := outageViews(target.Visibility, outage, oldOutage)
+		if !ok {
+			continue
+		}
+		notification := buildNotification(n.configManager, n.baseURL, view, oldView)
+		payload := map[string]string{"text": formatChatWebhookText(notification, target.Flavor)}
+		if err := postJSON(n.client, target.URL, payload); err != nil {
+			n.logger.WithFields(logrus.Fields{
+				"outage_id": outage.ID,
+				"flavor":    target.Flavor,
+				"error":     err,
+			}).Error("Failed to post outage notification to chat webhook")
+			lastErr = err
+			continue
+		}
+		n.logger.WithField("outage_id", outage.ID).Info("Successfully posted outage notification to chat webhook")
+	}
+	return lastErr
+}
+
+// formatChatWebhookText renders the notification as a bold title, facts, and lines, followed by a link
+// in the syntax of flavor.
+func formatChatWebhookText(notification outageNotification, flavor types.WebhookFlavor) string {
+	var bold, link string
+	switch flavor {
+	case types.WebhookFlavorGoogleChat:
+		bold = "*" + notification.Title + "*"
+		link = fmt.Sprintf("<%s|View Outage>", notification.URL)
+	default:
+		bold = "**" + notification.Title + "**"
+		link = fmt.Sprintf("[View Outage](%s)", notification.URL)
+	}
+
+	parts := []string{bold, ""}
+	for _, fact := range notification.Facts {
+		parts = append(parts, fmt.Sprintf("%s: %s", fact.Name, fact.Value))
+	}
+	if len(notification.Lines) > 0 {
+		parts = append(parts, "")
+		parts = append(parts, notification.Lines...)
+	}
+	parts = append(parts, "", link)
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,221 @@
+package outage
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/types"
+)
+
+// emailEvent is the kind of outage change an email announces.
+type emailEvent string
+
+const (
+	emailEventCreated         emailEvent = "created"
+	emailEventSeverityChanged emailEvent = "severity_changed"
+	emailEventResolved        emailEvent = "resolved"
+)
+
+// emailTemplateData is the data available to the email templates.
+type emailTemplateData struct {
+	Event            emailEvent
+	Headline         string
+	ComponentName    string
+	SubComponentName string
+	OutageID         uint
+	Severity         types.Severity
+	PreviousSeverity types.Severity
+	Description      string
+	Started          string
+	Resolved         string
+	Duration         string
+	CreatedBy        string
+	URL              string
+}
+
+var emailTextTemplate = texttemplate.Must(texttemplate.New("text").Parse(`{{.Headline}}
+
+Component: {{.ComponentName}} / {{.SubComponentName}}
+Severity:  {{.Severity}}{{if .PreviousSeverity}} (was {{.PreviousSeverity}}){{end}}
+Started:   {{.Started}}
+{{- if .Resolved}}
+Resolved:  {{.Resolved}}
+Duration:  {{.Duration}}
+{{- end}}
+{{- if eq .Event "created"}}
+Created by: {{.CreatedBy}}
+{{- end}}
+{{if .Description}}
+{{.Description}}
+{{end}}
+View outage: {{.URL}}
+`))
+
+var emailHTMLTemplate = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; font-size: 14px;">
+<h2 style="margin-bottom: 8px;">{{.Headline}}</h2>
+<table cellpadding="4" style="border-collapse: collapse;">
+<tr><td><strong>Component</strong></td><td>{{.ComponentName}} / {{.SubComponentName}}</td></tr>
+<tr><td><strong>Severity</strong></td><td>{{.Severity}}{{if .PreviousSeverity}} (was {{.PreviousSeverity}}){{end}}</td></tr>
+<tr><td><strong>Started</strong></td><td>{{.Started}}</td></tr>
+{{- if .Resolved}}
+<tr><td><strong>Resolved</strong></td><td>{{.Resolved}}</td></tr>
+<tr><td><strong>Duration</strong></td><td>{{.Duration}}</td></tr>
+{{- end}}
+{{- if eq .Event "created"}}
+<tr><td><strong>Created by</strong></td><td>{{.CreatedBy}}</td></tr>
+{{- end}}
+</table>
+{{- if .Description}}
+<p style="white-space: pre-wrap;">{{.Description}}</p>
+{{- end}}
+<p><a href="{{.URL}}">View outage</a></p>
+</body>
+</html>
+`))
+
+// EmailNotifier emails new outages, severity changes, and resolutions to the recipients configured
+// with email_reporting. Other updates, such as triage notes, are not emailed.
+type EmailNotifier struct {
+	mailer        *SMTPMailer
+	configManager *config.Manager[types.DashboardConfig]
+	baseURL       string
+	logger        *logrus.Logger
+}
+
+// NewEmailNotifier creates an EmailNotifier that sends through mailer. baseURL is used to link back to the outage.
+func NewEmailNotifier(mailer *SMTPMailer, configManager *config.Manager[types.DashboardConfig], baseURL string, logger *logrus.Logger) *EmailNotifier {
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &EmailNotifier{
+		mailer:        mailer,
+		configManager: configManager,
+		baseURL:       baseURL,
+		logger:        logger,
+	}
+}
+
+func (n *EmailNotifier) Name() string {
+	return "email"
+}
+
+func (n *EmailNotifier) OutageCreated(outage *types.Outage) error {
+	return n.send(outage, nil, emailEventCreated)
+}
+
+// OutageUpdated only emails severity changes.
+func (n *EmailNotifier) OutageUpdated(outage, oldOutage *types.Outage) error {
+	if oldOutage.Severity == outage.Severity {
+		return nil
+	}
+	return n.send(outage, oldOutage, emailEventSeverityChanged)
+}
+
+func (n *EmailNotifier) OutageResolved(outage, oldOutage *types.Outage) error {
+	return n.send(outage, oldOutage, emailEventResolved)
+}
+
+// recipients returns the deduplicated recipients whose severity threshold is met by the outage or its previous
+// state, split by whether their entry is internal. Recipients of both kinds of entry are internal.
+func (n *EmailNotifier) recipients(outage, oldOutage *types.Outage) (internal, public []string) {
+	component := n.configManager.Get().GetComponentBySlug(outage.ComponentName)
+	if component == nil {
+		return nil, nil
+	}
+	internalSet, publicSet := sets.NewString(), sets.NewString()
+	for _, reporting := range types.GetEmailReporting(component, component.GetSubComponentBySlug(outage.SubComponentName)) {
+		if meetsSeverityThreshold(outage.Severity, reporting.Severity) ||
+			(oldOutage != nil && meetsSeverityThreshold(oldOutage.Severity, reporting.Severity)) {
+			if reporting.Visibility.IsInternal() {
+				internalSet.Insert(reporting.Recipients...)
+			} else {
+				publicSet.Insert(reporting.Recipients...)
+			}
+		}
+	}
+	return internalSet.List(), publicSet.Difference(internalSet).List()
+}
+
+func (n *EmailNotifier) send(outage, oldOutage *types.Outage, event emailEvent) error {
+	internal, public := n.recipients(outage, oldOutage)
+	var lastErr error
+	if len(internal) > 0 {
+		if err := n.sendTo(internal, outage, oldOutage, event); err != nil {
+			lastErr = err
+		}
+	}
+	if len(public) > 0 {
+		publicOutage := outage.PublicView()
+		if err := n.sendTo(public, &publicOutage, oldOutage, event); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func (n *EmailNotifier) sendTo(recipients []string, outage, oldOutage *types.Outage, event emailEvent) error {
+	logger := n.logger.WithFields(logrus.Fields{
+		"outage_id":  outage.ID,
+		"event":      event,
+		"recipients": len(recipients),
+	})
+
+	data := n.templateData(outage, oldOutage, event)
+	var text, html bytes.Buffer
+	if err := emailTextTemplate.Execute(&text, data); err != nil {
+		return fmt.Errorf("failed to render email text: %w", err)
+	}
+	if err := emailHTMLTemplate.Execute(&html, data); err != nil {
+		return fmt.Errorf("failed to render email HTML: %w", err)
+	}
+
+	if err := n.mailer.Send(recipients, data.Headline, text.String(), html.String()); err != nil {
+		logger.WithField("error", err).Error("Failed to send outage email")
+		return err
+	}
+	logger.Info("Successfully sent outage email")
+	return nil
+}
+
+func (n *EmailNotifier) templateData(outage, oldOutage *types.Outage, event emailEvent) emailTemplateData {
+	componentName, subComponentName := resolveDisplayNames(n.configManager, outage)
+	data := emailTemplateData{
+		Event:            event,
+		ComponentName:    componentName,
+		SubComponentName: subComponentName,
+		OutageID:         outage.ID,
+		Severity:         outage.Severity,
+		Description:      outage.Description,
+		Started:          outage.StartTime.UTC().Format(time.RFC1123),
+		CreatedBy:        outage.CreatedBy,
+		URL:              buildOutageURL(n.baseURL, outage),
+	}
+	if oldOutage != nil && oldOutage.Severity != outage.Severity {
+		data.PreviousSeverity = oldOutage.Severity
+	}
+	if outage.EndTime.Valid {
+		data.Resolved = outage.EndTime.Time.UTC().Format(time.RFC1123)
+		data.Duration = outage.EndTime.Time.Sub(outage.StartTime).Round(time.Minute).String()
+	}
+
+	target := fmt.Sprintf("%s/%s", componentName, subComponentName)
+	switch event {
+	case emailEventCreated:
+		data.Headline = fmt.Sprintf("[Outage] %s: %s", outage.Severity, target)
+	case emailEventSeverityChanged:
+		data.Headline = fmt.Sprintf("[Outage] %s → %s: %s", oldOutage.Severity, outage.Severity, target)
+	case emailEventResolved:
+		data.Headline = fmt.Sprintf("[Resolved] %s", target)
+	}
+	return data
+}
@@ -0,0 +1,222 @@
+package outage
+
+import (
+	"database/sql"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ship-status-dash/pkg/types"
+)
+
+// parsedMail is a received multipart/alternative message split into its headers and parts.
+type parsedMail struct {
+	Subject string
+	To      string
+	Text    string
+	HTML    string
+}
+
+func parseReceivedMail(t *testing.T, data string) parsedMail {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	parsed := parsedMail{Subject: subject, To: msg.Header.Get("To")}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	if mediaType == "text/plain" {
+		body, err := io.ReadAll(msg.Body)
+		require.NoError(t, err)
+		parsed.Text = string(body)
+		return parsed
+	}
+	require.Equal(t, "multipart/alternative", mediaType)
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		switch {
+		case strings.HasPrefix(part.Header.Get("Content-Type"), "text/plain"):
+			parsed.Text = string(body)
+		case strings.HasPrefix(part.Header.Get("Content-Type"), "text/html"):
+			parsed.HTML = string(body)
+		}
+	}
+	return parsed
+}
+
+func TestSMTPMailer(t *testing.T) {
+	t.Run("plain connection without auth", func(t *testing.T) {
+		server := NewMockSMTPServer(t, MockSMTPOptions{})
+		mailer := NewSMTPMailer(SMTPConfig{Address: server.Address(), From: "status@example.com"})
+
+		require.NoError(t, mailer.Send([]string{"a@example.com", "b@example.com"}, "Hello", "line one\nline two", ""))
+
+		received := server.Received()
+		require.Len(t, received, 1)
+		assert.Equal(t, "status@example.com", received[0].From)
+		assert.Equal(t, []string{"a@example.com", "b@example.com"}, received[0].To)
+		assert.False(t, received[0].TLS)
+		parsed := parseReceivedMail(t, received[0].Data)
+		assert.Equal(t, "Hello", parsed.Subject)
+		assert.Equal(t, "a@example.com, b@example.com", parsed.To)
+		assert.Equal(t, "line one\r\nline two\r\n", parsed.Text)
+	})
+
+	t.Run("STARTTLS and auth", func(t *testing.T) {
+		server := NewMockSMTPServer(t, MockSMTPOptions{StartTLS: true, Username: "bot", Password: "secret"})
+		mailer := NewSMTPMailer(SMTPConfig{
+			Address:    server.Address(),
+			From:       "status@example.com",
+			Username:   "bot",
+			Password:   "secret",
+			RequireTLS: true,
+			TLSConfig:  server.ClientTLSConfig(),
+		})
+
+		require.NoError(t, mailer.Send([]string{"a@example.com"}, "Hello", "text", "<p>html</p>"))
+
+		received := server.Received()
+		require.Len(t, received, 1)
+		assert.True(t, received[0].TLS)
+		assert.Equal(t, "bot", received[0].AuthUser)
+		parsed := parseReceivedMail(t, received[0].Data)
+		assert.Equal(t, "text\r\n", parsed.Text)
+		assert.Equal(t, "<p>html</p>\r\n", parsed.HTML)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		server := NewMockSMTPServer(t, MockSMTPOptions{StartTLS: true, Username: "bot", Password: "secret"})
+		mailer := NewSMTPMailer(SMTPConfig{Address: server.Address(), From: "status@example.com", Username: "bot", Password: "nope", TLSConfig: server.ClientTLSConfig()})
+
+		err := mailer.Send([]string{"a@example.com"}, "Hello", "text", "")
+		assert.ErrorContains(t, err, "SMTP authentication failed")
+		assert.Empty(t, server.Received())
+	})
+
+	t.Run("TLS required but not offered", func(t *testing.T) {
+		server := NewMockSMTPServer(t, MockSMTPOptions{})
+		mailer := NewSMTPMailer(SMTPConfig{Address: server.Address(), From: "status@example.com", RequireTLS: true})
+
+		assert.EqualError(t, mailer.Send([]string{"a@example.com"}, "Hello", "text", ""), "SMTP server does not support STARTTLS")
+		assert.Empty(t, server.Received())
+	})
+}
+
+func TestEmailNotifier(t *testing.T) {
+	server := NewMockSMTPServer(t, MockSMTPOptions{})
+	down := types.SeverityDown
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug: "test-component",
+				Name: "Test Component",
+				EmailReporting: []types.EmailReportingConfig{
+					{Recipients: []string{"team@example.com"}},
+					{Recipients: []string{"management@example.com", "team@example.com"}, Severity: &down},
+				},
+				Subcomponents: []types.SubComponent{{Slug: "test-sub", Name: "Test Sub"}},
+			},
+		},
+	}
+	mailer := NewSMTPMailer(SMTPConfig{Address: server.Address(), From: "status@example.com"})
+	n := NewEmailNotifier(mailer, newNotifierTestConfigManager(t, cfg), "https://test.example.com", logrus.New())
+
+	outage := notifierTestOutage()
+	outage.Description = "Builds <b>fail</b>"
+	require.NoError(t, n.OutageCreated(outage))
+	received := server.Received()
+	require.Len(t, received, 1)
+	assert.Equal(t, []string{"team@example.com"}, received[0].To, "Degraded does not reach the Down-only recipients")
+	created := parseReceivedMail(t, received[0].Data)
+	assert.Equal(t, "[Outage] Degraded: Test Component/Test Sub", created.Subject)
+	assert.Equal(t, strings.Join([]string{
+		"[Outage] Degraded: Test Component/Test Sub",
+		"",
+		"Component: Test Component / Test Sub",
+		"Severity:  Degraded",
+		"Started:   Mon, 15 Jan 2024 10:30:00 UTC",
+		"Created by: alice",
+		"",
+		"Builds <b>fail</b>",
+		"",
+		"View outage: https://test.example.com/test-component/test-sub/outages/3",
+		"",
+	}, "\r\n"), created.Text)
+	assert.Contains(t, created.HTML, "Builds &lt;b&gt;fail&lt;/b&gt;", "descriptions are escaped in HTML")
+	assert.Contains(t, created.HTML, `<a href="https://test.example.com/test-component/test-sub/outages/3">View outage</a>`)
+
+	old := *outage
+	outage.TriageNotes = []types.TriageNote{{Author: "bob", Body: "looking"}}
+	require.NoError(t, n.OutageUpdated(outage, &old))
+	assert.Len(t, server.Received(), 1, "updates that do not change severity are not emailed")
+
+	old = *outage
+	outage.Severity = types.SeverityDown
+	require.NoError(t, n.OutageUpdated(outage, &old))
+	received = server.Received()
+	require.Len(t, received, 2)
+	assert.Equal(t, []string{"management@example.com", "team@example.com"}, received[1].To, "recipients are deduplicated")
+	changed := parseReceivedMail(t, received[1].Data)
+	assert.Equal(t, "[Outage] Degraded → Down: Test Component/Test Sub", changed.Subject)
+	assert.Contains(t, changed.Text, "Severity:  Down (was Degraded)")
+
+	old = *outage
+	outage.EndTime = sql.NullTime{Time: outage.StartTime.Add(90 * time.Minute), Valid: true}
+	require.NoError(t, n.OutageResolved(outage, &old))
+	received = server.Received()
+	require.Len(t, received, 3)
+	resolved := parseReceivedMail(t, received[2].Data)
+	assert.Equal(t, "[Resolved] Test Component/Test Sub", resolved.Subject)
+	assert.Contains(t, resolved.Text, "Resolved:  Mon, 15 Jan 2024 12:00:00 UTC\r\nDuration:  1h30m0s")
+	assert.NotContains(t, resolved.Text, "Created by")
+}
+
+func TestEmailNotifier_Visibility(t *testing.T) {
+	server := NewMockSMTPServer(t, MockSMTPOptions{})
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug: "test-component",
+				Name: "Test Component",
+				EmailReporting: []types.EmailReportingConfig{
+					{Recipients: []string{"customers@example.com", "team@example.com"}},
+					{Recipients: []string{"team@example.com"}, Visibility: types.VisibilityInternal},
+				},
+				Subcomponents: []types.SubComponent{{Slug: "test-sub", Name: "Test Sub"}},
+			},
+		},
+	}
+	mailer := NewSMTPMailer(SMTPConfig{Address: server.Address(), From: "status@example.com"})
+	n := NewEmailNotifier(mailer, newNotifierTestConfigManager(t, cfg), "https://test.example.com", logrus.New())
+
+	outage := notifierTestOutage()
+	outage.DescriptionVisibility = types.VisibilityInternal
+	require.NoError(t, n.OutageCreated(outage))
+	received := server.Received()
+	require.Len(t, received, 2)
+	byRecipient := map[string]parsedMail{}
+	for _, msg := range received {
+		require.Len(t, msg.To, 1)
+		byRecipient[msg.To[0]] = parseReceivedMail(t, msg.Data)
+	}
+	assert.Contains(t, byRecipient["team@example.com"].Text, "Builds are slow", "internal recipients read internal descriptions")
+	assert.NotContains(t, byRecipient["customers@example.com"].Text, "Builds are slow")
+	assert.NotContains(t, byRecipient["customers@example.com"].HTML, "Builds are slow")
+}
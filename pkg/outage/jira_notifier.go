@@ -0,0 +1,362 @@
+package outage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+const (
+	defaultJiraIssueType = "Bug"
+	// jiraMaxSummary is the longest summary Jira accepts.
+	jiraMaxSummary = 255
+	// jiraStatusSyncWindow is how far back issue links are synced. Issues of older outages rarely change
+	// and are not worth polling for.
+	jiraStatusSyncWindow = 30 * 24 * time.Hour
+)
+
+// JiraConfig configures access to a Jira server through its REST API v2.
+type JiraConfig struct {
+	// URL is the base URL of the Jira server, e.g. https://issues.example.com.
+	URL string
+	// Username and Token authenticate with basic auth. When Username is empty, Token is sent as a
+	// bearer personal access token.
+	Username string
+	Token    string
+	// SyncInterval is how often the status of linked issues is refreshed.
+	SyncInterval time.Duration
+}
+
+// JiraNotifier files a Jira issue for outages of components with a jira block, comments on linked issues
+// when the outage resolves, and keeps the status shown on jira_issue links in sync with Jira.
+type JiraNotifier struct {
+	cfg           JiraConfig
+	client        *http.Client
+	linkRepo      repositories.OutageLinkRepository
+	configManager *config.Manager[types.DashboardConfig]
+	baseURL       string
+	logger        *logrus.Logger
+}
+
+// NewJiraNotifier creates a JiraNotifier. Issues are linked through linkRepo, and baseURL is used to link
+// back to the outage from the issue.
+func NewJiraNotifier(cfg JiraConfig, linkRepo repositories.OutageLinkRepository, configManager *config.Manager[types.DashboardConfig], baseURL string, logger *logrus.Logger) *JiraNotifier {
+	cfg.URL = strings.TrimSuffix(cfg.URL, "/")
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &JiraNotifier{
+		cfg:           cfg,
+		client:        &http.Client{Timeout: notifierHTTPTimeout},
+		linkRepo:      linkRepo,
+		configManager: configManager,
+		baseURL:       baseURL,
+		logger:        logger,
+	}
+}
+
+func (n *JiraNotifier) Name() string {
+	return "jira"
+}
+
+func (n *JiraNotifier) OutageCreated(outage *types.Outage) error {
+	jira := n.jiraConfig(outage)
+	if jira == nil || !jira.AutoCreate || !meetsPagingThreshold(outage.Severity, jira.Severity) {
+		return nil
+	}
+	return n.fileIssue(outage, jira)
+}
+
+// OutageUpdated files an issue once an active outage is raised to the threshold. Outages that already have
+// an issue linked, whether filed by the dashboard or by hand, do not get another.
+func (n *JiraNotifier) OutageUpdated(outage, oldOutage *types.Outage) error {
+	if outage.EndTime.Valid {
+		return nil
+	}
+	jira := n.jiraConfig(outage)
+	if jira == nil || !jira.AutoCreate ||
+		!meetsPagingThreshold(outage.Severity, jira.Severity) || meetsPagingThreshold(oldOutage.Severity, jira.Severity) {
+		return nil
+	}
+	links, err := n.issueLinks(outage.ID)
+	if err != nil {
+		return err
+	}
+	if len(links) > 0 {
+		return nil
+	}
+	return n.fileIssue(outage, jira)
+}
+
+// OutageResolved comments on every issue on this Jira server linked to the outage. Issues are left open
+// for their assignee to close once any follow-up is done.
+func (n *JiraNotifier) OutageResolved(outage, _ *types.Outage) error {
+	links, err := n.issueLinks(outage.ID)
+	if err != nil {
+		return err
+	}
+	componentName, subComponentName := resolveDisplayNames(n.configManager, outage)
+	comment := fmt.Sprintf("The outage of %s/%s was resolved at %s.\n\n[View Outage|%s]",
+		componentName, subComponentName, outage.EndTime.Time.UTC().Format(time.RFC3339), buildOutageURL(n.baseURL, outage))
+
+	var lastErr error
+	for _, link := range links {
+		key, _ := n.issueKey(link.URL)
+		if err := n.addComment(key, comment); err != nil {
+			n.logger.WithFields(logrus.Fields{
+				"outage_id": outage.ID,
+				"issue":     key,
+				"error":     err,
+			}).Error("Failed to comment on Jira issue")
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// jiraConfig returns the outage component's jira block, or nil when it has none.
+func (n *JiraNotifier) jiraConfig(outage *types.Outage) *types.JiraConfig {
+	component := n.configManager.Get().GetComponentBySlug(outage.ComponentName)
+	if component == nil {
+		return nil
+	}
+	return component.Jira
+}
+
+// issueLinks returns the outage's links to issues on this Jira server.
+func (n *JiraNotifier) issueLinks(outageID uint) ([]types.OutageLink, error) {
+	links, err := n.linkRepo.ListOutageLinks(outageID)
+	if err != nil {
+		return nil, err
+	}
+	var issues []types.OutageLink
+	for _, link := range links {
+		if _, ok := n.issueKey(link.URL); ok && link.LinkType == types.LinkTypeJiraIssue {
+			issues = append(issues, link)
+		}
+	}
+	return issues, nil
+}
+
+// issueURL returns the browse URL of the issue with key.
+func (n *JiraNotifier) issueURL(key string) string {
+	return n.cfg.URL + "/browse/" + key
+}
+
+// issueKey extracts the issue key from a browse URL on this Jira server.
+func (n *JiraNotifier) issueKey(issueURL string) (string, bool) {
+	key, ok := strings.CutPrefix(issueURL, n.cfg.URL+"/browse/")
+	if !ok || key == "" || strings.ContainsAny(key, "/?#") {
+		return "", false
+	}
+	return key, true
+}
+
+type jiraIssueFields struct {
+	Project     jiraKeyRef  `json:"project"`
+	IssueType   jiraNameRef `json:"issuetype"`
+	Summary     string      `json:"summary"`
+	Description string      `json:"description"`
+	Labels      []string    `json:"labels,omitempty"`
+}
+
+type jiraKeyRef struct {
+	Key string `json:"key"`
+}
+
+type jiraNameRef struct {
+	Name string `json:"name"`
+}
+
+type jiraIssueRequest struct {
+	Fields jiraIssueFields `json:"fields"`
+}
+
+// jiraIssueStatus is the part of an issue returned by GET /rest/api/2/issue/{key}?fields=status.
+type jiraIssueStatus struct {
+	Fields struct {
+		Status struct {
+			Name string `json:"name"`
+		} `json:"status"`
+	} `json:"fields"`
+}
+
+// fileIssue files an issue for the outage, leaving out an internal description unless the project is internal.
+func (n *JiraNotifier) fileIssue(outage *types.Outage, jira *types.JiraConfig) error {
+	if !jira.Visibility.IsInternal() {
+		public := outage.PublicView()
+		outage = &public
+	}
+	componentName, subComponentName := resolveDisplayNames(n.configManager, outage)
+	summary := fmt.Sprintf("%s: %s/%s", outage.Severity, componentName, subComponentName)
+	if outage.Description != "" {
+		summary += " - " + strings.SplitN(outage.Description, "\n", 2)[0]
+	}
+	summary = truncate(summary, jiraMaxSummary)
+	outageURL := buildOutageURL(n.baseURL, outage)
+	description := []string{
+		fmt.Sprintf("*Severity:* %s", outage.Severity),
+		fmt.Sprintf("*Started:* %s", outage.StartTime.UTC().Format(time.RFC3339)),
+		fmt.Sprintf("*Created by:* %s", outage.CreatedBy),
+	}
+	if outage.DiscoveredFrom != "" {
+		description = append(description, fmt.Sprintf("*Discovered from:* %s", outage.DiscoveredFrom))
+	}
+	if outage.Description != "" {
+		description = append(description, "", outage.Description)
+	}
+	description = append(description, "", fmt.Sprintf("[View Outage|%s]", outageURL))
+	issueType := jira.IssueType
+	if issueType == "" {
+		issueType = defaultJiraIssueType
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	err := n.do(http.MethodPost, "/rest/api/2/issue", jiraIssueRequest{Fields: jiraIssueFields{
+		Project:     jiraKeyRef{Key: jira.Project},
+		IssueType:   jiraNameRef{Name: issueType},
+		Summary:     summary,
+		Description: strings.Join(description, "\n"),
+		Labels:      []string{"ship-status-dash"},
+	}}, &created)
+	if err != nil {
+		n.logger.WithFields(logrus.Fields{
+			"outage_id": outage.ID,
+			"project":   jira.Project,
+			"error":     err,
+		}).Error("Failed to create Jira issue")
+		return err
+	}
+
+	link := &types.OutageLink{
+		OutageID:    outage.ID,
+		URL:         n.issueURL(created.Key),
+		LinkType:    types.LinkTypeJiraIssue,
+		Description: "Jira " + created.Key,
+	}
+	if status, err := n.issueStatus(created.Key); err == nil {
+		link.Status = status
+	}
+	if err := n.linkRepo.AddOutageLink(link); err != nil {
+		return fmt.Errorf("failed to link Jira issue %s: %w", created.Key, err)
+	}
+	n.logger.WithFields(logrus.Fields{
+		"outage_id": outage.ID,
+		"issue":     created.Key,
+	}).Info("Created Jira issue for outage")
+	return nil
+}
+
+func (n *JiraNotifier) addComment(key, body string) error {
+	return n.do(http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/comment", map[string]string{"body": body}, nil)
+}
+
+func (n *JiraNotifier) issueStatus(key string) (string, error) {
+	var issue jiraIssueStatus
+	if err := n.do(http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields=status", nil, &issue); err != nil {
+		return "", err
+	}
+	return issue.Fields.Status.Name, nil
+}
+
+// do sends a request to the Jira REST API and decodes the response into out when it is not nil.
+func (n *JiraNotifier) do(method, path string, payload, out any) error {
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("failed to marshal Jira request: %w", err)
+		}
+	}
+	body := bytes.NewReader(data)
+	req, err := http.NewRequest(method, n.cfg.URL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if n.cfg.Username != "" {
+		req.SetBasicAuth(n.cfg.Username, n.cfg.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+n.cfg.Token)
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Jira API returned status %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode Jira response: %w", err)
+	}
+	return nil
+}
+
+// Start syncs the status of linked issues every SyncInterval until ctx is done.
+func (n *JiraNotifier) Start(ctx context.Context) {
+	n.logger.WithField("sync_interval", n.cfg.SyncInterval).Info("Starting Jira issue status sync")
+	ticker := time.NewTicker(n.cfg.SyncInterval)
+	defer ticker.Stop()
+
+	n.SyncIssueStatuses(time.Now().Add(-jiraStatusSyncWindow))
+	for {
+		select {
+		case <-ctx.Done():
+			n.logger.Info("Stopping Jira issue status sync")
+			return
+		case <-ticker.C:
+			n.SyncIssueStatuses(time.Now().Add(-jiraStatusSyncWindow))
+		}
+	}
+}
+
+// SyncIssueStatuses refreshes the status of the jira_issue links created after since. Links to issues on
+// other Jira servers are skipped.
+func (n *JiraNotifier) SyncIssueStatuses(since time.Time) {
+	links, err := n.linkRepo.ListOutageLinksByType(types.LinkTypeJiraIssue, since)
+	if err != nil {
+		n.logger.WithField("error", err).Error("Failed to list Jira issue links")
+		return
+	}
+	for _, link := range links {
+		key, ok := n.issueKey(link.URL)
+		if !ok {
+			continue
+		}
+		logger := n.logger.WithFields(logrus.Fields{
+			"outage_id": link.OutageID,
+			"issue":     key,
+		})
+		status, err := n.issueStatus(key)
+		if err != nil {
+			logger.WithField("error", err).Warn("Failed to get Jira issue status")
+			continue
+		}
+		if status == link.Status {
+			continue
+		}
+		if err := n.linkRepo.UpdateOutageLinkStatus(link.ID, status); err != nil {
+			logger.WithField("error", err).Error("Failed to update Jira issue link status")
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package outage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+type jiraComment struct {
+	Key  string
+	Body string
+}
+
+// mockJira serves the Jira REST API v2 endpoints used by JiraNotifier, numbering created issues OPS-1, OPS-2, ...
+type mockJira struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	issues   []jiraIssueRequest
+	comments []jiraComment
+	statuses map[string]string
+}
+
+func newMockJira(t *testing.T) *mockJira {
+	t.Helper()
+	jira := &mockJira{statuses: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /rest/api/2/issue", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer jira-token", r.Header.Get("Authorization"))
+		var issue jiraIssueRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&issue))
+		jira.mu.Lock()
+		jira.issues = append(jira.issues, issue)
+		key := fmt.Sprintf("OPS-%d", len(jira.issues))
+		jira.statuses[key] = "To Do"
+		jira.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"id":"1000","key":%q}`, key)
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/{key}/comment", func(w http.ResponseWriter, r *http.Request) {
+		var comment struct {
+			Body string `json:"body"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&comment))
+		jira.mu.Lock()
+		jira.comments = append(jira.comments, jiraComment{Key: r.PathValue("key"), Body: comment.Body})
+		jira.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("GET /rest/api/2/issue/{key}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "status", r.URL.Query().Get("fields"))
+		jira.mu.Lock()
+		status, ok := jira.statuses[r.PathValue("key")]
+		jira.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"key":%q,"fields":{"status":{"name":%q}}}`, r.PathValue("key"), status)
+	})
+	jira.server = httptest.NewServer(mux)
+	t.Cleanup(jira.server.Close)
+	return jira
+}
+
+func (j *mockJira) setStatus(key, status string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.statuses[key] = status
+}
+
+func newJiraNotifierTest(t *testing.T, jiraConfig *types.JiraConfig) (*JiraNotifier, *mockJira, repositories.OutageLinkRepository) {
+	t.Helper()
+	jira := newMockJira(t)
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug:          "test-component",
+				Name:          "Test Component",
+				Jira:          jiraConfig,
+				Subcomponents: []types.SubComponent{{Slug: "test-sub", Name: "Test Sub"}},
+			},
+		},
+	}
+	linkRepo := repositories.NewGORMOutageLinkRepository(setupTestDB(t))
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	n := NewJiraNotifier(JiraConfig{URL: jira.server.URL + "/", Token: "jira-token"}, linkRepo, newNotifierTestConfigManager(t, cfg), "https://test.example.com", logger)
+	return n, jira, linkRepo
+}
+
+func TestJiraNotifier(t *testing.T) {
+	n, jira, linkRepo := newJiraNotifierTest(t, &types.JiraConfig{Project: "OPS", AutoCreate: true})
+
+	outage := notifierTestOutage()
+	require.NoError(t, n.OutageCreated(outage))
+	assert.Empty(t, jira.issues, "issues default to Down outages")
+
+	old := *outage
+	outage.Severity = types.SeverityDown
+	require.NoError(t, n.OutageUpdated(outage, &old))
+	require.Len(t, jira.issues, 1)
+	assert.Equal(t, jiraIssueRequest{Fields: jiraIssueFields{
+		Project:   jiraKeyRef{Key: "OPS"},
+		IssueType: jiraNameRef{Name: "Bug"},
+		Summary:   "Down: Test Component/Test Sub - Builds are slow",
+		Description: "*Severity:* Down\n*Started:* 2024-01-15T10:30:00Z\n*Created by:* alice\n*Discovered from:* frontend\n\n" +
+			"Builds are slow\n\n[View Outage|https://test.example.com/test-component/test-sub/outages/3]",
+		Labels: []string{"ship-status-dash"},
+	}}, jira.issues[0])
+
+	links, err := linkRepo.ListOutageLinks(3)
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, jira.server.URL+"/browse/OPS-1", links[0].URL)
+	assert.Equal(t, types.LinkTypeJiraIssue, links[0].LinkType)
+	assert.Equal(t, "Jira OPS-1", links[0].Description)
+	assert.Equal(t, "To Do", links[0].Status)
+
+	// Dropping below and rising back to the threshold does not file a second issue.
+	old = *outage
+	outage.Severity = types.SeverityDegraded
+	require.NoError(t, n.OutageUpdated(outage, &old))
+	old = *outage
+	outage.Severity = types.SeverityDown
+	require.NoError(t, n.OutageUpdated(outage, &old))
+	assert.Len(t, jira.issues, 1)
+
+	jira.setStatus("OPS-1", "In Progress")
+	n.SyncIssueStatuses(time.Now().Add(-time.Hour))
+	links, err = linkRepo.ListOutageLinks(3)
+	require.NoError(t, err)
+	assert.Equal(t, "In Progress", links[0].Status)
+
+	// Issues linked by hand are commented on too, unless they live on another server.
+	require.NoError(t, linkRepo.AddOutageLink(&types.OutageLink{OutageID: 3, URL: jira.server.URL + "/browse/OPS-7", LinkType: types.LinkTypeJiraIssue}))
+	require.NoError(t, linkRepo.AddOutageLink(&types.OutageLink{OutageID: 3, URL: "https://other.example.com/browse/ABC-1", LinkType: types.LinkTypeJiraIssue}))
+	old = *outage
+	outage.EndTime = sql.NullTime{Time: outage.StartTime.Add(time.Hour), Valid: true}
+	require.NoError(t, n.OutageResolved(outage, &old))
+	comment := "The outage of Test Component/Test Sub was resolved at 2024-01-15T11:30:00Z.\n\n" +
+		"[View Outage|https://test.example.com/test-component/test-sub/outages/3]"
+	assert.Equal(t, []jiraComment{{Key: "OPS-1", Body: comment}, {Key: "OPS-7", Body: comment}}, jira.comments)
+}
+
+func TestJiraNotifier_OutageCreated(t *testing.T) {
+	degraded := types.SeverityDegraded
+	testCases := []struct {
+		name       string
+		jira       *types.JiraConfig
+		severity   types.Severity
+		wantIssues int
+	}{
+		{
+			name:     "no jira block",
+			severity: types.SeverityDown,
+		},
+		{
+			name:     "auto_create disabled",
+			jira:     &types.JiraConfig{Project: "OPS"},
+			severity: types.SeverityDown,
+		},
+		{
+			name:       "meets custom threshold",
+			jira:       &types.JiraConfig{Project: "OPS", IssueType: "Incident", Severity: &degraded, AutoCreate: true},
+			severity:   types.SeverityDegraded,
+			wantIssues: 1,
+		},
+		{
+			name:     "below custom threshold",
+			jira:     &types.JiraConfig{Project: "OPS", Severity: &degraded, AutoCreate: true},
+			severity: types.SeveritySuspected,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n, jira, _ := newJiraNotifierTest(t, tc.jira)
+			outage := notifierTestOutage()
+			outage.Severity = tc.severity
+			require.NoError(t, n.OutageCreated(outage))
+			require.Len(t, jira.issues, tc.wantIssues)
+			if tc.wantIssues > 0 {
+				assert.Equal(t, tc.jira.IssueType, jira.issues[0].Fields.IssueType.Name)
+			}
+		})
+	}
+}
+
+func TestJiraNotifier_IssueKey(t *testing.T) {
+	n := NewJiraNotifier(JiraConfig{URL: "https://issues.example.com/"}, nil, nil, "", logrus.New())
+	for url, want := range map[string]string{
+		"https://issues.example.com/browse/OPS-12":       "OPS-12",
+		"https://issues.example.com/browse/OPS-12?foo=1": "",
+		"https://issues.example.com/projects/OPS":        "",
+		"https://other.example.com/browse/OPS-12":        "",
+	} {
+		key, ok := n.issueKey(url)
+		assert.Equal(t, want, key, url)
+		assert.Equal(t, want != "", ok, url)
+	}
+}
+
+func TestJiraNotifier_Visibility(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		visibility      types.Visibility
+		wantDescription bool
+	}{
+		{name: "public project", wantDescription: false},
+		{name: "internal project", visibility: types.VisibilityInternal, wantDescription: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n, jira, _ := newJiraNotifierTest(t, &types.JiraConfig{Project: "OPS", AutoCreate: true, Visibility: tc.visibility})
+			outage := notifierTestOutage()
+			outage.Severity = types.SeverityDown
+			outage.DescriptionVisibility = types.VisibilityInternal
+			require.NoError(t, n.OutageCreated(outage))
+			require.Len(t, jira.issues, 1)
+			fields := jira.issues[0].Fields
+			assert.Equal(t, tc.wantDescription, strings.Contains(fields.Summary, "Builds are slow"))
+			assert.Equal(t, tc.wantDescription, strings.Contains(fields.Description, "Builds are slow"))
+		})
+	}
+}
@@ -18,7 +18,10 @@ type MockOutageManager struct {
 		Outage  *types.Outage
 		Reasons []types.Reason
 	}
-	UpdatedOutages []*types.Outage
+	UpdatedOutages     []*types.Outage
+	AddedTriageNotes   []*types.TriageNote
+	UpdatedTriageNotes []*types.TriageNote
+	Delegators         []string
 
 	// Mock functions
 	CreateOutageFn                          func(*types.Outage, []types.Reason, string) error
@@ -33,6 +36,10 @@ type MockOutageManager struct {
 	FindReopenableOutageFn                  func(string, string, string, time.Time, []types.Reason) (*types.Outage, error)
 	GetOutagesDuringFn                      func(time.Time, time.Time, []types.SubComponentRef) ([]types.Outage, error)
 	GetStaleSuspectedOutagesFn              func(time.Time) ([]types.Outage, error)
+	GetOutageAuditLogsFn                    func(uint) ([]types.OutageAuditLog, error)
+	GetOutagesCreatedByFn                   func(string, time.Time) ([]types.Outage, error)
+	GetOutagesReportedByFn                  func(string, time.Time) ([]types.Outage, error)
+	GetAuditLogsFn                          func(types.AuditLogFilter) ([]types.AuditLogEntry, error)
 
 	LastGetOutagesDuringQueryStart time.Time
 	LastGetOutagesDuringQueryEnd   time.Time
@@ -160,11 +167,38 @@ func (m *MockOutageManager) GetOutagesDuring(queryStart, queryEnd time.Time, ref
 	return []types.Outage{}, nil
 }
 
-// GetOutageAuditLogs is included for interface completeness.
+// GetOutagesCreatedBy delegates to GetOutagesCreatedByFn when set.
+func (m *MockOutageManager) GetOutagesCreatedBy(createdBy string, since time.Time) ([]types.Outage, error) {
+	if m.GetOutagesCreatedByFn != nil {
+		return m.GetOutagesCreatedByFn(createdBy, since)
+	}
+	return nil, nil
+}
+
+// GetOutagesReportedBy delegates to GetOutagesReportedByFn when set.
+func (m *MockOutageManager) GetOutagesReportedBy(user string, since time.Time) ([]types.Outage, error) {
+	if m.GetOutagesReportedByFn != nil {
+		return m.GetOutagesReportedByFn(user, since)
+	}
+	return nil, nil
+}
+
+// GetOutageAuditLogs delegates to GetOutageAuditLogsFn when set.
 func (m *MockOutageManager) GetOutageAuditLogs(outageID uint) ([]types.OutageAuditLog, error) {
+	if m.GetOutageAuditLogsFn != nil {
+		return m.GetOutageAuditLogsFn(outageID)
+	}
 	return nil, nil
 }
 
+// GetAuditLogs delegates to GetAuditLogsFn when set.
+func (m *MockOutageManager) GetAuditLogs(filter types.AuditLogFilter) ([]types.AuditLogEntry, error) {
+	if m.GetAuditLogsFn != nil {
+		return m.GetAuditLogsFn(filter)
+	}
+	return []types.AuditLogEntry{}, nil
+}
+
 // DeleteOutage removes an outage.
 func (m *MockOutageManager) DeleteOutage(outage *types.Outage, user string) error {
 	if m.DeleteOutageFn != nil {
@@ -202,12 +236,19 @@ func (m *MockOutageManager) ReportSuspectedOutage(componentSlug, subComponentSlu
 	return nil, nil
 }
 
+// AddTriageNote captures the note for assertions.
 func (m *MockOutageManager) AddTriageNote(note *types.TriageNote) error {
+	noteCopy := *note
+	m.AddedTriageNotes = append(m.AddedTriageNotes, &noteCopy)
 	return nil
 }
 
-func (m *MockOutageManager) UpdateTriageNote(outageID, noteID uint, body, user string) (*types.TriageNote, error) {
-	return nil, nil
+// UpdateTriageNote captures the edit for assertions, with the editing user as Author.
+func (m *MockOutageManager) UpdateTriageNote(outageID, noteID uint, body string, visibility types.Visibility, user string) (*types.TriageNote, error) {
+	note := &types.TriageNote{OutageID: outageID, Body: body, Author: user, Visibility: visibility}
+	note.ID = noteID
+	m.UpdatedTriageNotes = append(m.UpdatedTriageNotes, note)
+	return note, nil
 }
 
 func (m *MockOutageManager) DeleteTriageNote(outageID, noteID uint, user string) error {
@@ -218,10 +259,16 @@ func (m *MockOutageManager) AddOutageLink(link *types.OutageLink, user string) e
 	return nil
 }
 
-func (m *MockOutageManager) UpdateOutageLink(outageID, linkID uint, url string, linkType types.LinkType, description, user string) (*types.OutageLink, error) {
+func (m *MockOutageManager) UpdateOutageLink(outageID, linkID uint, url string, linkType types.LinkType, description string, visibility types.Visibility, user string) (*types.OutageLink, error) {
 	return nil, nil
 }
 
 func (m *MockOutageManager) DeleteOutageLink(outageID, linkID uint, user string) error {
 	return nil
 }
+
+// WithDelegator captures the delegator and returns the mock itself.
+func (m *MockOutageManager) WithDelegator(delegator string) OutageManager {
+	m.Delegators = append(m.Delegators, delegator)
+	return m
+}
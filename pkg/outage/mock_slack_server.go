@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"sync"
 	"testing"
 
@@ -17,6 +18,21 @@ type PostedMessage struct {
 	Text            string
 	ThreadTimestamp string
 	ResponseTS      string
+	// Blocks is the raw JSON of the message's blocks, if any.
+	Blocks string
+}
+
+// EphemeralMessage represents a message shown to a single user through the mock Slack server.
+type EphemeralMessage struct {
+	Channel string
+	User    string
+	Text    string
+}
+
+// OpenedView represents a modal opened through the mock Slack server.
+type OpenedView struct {
+	TriggerID string
+	View      slack.ModalViewRequest
 }
 
 // AddedReaction represents a reaction added to a message in the mock Slack server.
@@ -26,15 +42,49 @@ type AddedReaction struct {
 	Name      string
 }
 
+// TopicChange represents a channel topic set through the mock Slack server.
+type TopicChange struct {
+	Channel string
+	Topic   string
+}
+
+// PinChange represents a message pinned or unpinned through the mock Slack server.
+type PinChange struct {
+	Channel   string
+	Timestamp string
+	Pinned    bool
+}
+
+// EditedMessage represents a message updated or deleted through the mock Slack server.
+type EditedMessage struct {
+	Channel   string
+	Timestamp string
+	Text      string
+	Deleted   bool
+}
+
 // MockSlackServer is a mock Slack API server for testing.
 type MockSlackServer struct {
 	server         *httptest.Server
 	client         *slack.Client
 	postedMsgs     []PostedMessage
 	addedReactions []AddedReaction
+	ephemeralMsgs  []EphemeralMessage
+	openedViews    []OpenedView
+	userEmails     map[string]string
+	channelIDs     map[string]string
+	topicChanges   []TopicChange
+	pinChanges     []PinChange
+	editedMsgs     []EditedMessage
 	mu             sync.Mutex
 	tsCounter      int64
 	baseTS         int64
+	// rateLimitedPosts is how many upcoming chat.postMessage calls are rejected with HTTP 429.
+	rateLimitedPosts int
+	retryAfter       string
+	rejectedPosts    int
+	// pinError, when set, is the error returned by pins.add.
+	pinError string
 }
 
 // NewMockSlackServer creates a new mock Slack API server for testing.
@@ -43,6 +93,8 @@ func NewMockSlackServer(t *testing.T) *MockSlackServer {
 	m := &MockSlackServer{
 		postedMsgs:     make([]PostedMessage, 0),
 		addedReactions: make([]AddedReaction, 0),
+		userEmails:     map[string]string{},
+		channelIDs:     map[string]string{},
 		baseTS:         1234567890,
 		tsCounter:      0,
 	}
@@ -54,6 +106,25 @@ func NewMockSlackServer(t *testing.T) *MockSlackServer {
 			return
 		}
 
+		// views.open is the only JSON-bodied method used; decode it before the form parsing below.
+		if r.URL.Path == "/api/views.open" {
+			var req struct {
+				TriggerID string                 `json:"trigger_id"`
+				View      slack.ModalViewRequest `json:"view"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("Failed to decode views.open request: %v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			m.mu.Lock()
+			m.openedViews = append(m.openedViews, OpenedView{TriggerID: req.TriggerID, View: req.View})
+			m.mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "view": map[string]interface{}{"id": "V1"}})
+			return
+		}
+
 		if err := r.ParseForm(); err != nil {
 			t.Errorf("Failed to parse form: %v", err)
 			w.WriteHeader(http.StatusBadRequest)
@@ -65,6 +136,13 @@ func NewMockSlackServer(t *testing.T) *MockSlackServer {
 
 		switch r.URL.Path {
 		case "/api/chat.postMessage":
+			if m.rateLimitedPosts > 0 {
+				m.rateLimitedPosts--
+				m.rejectedPosts++
+				w.Header().Set("Retry-After", m.retryAfter)
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
 			// Capture the posted message
 			m.tsCounter++
 			// Generate sequential timestamp: baseTS.000001, baseTS.000002, etc.
@@ -74,6 +152,7 @@ func NewMockSlackServer(t *testing.T) *MockSlackServer {
 				Text:            r.FormValue("text"),
 				ThreadTimestamp: r.FormValue("thread_ts"),
 				ResponseTS:      responseTS,
+				Blocks:          r.FormValue("blocks"),
 			}
 			m.postedMsgs = append(m.postedMsgs, msg)
 
@@ -102,6 +181,68 @@ func NewMockSlackServer(t *testing.T) *MockSlackServer {
 			w.Header().Set("Content-Type", "application/json")
 			json.NewEncoder(w).Encode(response)
 
+		case "/api/chat.postEphemeral":
+			m.ephemeralMsgs = append(m.ephemeralMsgs, EphemeralMessage{
+				Channel: r.FormValue("channel"),
+				User:    r.FormValue("user"),
+				Text:    r.FormValue("text"),
+			})
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "message_ts": "1234567890.000000"})
+
+		case "/api/conversations.list":
+			channels := make([]map[string]interface{}, 0, len(m.channelIDs))
+			for name, id := range m.channelIDs {
+				channels = append(channels, map[string]interface{}{"id": id, "name": name})
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "channels": channels})
+
+		case "/api/conversations.setTopic":
+			m.topicChanges = append(m.topicChanges, TopicChange{Channel: r.FormValue("channel"), Topic: r.FormValue("topic")})
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "channel": map[string]interface{}{"id": r.FormValue("channel")}})
+
+		case "/api/pins.add", "/api/pins.remove":
+			if r.URL.Path == "/api/pins.add" && m.pinError != "" {
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": m.pinError})
+				return
+			}
+			m.pinChanges = append(m.pinChanges, PinChange{
+				Channel:   r.FormValue("channel"),
+				Timestamp: r.FormValue("timestamp"),
+				Pinned:    r.URL.Path == "/api/pins.add",
+			})
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+
+		case "/api/chat.update", "/api/chat.delete":
+			m.editedMsgs = append(m.editedMsgs, EditedMessage{
+				Channel:   r.FormValue("channel"),
+				Timestamp: r.FormValue("ts"),
+				Text:      r.FormValue("text"),
+				Deleted:   r.URL.Path == "/api/chat.delete",
+			})
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "channel": r.FormValue("channel"), "ts": r.FormValue("ts")})
+
+		case "/api/users.info":
+			userID := r.FormValue("user")
+			w.Header().Set("Content-Type", "application/json")
+			email, ok := m.userEmails[userID]
+			if !ok {
+				json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "user_not_found"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"ok": true,
+				"user": map[string]interface{}{
+					"id":      userID,
+					"profile": map[string]interface{}{"email": email},
+				},
+			})
+
 		default:
 			t.Errorf("Unexpected API path: %s", r.URL.Path)
 			w.WriteHeader(http.StatusNotFound)
@@ -117,6 +258,56 @@ func (m *MockSlackServer) Client() *slack.Client {
 	return m.client
 }
 
+// RateLimitPosts makes the next count chat.postMessage calls fail with HTTP 429 and the given Retry-After seconds.
+func (m *MockSlackServer) RateLimitPosts(count, retryAfterSeconds int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rateLimitedPosts = count
+	m.retryAfter = strconv.Itoa(retryAfterSeconds)
+}
+
+// RejectedPosts returns how many chat.postMessage calls were rejected by RateLimitPosts.
+func (m *MockSlackServer) RejectedPosts() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rejectedPosts
+}
+
+// FailPins makes pins.add fail with the given Slack error, such as "not_in_channel".
+func (m *MockSlackServer) FailPins(slackError string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pinError = slackError
+}
+
+// AddChannel registers a channel returned by conversations.list.
+func (m *MockSlackServer) AddChannel(name, id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.channelIDs[name] = id
+}
+
+// TopicChanges returns all channel topics that were set.
+func (m *MockSlackServer) TopicChanges() []TopicChange {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]TopicChange(nil), m.topicChanges...)
+}
+
+// PinChanges returns all messages that were pinned or unpinned.
+func (m *MockSlackServer) PinChanges() []PinChange {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]PinChange(nil), m.pinChanges...)
+}
+
+// EditedMessages returns all messages that were updated or deleted.
+func (m *MockSlackServer) EditedMessages() []EditedMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]EditedMessage(nil), m.editedMsgs...)
+}
+
 // PostedMessages returns all messages that were posted to the mock server.
 func (m *MockSlackServer) PostedMessages() []PostedMessage {
 	m.mu.Lock()
@@ -135,6 +326,27 @@ func (m *MockSlackServer) AddedReactions() []AddedReaction {
 	return result
 }
 
+// EphemeralMessages returns all ephemeral messages that were posted to the mock server.
+func (m *MockSlackServer) EphemeralMessages() []EphemeralMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]EphemeralMessage(nil), m.ephemeralMsgs...)
+}
+
+// OpenedViews returns all modals that were opened through the mock server.
+func (m *MockSlackServer) OpenedViews() []OpenedView {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]OpenedView(nil), m.openedViews...)
+}
+
+// AddUser registers a Slack user with the given profile email for users.info.
+func (m *MockSlackServer) AddUser(userID, email string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.userEmails[userID] = email
+}
+
 // Close shuts down the mock server.
 func (m *MockSlackServer) Close() {
 	m.server.Close()
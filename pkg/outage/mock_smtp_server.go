@@ -0,0 +1,253 @@
+package outage
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ReceivedMail is a message accepted by the mock SMTP server.
+type ReceivedMail struct {
+	From string
+	To   []string
+	Data string
+	// TLS reports whether the message was sent after STARTTLS.
+	TLS bool
+	// AuthUser is the user that authenticated the session, if any.
+	AuthUser string
+}
+
+// MockSMTPOptions configures the mock SMTP server.
+type MockSMTPOptions struct {
+	// StartTLS makes the server offer STARTTLS with a self-signed certificate for 127.0.0.1.
+	StartTLS bool
+	// Username and Password make the server offer AUTH PLAIN and require it before MAIL.
+	Username string
+	Password string
+}
+
+// MockSMTPServer is a minimal SMTP server on 127.0.0.1 for testing email delivery.
+type MockSMTPServer struct {
+	listener  net.Listener
+	opts      MockSMTPOptions
+	tlsConfig *tls.Config
+	certPool  *x509.CertPool
+	mu        sync.Mutex
+	received  []ReceivedMail
+	wg        sync.WaitGroup
+}
+
+// NewMockSMTPServer starts a mock SMTP server. It is stopped when the test completes.
+func NewMockSMTPServer(t *testing.T, opts MockSMTPOptions) *MockSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen for mock SMTP server: %v", err)
+	}
+	m := &MockSMTPServer{listener: listener, opts: opts}
+	if opts.StartTLS {
+		m.tlsConfig, m.certPool = selfSignedTLSConfig(t)
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			m.wg.Add(1)
+			go func() {
+				defer m.wg.Done()
+				m.serve(conn)
+			}()
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+		m.wg.Wait()
+	})
+	return m
+}
+
+// Address returns the host:port the server listens on.
+func (m *MockSMTPServer) Address() string {
+	return m.listener.Addr().String()
+}
+
+// ClientTLSConfig returns a client TLS configuration that trusts the server's certificate.
+func (m *MockSMTPServer) ClientTLSConfig() *tls.Config {
+	return &tls.Config{RootCAs: m.certPool, MinVersion: tls.VersionTLS12}
+}
+
+// Received returns the messages accepted so far.
+func (m *MockSMTPServer) Received() []ReceivedMail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ReceivedMail(nil), m.received...)
+}
+
+func (m *MockSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	text := textproto.NewConn(conn)
+	reply := func(format string, args ...any) bool {
+		return text.PrintfLine(format, args...) == nil
+	}
+
+	var mail ReceivedMail
+	isTLS := false
+	authUser := ""
+	if !reply("220 mock ESMTP ready") {
+		return
+	}
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			extensions := []string{"mock"}
+			if m.tlsConfig != nil && !isTLS {
+				extensions = append(extensions, "STARTTLS")
+			}
+			if m.opts.Username != "" {
+				extensions = append(extensions, "AUTH PLAIN")
+			}
+			extensions = append(extensions, "8BITMIME")
+			for i, ext := range extensions {
+				sep := "-"
+				if i == len(extensions)-1 {
+					sep = " "
+				}
+				reply("250%s%s", sep, ext)
+			}
+		case "STARTTLS":
+			if m.tlsConfig == nil || isTLS {
+				reply("502 STARTTLS not available")
+				continue
+			}
+			reply("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, m.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			text = textproto.NewConn(tlsConn)
+			isTLS = true
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			if !strings.EqualFold(mechanism, "PLAIN") {
+				reply("504 Unrecognized authentication type")
+				continue
+			}
+			decoded, err := base64.StdEncoding.DecodeString(initial)
+			parts := strings.Split(string(decoded), "\x00")
+			if err != nil || len(parts) != 3 || parts[1] != m.opts.Username || parts[2] != m.opts.Password {
+				reply("535 Authentication credentials invalid")
+				continue
+			}
+			authUser = parts[1]
+			reply("235 Authentication successful")
+		case "MAIL":
+			if m.opts.Username != "" && authUser == "" {
+				reply("530 Authentication required")
+				continue
+			}
+			mail = ReceivedMail{From: extractSMTPPath(arg), TLS: isTLS, AuthUser: authUser}
+			reply("250 OK")
+		case "RCPT":
+			mail.To = append(mail.To, extractSMTPPath(arg))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := readDotLines(text.Reader.R)
+			if err != nil {
+				return
+			}
+			mail.Data = data
+			m.mu.Lock()
+			m.received = append(m.received, mail)
+			m.mu.Unlock()
+			reply("250 OK")
+		case "RSET", "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// readDotLines reads an SMTP DATA section, keeping CRLF line endings and undoing dot-stuffing.
+func readDotLines(r *bufio.Reader) (string, error) {
+	var b strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		if line == ".\r\n" {
+			return b.String(), nil
+		}
+		b.WriteString(strings.TrimPrefix(line, "."))
+	}
+}
+
+func extractSMTPPath(arg string) string {
+	_, path, _ := strings.Cut(arg, ":")
+	// Drop ESMTP parameters such as BODY=8BITMIME.
+	path, _, _ = strings.Cut(strings.TrimSpace(path), " ")
+	return strings.Trim(path, "<>")
+}
+
+func selfSignedTLSConfig(t *testing.T) (*tls.Config, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mock-smtp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS12,
+	}, pool
+}
@@ -0,0 +1,185 @@
+package outage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/types"
+)
+
+// Notifier delivers outage lifecycle events to an external system such as a chat tool.
+// Implementations decide from configuration which destinations, if any, hear about an outage.
+// DBOutageManager calls notifiers after the change is committed and only logs their errors.
+type Notifier interface {
+	// Name identifies the notifier in logs.
+	Name() string
+	OutageCreated(outage *types.Outage) error
+	// OutageUpdated is called for every change except the one that resolves the outage.
+	OutageUpdated(outage, oldOutage *types.Outage) error
+	OutageResolved(outage, oldOutage *types.Outage) error
+}
+
+// notifierHTTPTimeout bounds each webhook delivery so a slow endpoint cannot hold up the notifier's queue for long.
+const notifierHTTPTimeout = 10 * time.Second
+
+// postJSON POSTs payload as JSON to url and treats any non-2xx response as a failure.
+func postJSON(client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// meetsSeverityThreshold reports whether severity is at or above threshold. A nil or empty threshold
+// accepts every severity, matching the slack_reporting default.
+func meetsSeverityThreshold(severity types.Severity, threshold *types.Severity) bool {
+	if threshold == nil || *threshold == "" {
+		return true
+	}
+	return types.GetSeverityLevel(severity) >= types.GetSeverityLevel(*threshold)
+}
+
+// notificationFact is a labelled value shown alongside a notification, e.g. Severity: Down.
+type notificationFact struct {
+	Name  string
+	Value string
+}
+
+// outageNotification is the chat-tool-neutral content of an outage notification.
+// Notifiers other than Slack render it into their own message format.
+type outageNotification struct {
+	Title string
+	Facts []notificationFact
+	// Lines are free-text lines: the description of a new outage or the changes of an update.
+	Lines []string
+	URL   string
+}
+
+// resolveDisplayNames returns the configured display names of the outage's component and sub-component,
+// falling back to the slugs when they are no longer configured.
+func resolveDisplayNames(configManager *config.Manager[types.DashboardConfig], outage *types.Outage) (string, string) {
+	componentName, subComponentName := outage.ComponentName, outage.SubComponentName
+	if component := configManager.Get().GetComponentBySlug(outage.ComponentName); component != nil {
+		componentName = component.Name
+		if sub := component.GetSubComponentBySlug(outage.SubComponentName); sub != nil {
+			subComponentName = sub.Name
+		}
+	}
+	return componentName, subComponentName
+}
+
+func buildCreatedNotification(configManager *config.Manager[types.DashboardConfig], baseURL string, outage *types.Outage) outageNotification {
+	componentName, subComponentName := resolveDisplayNames(configManager, outage)
+	n := outageNotification{
+		Title: fmt.Sprintf("Outage Detected: %s/%s", componentName, subComponentName),
+		Facts: []notificationFact{
+			{Name: "Severity", Value: string(outage.Severity)},
+			{Name: "Started", Value: outage.StartTime.Format(time.RFC3339)},
+			{Name: "Created by", Value: outage.CreatedBy},
+		},
+		URL: buildOutageURL(baseURL, outage),
+	}
+	if outage.DiscoveredFrom != "" {
+		n.Facts = append(n.Facts, notificationFact{Name: "Discovered from", Value: outage.DiscoveredFrom})
+	}
+	if outage.Description != "" {
+		n.Lines = []string{truncateString(outage.Description)}
+	}
+	return n
+}
+
+func buildUpdateNotification(configManager *config.Manager[types.DashboardConfig], baseURL string, outage, oldOutage *types.Outage) outageNotification {
+	componentName, subComponentName := resolveDisplayNames(configManager, outage)
+	verb := "Updated"
+	if !oldOutage.EndTime.Valid && outage.EndTime.Valid {
+		verb = "Resolved"
+	}
+	return outageNotification{
+		Title: fmt.Sprintf("Outage %s: %s/%s (#%d)", verb, componentName, subComponentName, outage.ID),
+		Facts: []notificationFact{{Name: "Severity", Value: string(outage.Severity)}},
+		Lines: describeOutageChanges(outage, oldOutage),
+		URL:   buildOutageURL(baseURL, outage),
+	}
+}
+
+// buildNotification builds the notification of a new outage when oldOutage is nil, and of an update otherwise.
+func buildNotification(configManager *config.Manager[types.DashboardConfig], baseURL string, outage, oldOutage *types.Outage) outageNotification {
+	if oldOutage == nil {
+		return buildCreatedNotification(configManager, baseURL, outage)
+	}
+	return buildUpdateNotification(configManager, baseURL, outage, oldOutage)
+}
+
+// outageViews returns the outage and its previous state as a destination of visibility may see them, and whether
+// the destination should hear of the change: public destinations are not told of updates that only touched
+// internal items. oldOutage is nil for a new outage.
+func outageViews(visibility types.Visibility, outage, oldOutage *types.Outage) (*types.Outage, *types.Outage, bool) {
+	if visibility.IsInternal() {
+		return outage, oldOutage, true
+	}
+	public := outage.PublicView()
+	if oldOutage == nil {
+		return &public, nil, true
+	}
+	publicOld := oldOutage.PublicView()
+	visible := len(outageChanges(&public, &publicOld)) > 0 || len(outageChanges(outage, oldOutage)) == 0
+	return &public, &publicOld, visible
+}
+
+// describeOutageChanges lists the user-visible differences between two states of an outage as plain text.
+func describeOutageChanges(outage, oldOutage *types.Outage) []string {
+	changes := outageChanges(outage, oldOutage)
+	if len(changes) == 0 {
+		changes = append(changes, "Outage updated")
+	}
+	return changes
+}
+
+// outageChanges lists the differences describeOutageChanges reports, without its fallback for other changes.
+func outageChanges(outage, oldOutage *types.Outage) []string {
+	var changes []string
+	if oldOutage.Severity != outage.Severity {
+		changes = append(changes, fmt.Sprintf("Severity changed: %s → %s", oldOutage.Severity, outage.Severity))
+	}
+	if oldOutage.EndTime.Valid != outage.EndTime.Valid {
+		if outage.EndTime.Valid {
+			changes = append(changes, fmt.Sprintf("Resolved at %s", outage.EndTime.Time.Format(time.RFC3339)))
+		} else {
+			changes = append(changes, "Reopened")
+		}
+	} else if oldOutage.EndTime.Valid && outage.EndTime.Valid && !oldOutage.EndTime.Time.Equal(outage.EndTime.Time) {
+		changes = append(changes, fmt.Sprintf("Resolved time updated: %s", outage.EndTime.Time.Format(time.RFC3339)))
+	}
+	if oldOutage.ConfirmedAt.Valid != outage.ConfirmedAt.Valid {
+		if outage.ConfirmedAt.Valid {
+			changes = append(changes, fmt.Sprintf("Confirmed at %s", outage.ConfirmedAt.Time.Format(time.RFC3339)))
+		} else {
+			changes = append(changes, "Unconfirmed")
+		}
+	}
+	if oldOutage.Description != outage.Description {
+		changes = append(changes, "Description updated: "+truncateString(outage.Description))
+	}
+	if len(outage.TriageNotes) > len(oldOutage.TriageNotes) {
+		note := outage.TriageNotes[len(outage.TriageNotes)-1]
+		changes = append(changes, fmt.Sprintf("Triage note from %s: %s", note.Author, truncateString(note.Body)))
+	}
+	if len(outage.Links) > len(oldOutage.Links) {
+		link := outage.Links[len(outage.Links)-1]
+		changes = append(changes, fmt.Sprintf("Link added: %s", link.URL))
+	}
+	return changes
+}
@@ -0,0 +1,123 @@
+package outage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"ship-status-dash/pkg/types"
+)
+
+// DefaultNotifierQueueSize is the number of events a NotifierQueue holds when its size is unset.
+const DefaultNotifierQueueSize = 1000
+
+type notifierEventKind int
+
+const (
+	notifierEventCreated notifierEventKind = iota
+	notifierEventUpdated
+	notifierEventResolved
+)
+
+// notifierEvent is a queued outage event. oldOutage is nil for a new outage.
+type notifierEvent struct {
+	kind      notifierEventKind
+	outage    *types.Outage
+	oldOutage *types.Outage
+}
+
+// NotifierQueue delivers the events of a Notifier from its own background worker, so outage changes never wait
+// on the notifier's endpoint and a slow notifier does not delay the others.
+type NotifierQueue struct {
+	notifier Notifier
+	events   chan notifierEvent
+	logger   *logrus.Logger
+}
+
+// NewNotifierQueue creates a NotifierQueue that delivers to notifier and holds up to size events.
+func NewNotifierQueue(notifier Notifier, size int, logger *logrus.Logger) *NotifierQueue {
+	if size <= 0 {
+		size = DefaultNotifierQueueSize
+	}
+	return &NotifierQueue{
+		notifier: notifier,
+		events:   make(chan notifierEvent, size),
+		logger:   logger,
+	}
+}
+
+func (q *NotifierQueue) Name() string {
+	return q.notifier.Name()
+}
+
+// OutageCreated implements Notifier by queueing the event for the notifier.
+func (q *NotifierQueue) OutageCreated(outage *types.Outage) error {
+	return q.enqueue(notifierEventCreated, outage, nil)
+}
+
+// OutageUpdated implements Notifier by queueing the event for the notifier.
+func (q *NotifierQueue) OutageUpdated(outage, oldOutage *types.Outage) error {
+	return q.enqueue(notifierEventUpdated, outage, oldOutage)
+}
+
+// OutageResolved implements Notifier by queueing the event for the notifier.
+func (q *NotifierQueue) OutageResolved(outage, oldOutage *types.Outage) error {
+	return q.enqueue(notifierEventResolved, outage, oldOutage)
+}
+
+// enqueue copies the outages, as callers may keep changing them, and hands them to the worker without blocking.
+func (q *NotifierQueue) enqueue(kind notifierEventKind, outage, oldOutage *types.Outage) error {
+	event := notifierEvent{kind: kind, outage: copyOutage(outage), oldOutage: copyOutage(oldOutage)}
+	select {
+	case q.events <- event:
+		return nil
+	default:
+		return fmt.Errorf("%s notification queue is full", q.notifier.Name())
+	}
+}
+
+// Start delivers queued events until ctx is done, then delivers the events still queued.
+func (q *NotifierQueue) Start(ctx context.Context) {
+	q.logger.WithField("notifier", q.notifier.Name()).Info("Starting notifier queue")
+	for {
+		select {
+		case <-ctx.Done():
+			q.drain()
+			q.logger.WithField("notifier", q.notifier.Name()).Info("Stopping notifier queue")
+			return
+		case event := <-q.events:
+			q.deliver(event)
+		}
+	}
+}
+
+func (q *NotifierQueue) drain() {
+	for {
+		select {
+		case event := <-q.events:
+			q.deliver(event)
+		default:
+			return
+		}
+	}
+}
+
+func (q *NotifierQueue) deliver(event notifierEvent) {
+	var err error
+	switch event.kind {
+	case notifierEventCreated:
+		err = q.notifier.OutageCreated(event.outage)
+	case notifierEventUpdated:
+		err = q.notifier.OutageUpdated(event.outage, event.oldOutage)
+	case notifierEventResolved:
+		err = q.notifier.OutageResolved(event.outage, event.oldOutage)
+	}
+	if err != nil {
+		q.logger.WithFields(logrus.Fields{
+			"outage_id": event.outage.ID,
+			"notifier":  q.notifier.Name(),
+			"error":     err,
+		}).Error("Failed to deliver outage notification")
+	}
+}
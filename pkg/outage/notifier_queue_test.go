@@ -0,0 +1,71 @@
+package outage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ship-status-dash/pkg/types"
+)
+
+func TestNotifierQueue(t *testing.T) {
+	notifier := &recordingNotifier{}
+	queue := NewNotifierQueue(notifier, 3, logrus.New())
+
+	outage := notifierTestOutage()
+	require.NoError(t, queue.OutageCreated(outage))
+	require.NoError(t, queue.OutageUpdated(outage, outage))
+	require.NoError(t, queue.OutageResolved(outage, outage))
+	assert.EqualError(t, queue.OutageUpdated(outage, outage), "recording notification queue is full")
+	assert.Empty(t, notifier.events, "nothing is delivered from the caller")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	queue.Start(ctx)
+	assert.Equal(t, []string{"created", "updated", "resolved"}, notifier.events)
+}
+
+func TestNewDBOutageManager_ChatNotifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		component *types.Component
+		want      []string
+	}{
+		{
+			name:      "no chat reporting yet",
+			component: &types.Component{Slug: "c", Subcomponents: []types.SubComponent{{Slug: "s"}}},
+			want:      []string{"teams", "webhook"},
+		},
+		{
+			name: "teams on the component",
+			component: &types.Component{
+				Slug:           "c",
+				TeamsReporting: []types.TeamsReportingConfig{{WebhookURL: "https://teams.example.com/hook"}},
+				Subcomponents:  []types.SubComponent{{Slug: "s"}},
+			},
+			want: []string{"teams", "webhook"},
+		},
+		{
+			name: "webhook on a sub-component",
+			component: &types.Component{
+				Slug:          "c",
+				Subcomponents: []types.SubComponent{{Slug: "s", WebhookReporting: []types.WebhookReportingConfig{{URL: "https://chat.example.com/hook"}}}},
+			},
+			want: []string{"teams", "webhook"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &types.DashboardConfig{Components: []*types.Component{tt.component}}
+			manager := NewDBOutageManager(setupTestDB(t), nil, newNotifierTestConfigManager(t, cfg), "https://test.example.com/", "", logrus.New())
+			var names []string
+			for _, n := range manager.notifiers {
+				names = append(names, n.Name())
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
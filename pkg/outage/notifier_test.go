@@ -0,0 +1,363 @@
+package outage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/types"
+)
+
+// webhookRecorder is an httptest server that records the JSON bodies posted to each path.
+type webhookRecorder struct {
+	server *httptest.Server
+	mu     sync.Mutex
+	bodies map[string][]map[string]any
+}
+
+func newWebhookRecorder(t *testing.T) *webhookRecorder {
+	t.Helper()
+	rec := &webhookRecorder{bodies: map[string][]map[string]any{}}
+	rec.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		rec.mu.Lock()
+		rec.bodies[r.URL.Path] = append(rec.bodies[r.URL.Path], body)
+		rec.mu.Unlock()
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(rec.server.Close)
+	return rec
+}
+
+func (r *webhookRecorder) posted(path string) []map[string]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bodies[path]
+}
+
+func newNotifierTestConfigManager(t *testing.T, cfg *types.DashboardConfig) *config.Manager[types.DashboardConfig] {
+	t.Helper()
+	cfgManager, err := config.NewManager("", func(string) (*types.DashboardConfig, error) {
+		return cfg, nil
+	}, logrus.New(), time.Second)
+	require.NoError(t, err)
+	cfgManager.Get()
+	return cfgManager
+}
+
+func notifierTestOutage() *types.Outage {
+	return &types.Outage{
+		Model:            gorm.Model{ID: 3},
+		ComponentName:    "test-component",
+		SubComponentName: "test-sub",
+		Severity:         types.SeverityDegraded,
+		StartTime:        time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Description:      "Builds are slow",
+		CreatedBy:        "alice",
+		DiscoveredFrom:   "frontend",
+	}
+}
+
+func TestTeamsNotifier(t *testing.T) {
+	rec := newWebhookRecorder(t)
+	down := types.SeverityDown
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug: "test-component",
+				Name: "Test Component",
+				TeamsReporting: []types.TeamsReportingConfig{
+					{WebhookURL: rec.server.URL + "/component"},
+				},
+				Subcomponents: []types.SubComponent{
+					{
+						Slug: "test-sub",
+						Name: "Test Sub",
+						TeamsReporting: []types.TeamsReportingConfig{
+							{WebhookURL: rec.server.URL + "/all"},
+							{WebhookURL: rec.server.URL + "/down-only", Severity: &down},
+						},
+					},
+				},
+			},
+		},
+	}
+	n := NewTeamsNotifier(newNotifierTestConfigManager(t, cfg), "https://test.example.com", logrus.New())
+
+	outage := notifierTestOutage()
+	require.NoError(t, n.OutageCreated(outage))
+	assert.Empty(t, rec.posted("/component"), "sub-component configuration overrides the component's")
+	assert.Empty(t, rec.posted("/down-only"), "Degraded is below the Down threshold")
+	require.Len(t, rec.posted("/all"), 1)
+
+	want := map[string]any{
+		"type": "message",
+		"attachments": []any{
+			map[string]any{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]any{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body": []any{
+						map[string]any{"type": "TextBlock", "text": "Outage Detected: Test Component/Test Sub", "weight": "Bolder", "size": "Medium", "wrap": true},
+						map[string]any{"type": "FactSet", "facts": []any{
+							map[string]any{"title": "Severity", "value": "Degraded"},
+							map[string]any{"title": "Started", "value": "2024-01-15T10:30:00Z"},
+							map[string]any{"title": "Created by", "value": "alice"},
+							map[string]any{"title": "Discovered from", "value": "frontend"},
+						}},
+						map[string]any{"type": "TextBlock", "text": "Builds are slow", "wrap": true},
+					},
+					"actions": []any{
+						map[string]any{"type": "Action.OpenUrl", "title": "View Outage", "url": "https://test.example.com/test-component/test-sub/outages/3"},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, rec.posted("/all")[0]); diff != "" {
+		t.Errorf("Teams card mismatch (-want +got):\n%s", diff)
+	}
+
+	old := *outage
+	outage.Severity = types.SeverityDown
+	require.NoError(t, n.OutageUpdated(outage, &old))
+	require.Len(t, rec.posted("/down-only"), 1)
+
+	// Downgrades still reach the channel that saw the Down outage.
+	old = *outage
+	outage.Severity = types.SeverityDegraded
+	outage.EndTime = sql.NullTime{Time: time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), Valid: true}
+	require.NoError(t, n.OutageResolved(outage, &old))
+	resolved := rec.posted("/down-only")
+	require.Len(t, resolved, 2)
+	body := resolved[1]["attachments"].([]any)[0].(map[string]any)["content"].(map[string]any)["body"].([]any)
+	assert.Equal(t, "Outage Resolved: Test Component/Test Sub (#3)", body[0].(map[string]any)["text"])
+	assert.Equal(t, "Severity changed: Down → Degraded", body[2].(map[string]any)["text"])
+	assert.Equal(t, "Resolved at 2024-01-15T12:00:00Z", body[3].(map[string]any)["text"])
+}
+
+func TestChatWebhookNotifier(t *testing.T) {
+	rec := newWebhookRecorder(t)
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug: "test-component",
+				Name: "Test Component",
+				WebhookReporting: []types.WebhookReportingConfig{
+					{URL: rec.server.URL + "/mattermost"},
+					{URL: rec.server.URL + "/gchat", Flavor: types.WebhookFlavorGoogleChat},
+					{URL: rec.server.URL + "/fail"},
+				},
+				Subcomponents: []types.SubComponent{
+					{Slug: "test-sub", Name: "Test Sub"},
+				},
+			},
+		},
+	}
+	n := NewChatWebhookNotifier(newNotifierTestConfigManager(t, cfg), "https://test.example.com/", logrus.New())
+
+	outage := notifierTestOutage()
+	err := n.OutageCreated(outage)
+	assert.EqualError(t, err, "webhook returned status 500", "a failing webhook is reported without stopping the others")
+
+	wantMattermost := "**Outage Detected: Test Component/Test Sub**\n\nSeverity: Degraded\nStarted: 2024-01-15T10:30:00Z\nCreated by: alice\nDiscovered from: frontend\n\nBuilds are slow\n\n[View Outage](https://test.example.com/test-component/test-sub/outages/3)"
+	require.Len(t, rec.posted("/mattermost"), 1)
+	assert.Equal(t, wantMattermost, rec.posted("/mattermost")[0]["text"])
+
+	wantGoogleChat := "*Outage Detected: Test Component/Test Sub*\n\nSeverity: Degraded\nStarted: 2024-01-15T10:30:00Z\nCreated by: alice\nDiscovered from: frontend\n\nBuilds are slow\n\n<https://test.example.com/test-component/test-sub/outages/3|View Outage>"
+	require.Len(t, rec.posted("/gchat"), 1)
+	assert.Equal(t, wantGoogleChat, rec.posted("/gchat")[0]["text"])
+
+	old := *outage
+	outage.TriageNotes = []types.TriageNote{{Author: "bob", Body: "rolling back"}}
+	assert.Error(t, n.OutageUpdated(outage, &old))
+	require.Len(t, rec.posted("/mattermost"), 2)
+	wantUpdate := "**Outage Updated: Test Component/Test Sub (#3)**\n\nSeverity: Degraded\n\nTriage note from bob: rolling back\n\n[View Outage](https://test.example.com/test-component/test-sub/outages/3)"
+	assert.Equal(t, wantUpdate, rec.posted("/mattermost")[1]["text"])
+}
+
+func TestChatWebhookNotifier_Visibility(t *testing.T) {
+	rec := newWebhookRecorder(t)
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug: "test-component",
+				Name: "Test Component",
+				WebhookReporting: []types.WebhookReportingConfig{
+					{URL: rec.server.URL + "/public"},
+					{URL: rec.server.URL + "/internal", Visibility: types.VisibilityInternal},
+				},
+				Subcomponents: []types.SubComponent{{Slug: "test-sub", Name: "Test Sub"}},
+			},
+		},
+	}
+	n := NewChatWebhookNotifier(newNotifierTestConfigManager(t, cfg), "https://test.example.com/", logrus.New())
+
+	outage := notifierTestOutage()
+	outage.DescriptionVisibility = types.VisibilityInternal
+	require.NoError(t, n.OutageCreated(outage))
+	require.Len(t, rec.posted("/public"), 1)
+	assert.NotContains(t, rec.posted("/public")[0]["text"], "Builds are slow")
+	require.Len(t, rec.posted("/internal"), 1)
+	assert.Contains(t, rec.posted("/internal")[0]["text"], "Builds are slow")
+
+	old := *outage
+	outage.TriageNotes = []types.TriageNote{{Author: "bob", Body: "customer X is affected", Visibility: types.VisibilityInternal}}
+	require.NoError(t, n.OutageUpdated(outage, &old))
+	assert.Len(t, rec.posted("/public"), 1, "public webhooks hear nothing of updates to internal items")
+	require.Len(t, rec.posted("/internal"), 2)
+	assert.Contains(t, rec.posted("/internal")[1]["text"], "Triage note from bob: customer X is affected")
+
+	old = *outage
+	outage.TriageNotes = append(outage.TriageNotes, types.TriageNote{Author: "bob", Body: "rolling back", Visibility: types.VisibilityPublic})
+	require.NoError(t, n.OutageUpdated(outage, &old))
+	require.Len(t, rec.posted("/public"), 2)
+	assert.Contains(t, rec.posted("/public")[1]["text"], "Triage note from bob: rolling back")
+}
+
+func TestTeamsNotifier_Visibility(t *testing.T) {
+	rec := newWebhookRecorder(t)
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug: "test-component",
+				Name: "Test Component",
+				TeamsReporting: []types.TeamsReportingConfig{
+					{WebhookURL: rec.server.URL + "/public"},
+					{WebhookURL: rec.server.URL + "/internal", Visibility: types.VisibilityInternal},
+				},
+				Subcomponents: []types.SubComponent{{Slug: "test-sub", Name: "Test Sub"}},
+			},
+		},
+	}
+	n := NewTeamsNotifier(newNotifierTestConfigManager(t, cfg), "https://test.example.com", logrus.New())
+
+	outage := notifierTestOutage()
+	old := *outage
+	outage.Description = "bad deploy of the auth proxy"
+	outage.DescriptionVisibility = types.VisibilityInternal
+	require.NoError(t, n.OutageUpdated(outage, &old))
+
+	card := func(path string) string {
+		posted := rec.posted(path)
+		require.Len(t, posted, 1)
+		data, err := json.Marshal(posted[0])
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.NotContains(t, card("/public"), "auth proxy")
+	assert.Contains(t, card("/internal"), "Description updated: bad deploy of the auth proxy")
+}
+
+// recordingNotifier records which Notifier method was called for each outage event.
+type recordingNotifier struct {
+	events []string
+}
+
+func (n *recordingNotifier) Name() string { return "recording" }
+
+func (n *recordingNotifier) OutageCreated(outage *types.Outage) error {
+	n.events = append(n.events, "created")
+	return nil
+}
+
+func (n *recordingNotifier) OutageUpdated(outage, oldOutage *types.Outage) error {
+	n.events = append(n.events, "updated")
+	return nil
+}
+
+func (n *recordingNotifier) OutageResolved(outage, oldOutage *types.Outage) error {
+	n.events = append(n.events, "resolved")
+	return nil
+}
+
+func TestDBOutageManager_Notifiers(t *testing.T) {
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug:          "test-component",
+				Name:          "Test Component",
+				Subcomponents: []types.SubComponent{{Slug: "test-sub", Name: "Test Sub"}},
+			},
+		},
+	}
+	db := setupTestDB(t)
+	manager := NewDBOutageManager(db, nil, newNotifierTestConfigManager(t, cfg), "https://test.example.com/", "", logrus.New())
+	notifier := &recordingNotifier{}
+	manager.AddNotifier(notifier)
+
+	outage := notifierTestOutage()
+	outage.ID = 0
+	require.NoError(t, manager.CreateOutage(outage, nil, "alice", ""))
+
+	outage.Severity = types.SeverityDown
+	require.NoError(t, manager.UpdateOutage(outage, "alice"))
+
+	require.NoError(t, manager.AddTriageNote(&types.TriageNote{OutageID: outage.ID, Body: "looking", Author: "alice"}))
+
+	outage.EndTime = sql.NullTime{Time: time.Now(), Valid: true}
+	require.NoError(t, manager.UpdateOutage(outage, "alice"))
+
+	outage.Description = "post-mortem pending"
+	require.NoError(t, manager.UpdateOutage(outage, "alice"))
+
+	assert.Equal(t, []string{"created", "updated", "updated", "resolved", "updated"}, notifier.events)
+}
+
+func TestDBOutageManager_TeamsReportingAddedOnReload(t *testing.T) {
+	rec := newWebhookRecorder(t)
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("without teams"), 0o600))
+	cfgManager, err := config.NewManager(configPath, func(path string) (*types.DashboardConfig, error) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sub := types.SubComponent{Slug: "test-sub", Name: "Test Sub"}
+		if string(content) == "with teams" {
+			sub.TeamsReporting = []types.TeamsReportingConfig{{WebhookURL: rec.server.URL + "/teams"}}
+		}
+		return &types.DashboardConfig{
+			Components: []*types.Component{{Slug: "test-component", Name: "Test Component", Subcomponents: []types.SubComponent{sub}}},
+		}, nil
+	}, logrus.New(), 10*time.Millisecond)
+	require.NoError(t, err)
+	reloaded := make(chan struct{}, 1)
+	cfgManager.OnUpdate(func(*types.DashboardConfig) { reloaded <- struct{}{} })
+
+	manager := NewDBOutageManager(setupTestDB(t), nil, cfgManager, "https://test.example.com/", "", logrus.New())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, cfgManager.Watch(ctx))
+	require.NoError(t, os.WriteFile(configPath, []byte("with teams"), 0o600))
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+
+	outage := notifierTestOutage()
+	outage.ID = 0
+	require.NoError(t, manager.CreateOutage(outage, nil, "alice", ""))
+	assert.Len(t, rec.posted("/teams"), 1, "a Teams destination added after startup must be notified")
+}
@@ -1,6 +1,7 @@
 package outage
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -42,23 +43,30 @@ type OutageManager interface {
 	GetActiveSuspectedOutages(componentSlug, subComponentSlug string) ([]types.Outage, error)
 	GetActiveSuspectedOutagesForComponent(componentSlug string) ([]types.Outage, error)
 	GetStaleSuspectedOutages(cutoff time.Time) ([]types.Outage, error)
+	GetOutagesCreatedBy(createdBy string, since time.Time) ([]types.Outage, error)
+	GetOutagesReportedBy(user string, since time.Time) ([]types.Outage, error)
 	GetOutageAuditLogs(outageID uint) ([]types.OutageAuditLog, error)
+	GetAuditLogs(filter types.AuditLogFilter) ([]types.AuditLogEntry, error)
 	DeleteOutage(outage *types.Outage, user string) error
 	ReportSuspectedOutage(componentSlug, subComponentSlug, description, user string, threshold int) (*ReportResult, error)
 
 	AddTriageNote(note *types.TriageNote) error
-	UpdateTriageNote(outageID, noteID uint, body, user string) (*types.TriageNote, error)
+	UpdateTriageNote(outageID, noteID uint, body string, visibility types.Visibility, user string) (*types.TriageNote, error)
 	DeleteTriageNote(outageID, noteID uint, user string) error
 	AddOutageLink(link *types.OutageLink, user string) error
-	UpdateOutageLink(outageID, linkID uint, url string, linkType types.LinkType, description, user string) (*types.OutageLink, error)
+	UpdateOutageLink(outageID, linkID uint, url string, linkType types.LinkType, description string, visibility types.Visibility, user string) (*types.OutageLink, error)
 	DeleteOutageLink(outageID, linkID uint, user string) error
+
+	// WithDelegator returns a manager that records delegator in the audit logs of the changes it makes.
+	WithDelegator(delegator string) OutageManager
 }
 
-// DBOutageManager implements OutageManager with PostgreSQL persistence and optional Slack reporting.
+// DBOutageManager implements OutageManager with PostgreSQL persistence and notification of outage changes
+// through its Notifiers.
 type DBOutageManager struct {
 	slackThreadRepo repositories.SlackThreadRepository
 	db              *gorm.DB
-	slackReporter   *SlackReporter
+	notifiers       []Notifier
 	logger          *logrus.Logger
 }
 
@@ -71,24 +79,141 @@ func NewDBOutageManager(
 	logger *logrus.Logger,
 ) *DBOutageManager {
 	slackThreadRepo := repositories.NewGORMSlackThreadRepository(db)
-	var slackReporter *SlackReporter
+	var notifiers []Notifier
 	if slackClient != nil {
-		slackReporter = NewSlackReporter(slackClient, slackThreadRepo, configManager, baseURL, slackWorkspaceURL, logger)
+		notifiers = append(notifiers, NewSlackReporter(slackClient, slackThreadRepo, configManager, baseURL, slackWorkspaceURL, logger))
 	}
+	// Both look up their destinations on every event, so entries added on config reload are notified too.
+	notifiers = append(notifiers, NewTeamsNotifier(configManager, baseURL, logger), NewChatWebhookNotifier(configManager, baseURL, logger))
 
 	return &DBOutageManager{
 		slackThreadRepo: slackThreadRepo,
 		db:              db,
-		slackReporter:   slackReporter,
+		notifiers:       notifiers,
 		logger:          logger,
 	}
 }
 
+// WithDelegator returns a copy of the manager whose database context carries delegator to the audit logs.
+func (m *DBOutageManager) WithDelegator(delegator string) OutageManager {
+	delegated := *m
+	delegated.db = m.db.WithContext(context.WithValue(m.db.Statement.Context, types.CurrentDelegatorKey, delegator))
+	return &delegated
+}
+
+// AddNotifier registers an additional Notifier for outage lifecycle events.
+func (m *DBOutageManager) AddNotifier(n Notifier) {
+	m.notifiers = append(m.notifiers, n)
+}
+
+// suspectedOutageNotifier is implemented by notifiers that also hear of suspected outages. Other notifiers hear of
+// a suspected outage only once it is confirmed, as a new outage.
+type suspectedOutageNotifier interface {
+	notifiesSuspectedOutages()
+}
+
+func notifiesSuspectedOutages(n Notifier) bool {
+	if queue, ok := n.(*NotifierQueue); ok {
+		n = queue.notifier
+	}
+	_, ok := n.(suspectedOutageNotifier)
+	return ok
+}
+
+// splitNotifiers returns the notifiers that hear of suspected outages and the ones that do not.
+func (m *DBOutageManager) splitNotifiers() (suspected, confirmed []Notifier) {
+	for _, n := range m.notifiers {
+		if notifiesSuspectedOutages(n) {
+			suspected = append(suspected, n)
+		} else {
+			confirmed = append(confirmed, n)
+		}
+	}
+	return suspected, confirmed
+}
+
+// notifyCreated sends a new outage to notifiers. Failures are logged, never returned, so that
+// notification problems cannot fail a change that has already been committed.
+func (m *DBOutageManager) notifyCreated(notifiers []Notifier, outage *types.Outage) {
+	for _, n := range notifiers {
+		if err := n.OutageCreated(outage); err != nil {
+			m.logger.WithFields(logrus.Fields{
+				"outage_id": outage.ID,
+				"notifier":  n.Name(),
+				"error":     err,
+			}).Error("Failed to notify outage creation")
+		}
+	}
+}
+
+// notifyUpdated sends an outage change to notifiers, as a resolution when it ended the outage.
+func (m *DBOutageManager) notifyUpdated(notifiers []Notifier, outage, oldOutage *types.Outage) {
+	resolved := !oldOutage.EndTime.Valid && outage.EndTime.Valid
+	for _, n := range notifiers {
+		var err error
+		if resolved {
+			err = n.OutageResolved(outage, oldOutage)
+		} else {
+			err = n.OutageUpdated(outage, oldOutage)
+		}
+		if err != nil {
+			m.logger.WithFields(logrus.Fields{
+				"outage_id": outage.ID,
+				"notifier":  n.Name(),
+				"error":     err,
+			}).Error("Failed to notify outage update")
+		}
+	}
+}
+
+// EnableSlackActions adds the interactive outage actions to new Slack outage messages.
+func (m *DBOutageManager) EnableSlackActions() {
+	for _, n := range m.notifiers {
+		if queue, ok := n.(*NotifierQueue); ok {
+			n = queue.notifier
+		}
+		switch n := n.(type) {
+		case *SlackReporter:
+			n.SetInteractive(true)
+		case *SlackNotificationQueue:
+			n.reporter.SetInteractive(true)
+		}
+	}
+}
+
+// QueueSlackNotifications delivers Slack notifications through a SlackNotificationQueue that runs until ctx is
+// done, instead of posting from the request that changed the outage.
+func (m *DBOutageManager) QueueSlackNotifications(ctx context.Context, config SlackQueueConfig) {
+	for i, n := range m.notifiers {
+		if reporter, ok := n.(*SlackReporter); ok {
+			queue := NewSlackNotificationQueue(reporter, config, m.logger)
+			m.notifiers[i] = queue
+			go queue.Start(ctx)
+		}
+	}
+}
+
+// QueueNotifications delivers the events of every notifier registered so far from its own NotifierQueue, which
+// runs until ctx is done, instead of from the request that changed the outage. Notifiers with their own queue,
+// like SlackNotificationQueue, are left as they are.
+func (m *DBOutageManager) QueueNotifications(ctx context.Context, size int) {
+	for i, n := range m.notifiers {
+		switch n.(type) {
+		case *SlackNotificationQueue, *NotifierQueue:
+			continue
+		}
+		queue := NewNotifierQueue(n, size, m.logger)
+		m.notifiers[i] = queue
+		go queue.Start(ctx)
+	}
+}
+
 func (m *DBOutageManager) CreateOutage(outage *types.Outage, reasons []types.Reason, user, initialTriageNote string) error {
 	if msg, ok := outage.Validate(); !ok {
 		return fmt.Errorf("validation failed: %s", msg)
 	}
 
+	var autoResolved []autoResolvedOutage
 	if err := m.db.Transaction(func(tx *gorm.DB) error {
 		outageRepo := repositories.NewGORMOutageRepository(tx)
 		if err := outageRepo.CreateOutage(outage, user); err != nil {
@@ -122,6 +247,7 @@ func (m *DBOutageManager) CreateOutage(outage *types.Outage, reasons []types.Rea
 			}
 			now := time.Now()
 			for i := range suspected {
+				old := suspected[i]
 				suspected[i].EndTime = sql.NullTime{Time: now, Valid: true}
 				if err := outageRepo.SaveOutage(&suspected[i], user); err != nil {
 					m.logger.WithFields(logrus.Fields{
@@ -130,6 +256,7 @@ func (m *DBOutageManager) CreateOutage(outage *types.Outage, reasons []types.Rea
 					}).Warn("Failed to auto-resolve suspected outage")
 				} else {
 					m.logger.WithField("outage_id", suspected[i].ID).Info("Auto-resolved suspected outage")
+					autoResolved = append(autoResolved, autoResolvedOutage{outage: &suspected[i], old: &old})
 				}
 			}
 		}
@@ -139,19 +266,24 @@ func (m *DBOutageManager) CreateOutage(outage *types.Outage, reasons []types.Rea
 		return err
 	}
 
-	// Slack reporting is done outside the transaction as we don't want to fail to create the outage due to slack reporting issues
-	if m.slackReporter != nil {
-		if err := m.slackReporter.ReportOutage(outage); err != nil {
-			m.logger.WithFields(logrus.Fields{
-				"outage_id": outage.ID,
-				"error":     err,
-			}).Error("Failed to report outage to Slack, but outage was created")
-		}
+	// Notification is done outside the transaction as we don't want to fail to create the outage due to notification issues
+	m.notifyCreated(m.notifiers, outage)
+
+	suspectedNotifiers, _ := m.splitNotifiers()
+	for _, resolved := range autoResolved {
+		m.notifyUpdated(suspectedNotifiers, resolved.outage, resolved.old)
 	}
 
 	return nil
 }
 
+// autoResolvedOutage is a suspected outage resolved as a side effect of creating a confirmed outage,
+// with its state before resolution.
+type autoResolvedOutage struct {
+	outage *types.Outage
+	old    *types.Outage
+}
+
 func (m *DBOutageManager) UpdateOutage(outage *types.Outage, user string) error {
 	if msg, ok := outage.Validate(); !ok {
 		return fmt.Errorf("validation failed: %s", msg)
@@ -167,14 +299,7 @@ func (m *DBOutageManager) UpdateOutage(outage *types.Outage, user string) error
 		return err
 	}
 
-	if m.slackReporter != nil {
-		if err := m.slackReporter.ReportOutageUpdate(outage, oldOutage); err != nil {
-			m.logger.WithFields(logrus.Fields{
-				"outage_id": outage.ID,
-				"error":     err,
-			}).Error("Failed to report outage update to Slack, but outage was updated")
-		}
-	}
+	m.notifyUpdated(m.notifiers, outage, oldOutage)
 
 	return nil
 }
@@ -244,11 +369,26 @@ func (m *DBOutageManager) GetStaleSuspectedOutages(cutoff time.Time) ([]types.Ou
 	return outageRepo.GetStaleSuspectedOutages(cutoff)
 }
 
+func (m *DBOutageManager) GetOutagesCreatedBy(createdBy string, since time.Time) ([]types.Outage, error) {
+	outageRepo := repositories.NewGORMOutageRepository(m.db)
+	return outageRepo.GetOutagesCreatedBy(createdBy, since)
+}
+
+func (m *DBOutageManager) GetOutagesReportedBy(user string, since time.Time) ([]types.Outage, error) {
+	outageRepo := repositories.NewGORMOutageRepository(m.db)
+	return outageRepo.GetOutagesReportedBy(user, since)
+}
+
 func (m *DBOutageManager) GetOutageAuditLogs(outageID uint) ([]types.OutageAuditLog, error) {
 	outageRepo := repositories.NewGORMOutageRepository(m.db)
 	return outageRepo.GetOutageAuditLogs(outageID)
 }
 
+func (m *DBOutageManager) GetAuditLogs(filter types.AuditLogFilter) ([]types.AuditLogEntry, error) {
+	outageRepo := repositories.NewGORMOutageRepository(m.db)
+	return outageRepo.GetAuditLogs(filter)
+}
+
 func (m *DBOutageManager) DeleteOutage(outage *types.Outage, user string) error {
 	outageRepo := repositories.NewGORMOutageRepository(m.db)
 	return outageRepo.DeleteOutage(outage, user)
@@ -273,12 +413,19 @@ func (m *DBOutageManager) ReportSuspectedOutage(componentSlug, subComponentSlug,
 		return nil, err
 	}
 
-	if result.Outage != nil && result.Outage.Severity == types.SeverityDegraded && m.slackReporter != nil {
-		if err := m.slackReporter.ReportOutage(result.Outage); err != nil {
-			logger.WithFields(logrus.Fields{
-				"outage_id": result.Outage.ID,
-				"error":     err,
-			}).Error("Failed to report upgraded outage to Slack")
+	if result.Outage != nil {
+		suspectedNotifiers, confirmedNotifiers := m.splitNotifiers()
+		if result.Created {
+			created := *result.Outage
+			created.Severity = types.SeveritySuspected
+			m.notifyCreated(suspectedNotifiers, &created)
+		}
+		if result.Outage.Severity == types.SeverityDegraded {
+			old := *result.Outage
+			old.Severity = types.SeveritySuspected
+			m.notifyUpdated(suspectedNotifiers, result.Outage, &old)
+			// For the other notifiers, reaching the threshold is the first they hear of the outage.
+			m.notifyCreated(confirmedNotifiers, result.Outage)
 		}
 	}
 
@@ -378,9 +525,11 @@ func (m *DBOutageManager) snapshotOutage(outageID uint) []byte {
 
 func (m *DBOutageManager) auditMutation(outageID uint, user string, old []byte) {
 	newSnap := m.snapshotOutage(outageID)
+	delegator, _ := m.db.Statement.Context.Value(types.CurrentDelegatorKey).(string)
 	if err := m.db.Create(&types.OutageAuditLog{
 		OutageID:  outageID,
 		User:      user,
+		Delegator: delegator,
 		Operation: string(types.Update),
 		Old:       old,
 		New:       newSnap,
@@ -407,11 +556,11 @@ func (m *DBOutageManager) AddTriageNote(note *types.TriageNote) error {
 	return nil
 }
 
-func (m *DBOutageManager) UpdateTriageNote(outageID, noteID uint, body, user string) (*types.TriageNote, error) {
+func (m *DBOutageManager) UpdateTriageNote(outageID, noteID uint, body string, visibility types.Visibility, user string) (*types.TriageNote, error) {
 	old := m.snapshotOutage(outageID)
 
 	noteRepo := repositories.NewGORMTriageNoteRepository(m.db)
-	result, err := noteRepo.UpdateTriageNote(outageID, noteID, body)
+	result, err := noteRepo.UpdateTriageNote(outageID, noteID, body, visibility)
 	if err != nil {
 		return nil, err
 	}
@@ -446,12 +595,12 @@ func (m *DBOutageManager) AddOutageLink(link *types.OutageLink, user string) err
 	return nil
 }
 
-func (m *DBOutageManager) UpdateOutageLink(outageID, linkID uint, url string, linkType types.LinkType, description, user string) (*types.OutageLink, error) {
+func (m *DBOutageManager) UpdateOutageLink(outageID, linkID uint, url string, linkType types.LinkType, description string, visibility types.Visibility, user string) (*types.OutageLink, error) {
 	oldOutage := m.loadOutage(outageID)
 	old := m.snapshotOutage(outageID)
 
 	linkRepo := repositories.NewGORMOutageLinkRepository(m.db)
-	result, err := linkRepo.UpdateOutageLink(outageID, linkID, url, linkType, description)
+	result, err := linkRepo.UpdateOutageLink(outageID, linkID, url, linkType, description, visibility)
 	if err != nil {
 		return nil, err
 	}
@@ -482,19 +631,14 @@ func (m *DBOutageManager) loadOutage(outageID uint) *types.Outage {
 	return &outage
 }
 
-// reportChildUpdate triggers Slack thread replies by diffing the pre/post outage state.
+// reportChildUpdate notifies of triage note and link changes by diffing the pre/post outage state.
 func (m *DBOutageManager) reportChildUpdate(outageID uint, oldOutage *types.Outage) {
-	if m.slackReporter == nil || oldOutage == nil {
+	if len(m.notifiers) == 0 || oldOutage == nil {
 		return
 	}
 	newOutage := m.loadOutage(outageID)
 	if newOutage == nil {
 		return
 	}
-	if err := m.slackReporter.ReportOutageUpdate(newOutage, oldOutage); err != nil {
-		m.logger.WithFields(logrus.Fields{
-			"outage_id": outageID,
-			"error":     err,
-		}).Error("Failed to report child update to Slack")
-	}
+	m.notifyUpdated(m.notifiers, newOutage, oldOutage)
 }
@@ -27,7 +27,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
-	err = db.AutoMigrate(&types.Outage{}, &types.Reason{}, &types.SlackThread{}, &types.OutageAuditLog{}, &types.OutageReport{}, &types.TriageNote{}, &types.OutageLink{})
+	err = db.AutoMigrate(&types.Outage{}, &types.Reason{}, &types.SlackThread{}, &types.OutageAuditLog{}, &types.OutageReport{}, &types.TriageNote{}, &types.OutageLink{}, &types.WatchSubscription{}, &types.NotificationPreference{})
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -453,6 +453,179 @@ func TestOutageManager_GetOutageAuditLogs(t *testing.T) {
 	}
 }
 
+func TestOutageManager_GetAuditLogs(t *testing.T) {
+	config := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug: "alpha",
+				Name: "Alpha",
+				Subcomponents: []types.SubComponent{
+					{Slug: "one", Name: "One"},
+				},
+			},
+			{
+				Slug: "beta",
+				Name: "Beta",
+				Subcomponents: []types.SubComponent{
+					{Slug: "one", Name: "One"},
+				},
+			},
+		},
+	}
+	tm := setupTestManager(t, config)
+	defer tm.close()
+
+	newOutage := func(component string) *types.Outage {
+		return &types.Outage{
+			ComponentName:    component,
+			SubComponentName: "one",
+			Severity:         types.SeverityDown,
+			StartTime:        time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+			Description:      "Audit feed test outage",
+			CreatedBy:        "system",
+			DiscoveredFrom:   "frontend",
+		}
+	}
+	alpha := newOutage("alpha")
+	require.NoError(t, tm.manager.CreateOutage(alpha, nil, "alice", ""))
+	beta := newOutage("beta")
+	require.NoError(t, tm.manager.CreateOutage(beta, nil, "bob", ""))
+	require.NoError(t, tm.manager.AddTriageNote(&types.TriageNote{OutageID: alpha.ID, Body: "looking", Author: "bob"}))
+
+	tests := []struct {
+		name       string
+		filter     types.AuditLogFilter
+		wantUsers  []string
+		wantOps    []string
+		wantFields []string
+	}{
+		{
+			name:      "no filter returns everything newest first",
+			wantUsers: []string{"bob", "bob", "alice"},
+			wantOps:   []string{"UPDATE", "CREATE", "CREATE"},
+		},
+		{
+			name:      "by user",
+			filter:    types.AuditLogFilter{User: "alice"},
+			wantUsers: []string{"alice"},
+			wantOps:   []string{"CREATE"},
+		},
+		{
+			name:       "by component and operation",
+			filter:     types.AuditLogFilter{ComponentSlug: "alpha", Operation: "UPDATE"},
+			wantUsers:  []string{"bob"},
+			wantOps:    []string{"UPDATE"},
+			wantFields: []string{"triage_notes[1]"},
+		},
+		{
+			name:      "limit",
+			filter:    types.AuditLogFilter{Limit: 1},
+			wantUsers: []string{"bob"},
+			wantOps:   []string{"UPDATE"},
+		},
+		{
+			name:      "limit and offset",
+			filter:    types.AuditLogFilter{Limit: 1, Offset: 1},
+			wantUsers: []string{"bob"},
+			wantOps:   []string{"CREATE"},
+		},
+		{
+			name:   "time range excludes everything",
+			filter: types.AuditLogFilter{End: time.Now().Add(-time.Hour)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := tm.manager.GetAuditLogs(tt.filter)
+			require.NoError(t, err)
+			require.Len(t, entries, len(tt.wantUsers))
+			for i, entry := range entries {
+				assert.Equal(t, tt.wantUsers[i], entry.User)
+				assert.Equal(t, tt.wantOps[i], entry.Operation)
+				assert.Equal(t, "one", entry.SubComponentName)
+			}
+			if tt.wantFields != nil {
+				var fields []string
+				for _, change := range entries[0].Changes {
+					fields = append(fields, change.Field)
+				}
+				assert.Equal(t, tt.wantFields, fields)
+				assert.Equal(t, "alpha", entries[0].ComponentName)
+			}
+		})
+	}
+}
+
+func TestOutageManager_GetOutagesForUser(t *testing.T) {
+	config := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug: "test-component",
+				Name: "Test Component",
+				Subcomponents: []types.SubComponent{
+					{Slug: "test-sub", Name: "Test Sub", ReportThreshold: 5},
+					{Slug: "other-sub", Name: "Other Sub", ReportThreshold: 5},
+				},
+			},
+		},
+	}
+	tm := setupTestManager(t, config)
+	defer tm.close()
+
+	now := time.Now().UTC()
+	oldResolved := &types.Outage{
+		ComponentName:    "test-component",
+		SubComponentName: "test-sub",
+		Severity:         types.SeverityDown,
+		StartTime:        now.AddDate(0, 0, -60),
+		EndTime:          sql.NullTime{Time: now.AddDate(0, 0, -59), Valid: true},
+		Description:      "old",
+		CreatedBy:        "alice",
+		DiscoveredFrom:   "frontend",
+	}
+	require.NoError(t, tm.manager.CreateOutage(oldResolved, nil, "alice", ""))
+	active := &types.Outage{
+		ComponentName:    "test-component",
+		SubComponentName: "test-sub",
+		Severity:         types.SeverityDown,
+		StartTime:        now.AddDate(0, 0, -90),
+		Description:      "still active",
+		CreatedBy:        "alice",
+		DiscoveredFrom:   "frontend",
+	}
+	require.NoError(t, tm.manager.CreateOutage(active, nil, "alice", ""))
+
+	_, err := tm.manager.ReportSuspectedOutage("test-component", "other-sub", "flaky", "bob", 5)
+	require.NoError(t, err)
+	result, err := tm.manager.ReportSuspectedOutage("test-component", "other-sub", "", "carol", 5)
+	require.NoError(t, err)
+
+	since := now.AddDate(0, 0, -30)
+	created, err := tm.manager.GetOutagesCreatedBy("alice", since)
+	require.NoError(t, err)
+	require.Len(t, created, 1)
+	assert.Equal(t, active.ID, created[0].ID)
+
+	reported, err := tm.manager.GetOutagesReportedBy("carol", since)
+	require.NoError(t, err)
+	require.Len(t, reported, 1)
+	assert.Equal(t, result.Outage.ID, reported[0].ID)
+	assert.Len(t, reported[0].Reports, 2)
+
+	reported, err = tm.manager.GetOutagesReportedBy("alice", since)
+	require.NoError(t, err)
+	assert.Empty(t, reported)
+
+	require.NoError(t, tm.manager.AddTriageNote(&types.TriageNote{OutageID: active.ID, Body: "looking", Author: "alice"}))
+	notes, err := repositories.NewGORMTriageNoteRepository(tm.db).ListTriageNotesByAuthor("alice", since)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "looking", notes[0].Body)
+	assert.Equal(t, "test-component", notes[0].ComponentName)
+	assert.Equal(t, "test-sub", notes[0].SubComponentName)
+}
+
 func TestOutageManager_DeleteOutage(t *testing.T) {
 	config := &types.DashboardConfig{
 		Components: []*types.Component{
@@ -495,6 +668,46 @@ func TestOutageManager_DeleteOutage(t *testing.T) {
 	assert.Equal(t, "test-user", logs[1].User)
 }
 
+func TestOutageManager_WithDelegator(t *testing.T) {
+	config := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug: "test-component",
+				Name: "Test Component",
+				Subcomponents: []types.SubComponent{
+					{Slug: "test-sub", Name: "Test Sub"},
+				},
+			},
+		},
+	}
+	tm := setupTestManager(t, config)
+	defer tm.close()
+
+	delegated := tm.manager.WithDelegator("system:serviceaccount:ship-status:chat-bot")
+	outage := &types.Outage{
+		ComponentName:    "test-component",
+		SubComponentName: "test-sub",
+		Severity:         types.SeverityDown,
+		StartTime:        time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Description:      "Reported from chat",
+		CreatedBy:        "on-call-user",
+		DiscoveredFrom:   "mcp",
+	}
+	require.NoError(t, delegated.CreateOutage(outage, nil, "on-call-user", ""))
+	require.NoError(t, delegated.AddTriageNote(&types.TriageNote{OutageID: outage.ID, Body: "Looking", Author: "on-call-user"}))
+	require.NoError(t, tm.manager.UpdateOutage(outage, "other-user"))
+
+	var logs []types.OutageAuditLog
+	require.NoError(t, tm.db.Where("outage_id = ?", outage.ID).Order("id").Find(&logs).Error)
+	require.Len(t, logs, 3)
+	for _, log := range logs[:2] {
+		assert.Equal(t, "on-call-user", log.User, log.Operation)
+		assert.Equal(t, "system:serviceaccount:ship-status:chat-bot", log.Delegator, log.Operation)
+	}
+	assert.Equal(t, "other-user", logs[2].User)
+	assert.Empty(t, logs[2].Delegator, "the original manager must not record the delegator")
+}
+
 func TestOutageManager_CreateOutage_WithInitialTriageNote(t *testing.T) {
 	config := &types.DashboardConfig{
 		Components: []*types.Component{
@@ -743,7 +956,7 @@ func TestOutageManager_UpdateTriageNote(t *testing.T) {
 	err = tm.manager.AddTriageNote(note)
 	require.NoError(t, err)
 
-	updated, err := tm.manager.UpdateTriageNote(outage.ID, note.ID, "Revised text", "author-user")
+	updated, err := tm.manager.UpdateTriageNote(outage.ID, note.ID, "Revised text", "", "author-user")
 	require.NoError(t, err)
 	assert.Equal(t, "Revised text", updated.Body)
 
@@ -911,7 +1124,7 @@ func TestOutageManager_UpdateOutageLink(t *testing.T) {
 	err = tm.manager.AddOutageLink(link, "on-call-user")
 	require.NoError(t, err)
 
-	updated, err := tm.manager.UpdateOutageLink(outage.ID, link.ID, "https://new.example.com", types.LinkTypeRCA, "Updated RCA", "on-call-user")
+	updated, err := tm.manager.UpdateOutageLink(outage.ID, link.ID, "https://new.example.com", types.LinkTypeRCA, "Updated RCA", "", "on-call-user")
 	require.NoError(t, err)
 	assert.Equal(t, "https://new.example.com", updated.URL)
 	assert.Equal(t, types.LinkTypeRCA, updated.LinkType)
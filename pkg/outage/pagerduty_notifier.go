@@ -0,0 +1,377 @@
+package outage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+const (
+	DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+	DefaultPagerDutyAPIURL    = "https://api.pagerduty.com"
+
+	pagerDutySource = "ship-status-dash"
+	// pagerDutyMaxSummary is the longest summary the Events API accepts.
+	pagerDutyMaxSummary = 1024
+)
+
+// PagerDutyConfig configures access to PagerDuty.
+type PagerDutyConfig struct {
+	// RoutingKeys maps the routing_key_ref names used in paging config to Events API v2 integration keys.
+	RoutingKeys map[string]string
+	// APIToken is a read-only REST API token used to look up the incident created for an outage so it can be
+	// linked from the dashboard. Incident links are not recorded without it.
+	APIToken string
+	// EventsURL and APIURL default to PagerDuty's public endpoints.
+	EventsURL string
+	APIURL    string
+}
+
+// PagerDutyNotifier pages the services configured with paging through the PagerDuty Events API v2.
+// All events for an outage share a dedup key, so PagerDuty folds them into a single incident per service.
+type PagerDutyNotifier struct {
+	cfg           PagerDutyConfig
+	client        *http.Client
+	linkRepo      repositories.OutageLinkRepository
+	configManager *config.Manager[types.DashboardConfig]
+	baseURL       string
+	logger        *logrus.Logger
+
+	// PagerDuty creates incidents asynchronously, so the lookup for the incident link is retried.
+	linkLookupAttempts int
+	linkLookupInterval time.Duration
+	linkLookups        sync.WaitGroup
+}
+
+// NewPagerDutyNotifier creates a PagerDutyNotifier. Incident links are stored through linkRepo, and baseURL
+// is used to link back to the outage from the incident.
+func NewPagerDutyNotifier(cfg PagerDutyConfig, linkRepo repositories.OutageLinkRepository, configManager *config.Manager[types.DashboardConfig], baseURL string, logger *logrus.Logger) *PagerDutyNotifier {
+	if cfg.EventsURL == "" {
+		cfg.EventsURL = DefaultPagerDutyEventsURL
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultPagerDutyAPIURL
+	}
+	cfg.APIURL = strings.TrimSuffix(cfg.APIURL, "/")
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &PagerDutyNotifier{
+		cfg:                cfg,
+		client:             &http.Client{Timeout: notifierHTTPTimeout},
+		linkRepo:           linkRepo,
+		configManager:      configManager,
+		baseURL:            baseURL,
+		logger:             logger,
+		linkLookupAttempts: 5,
+		linkLookupInterval: 3 * time.Second,
+	}
+}
+
+func (n *PagerDutyNotifier) Name() string {
+	return "pagerduty"
+}
+
+func (n *PagerDutyNotifier) OutageCreated(outage *types.Outage) error {
+	internal, public := n.routingKeyRefs(outage, func(paging types.PagingConfig) bool {
+		return meetsPagingThreshold(outage.Severity, paging.Severity)
+	})
+	return n.trigger(outage, internal, public)
+}
+
+// OutageUpdated triggers the services whose threshold the outage now meets. Services that were already paged
+// only receive a new event when the severity or the description they see changed, which updates the open
+// incident. A downgrade below a threshold leaves the incident open for the responder to resolve, and edits to
+// an outage that has already ended never page again.
+func (n *PagerDutyNotifier) OutageUpdated(outage, oldOutage *types.Outage) error {
+	if outage.EndTime.Valid {
+		return nil
+	}
+	publicOutage, publicOld := outage.PublicView(), oldOutage.PublicView()
+	internal, public := n.routingKeyRefs(outage, func(paging types.PagingConfig) bool {
+		if !meetsPagingThreshold(outage.Severity, paging.Severity) {
+			return false
+		}
+		changed := outage.Severity != oldOutage.Severity
+		if paging.Visibility.IsInternal() {
+			changed = changed || outage.Description != oldOutage.Description
+		} else {
+			changed = changed || publicOutage.Description != publicOld.Description
+		}
+		return changed || !meetsPagingThreshold(oldOutage.Severity, paging.Severity)
+	})
+	return n.trigger(outage, internal, public)
+}
+
+// OutageResolved resolves the incident on every service the outage paged.
+func (n *PagerDutyNotifier) OutageResolved(outage, oldOutage *types.Outage) error {
+	internal, public := n.routingKeyRefs(outage, func(paging types.PagingConfig) bool {
+		return meetsPagingThreshold(outage.Severity, paging.Severity) || meetsPagingThreshold(oldOutage.Severity, paging.Severity)
+	})
+	var lastErr error
+	for _, ref := range append(internal, public...) {
+		if err := n.sendEvent(ref, pagerDutyEvent{EventAction: "resolve", DedupKey: PagerDutyDedupKey(outage.ID)}); err != nil {
+			lastErr = n.logEventError(outage, ref, "resolve", err)
+		}
+	}
+	return lastErr
+}
+
+// PagerDutyDedupKey returns the dedup key used for every PagerDuty event about an outage.
+func PagerDutyDedupKey(outageID uint) string {
+	return fmt.Sprintf("%s/outage/%d", pagerDutySource, outageID)
+}
+
+// meetsPagingThreshold is meetsSeverityThreshold with a Down default, since paging on every
+// severity would wake people for suspected outages.
+func meetsPagingThreshold(severity types.Severity, threshold *types.Severity) bool {
+	if threshold == nil || *threshold == "" {
+		down := types.SeverityDown
+		threshold = &down
+	}
+	return meetsSeverityThreshold(severity, threshold)
+}
+
+// routingKeyRefs returns the deduplicated routing key references of the paging entries selected by include,
+// split by whether their entry is internal. References with both kinds of entry are internal.
+func (n *PagerDutyNotifier) routingKeyRefs(outage *types.Outage, include func(paging types.PagingConfig) bool) (internal, public []string) {
+	component := n.configManager.Get().GetComponentBySlug(outage.ComponentName)
+	if component == nil {
+		return nil, nil
+	}
+	internalRefs, publicRefs := sets.NewString(), sets.NewString()
+	for _, paging := range types.GetPaging(component, component.GetSubComponentBySlug(outage.SubComponentName)) {
+		if !include(paging) {
+			continue
+		}
+		if paging.Visibility.IsInternal() {
+			internalRefs.Insert(paging.RoutingKeyRef)
+		} else {
+			publicRefs.Insert(paging.RoutingKeyRef)
+		}
+	}
+	return internalRefs.List(), publicRefs.Difference(internalRefs).List()
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Client      string            `json:"client,omitempty"`
+	ClientURL   string            `json:"client_url,omitempty"`
+	Links       []pagerDutyLink   `json:"links,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp,omitempty"`
+	Component     string            `json:"component,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// pagerDutySeverity maps an outage severity to the PagerDuty event severity.
+func pagerDutySeverity(severity types.Severity) string {
+	switch severity {
+	case types.SeverityDown:
+		return "critical"
+	case types.SeverityDegraded:
+		return "error"
+	case types.SeverityCapacityExhausted, types.SeveritySuspected:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// trigger sends a trigger event for the outage to the internal refs, and for its PublicView to the public ones.
+func (n *PagerDutyNotifier) trigger(outage *types.Outage, internal, public []string) error {
+	if len(internal) == 0 && len(public) == 0 {
+		return nil
+	}
+	publicOutage := outage.PublicView()
+	var lastErr error
+	triggered := false
+	for _, target := range []struct {
+		view *types.Outage
+		refs []string
+	}{{outage, internal}, {&publicOutage, public}} {
+		if len(target.refs) == 0 {
+			continue
+		}
+		event := n.triggerEvent(target.view)
+		for _, ref := range target.refs {
+			if err := n.sendEvent(ref, event); err != nil {
+				lastErr = n.logEventError(outage, ref, "trigger", err)
+				continue
+			}
+			triggered = true
+		}
+	}
+	if triggered && n.cfg.APIToken != "" {
+		n.linkLookups.Add(1)
+		go func() {
+			defer n.linkLookups.Done()
+			n.recordIncidentLinks(outage.ID)
+		}()
+	}
+	return lastErr
+}
+
+func (n *PagerDutyNotifier) triggerEvent(outage *types.Outage) pagerDutyEvent {
+	componentName, subComponentName := resolveDisplayNames(n.configManager, outage)
+	summary := fmt.Sprintf("%s: %s/%s", outage.Severity, componentName, subComponentName)
+	if outage.Description != "" {
+		summary += " - " + outage.Description
+	}
+	summary = truncate(summary, pagerDutyMaxSummary)
+	outageURL := buildOutageURL(n.baseURL, outage)
+	return pagerDutyEvent{
+		EventAction: "trigger",
+		DedupKey:    PagerDutyDedupKey(outage.ID),
+		Payload: &pagerDutyPayload{
+			Summary:   summary,
+			Source:    pagerDutySource,
+			Severity:  pagerDutySeverity(outage.Severity),
+			Timestamp: outage.StartTime.UTC().Format(time.RFC3339),
+			Component: componentName,
+			Group:     subComponentName,
+			CustomDetails: map[string]string{
+				"outage_id":       fmt.Sprintf("%d", outage.ID),
+				"severity":        string(outage.Severity),
+				"description":     outage.Description,
+				"created_by":      outage.CreatedBy,
+				"discovered_from": outage.DiscoveredFrom,
+			},
+		},
+		Client:    pagerDutySource,
+		ClientURL: outageURL,
+		Links:     []pagerDutyLink{{Href: outageURL, Text: "View Outage"}},
+	}
+}
+
+func (n *PagerDutyNotifier) sendEvent(ref string, event pagerDutyEvent) error {
+	routingKey, ok := n.cfg.RoutingKeys[ref]
+	if !ok {
+		return fmt.Errorf("unknown routing key reference %q", ref)
+	}
+	event.RoutingKey = routingKey
+	return postJSON(n.client, n.cfg.EventsURL, event)
+}
+
+// logEventError logs a failed event without the routing key, which is a credential, and returns err.
+func (n *PagerDutyNotifier) logEventError(outage *types.Outage, ref, action string, err error) error {
+	n.logger.WithFields(logrus.Fields{
+		"outage_id":       outage.ID,
+		"routing_key_ref": ref,
+		"event_action":    action,
+		"error":           err,
+	}).Error("Failed to send PagerDuty event")
+	return err
+}
+
+type pagerDutyIncident struct {
+	HTMLURL        string `json:"html_url"`
+	IncidentNumber int    `json:"incident_number"`
+	Service        struct {
+		Summary string `json:"summary"`
+	} `json:"service"`
+}
+
+// recordIncidentLinks stores a link to each PagerDuty incident opened for the outage that is not linked yet.
+// It retries until at least one incident is found, since PagerDuty processes events asynchronously.
+func (n *PagerDutyNotifier) recordIncidentLinks(outageID uint) {
+	logger := n.logger.WithField("outage_id", outageID)
+	for attempt := 1; attempt <= n.linkLookupAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(n.linkLookupInterval)
+		}
+		incidents, err := n.findIncidents(PagerDutyDedupKey(outageID))
+		if err != nil {
+			logger.WithField("error", err).Warn("Failed to look up PagerDuty incident")
+			continue
+		}
+		if len(incidents) == 0 {
+			continue
+		}
+		if err := n.storeIncidentLinks(outageID, incidents); err != nil {
+			logger.WithField("error", err).Error("Failed to store PagerDuty incident link")
+		}
+		return
+	}
+	logger.Warn("No PagerDuty incident found for outage, incident link not recorded")
+}
+
+func (n *PagerDutyNotifier) storeIncidentLinks(outageID uint, incidents []pagerDutyIncident) error {
+	existing, err := n.linkRepo.ListOutageLinks(outageID)
+	if err != nil {
+		return err
+	}
+	linked := sets.NewString()
+	for _, link := range existing {
+		if link.LinkType == types.LinkTypePagerDutyIncident {
+			linked.Insert(link.URL)
+		}
+	}
+	for _, incident := range incidents {
+		if incident.HTMLURL == "" || linked.Has(incident.HTMLURL) {
+			continue
+		}
+		link := &types.OutageLink{
+			OutageID:    outageID,
+			URL:         incident.HTMLURL,
+			LinkType:    types.LinkTypePagerDutyIncident,
+			Description: fmt.Sprintf("PagerDuty incident #%d on %s", incident.IncidentNumber, incident.Service.Summary),
+		}
+		if err := n.linkRepo.AddOutageLink(link); err != nil {
+			return err
+		}
+		linked.Insert(incident.HTMLURL)
+	}
+	return nil
+}
+
+// findIncidents returns the incidents PagerDuty opened for dedupKey, which it calls the incident key.
+func (n *PagerDutyNotifier) findIncidents(dedupKey string) ([]pagerDutyIncident, error) {
+	query := url.Values{"incident_key": {dedupKey}}
+	req, err := http.NewRequest(http.MethodGet, n.cfg.APIURL+"/incidents?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Token token="+n.cfg.APIToken)
+	req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("PagerDuty API returned status %d", resp.StatusCode)
+	}
+	var body struct {
+		Incidents []pagerDutyIncident `json:"incidents"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode PagerDuty incidents: %w", err)
+	}
+	return body.Incidents, nil
+}
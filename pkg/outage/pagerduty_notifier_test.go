@@ -0,0 +1,233 @@
+package outage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+// mockPagerDuty records Events API v2 events and serves a single incident for the incidents REST API.
+type mockPagerDuty struct {
+	server *httptest.Server
+	mu     sync.Mutex
+	events []pagerDutyEvent
+}
+
+func newMockPagerDuty(t *testing.T) *mockPagerDuty {
+	t.Helper()
+	pd := &mockPagerDuty{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v2/enqueue", func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		pd.mu.Lock()
+		pd.events = append(pd.events, event)
+		pd.mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("GET /incidents", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Token token=api-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "ship-status-dash/outage/3", r.URL.Query().Get("incident_key"))
+		_, _ = w.Write([]byte(`{"incidents":[{"html_url":"https://acme.pagerduty.com/incidents/Q1","incident_number":42,"service":{"summary":"Build Farm"}}]}`))
+	})
+	pd.server = httptest.NewServer(mux)
+	t.Cleanup(pd.server.Close)
+	return pd
+}
+
+func (pd *mockPagerDuty) received() []pagerDutyEvent {
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+	return append([]pagerDutyEvent(nil), pd.events...)
+}
+
+func TestPagerDutyNotifier(t *testing.T) {
+	pd := newMockPagerDuty(t)
+	degraded := types.SeverityDegraded
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug:   "test-component",
+				Name:   "Test Component",
+				Paging: []types.PagingConfig{{RoutingKeyRef: "component"}},
+				Subcomponents: []types.SubComponent{
+					{
+						Slug: "test-sub",
+						Name: "Test Sub",
+						Paging: []types.PagingConfig{
+							{RoutingKeyRef: "primary"},
+							{RoutingKeyRef: "secondary", Severity: &degraded},
+						},
+					},
+				},
+			},
+		},
+	}
+	db := setupTestDB(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// Incident links are stored from a goroutine; a single connection keeps it on the same in-memory database.
+	sqlDB.SetMaxOpenConns(1)
+	linkRepo := repositories.NewGORMOutageLinkRepository(db)
+
+	n := NewPagerDutyNotifier(PagerDutyConfig{
+		RoutingKeys: map[string]string{"primary": "key-primary", "secondary": "key-secondary"},
+		APIToken:    "api-token",
+		EventsURL:   pd.server.URL + "/v2/enqueue",
+		APIURL:      pd.server.URL,
+	}, linkRepo, newNotifierTestConfigManager(t, cfg), "https://test.example.com", logrus.New())
+	n.linkLookupInterval = time.Millisecond
+
+	outage := notifierTestOutage()
+	require.NoError(t, n.OutageCreated(outage))
+	n.linkLookups.Wait()
+	events := pd.received()
+	require.Len(t, events, 1, "only the Degraded threshold is met; paging defaults to Down")
+	assert.Equal(t, pagerDutyEvent{
+		RoutingKey:  "key-secondary",
+		EventAction: "trigger",
+		DedupKey:    "ship-status-dash/outage/3",
+		Payload: &pagerDutyPayload{
+			Summary:   "Degraded: Test Component/Test Sub - Builds are slow",
+			Source:    "ship-status-dash",
+			Severity:  "error",
+			Timestamp: "2024-01-15T10:30:00Z",
+			Component: "Test Component",
+			Group:     "Test Sub",
+			CustomDetails: map[string]string{
+				"outage_id":       "3",
+				"severity":        "Degraded",
+				"description":     "Builds are slow",
+				"created_by":      "alice",
+				"discovered_from": "frontend",
+			},
+		},
+		Client:    "ship-status-dash",
+		ClientURL: "https://test.example.com/test-component/test-sub/outages/3",
+		Links:     []pagerDutyLink{{Href: "https://test.example.com/test-component/test-sub/outages/3", Text: "View Outage"}},
+	}, events[0])
+
+	links, err := linkRepo.ListOutageLinks(3)
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, "https://acme.pagerduty.com/incidents/Q1", links[0].URL)
+	assert.Equal(t, types.LinkTypePagerDutyIncident, links[0].LinkType)
+	assert.Equal(t, "PagerDuty incident #42 on Build Farm", links[0].Description)
+
+	// Triage notes do not re-trigger an incident.
+	old := *outage
+	outage.TriageNotes = []types.TriageNote{{Author: "bob", Body: "looking"}}
+	require.NoError(t, n.OutageUpdated(outage, &old))
+	assert.Len(t, pd.received(), 1)
+
+	// Escalating to Down pages the primary service and updates the secondary incident.
+	old = *outage
+	outage.Severity = types.SeverityDown
+	require.NoError(t, n.OutageUpdated(outage, &old))
+	n.linkLookups.Wait()
+	events = pd.received()
+	require.Len(t, events, 3)
+	assert.Equal(t, "key-primary", events[1].RoutingKey)
+	assert.Equal(t, "key-secondary", events[2].RoutingKey)
+	assert.Equal(t, "critical", events[1].Payload.Severity)
+	links, err = linkRepo.ListOutageLinks(3)
+	require.NoError(t, err)
+	assert.Len(t, links, 1, "an incident is only linked once")
+
+	old = *outage
+	outage.EndTime = sql.NullTime{Time: outage.StartTime.Add(time.Hour), Valid: true}
+	require.NoError(t, n.OutageResolved(outage, &old))
+	events = pd.received()
+	require.Len(t, events, 5)
+	for _, event := range events[3:] {
+		assert.Equal(t, "resolve", event.EventAction)
+		assert.Equal(t, "ship-status-dash/outage/3", event.DedupKey)
+		assert.Nil(t, event.Payload)
+	}
+
+	// Edits after resolution never page again.
+	old = *outage
+	outage.Description = "post-mortem pending"
+	require.NoError(t, n.OutageUpdated(outage, &old))
+	assert.Len(t, pd.received(), 5)
+}
+
+func TestPagerDutyNotifier_UnknownRoutingKeyRef(t *testing.T) {
+	pd := newMockPagerDuty(t)
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug:          "test-component",
+				Name:          "Test Component",
+				Paging:        []types.PagingConfig{{RoutingKeyRef: "missing"}, {RoutingKeyRef: "primary"}},
+				Subcomponents: []types.SubComponent{{Slug: "test-sub", Name: "Test Sub"}},
+			},
+		},
+	}
+	n := NewPagerDutyNotifier(PagerDutyConfig{
+		RoutingKeys: map[string]string{"primary": "key-primary"},
+		EventsURL:   pd.server.URL + "/v2/enqueue",
+	}, &repositories.MockOutageLinkRepository{}, newNotifierTestConfigManager(t, cfg), "https://test.example.com", logrus.New())
+
+	outage := notifierTestOutage()
+	outage.Severity = types.SeverityDown
+	assert.EqualError(t, n.OutageCreated(outage), `unknown routing key reference "missing"`)
+	events := pd.received()
+	require.Len(t, events, 1, "other services are still paged")
+	assert.Equal(t, "key-primary", events[0].RoutingKey)
+}
+
+func TestPagerDutyNotifier_Visibility(t *testing.T) {
+	pd := newMockPagerDuty(t)
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug: "test-component",
+				Name: "Test Component",
+				Paging: []types.PagingConfig{
+					{RoutingKeyRef: "public"},
+					{RoutingKeyRef: "internal", Visibility: types.VisibilityInternal},
+				},
+				Subcomponents: []types.SubComponent{{Slug: "test-sub", Name: "Test Sub"}},
+			},
+		},
+	}
+	n := NewPagerDutyNotifier(PagerDutyConfig{
+		RoutingKeys: map[string]string{"public": "key-public", "internal": "key-internal"},
+		EventsURL:   pd.server.URL + "/v2/enqueue",
+	}, &repositories.MockOutageLinkRepository{}, newNotifierTestConfigManager(t, cfg), "https://test.example.com", logrus.New())
+
+	outage := notifierTestOutage()
+	outage.Severity = types.SeverityDown
+	outage.DescriptionVisibility = types.VisibilityInternal
+	require.NoError(t, n.OutageCreated(outage))
+	events := pd.received()
+	require.Len(t, events, 2)
+	byKey := map[string]pagerDutyEvent{}
+	for _, event := range events {
+		byKey[event.RoutingKey] = event
+	}
+	assert.Equal(t, "Down: Test Component/Test Sub - Builds are slow", byKey["key-internal"].Payload.Summary)
+	assert.Equal(t, "Builds are slow", byKey["key-internal"].Payload.CustomDetails["description"])
+	assert.Equal(t, "Down: Test Component/Test Sub", byKey["key-public"].Payload.Summary)
+	assert.Empty(t, byKey["key-public"].Payload.CustomDetails["description"])
+
+	// Editing the internal description only updates the incident that can see it.
+	old := *outage
+	outage.Description = "Builds are slow on build01"
+	require.NoError(t, n.OutageUpdated(outage, &old))
+	events = pd.received()
+	require.Len(t, events, 3)
+	assert.Equal(t, "key-internal", events[2].RoutingKey)
+}
@@ -0,0 +1,137 @@
+package outage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"ship-status-dash/pkg/types"
+)
+
+// Action and callback IDs of the interactive elements on Slack outage messages.
+const (
+	SlackActionResolve        = "outage_resolve"
+	SlackActionChangeSeverity = "outage_change_severity"
+	SlackActionAddNote        = "outage_add_note"
+	SlackActionConfirm        = "outage_confirm"
+
+	// SlackViewTriageNote is the callback ID of the modal that collects a triage note.
+	SlackViewTriageNote = "outage_triage_note"
+	// SlackTriageNoteBlockID and SlackTriageNoteActionID locate the note text in the modal's submitted state.
+	SlackTriageNoteBlockID  = "triage_note"
+	SlackTriageNoteActionID = "triage_note_body"
+
+	slackOutageRefPrefix = "outage:"
+)
+
+// SlackOutageRef identifies the outage an interactive Slack element acts on. It is carried in the
+// block ID of the actions block and in the private metadata of the triage note modal.
+type SlackOutageRef struct {
+	ComponentSlug    string
+	SubComponentSlug string
+	OutageID         uint
+}
+
+func (r SlackOutageRef) String() string {
+	return fmt.Sprintf("%s%s/%s/%d", slackOutageRefPrefix, r.ComponentSlug, r.SubComponentSlug, r.OutageID)
+}
+
+// ParseSlackOutageRef parses a reference produced by SlackOutageRef.String.
+func ParseSlackOutageRef(s string) (SlackOutageRef, error) {
+	parts := strings.Split(strings.TrimPrefix(s, slackOutageRefPrefix), "/")
+	if !strings.HasPrefix(s, slackOutageRefPrefix) || len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return SlackOutageRef{}, fmt.Errorf("invalid outage reference %q", s)
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 32)
+	if err != nil {
+		return SlackOutageRef{}, fmt.Errorf("invalid outage reference %q", s)
+	}
+	return SlackOutageRef{ComponentSlug: parts[0], SubComponentSlug: parts[1], OutageID: uint(id)}, nil
+}
+
+// slackSeverityOptions lists the severities offered by the change severity menu.
+var slackSeverityOptions = []types.Severity{
+	types.SeverityDown,
+	types.SeverityDegraded,
+	types.SeverityCapacityExhausted,
+	types.SeveritySuspected,
+}
+
+// buildOutageMessageBlocks renders text as a section followed by the outage actions. Resolved outages get no actions.
+func buildOutageMessageBlocks(outage *types.Outage, subComponent *types.SubComponent, text string) []slack.Block {
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil),
+	}
+	if outage.EndTime.Valid {
+		return blocks
+	}
+
+	ref := SlackOutageRef{ComponentSlug: outage.ComponentName, SubComponentSlug: outage.SubComponentName, OutageID: outage.ID}
+	resolve := slack.NewButtonBlockElement(SlackActionResolve, "", slack.NewTextBlockObject(slack.PlainTextType, "Resolve", false, false)).
+		WithStyle(slack.StylePrimary).
+		WithConfirm(slack.NewConfirmationBlockObject(
+			slack.NewTextBlockObject(slack.PlainTextType, "Resolve outage?", false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, "The outage will be marked as resolved now.", false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, "Resolve", false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		))
+
+	var options []*slack.OptionBlockObject
+	for _, severity := range slackSeverityOptions {
+		if severity == outage.Severity {
+			continue
+		}
+		options = append(options, slack.NewOptionBlockObject(string(severity), slack.NewTextBlockObject(slack.PlainTextType, string(severity), false, false), nil))
+	}
+	severity := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, slack.NewTextBlockObject(slack.PlainTextType, "Change severity", false, false), SlackActionChangeSeverity, options...)
+
+	elements := []slack.BlockElement{
+		resolve,
+		severity,
+		slack.NewButtonBlockElement(SlackActionAddNote, "", slack.NewTextBlockObject(slack.PlainTextType, "Add triage note", false, false)),
+	}
+	if subComponent != nil && subComponent.RequiresConfirmation && !outage.ConfirmedAt.Valid {
+		elements = append(elements, slack.NewButtonBlockElement(SlackActionConfirm, "", slack.NewTextBlockObject(slack.PlainTextType, "Confirm", false, false)))
+	}
+	return append(blocks, slack.NewActionBlock(ref.String(), elements...))
+}
+
+// BuildTriageNoteModal returns the modal that collects a triage note for the referenced outage. The note is added
+// with visibility, which the modal shows and carries in its private metadata after the outage reference.
+func BuildTriageNoteModal(ref SlackOutageRef, title string, visibility types.Visibility) slack.ModalViewRequest {
+	input := slack.NewPlainTextInputBlockElement(slack.NewTextBlockObject(slack.PlainTextType, "What did you find?", false, false), SlackTriageNoteActionID).
+		WithMultiline(true)
+	notice := "Only signed-in dashboard users will see this note."
+	if visibility == types.VisibilityPublic {
+		notice = "This note will be public on the status page."
+	}
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      SlackViewTriageNote,
+		PrivateMetadata: ref.String() + " " + string(visibility),
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, "Add triage note", false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, "Add note", false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, title, false, false)),
+			slack.NewInputBlock(SlackTriageNoteBlockID, slack.NewTextBlockObject(slack.PlainTextType, "Triage note", false, false), nil, input),
+			slack.NewContextBlock("", slack.NewTextBlockObject(slack.PlainTextType, notice, false, false)),
+		}},
+	}
+}
+
+// ParseTriageNoteModalMetadata parses the private metadata of a modal built by BuildTriageNoteModal. Notes from
+// modals without a visibility, opened before it was added, are internal.
+func ParseTriageNoteModalMetadata(metadata string) (SlackOutageRef, types.Visibility, error) {
+	refString, visibility, _ := strings.Cut(metadata, " ")
+	ref, err := ParseSlackOutageRef(refString)
+	if err != nil {
+		return SlackOutageRef{}, "", err
+	}
+	if types.Visibility(visibility) == types.VisibilityPublic {
+		return ref, types.VisibilityPublic, nil
+	}
+	return ref, types.VisibilityInternal, nil
+}
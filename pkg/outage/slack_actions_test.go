@@ -0,0 +1,106 @@
+package outage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+func TestParseSlackOutageRef(t *testing.T) {
+	ref := SlackOutageRef{ComponentSlug: "build-farm", SubComponentSlug: "build02", OutageID: 12}
+	parsed, err := ParseSlackOutageRef(ref.String())
+	require.NoError(t, err)
+	assert.Equal(t, ref, parsed)
+
+	for _, invalid := range []string{"", "build-farm/build02/12", "outage:build-farm/12", "outage:build-farm/build02/x", "outage://12"} {
+		_, err := ParseSlackOutageRef(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseTriageNoteModalMetadata(t *testing.T) {
+	ref := SlackOutageRef{ComponentSlug: "build-farm", SubComponentSlug: "build02", OutageID: 12}
+	for _, visibility := range []types.Visibility{types.VisibilityPublic, types.VisibilityInternal} {
+		parsed, parsedVisibility, err := ParseTriageNoteModalMetadata(BuildTriageNoteModal(ref, "title", visibility).PrivateMetadata)
+		require.NoError(t, err)
+		assert.Equal(t, ref, parsed)
+		assert.Equal(t, visibility, parsedVisibility)
+	}
+
+	_, visibility, err := ParseTriageNoteModalMetadata(ref.String())
+	require.NoError(t, err)
+	assert.Equal(t, types.VisibilityInternal, visibility, "modals opened before visibility was carried add internal notes")
+
+	_, _, err = ParseTriageNoteModalMetadata("build-farm/build02/12 public")
+	assert.Error(t, err)
+}
+
+// slackMessageActions returns the action IDs and block ID of the actions block in a posted message's blocks.
+func slackMessageActions(t *testing.T, rawBlocks string) (blockID string, actionIDs []string) {
+	t.Helper()
+	var blocks []struct {
+		Type     string `json:"type"`
+		BlockID  string `json:"block_id"`
+		Elements []struct {
+			ActionID string `json:"action_id"`
+		} `json:"elements"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(rawBlocks), &blocks))
+	for _, block := range blocks {
+		if block.Type != "actions" {
+			continue
+		}
+		for _, element := range block.Elements {
+			actionIDs = append(actionIDs, element.ActionID)
+		}
+		return block.BlockID, actionIDs
+	}
+	return "", nil
+}
+
+func TestSlackReporter_InteractiveMessages(t *testing.T) {
+	mockServer := NewMockSlackServer(t)
+	defer mockServer.Close()
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug:           "test-component",
+				Name:           "Test Component",
+				SlackReporting: []types.SlackReportingConfig{{Channel: "#alerts"}},
+				Subcomponents:  []types.SubComponent{{Slug: "test-sub", Name: "Test Sub", RequiresConfirmation: true}},
+			},
+		},
+	}
+	reporter := NewSlackReporter(mockServer.Client(), &repositories.MockSlackThreadRepository{}, newNotifierTestConfigManager(t, cfg), "https://test.example.com", "https://slack.example.com", logrus.New())
+
+	outage := notifierTestOutage()
+	require.NoError(t, reporter.ReportOutage(outage))
+	require.Len(t, mockServer.PostedMessages(), 1)
+	assert.Empty(t, mockServer.PostedMessages()[0].Blocks, "actions are only added when interactivity is enabled")
+
+	reporter.SetInteractive(true)
+	require.NoError(t, reporter.ReportOutage(outage))
+	posted := mockServer.PostedMessages()[1]
+	assert.Contains(t, posted.Text, "Outage Detected", "the text is kept as the notification fallback")
+	blockID, actions := slackMessageActions(t, posted.Blocks)
+	assert.Equal(t, "outage:test-component/test-sub/3", blockID)
+	assert.Equal(t, []string{SlackActionResolve, SlackActionChangeSeverity, SlackActionAddNote, SlackActionConfirm}, actions)
+
+	outage.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	require.NoError(t, reporter.ReportOutage(outage))
+	_, actions = slackMessageActions(t, mockServer.PostedMessages()[2].Blocks)
+	assert.Equal(t, []string{SlackActionResolve, SlackActionChangeSeverity, SlackActionAddNote}, actions, "confirmed outages cannot be confirmed again")
+
+	outage.EndTime = sql.NullTime{Time: time.Now(), Valid: true}
+	require.NoError(t, reporter.ReportOutage(outage))
+	_, actions = slackMessageActions(t, mockServer.PostedMessages()[3].Blocks)
+	assert.Empty(t, actions, "resolved outages have no actions")
+}
@@ -0,0 +1,74 @@
+package outage
+
+import (
+	"fmt"
+	"strings"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/types"
+)
+
+// channelStatusOrder lists the statuses counted in a channel status, most severe first.
+var channelStatusOrder = []types.Status{types.StatusDown, types.StatusDegraded, types.StatusCapacityExhausted, types.StatusSuspected}
+
+// SlackChannelStatusSummary is the current status of the sub-components reported to a Slack channel.
+type SlackChannelStatusSummary struct {
+	// Label names what the channel covers, e.g. the component name.
+	Label string
+	// URL is the dashboard page for the channel's components.
+	URL string
+	// Unhealthy lists the sub-components that are not Healthy.
+	Unhealthy []SubComponentStatus
+}
+
+// SubComponentStatus is the status of one sub-component.
+type SubComponentStatus struct {
+	ComponentSlug    string
+	SubComponentSlug string
+	Status           types.Status
+}
+
+// counts renders how many sub-components are in each unhealthy status, e.g. "2 Down, 1 Degraded".
+func (s *SlackChannelStatusSummary) counts() string {
+	var counts []string
+	for _, status := range channelStatusOrder {
+		n := 0
+		for _, sub := range s.Unhealthy {
+			if sub.Status == status {
+				n++
+			}
+		}
+		if n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, status))
+		}
+	}
+	return strings.Join(counts, ", ")
+}
+
+// FormatSlackChannelTopic renders summary as a channel topic, or "" when every sub-component is healthy.
+func FormatSlackChannelTopic(summary *SlackChannelStatusSummary) string {
+	if len(summary.Unhealthy) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s: %s — %s", summary.Label, summary.counts(), summary.URL)
+}
+
+// FormatSlackChannelPinnedStatus renders summary as a pinned status message listing each unhealthy
+// sub-component, or "" when every sub-component is healthy.
+func FormatSlackChannelPinnedStatus(summary *SlackChannelStatusSummary, configManager *config.Manager[types.DashboardConfig]) string {
+	if len(summary.Unhealthy) == 0 {
+		return ""
+	}
+	parts := []string{fmt.Sprintf("📌 *%s status: %s*", summary.Label, summary.counts())}
+	for _, status := range channelStatusOrder {
+		for _, sub := range summary.Unhealthy {
+			if sub.Status != status {
+				continue
+			}
+			componentName, subComponentName := resolveDisplayNames(configManager, &types.Outage{ComponentName: sub.ComponentSlug, SubComponentName: sub.SubComponentSlug})
+			parts = append(parts, fmt.Sprintf("• %s/%s: `%s`", componentName, subComponentName, sub.Status))
+		}
+	}
+	parts = append(parts, "", fmt.Sprintf("<%s|View Dashboard>", summary.URL))
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,43 @@
+package outage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"ship-status-dash/pkg/types"
+)
+
+func TestFormatSlackChannelStatus(t *testing.T) {
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug:          "build-farm",
+				Name:          "Build Farm",
+				Subcomponents: []types.SubComponent{{Slug: "build01", Name: "build01"}, {Slug: "build02", Name: "build02"}, {Slug: "build03", Name: "build03"}},
+			},
+		},
+	}
+	configManager := newNotifierTestConfigManager(t, cfg)
+	summary := &SlackChannelStatusSummary{
+		Label: "Build Farm",
+		URL:   "https://test.example.com/build-farm",
+		Unhealthy: []SubComponentStatus{
+			{ComponentSlug: "build-farm", SubComponentSlug: "build01", Status: types.StatusDegraded},
+			{ComponentSlug: "build-farm", SubComponentSlug: "build02", Status: types.StatusDown},
+			{ComponentSlug: "build-farm", SubComponentSlug: "build03", Status: types.StatusDown},
+		},
+	}
+
+	assert.Equal(t, "Build Farm: 2 Down, 1 Degraded — https://test.example.com/build-farm", FormatSlackChannelTopic(summary))
+	assert.Equal(t, "📌 *Build Farm status: 2 Down, 1 Degraded*\n"+
+		"• Build Farm/build02: `Down`\n"+
+		"• Build Farm/build03: `Down`\n"+
+		"• Build Farm/build01: `Degraded`\n"+
+		"\n"+
+		"<https://test.example.com/build-farm|View Dashboard>", FormatSlackChannelPinnedStatus(summary, configManager))
+
+	healthy := &SlackChannelStatusSummary{Label: "Build Farm", URL: "https://test.example.com/build-farm"}
+	assert.Empty(t, FormatSlackChannelTopic(healthy))
+	assert.Empty(t, FormatSlackChannelPinnedStatus(healthy, configManager))
+}
@@ -0,0 +1,144 @@
+package outage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/types"
+)
+
+const (
+	digestTimeFormat = "Mon Jan 2 15:04 MST"
+	// maxShortDescriptionLength keeps each digest or grouped message entry to a single short line.
+	maxShortDescriptionLength = 100
+)
+
+// SlackDigest is the outage activity summarized by a scheduled Slack digest.
+type SlackDigest struct {
+	Schedule    types.DigestSchedule
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	// Open holds active, confirmed outages.
+	Open []types.Outage
+	// Unconfirmed holds active outages waiting for an admin to confirm them.
+	Unconfirmed []types.Outage
+	// Suspected holds active suspected outages raised by user reports.
+	Suspected []types.Outage
+	// Opened and Resolved hold outages that started or ended during the period.
+	Opened   []types.Outage
+	Resolved []types.Outage
+	// AbsentReports lists monitored sub-components whose component-monitor has stopped reporting.
+	AbsentReports []AbsentMonitorReport
+}
+
+// AbsentMonitorReport is a monitored sub-component that has missed its reporting threshold.
+type AbsentMonitorReport struct {
+	ComponentSlug    string
+	SubComponentSlug string
+	Reason           string
+}
+
+func (d *SlackDigest) empty() bool {
+	return len(d.Open) == 0 && len(d.Unconfirmed) == 0 && len(d.Suspected) == 0 &&
+		len(d.Opened) == 0 && len(d.Resolved) == 0 && len(d.AbsentReports) == 0
+}
+
+// PublicView returns a copy of the digest with the public view of each outage, for channels that are not internal.
+func (d *SlackDigest) PublicView() *SlackDigest {
+	public := *d
+	public.Open = types.PublicOutages(d.Open)
+	public.Unconfirmed = types.PublicOutages(d.Unconfirmed)
+	public.Suspected = types.PublicOutages(d.Suspected)
+	public.Opened = types.PublicOutages(d.Opened)
+	public.Resolved = types.PublicOutages(d.Resolved)
+	return &public
+}
+
+// FormatSlackDigest renders digest as a Slack message. Outages link to their page under baseURL.
+func FormatSlackDigest(digest *SlackDigest, configManager *config.Manager[types.DashboardConfig], baseURL string) string {
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	title := "Daily"
+	if digest.Schedule == types.DigestScheduleWeekly {
+		title = "Weekly"
+	}
+	parts := []string{
+		fmt.Sprintf("📰 %s Outage Digest: %s to %s", title, digest.PeriodStart.Format(digestTimeFormat), digest.PeriodEnd.Format(digestTimeFormat)),
+	}
+	if digest.empty() {
+		parts = append(parts, "", "✅ No open outages and no outage activity in this period.")
+		return strings.Join(parts, "\n")
+	}
+
+	now := digest.PeriodEnd
+	link := func(outage *types.Outage) string {
+		componentName, subComponentName := resolveDisplayNames(configManager, outage)
+		return fmt.Sprintf("<%s|%s/%s>", buildOutageURL(baseURL, outage), componentName, subComponentName)
+	}
+	section := func(heading string, outages []types.Outage, line func(outage *types.Outage) string) {
+		if len(outages) == 0 {
+			return
+		}
+		parts = append(parts, "", fmt.Sprintf("*%s (%d)*", heading, len(outages)))
+		for i := range outages {
+			parts = append(parts, "• "+line(&outages[i]))
+		}
+	}
+
+	section("Open outages", digest.Open, func(o *types.Outage) string {
+		return fmt.Sprintf("%s `%s` for %s%s", link(o), o.Severity, formatShortDuration(now.Sub(o.StartTime)), shortDescription(o))
+	})
+	section("Awaiting confirmation", digest.Unconfirmed, func(o *types.Outage) string {
+		return fmt.Sprintf("%s `%s` for %s, created by `%s`%s", link(o), o.Severity, formatShortDuration(now.Sub(o.StartTime)), o.CreatedBy, shortDescription(o))
+	})
+	section("Suspected reports", digest.Suspected, func(o *types.Outage) string {
+		reports := "reported by 1 user"
+		if len(o.Reports) != 1 {
+			reports = fmt.Sprintf("reported by %d users", len(o.Reports))
+		}
+		return fmt.Sprintf("%s %s, open for %s", link(o), reports, formatShortDuration(now.Sub(o.StartTime)))
+	})
+	if len(digest.AbsentReports) > 0 {
+		parts = append(parts, "", fmt.Sprintf("*Missing monitor reports (%d)*", len(digest.AbsentReports)))
+		for _, absent := range digest.AbsentReports {
+			componentName, subComponentName := resolveDisplayNames(configManager, &types.Outage{ComponentName: absent.ComponentSlug, SubComponentName: absent.SubComponentSlug})
+			parts = append(parts, fmt.Sprintf("• %s/%s: %s", componentName, subComponentName, absent.Reason))
+		}
+	}
+	section("Opened", digest.Opened, func(o *types.Outage) string {
+		return fmt.Sprintf("%s `%s` at %s", link(o), o.Severity, o.StartTime.In(now.Location()).Format(digestTimeFormat))
+	})
+	section("Resolved", digest.Resolved, func(o *types.Outage) string {
+		return fmt.Sprintf("%s `%s`, lasted %s", link(o), o.Severity, formatShortDuration(o.EndTime.Time.Sub(o.StartTime)))
+	})
+
+	return strings.Join(parts, "\n")
+}
+
+// shortDescription returns the first line of the outage description, shortened for a one-line entry.
+func shortDescription(outage *types.Outage) string {
+	description, _, _ := strings.Cut(strings.TrimSpace(outage.Description), "\n")
+	if description == "" {
+		return ""
+	}
+	return ": " + truncate(description, maxShortDescriptionLength)
+}
+
+// formatShortDuration renders d to the minute, e.g. 45m, 3h20m or 2d4h.
+func formatShortDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := d / (24 * time.Hour)
+	hours := (d % (24 * time.Hour)) / time.Hour
+	minutes := (d % time.Hour) / time.Minute
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
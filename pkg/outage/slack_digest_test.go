@@ -0,0 +1,94 @@
+package outage
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/types"
+)
+
+func TestFormatSlackDigest(t *testing.T) {
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug:          "build-farm",
+				Name:          "Build Farm",
+				Subcomponents: []types.SubComponent{{Slug: "build01", Name: "build01"}, {Slug: "build02", Name: "build02"}},
+			},
+		},
+	}
+	configManager := newNotifierTestConfigManager(t, cfg)
+	end := time.Date(2024, 1, 17, 9, 0, 0, 0, time.UTC)
+	outageAt := func(id uint, sub string, severity types.Severity, start time.Time) types.Outage {
+		return types.Outage{Model: gorm.Model{ID: id}, ComponentName: "build-farm", SubComponentName: sub, Severity: severity, StartTime: start, CreatedBy: "alice"}
+	}
+
+	open := outageAt(1, "build01", types.SeverityDown, end.Add(-26*time.Hour-30*time.Minute))
+	open.Description = "Pods are failing to schedule\nmore details"
+	unconfirmed := outageAt(2, "build02", types.SeverityDegraded, end.Add(-90*time.Minute))
+	suspected := outageAt(3, "build02", types.SeveritySuspected, end.Add(-2*time.Hour))
+	suspected.Reports = []types.OutageReport{{User: "bob"}, {User: "carol"}}
+	resolved := outageAt(4, "build01", types.SeverityDegraded, end.Add(-5*time.Hour))
+	resolved.EndTime = sql.NullTime{Time: end.Add(-4*time.Hour - 15*time.Minute), Valid: true}
+
+	digest := &SlackDigest{
+		Schedule:      types.DigestScheduleDaily,
+		PeriodStart:   end.Add(-24 * time.Hour),
+		PeriodEnd:     end,
+		Open:          []types.Outage{open},
+		Unconfirmed:   []types.Outage{unconfirmed},
+		Suspected:     []types.Outage{suspected},
+		Opened:        []types.Outage{unconfirmed, resolved},
+		Resolved:      []types.Outage{resolved},
+		AbsentReports: []AbsentMonitorReport{{ComponentSlug: "build-farm", SubComponentSlug: "build02", Reason: "No report from component-monitor found"}},
+	}
+
+	assert.Equal(t, strings.Join([]string{
+		"📰 Daily Outage Digest: Tue Jan 16 09:00 UTC to Wed Jan 17 09:00 UTC",
+		"",
+		"*Open outages (1)*",
+		"• <https://test.example.com/build-farm/build01/outages/1|Build Farm/build01> `Down` for 1d2h: Pods are failing to schedule",
+		"",
+		"*Awaiting confirmation (1)*",
+		"• <https://test.example.com/build-farm/build02/outages/2|Build Farm/build02> `Degraded` for 1h30m, created by `alice`",
+		"",
+		"*Suspected reports (1)*",
+		"• <https://test.example.com/build-farm/build02/outages/3|Build Farm/build02> reported by 2 users, open for 2h0m",
+		"",
+		"*Missing monitor reports (1)*",
+		"• Build Farm/build02: No report from component-monitor found",
+		"",
+		"*Opened (2)*",
+		"• <https://test.example.com/build-farm/build02/outages/2|Build Farm/build02> `Degraded` at Wed Jan 17 07:30 UTC",
+		"• <https://test.example.com/build-farm/build01/outages/4|Build Farm/build01> `Degraded` at Wed Jan 17 04:00 UTC",
+		"",
+		"*Resolved (1)*",
+		"• <https://test.example.com/build-farm/build01/outages/4|Build Farm/build01> `Degraded`, lasted 45m",
+	}, "\n"), FormatSlackDigest(digest, configManager, "https://test.example.com"))
+
+	quiet := &SlackDigest{Schedule: types.DigestScheduleWeekly, PeriodStart: end.Add(-7 * 24 * time.Hour), PeriodEnd: end}
+	assert.Equal(t, "📰 Weekly Outage Digest: Wed Jan 10 09:00 UTC to Wed Jan 17 09:00 UTC\n\n✅ No open outages and no outage activity in this period.",
+		FormatSlackDigest(quiet, configManager, "https://test.example.com/"))
+}
+
+func TestSlackDigest_PublicView(t *testing.T) {
+	digest := &SlackDigest{
+		Schedule: types.DigestScheduleDaily,
+		Open: []types.Outage{
+			{Description: "bad deploy of the auth proxy", DescriptionVisibility: types.VisibilityInternal},
+			{Description: "registry is slow"},
+		},
+		AbsentReports: []AbsentMonitorReport{{ComponentSlug: "alpha", SubComponentSlug: "one"}},
+	}
+
+	public := digest.PublicView()
+	assert.Empty(t, public.Open[0].Description)
+	assert.Equal(t, "registry is slow", public.Open[1].Description)
+	assert.Equal(t, digest.AbsentReports, public.AbsentReports)
+	assert.Equal(t, "bad deploy of the auth proxy", digest.Open[0].Description, "the original digest is unchanged")
+}
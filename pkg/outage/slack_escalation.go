@@ -0,0 +1,41 @@
+package outage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/types"
+)
+
+// SlackMention renders a Slack user (U...) or user group (S...) ID as a mention.
+func SlackMention(id string) string {
+	if strings.HasPrefix(id, "S") {
+		return fmt.Sprintf("<!subteam^%s>", id)
+	}
+	return fmt.Sprintf("<@%s>", id)
+}
+
+// FormatEscalationReminder renders the reminder posted for an outage that has had no update or triage note
+// for quietFor. mentionIDs are Slack user or user group IDs to mention.
+func FormatEscalationReminder(outage *types.Outage, configManager *config.Manager[types.DashboardConfig], baseURL string, now time.Time, quietFor time.Duration, mentionIDs []string) string {
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	componentName, subComponentName := resolveDisplayNames(configManager, outage)
+	parts := []string{
+		fmt.Sprintf("⏰ Outage needs attention: %s/%s (#%d)", componentName, subComponentName, outage.ID),
+		"",
+		fmt.Sprintf("`%s` for %s with no update or triage note for %s.", outage.Severity, formatShortDuration(now.Sub(outage.StartTime)), formatShortDuration(quietFor)),
+	}
+	if len(mentionIDs) > 0 {
+		mentions := make([]string, 0, len(mentionIDs))
+		for _, id := range mentionIDs {
+			mentions = append(mentions, SlackMention(id))
+		}
+		parts = append(parts, strings.Join(mentions, " "))
+	}
+	parts = append(parts, "", fmt.Sprintf("<%s|View Outage>", buildOutageURL(baseURL, outage)))
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,277 @@
+package outage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"ship-status-dash/pkg/types"
+)
+
+// DefaultSlackQueueSize is the number of notifications a SlackNotificationQueue holds when QueueSize is unset.
+const DefaultSlackQueueSize = 1000
+
+// SlackQueueConfig tunes how a SlackNotificationQueue batches and paces Slack posts.
+type SlackQueueConfig struct {
+	// CoalesceWindow is how long a new outage waits for others bound for the same channel. Two or more
+	// outages in one window are announced in a single grouped message. Zero posts every outage on its own.
+	CoalesceWindow time.Duration
+	// ChannelInterval is the minimum time between posts to the same channel.
+	ChannelInterval time.Duration
+	// MaxRetries is how many times a post rejected by Slack's rate limit is retried after its Retry-After.
+	MaxRetries int
+	// QueueSize is how many notifications can wait for delivery before new ones are dropped.
+	QueueSize int
+}
+
+// slackNotification is a queued outage event. oldOutage is nil for a new outage.
+type slackNotification struct {
+	outage    *types.Outage
+	oldOutage *types.Outage
+}
+
+// pendingSlackGroup holds the new outages waiting to be announced in a channel.
+type pendingSlackGroup struct {
+	deadline time.Time
+	outages  []*types.Outage
+}
+
+// SlackNotificationQueue delivers SlackReporter notifications from a single background worker, so outage
+// changes never wait on Slack. New outages bound for the same channel within CoalesceWindow are announced
+// together, posts to a channel are spaced by ChannelInterval, and posts rate limited by Slack are retried.
+type SlackNotificationQueue struct {
+	reporter      *SlackReporter
+	config        SlackQueueConfig
+	notifications chan slackNotification
+	logger        *logrus.Logger
+
+	// The fields below are only used by the worker.
+	ctx      context.Context
+	pending  map[string]*pendingSlackGroup
+	lastPost map[string]time.Time
+}
+
+// NewSlackNotificationQueue creates a SlackNotificationQueue that delivers through reporter. All of reporter's
+// posts are paced and retried by the queue, so reporter should only be used through it afterwards.
+func NewSlackNotificationQueue(reporter *SlackReporter, config SlackQueueConfig, logger *logrus.Logger) *SlackNotificationQueue {
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultSlackQueueSize
+	}
+	q := &SlackNotificationQueue{
+		reporter:      reporter,
+		config:        config,
+		notifications: make(chan slackNotification, config.QueueSize),
+		logger:        logger,
+		ctx:           context.Background(),
+		pending:       map[string]*pendingSlackGroup{},
+		lastPost:      map[string]time.Time{},
+	}
+	reporter.poster = q.post
+	return q
+}
+
+func (q *SlackNotificationQueue) Name() string {
+	return q.reporter.Name()
+}
+
+// OutageCreated implements Notifier by queueing the outage to be announced.
+func (q *SlackNotificationQueue) OutageCreated(outage *types.Outage) error {
+	return q.enqueue(outage, nil)
+}
+
+// OutageUpdated implements Notifier by queueing a reply to the outage's threads.
+func (q *SlackNotificationQueue) OutageUpdated(outage, oldOutage *types.Outage) error {
+	return q.enqueue(outage, oldOutage)
+}
+
+// OutageResolved implements Notifier by queueing a reply to the outage's threads.
+func (q *SlackNotificationQueue) OutageResolved(outage, oldOutage *types.Outage) error {
+	return q.enqueue(outage, oldOutage)
+}
+
+// enqueue copies the outages, as callers may keep changing them, and hands them to the worker without blocking.
+func (q *SlackNotificationQueue) enqueue(outage, oldOutage *types.Outage) error {
+	notification := slackNotification{outage: copyOutage(outage), oldOutage: copyOutage(oldOutage)}
+	select {
+	case q.notifications <- notification:
+		return nil
+	default:
+		return errors.New("slack notification queue is full")
+	}
+}
+
+func copyOutage(outage *types.Outage) *types.Outage {
+	if outage == nil {
+		return nil
+	}
+	c := *outage
+	c.TriageNotes = append([]types.TriageNote(nil), outage.TriageNotes...)
+	c.Links = append([]types.OutageLink(nil), outage.Links...)
+	return &c
+}
+
+// Start delivers queued notifications until ctx is done. Notifications still queued and outages waiting to be
+// announced are then delivered without pacing.
+func (q *SlackNotificationQueue) Start(ctx context.Context) {
+	q.logger.WithFields(logrus.Fields{
+		"coalesce_window":  q.config.CoalesceWindow,
+		"channel_interval": q.config.ChannelInterval,
+	}).Info("Starting Slack notification queue")
+	q.ctx = ctx
+
+	for {
+		var flush <-chan time.Time
+		if deadline, ok := q.nextDeadline(); ok {
+			flush = time.After(time.Until(deadline))
+		}
+		select {
+		case <-ctx.Done():
+			q.drain()
+			q.logger.Info("Stopping Slack notification queue")
+			return
+		case notification := <-q.notifications:
+			q.handle(notification, time.Now())
+		case now := <-flush:
+			q.flushDue(now)
+		}
+	}
+}
+
+func (q *SlackNotificationQueue) drain() {
+	for {
+		select {
+		case notification := <-q.notifications:
+			q.handle(notification, time.Now())
+		default:
+			q.flushDue(time.Time{})
+			return
+		}
+	}
+}
+
+func (q *SlackNotificationQueue) handle(notification slackNotification, now time.Time) {
+	outage := notification.outage
+	logger := q.logger.WithField("outage_id", outage.ID)
+
+	if notification.oldOutage == nil {
+		channels := q.reporter.channelsForOutage(outage)
+		if q.config.CoalesceWindow <= 0 {
+			if len(channels) > 0 {
+				if err := q.reporter.reportOutageToChannels(outage, channels); err != nil {
+					logger.WithField("error", err).Error("Failed to report outage to Slack")
+				}
+			}
+			return
+		}
+		for _, channel := range channels {
+			group, ok := q.pending[channel]
+			if !ok {
+				group = &pendingSlackGroup{deadline: now.Add(q.config.CoalesceWindow)}
+				q.pending[channel] = group
+			}
+			group.outages = append(group.outages, outage)
+		}
+		return
+	}
+
+	// The outage must be announced before its update can be replied to its threads.
+	for channel, group := range q.pending {
+		for _, pending := range group.outages {
+			if pending.ID == outage.ID {
+				q.flushChannel(channel)
+				break
+			}
+		}
+	}
+	if err := q.reporter.ReportOutageUpdate(outage, notification.oldOutage); err != nil {
+		logger.WithField("error", err).Error("Failed to report outage update to Slack")
+	}
+}
+
+func (q *SlackNotificationQueue) nextDeadline() (time.Time, bool) {
+	var next time.Time
+	for _, group := range q.pending {
+		if next.IsZero() || group.deadline.Before(next) {
+			next = group.deadline
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// flushDue announces the outages of every channel whose window has closed by now. A zero now flushes all channels.
+func (q *SlackNotificationQueue) flushDue(now time.Time) {
+	for channel, group := range q.pending {
+		if now.IsZero() || !group.deadline.After(now) {
+			q.flushChannel(channel)
+		}
+	}
+}
+
+// flushChannel announces the outages waiting for channel, grouped into one message when there are several.
+func (q *SlackNotificationQueue) flushChannel(channel string) {
+	group, ok := q.pending[channel]
+	if !ok {
+		return
+	}
+	delete(q.pending, channel)
+
+	if len(group.outages) == 1 {
+		if err := q.reporter.reportOutageToChannels(group.outages[0], []string{channel}); err != nil {
+			q.logger.WithFields(logrus.Fields{
+				"outage_id": group.outages[0].ID,
+				"channel":   channel,
+				"error":     err,
+			}).Error("Failed to report outage to Slack")
+		}
+		return
+	}
+	if err := q.reporter.postOutageGroup(channel, group.outages); err != nil {
+		q.logger.WithFields(logrus.Fields{
+			"channel":      channel,
+			"outage_count": len(group.outages),
+			"error":        err,
+		}).Error("Failed to report grouped outages to Slack")
+	}
+}
+
+// post spaces posts to channel by ChannelInterval and retries posts rate limited by Slack after their Retry-After.
+func (q *SlackNotificationQueue) post(channel string, options ...slack.MsgOption) (string, string, error) {
+	if last, ok := q.lastPost[channel]; ok {
+		q.sleep(time.Until(last.Add(q.config.ChannelInterval)))
+	}
+	for attempt := 0; ; attempt++ {
+		channelID, timestamp, err := q.reporter.slackClient.PostMessage(channel, options...)
+		q.lastPost[channel] = time.Now()
+
+		var rateLimited *slack.RateLimitedError
+		if !errors.As(err, &rateLimited) || attempt >= q.config.MaxRetries {
+			return channelID, timestamp, err
+		}
+		q.logger.WithFields(logrus.Fields{
+			"channel":     channel,
+			"retry_after": rateLimited.RetryAfter,
+			"attempt":     attempt + 1,
+		}).Warn("Slack rate limited post, retrying")
+		if !q.sleep(rateLimited.RetryAfter) {
+			return channelID, timestamp, err
+		}
+	}
+}
+
+// sleep waits for d and reports false if the queue was stopped first.
+func (q *SlackNotificationQueue) sleep(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-q.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,180 @@
+package outage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+func newSlackQueueTest(t *testing.T, queueConfig SlackQueueConfig) (*SlackNotificationQueue, *MockSlackServer, *repositories.MockSlackThreadRepository) {
+	t.Helper()
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug:           "build-farm",
+				Name:           "Build Farm",
+				SlackReporting: []types.SlackReportingConfig{{Channel: "#build-farm"}},
+				Subcomponents:  []types.SubComponent{{Slug: "build01", Name: "build01"}, {Slug: "build02", Name: "build02"}},
+			},
+			{
+				Slug:           "prow",
+				Name:           "Prow",
+				SlackReporting: []types.SlackReportingConfig{{Channel: "#prow"}},
+				Subcomponents:  []types.SubComponent{{Slug: "deck", Name: "Deck"}},
+			},
+		},
+	}
+	slackServer := NewMockSlackServer(t)
+	t.Cleanup(slackServer.Close)
+	threadRepo := &repositories.MockSlackThreadRepository{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	reporter := NewSlackReporter(slackServer.Client(), threadRepo, newNotifierTestConfigManager(t, cfg), "https://test.example.com/", "https://rhsandbox.slack.com/", logger)
+	return NewSlackNotificationQueue(reporter, queueConfig, logger), slackServer, threadRepo
+}
+
+func queueTestOutage(id uint, component, sub string) *types.Outage {
+	return &types.Outage{
+		Model:            gorm.Model{ID: id},
+		ComponentName:    component,
+		SubComponentName: sub,
+		Severity:         types.SeverityDown,
+		StartTime:        time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		CreatedBy:        "system",
+		DiscoveredFrom:   "component-monitor",
+	}
+}
+
+func TestSlackNotificationQueue_Coalescing(t *testing.T) {
+	queue, slackServer, threadRepo := newSlackQueueTest(t, SlackQueueConfig{CoalesceWindow: time.Minute})
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	first := queueTestOutage(1, "build-farm", "build01")
+	first.Description = "Nodes are NotReady\nmore details"
+	queue.handle(slackNotification{outage: first}, now)
+	queue.handle(slackNotification{outage: queueTestOutage(2, "build-farm", "build02")}, now.Add(10*time.Second))
+	queue.handle(slackNotification{outage: queueTestOutage(3, "prow", "deck")}, now.Add(20*time.Second))
+	assert.Empty(t, slackServer.PostedMessages(), "new outages wait for the coalesce window")
+
+	queue.flushDue(now.Add(30 * time.Second))
+	assert.Empty(t, slackServer.PostedMessages())
+
+	queue.flushDue(now.Add(time.Minute))
+	posted := slackServer.PostedMessages()
+	require.Len(t, posted, 1, "only #build-farm's window has closed")
+	assert.Equal(t, "#build-farm", posted[0].Channel)
+	assert.Equal(t, "🚨 2 Outages Detected\n\n"+
+		"• <https://test.example.com/build-farm/build01/outages/1|Build Farm/build01> (#1) `Down`: Nodes are NotReady\n"+
+		"• <https://test.example.com/build-farm/build02/outages/2|Build Farm/build02> (#2) `Down`\n\n"+
+		"Updates to these outages are posted in this thread.", posted[0].Text)
+	require.Len(t, threadRepo.CreatedThreads, 2)
+	for i, thread := range threadRepo.CreatedThreads {
+		assert.Equal(t, uint(i+1), thread.OutageID)
+		assert.Equal(t, posted[0].ResponseTS, thread.ThreadTimestamp)
+		assert.True(t, thread.Grouped)
+	}
+
+	queue.flushDue(now.Add(80 * time.Second))
+	posted = slackServer.PostedMessages()
+	require.Len(t, posted, 2)
+	assert.Equal(t, "#prow", posted[1].Channel)
+	assert.Contains(t, posted[1].Text, "🚨 Outage Detected: Prow/Deck", "a lone outage gets the usual message")
+	assert.False(t, threadRepo.CreatedThreads[2].Grouped)
+
+	// Resolving one outage of a group replies in the shared thread without marking the message resolved.
+	threadRepo.ThreadsForOutage = []types.SlackThread{*threadRepo.CreatedThreads[0]}
+	resolved := copyOutage(first)
+	resolved.EndTime = sql.NullTime{Time: now.Add(time.Hour), Valid: true}
+	queue.handle(slackNotification{outage: resolved, oldOutage: first}, now.Add(time.Hour))
+	posted = slackServer.PostedMessages()
+	require.Len(t, posted, 3)
+	assert.Equal(t, posted[0].ResponseTS, posted[2].ThreadTimestamp)
+	assert.Contains(t, posted[2].Text, "Outage Updated: Build Farm/build01 (#1)")
+	assert.Empty(t, slackServer.AddedReactions())
+}
+
+func TestSlackNotificationQueue_UpdateFlushesPendingOutage(t *testing.T) {
+	queue, slackServer, threadRepo := newSlackQueueTest(t, SlackQueueConfig{CoalesceWindow: time.Minute})
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	created := queueTestOutage(1, "build-farm", "build01")
+	queue.handle(slackNotification{outage: created}, now)
+	threadRepo.CreateThreadFn = func(thread *types.SlackThread) {
+		threadRepo.ThreadsForOutage = append(threadRepo.ThreadsForOutage, *thread)
+	}
+
+	updated := copyOutage(created)
+	updated.Severity = types.SeverityDegraded
+	queue.handle(slackNotification{outage: updated, oldOutage: created}, now.Add(time.Second))
+
+	posted := slackServer.PostedMessages()
+	require.Len(t, posted, 2)
+	assert.Contains(t, posted[0].Text, "🚨 Outage Detected: Build Farm/build01")
+	assert.Contains(t, posted[0].Text, "Severity: `Down`", "the outage is announced as it was created")
+	assert.Equal(t, posted[0].ResponseTS, posted[1].ThreadTimestamp)
+	assert.Contains(t, posted[1].Text, "Severity changed: `Down` → `Degraded`")
+	assert.Empty(t, queue.pending)
+}
+
+func TestSlackNotificationQueue_RetriesRateLimitedPosts(t *testing.T) {
+	queue, slackServer, _ := newSlackQueueTest(t, SlackQueueConfig{MaxRetries: 1})
+	slackServer.RateLimitPosts(1, 1)
+
+	started := time.Now()
+	queue.handle(slackNotification{outage: queueTestOutage(1, "build-farm", "build01")}, started)
+	assert.GreaterOrEqual(t, time.Since(started), time.Second, "the retry waits for Retry-After")
+	assert.Equal(t, 1, slackServer.RejectedPosts())
+	assert.Len(t, slackServer.PostedMessages(), 1)
+
+	slackServer.RateLimitPosts(2, 0)
+	queue.handle(slackNotification{outage: queueTestOutage(2, "build-farm", "build01")}, time.Now())
+	assert.Equal(t, 3, slackServer.RejectedPosts())
+	assert.Len(t, slackServer.PostedMessages(), 1, "a post is given up after MaxRetries")
+}
+
+func TestSlackNotificationQueue_ChannelInterval(t *testing.T) {
+	queue, slackServer, _ := newSlackQueueTest(t, SlackQueueConfig{ChannelInterval: 200 * time.Millisecond})
+
+	started := time.Now()
+	queue.handle(slackNotification{outage: queueTestOutage(1, "build-farm", "build01")}, started)
+	queue.handle(slackNotification{outage: queueTestOutage(2, "prow", "deck")}, started)
+	assert.Less(t, time.Since(started), 200*time.Millisecond, "different channels are not paced against each other")
+	queue.handle(slackNotification{outage: queueTestOutage(3, "build-farm", "build02")}, started)
+	assert.GreaterOrEqual(t, time.Since(started), 200*time.Millisecond)
+	assert.Len(t, slackServer.PostedMessages(), 3)
+}
+
+func TestSlackNotificationQueue_DeliversAsynchronously(t *testing.T) {
+	queue, slackServer, _ := newSlackQueueTest(t, SlackQueueConfig{CoalesceWindow: 50 * time.Millisecond, QueueSize: 2})
+	outage := queueTestOutage(1, "build-farm", "build01")
+
+	require.NoError(t, queue.OutageCreated(outage))
+	require.NoError(t, queue.OutageCreated(queueTestOutage(2, "build-farm", "build02")))
+	assert.EqualError(t, queue.OutageCreated(queueTestOutage(3, "prow", "deck")), "slack notification queue is full")
+	outage.Severity = types.SeverityDegraded
+	assert.Empty(t, slackServer.PostedMessages(), "nothing is posted until the worker runs")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		queue.Start(ctx)
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return len(slackServer.PostedMessages()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, slackServer.PostedMessages()[0].Text, "🚨 2 Outages Detected")
+	assert.Contains(t, slackServer.PostedMessages()[0].Text, "(#1) `Down`", "the queued outage is not changed by its caller")
+
+	require.NoError(t, queue.OutageCreated(queueTestOutage(3, "prow", "deck")))
+	cancel()
+	<-done
+	assert.Len(t, slackServer.PostedMessages(), 2, "pending outages are announced when the queue stops")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
@@ -22,6 +23,10 @@ type SlackReporter struct {
 	baseURL           string
 	slackWorkspaceURL string
 	logger            *logrus.Logger
+	// interactive adds the outage action buttons to new outage messages.
+	interactive bool
+	// poster replaces slackClient.PostMessage when set, e.g. to pace and retry posts through a queue.
+	poster func(channel string, options ...slack.MsgOption) (string, string, error)
 }
 
 // NewSlackReporter creates a new SlackReporter instance.
@@ -53,25 +58,71 @@ func NewSlackReporter(
 
 // ReportOutage reports a new outage to Slack channels.
 func (r *SlackReporter) ReportOutage(outage *types.Outage) error {
-	reporting := r.getSlackReportingForSubComponent(outage.ComponentName, outage.SubComponentName)
-	if len(reporting) == 0 {
+	channels := r.channelsForOutage(outage)
+	if len(channels) == 0 {
 		return nil
 	}
+	return r.reportOutageToChannels(outage, channels)
+}
 
-	channels := filterChannelsBySeverity(reporting, outage.Severity)
-	if len(channels) == 0 {
+// channelsForOutage returns the channels whose slack_reporting severity threshold the outage meets.
+func (r *SlackReporter) channelsForOutage(outage *types.Outage) []string {
+	reporting := r.getSlackReportingForSubComponent(outage.ComponentName, outage.SubComponentName)
+	if len(reporting) == 0 {
 		return nil
 	}
+	return filterChannelsBySeverity(reporting, outage.Severity)
+}
 
+func (r *SlackReporter) reportOutageToChannels(outage *types.Outage, channels []string) error {
 	cfg := r.configManager.Get()
 	component := cfg.GetComponentBySlug(outage.ComponentName)
 	if component == nil {
 		return fmt.Errorf("component not found: %s", outage.ComponentName)
 	}
 
-	message := r.formatOutageMessage(outage, component)
+	var internal, public []string
+	for _, channel := range channels {
+		if r.isInternalChannel(outage, channel) {
+			internal = append(internal, channel)
+		} else {
+			public = append(public, channel)
+		}
+	}
+
+	var lastErr error
+	if len(internal) > 0 {
+		if err := r.postToSlackChannels(outage, internal, r.formatOutageMessage(outage, component)); err != nil {
+			lastErr = err
+		}
+	}
+	if len(public) > 0 {
+		publicOutage := outage.PublicView()
+		if err := r.postToSlackChannels(&publicOutage, public, r.formatOutageMessage(&publicOutage, component)); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// isInternalChannel reports whether the slack_reporting entry for channel on the outage's sub-component has
+// internal visibility.
+func (r *SlackReporter) isInternalChannel(outage *types.Outage, channel string) bool {
+	for _, reporting := range r.getSlackReportingForSubComponent(outage.ComponentName, outage.SubComponentName) {
+		if reporting.Channel == channel {
+			return reporting.Visibility.IsInternal()
+		}
+	}
+	return false
+}
 
-	return r.postToSlackChannels(outage, channels, message)
+// outageForChannel returns outage whole for internal channels and its public view for the others.
+func (r *SlackReporter) outageForChannel(outage *types.Outage, channel string) *types.Outage {
+	if r.isInternalChannel(outage, channel) {
+		return outage
+	}
+	public := outage.PublicView()
+	return &public
 }
 
 // ReportOutageUpdate reports an outage update to existing Slack threads.
@@ -91,9 +142,59 @@ func (r *SlackReporter) ReportOutageUpdate(outage *types.Outage, oldOutage *type
 		return r.ReportOutage(outage)
 	}
 
-	message := r.formatUpdateMessage(outage, oldOutage)
+	// A reply mirrored from a thread is already visible there.
+	if addedMirroredSlackReply(outage, oldOutage) {
+		return nil
+	}
 
-	return r.replyToSlackThreads(outage, threads, message)
+	var internal, public []types.SlackThread
+	for _, thread := range threads {
+		if r.isInternalChannel(outage, thread.Channel) {
+			internal = append(internal, thread)
+		} else {
+			public = append(public, thread)
+		}
+	}
+
+	var lastErr error
+	if len(internal) > 0 {
+		if err := r.replyToSlackThreads(outage, internal, r.formatUpdateMessage(outage, oldOutage)); err != nil {
+			lastErr = err
+		}
+	}
+	publicOutage, publicOld := outage.PublicView(), oldOutage.PublicView()
+	// Public threads hear nothing of updates that only touched internal items.
+	if len(public) > 0 && (len(slackUpdateChanges(&publicOutage, &publicOld)) > 0 || len(slackUpdateChanges(outage, oldOutage)) == 0) {
+		if err := r.replyToSlackThreads(&publicOutage, public, r.formatUpdateMessage(&publicOutage, &publicOld)); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// SetInteractive controls whether new outage messages carry the Resolve, Change severity, Add triage note
+// and Confirm actions. It should only be enabled when the dashboard serves the Slack interactivity endpoint.
+func (r *SlackReporter) SetInteractive(interactive bool) {
+	r.interactive = interactive
+}
+
+func (r *SlackReporter) Name() string {
+	return "slack"
+}
+
+// OutageCreated implements Notifier by posting a new thread to each channel whose severity threshold is met.
+func (r *SlackReporter) OutageCreated(outage *types.Outage) error {
+	return r.ReportOutage(outage)
+}
+
+// OutageUpdated implements Notifier by replying to the outage's existing Slack threads.
+func (r *SlackReporter) OutageUpdated(outage, oldOutage *types.Outage) error {
+	return r.ReportOutageUpdate(outage, oldOutage)
+}
+
+// OutageResolved implements Notifier. The thread reply also marks the original message as resolved.
+func (r *SlackReporter) OutageResolved(outage, oldOutage *types.Outage) error {
+	return r.ReportOutageUpdate(outage, oldOutage)
 }
 
 func (r *SlackReporter) getSlackReportingForSubComponent(componentSlug, subComponentSlug string) []types.SlackReportingConfig {
@@ -135,9 +236,14 @@ func filterChannelsBySeverity(reporting []types.SlackReportingConfig, outageSeve
 }
 
 func (r *SlackReporter) buildOutageLink(outage *types.Outage) string {
+	return buildOutageURL(r.baseURL, outage)
+}
+
+// buildOutageURL returns the dashboard page of outage. baseURL must end with a slash.
+func buildOutageURL(baseURL string, outage *types.Outage) string {
 	componentSlug := utils.Slugify(outage.ComponentName)
 	subComponentSlug := utils.Slugify(outage.SubComponentName)
-	return fmt.Sprintf("%s%s/%s/outages/%d", r.baseURL, componentSlug, subComponentSlug, outage.ID)
+	return fmt.Sprintf("%s%s/%s/outages/%d", baseURL, componentSlug, subComponentSlug, outage.ID)
 }
 
 func (r *SlackReporter) buildThreadURL(channel string, timestamp string) string {
@@ -146,6 +252,14 @@ func (r *SlackReporter) buildThreadURL(channel string, timestamp string) string
 	return fmt.Sprintf("%sarchives/%s/p%s", r.slackWorkspaceURL, channelID, timestampID)
 }
 
+// postMessage posts through poster when set and directly to Slack otherwise.
+func (r *SlackReporter) postMessage(channel string, options ...slack.MsgOption) (string, string, error) {
+	if r.poster != nil {
+		return r.poster(channel, options...)
+	}
+	return r.slackClient.PostMessage(channel, options...)
+}
+
 func (r *SlackReporter) addResolvedEmoji(channelID string, timestamp string) error {
 	itemRef := slack.NewRefToMessage(channelID, timestamp)
 	return r.slackClient.AddReaction("outage_resolved", itemRef)
@@ -196,6 +310,20 @@ func (r *SlackReporter) formatUpdateMessage(outage *types.Outage, oldOutage *typ
 	parts = append(parts, fmt.Sprintf("%s Outage Updated: %s/%s (#%d)", emoji, componentName, subComponentName, outage.ID))
 	parts = append(parts, "")
 
+	changes := slackUpdateChanges(outage, oldOutage)
+	if len(changes) == 0 {
+		changes = append(changes, "Outage updated")
+	}
+
+	parts = append(parts, strings.Join(changes, "\n"))
+	parts = append(parts, "")
+	parts = append(parts, fmt.Sprintf("<%s|View Outage>", r.buildOutageLink(outage)))
+
+	return strings.Join(parts, "\n")
+}
+
+// slackUpdateChanges lists the lines describing what changed between oldOutage and outage.
+func slackUpdateChanges(outage, oldOutage *types.Outage) []string {
 	var changes []string
 
 	if oldOutage.Severity != outage.Severity {
@@ -254,24 +382,30 @@ func (r *SlackReporter) formatUpdateMessage(outage *types.Outage, oldOutage *typ
 		}
 	}
 
-	if len(changes) == 0 {
-		changes = append(changes, "Outage updated")
-	}
-
-	parts = append(parts, strings.Join(changes, "\n"))
-	parts = append(parts, "")
-	parts = append(parts, fmt.Sprintf("<%s|View Outage>", r.buildOutageLink(outage)))
+	return changes
+}
 
-	return strings.Join(parts, "\n")
+// addedMirroredSlackReply reports whether the update added a triage note mirrored from a Slack thread reply.
+func addedMirroredSlackReply(outage, oldOutage *types.Outage) bool {
+	if len(outage.TriageNotes) <= len(oldOutage.TriageNotes) {
+		return false
+	}
+	return outage.TriageNotes[len(outage.TriageNotes)-1].SlackMessageTS != nil
 }
 
 const maxTruncateLength = 240
 
 func truncateString(s string) string {
-	if len(s) <= maxTruncateLength {
+	return truncate(s, maxTruncateLength)
+}
+
+// truncate shortens s to at most maxLength characters, ending with "..." when it was cut. It counts and cuts
+// runes rather than bytes, so multi-byte characters are never split into invalid UTF-8.
+func truncate(s string, maxLength int) string {
+	if utf8.RuneCountInString(s) <= maxLength {
 		return s
 	}
-	return s[:maxTruncateLength-3] + "..."
+	return string([]rune(s)[:maxLength-3]) + "..."
 }
 
 func formatQuoteBlock(text string) string {
@@ -297,11 +431,16 @@ func (r *SlackReporter) postToSlackChannels(outage *types.Outage, channels []str
 			"channel":   channel,
 		})
 
-		channelID, timestamp, err := r.slackClient.PostMessage(
-			channel,
+		options := []slack.MsgOption{
 			slack.MsgOptionText(message, false),
 			slack.MsgOptionAsUser(true),
-		)
+		}
+		if r.interactive {
+			// The text stays as the notification fallback; the blocks add the outage actions.
+			subComponent := component.GetSubComponentBySlug(outage.SubComponentName)
+			options = append(options, slack.MsgOptionBlocks(buildOutageMessageBlocks(outage, subComponent, message)...))
+		}
+		channelID, timestamp, err := r.postMessage(channel, options...)
 
 		if err != nil {
 			logger.WithField("error", err).Error("Failed to post message to Slack")
@@ -345,7 +484,7 @@ func (r *SlackReporter) replyToSlackThreads(outage *types.Outage, threads []type
 			"thread_timestamp": thread.ThreadTimestamp,
 		})
 
-		_, _, err := r.slackClient.PostMessage(
+		_, _, err := r.postMessage(
 			thread.Channel,
 			slack.MsgOptionText(message, false),
 			slack.MsgOptionTS(thread.ThreadTimestamp),
@@ -358,7 +497,8 @@ func (r *SlackReporter) replyToSlackThreads(outage *types.Outage, threads []type
 			continue
 		}
 
-		if outage.EndTime.Valid {
+		// A grouped message also announced other outages, so it is not marked resolved for one of them.
+		if outage.EndTime.Valid && !thread.Grouped {
 			if err := r.addResolvedEmoji(thread.ChannelID, thread.ThreadTimestamp); err != nil {
 				logger.WithField("error", err).Warn("Failed to add resolved emoji to message")
 			}
@@ -369,3 +509,57 @@ func (r *SlackReporter) replyToSlackThreads(outage *types.Outage, threads []type
 
 	return lastErr
 }
+
+// formatOutageGroupMessage announces several outages posted to a channel together.
+func (r *SlackReporter) formatOutageGroupMessage(outages []*types.Outage) string {
+	parts := []string{fmt.Sprintf("🚨 %d Outages Detected", len(outages)), ""}
+	for _, outage := range outages {
+		componentName, subComponentName := resolveDisplayNames(r.configManager, outage)
+		parts = append(parts, fmt.Sprintf("• <%s|%s/%s> (#%d) `%s`%s", r.buildOutageLink(outage), componentName, subComponentName, outage.ID, outage.Severity, shortDescription(outage)))
+	}
+	parts = append(parts, "", "Updates to these outages are posted in this thread.")
+	return strings.Join(parts, "\n")
+}
+
+// postOutageGroup posts outages to channel as one message whose thread then carries the updates of every one of them.
+func (r *SlackReporter) postOutageGroup(channel string, outages []*types.Outage) error {
+	logger := r.logger.WithFields(logrus.Fields{
+		"channel":      channel,
+		"outage_count": len(outages),
+	})
+	visible := make([]*types.Outage, len(outages))
+	for i, outage := range outages {
+		visible[i] = r.outageForChannel(outage, channel)
+	}
+	channelID, timestamp, err := r.postMessage(channel,
+		slack.MsgOptionText(r.formatOutageGroupMessage(visible), false),
+		slack.MsgOptionAsUser(true),
+	)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to post grouped outages to Slack")
+		return err
+	}
+
+	var lastErr error
+	threadURL := r.buildThreadURL(channel, timestamp)
+	for _, outage := range outages {
+		thread := &types.SlackThread{
+			OutageID:        outage.ID,
+			Channel:         channel,
+			ChannelID:       channelID,
+			ThreadTimestamp: timestamp,
+			ThreadURL:       threadURL,
+			Grouped:         true,
+		}
+		if err := r.slackThreadRepo.CreateThread(thread); err != nil {
+			logger.WithFields(logrus.Fields{
+				"outage_id": outage.ID,
+				"error":     err,
+			}).Error("Failed to store Slack thread timestamp")
+			lastErr = err
+		}
+	}
+
+	logger.Info("Successfully posted grouped outages to Slack")
+	return lastErr
+}
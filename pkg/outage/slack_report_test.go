@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"gorm.io/gorm"
 
@@ -135,6 +136,61 @@ func TestFilterChannelsBySeverity(t *testing.T) {
 	}
 }
 
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		maxLength int
+		want      string
+	}{
+		{
+			name:      "short string unchanged",
+			input:     "registry down",
+			maxLength: 20,
+			want:      "registry down",
+		},
+		{
+			name:      "exact length unchanged",
+			input:     "héllo",
+			maxLength: 5,
+			want:      "héllo",
+		},
+		{
+			name:      "ascii cut with ellipsis",
+			input:     "registry is down",
+			maxLength: 10,
+			want:      "registr...",
+		},
+		{
+			name:      "multi-byte characters are not split",
+			input:     "ééééééééé",
+			maxLength: 6,
+			want:      "ééé...",
+		},
+		{
+			name:      "emoji are not split",
+			input:     "🔥🔥🔥🔥🔥🔥",
+			maxLength: 5,
+			want:      "🔥🔥...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.input, tt.maxLength)
+			if got != tt.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.input, tt.maxLength, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncate(%q, %d) returned invalid UTF-8 %q", tt.input, tt.maxLength, got)
+			}
+			if n := utf8.RuneCountInString(got); n > tt.maxLength {
+				t.Errorf("truncate(%q, %d) returned %d characters", tt.input, tt.maxLength, n)
+			}
+		})
+	}
+}
+
 func TestSlackReporter_BuildOutageLink(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -408,6 +464,48 @@ func TestSlackReporter_ReportOutage(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "internal description only goes to internal channels",
+			outage: &types.Outage{
+				Model:                 gorm.Model{ID: 3},
+				ComponentName:         "test-component",
+				SubComponentName:      "test-sub",
+				Severity:              types.SeverityDown,
+				StartTime:             time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+				Description:           "bad deploy of the auth proxy",
+				DescriptionVisibility: types.VisibilityInternal,
+				CreatedBy:             "system",
+				DiscoveredFrom:        "component-monitor",
+			},
+			config: &types.DashboardConfig{
+				Components: []*types.Component{
+					{
+						Slug: "test-component",
+						Name: "Test Component",
+						SlackReporting: []types.SlackReportingConfig{
+							{Channel: "#internal", Visibility: types.VisibilityInternal},
+							{Channel: "#public"},
+						},
+						Subcomponents: []types.SubComponent{
+							{Slug: "test-sub", Name: "Test Sub"},
+						},
+					},
+				},
+			},
+			slackThreadRepo: &repositories.MockSlackThreadRepository{},
+			wantMessages: []PostedMessage{
+				{
+					Channel:    "#internal",
+					Text:       "🚨 Outage Detected: Test Component/Test Sub\n\nSeverity: `Down`\nDescription:\n>bad deploy of the auth proxy\nStarted: `2024-01-15T10:30:00Z`\nCreated by: `system`\nDiscovered from: `component-monitor`\n\n<https://ship-status.ci.openshift.org/test-component/test-sub/outages/3|View Outage>",
+					ResponseTS: "1234567890.000001",
+				},
+				{
+					Channel:    "#public",
+					Text:       "🚨 Outage Detected: Test Component/Test Sub\n\nSeverity: `Down`\nStarted: `2024-01-15T10:30:00Z`\nCreated by: `system`\nDiscovered from: `component-monitor`\n\n<https://ship-status.ci.openshift.org/test-component/test-sub/outages/3|View Outage>",
+					ResponseTS: "1234567890.000002",
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -506,6 +604,56 @@ func TestSlackReporter_ReportOutageUpdate(t *testing.T) {
 			wantMessages:  []PostedMessage{},
 			wantReactions: []AddedReaction{},
 		},
+		{
+			name: "mirrored Slack reply is not echoed to the thread",
+			outage: &types.Outage{
+				Model:            gorm.Model{ID: 1},
+				ComponentName:    "test-component",
+				SubComponentName: "test-sub",
+				Severity:         types.SeverityDown,
+				TriageNotes: []types.TriageNote{
+					{Author: "alice", Body: "rolled back", SlackMessageTS: func() *string { ts := "1234567890.200000"; return &ts }()},
+				},
+			},
+			oldOutage: &types.Outage{
+				Severity: types.SeverityDown,
+			},
+			slackThreadRepo: &repositories.MockSlackThreadRepository{
+				ThreadsForOutage: []types.SlackThread{
+					{
+						Channel:         "#test-channel",
+						ChannelID:       "C1234567890",
+						ThreadTimestamp: "1234567890.123456",
+					},
+				},
+			},
+			wantMessages: []PostedMessage{},
+		},
+		{
+			name: "internal triage note is not posted to public threads",
+			outage: &types.Outage{
+				Model:            gorm.Model{ID: 1},
+				ComponentName:    "test-component",
+				SubComponentName: "test-sub",
+				Severity:         types.SeverityDown,
+				TriageNotes: []types.TriageNote{
+					{Author: "alice", Body: "customer X is affected", Visibility: types.VisibilityInternal},
+				},
+			},
+			oldOutage: &types.Outage{
+				Severity: types.SeverityDown,
+			},
+			slackThreadRepo: &repositories.MockSlackThreadRepository{
+				ThreadsForOutage: []types.SlackThread{
+					{
+						Channel:         "#test-channel",
+						ChannelID:       "C1234567890",
+						ThreadTimestamp: "1234567890.123456",
+					},
+				},
+			},
+			wantMessages: []PostedMessage{},
+		},
 		{
 			name: "error getting threads",
 			outage: &types.Outage{
@@ -0,0 +1,169 @@
+package outage
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// smtpTimeout bounds a whole SMTP conversation.
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig describes how to reach the SMTP relay used for email notifications.
+type SMTPConfig struct {
+	// Address is the relay's host:port.
+	Address string
+	From    string
+	// Username and Password enable AUTH PLAIN. net/smtp refuses to send them over an unencrypted
+	// connection to anything but localhost.
+	Username string
+	Password string
+	// RequireTLS fails delivery when the relay does not offer STARTTLS. Otherwise STARTTLS is used when offered.
+	RequireTLS bool
+	// TLSConfig overrides the client TLS configuration, e.g. to trust a private CA. ServerName defaults to the relay host.
+	TLSConfig *tls.Config
+}
+
+// SMTPMailer sends multipart emails through an SMTP relay.
+type SMTPMailer struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPMailer creates an SMTPMailer for cfg.
+func NewSMTPMailer(cfg SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{cfg: cfg}
+}
+
+// Send delivers one message to all recipients. htmlBody is optional; when set the message is
+// multipart/alternative with textBody as the fallback.
+func (m *SMTPMailer) Send(to []string, subject, textBody, htmlBody string) error {
+	if len(to) == 0 {
+		return nil
+	}
+	msg, err := buildMIMEMessage(m.cfg.From, to, subject, textBody, htmlBody)
+	if err != nil {
+		return err
+	}
+
+	host, _, err := net.SplitHostPort(m.cfg.Address)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP address %q: %w", m.cfg.Address, err)
+	}
+	conn, err := net.DialTimeout("tcp", m.cfg.Address, smtpTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		tlsConfig := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+		if m.cfg.TLSConfig != nil {
+			tlsConfig = m.cfg.TLSConfig.Clone()
+			if tlsConfig.ServerName == "" {
+				tlsConfig.ServerName = host
+			}
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	} else if m.cfg.RequireTLS {
+		return errors.New("SMTP server does not support STARTTLS")
+	}
+
+	if m.cfg.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("SMTP server does not support AUTH")
+		}
+		if err := c.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := c.Mail(m.cfg.From); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", rcpt, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// buildMIMEMessage renders the headers and body of an email with CRLF line endings.
+func buildMIMEMessage(from string, to []string, subject, textBody, htmlBody string) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", from)
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	if htmlBody == "" {
+		header("Content-Type", "text/plain; charset=UTF-8")
+		buf.WriteString("\r\n")
+		buf.WriteString(toCRLF(textBody))
+		return buf.Bytes(), nil
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", textBody},
+		{"text/html; charset=UTF-8", htmlBody},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := pw.Write([]byte(toCRLF(part.content))); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%s", mw.Boundary()))
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+func toCRLF(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\n", "\r\n")
+	if !strings.HasSuffix(s, "\r\n") {
+		s += "\r\n"
+	}
+	return s
+}
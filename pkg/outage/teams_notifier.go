@@ -0,0 +1,159 @@
+package outage
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/types"
+)
+
+// TeamsNotifier posts outage notifications as Adaptive Cards to Microsoft Teams incoming webhooks
+// configured with teams_reporting.
+type TeamsNotifier struct {
+	client        *http.Client
+	configManager *config.Manager[types.DashboardConfig]
+	baseURL       string
+	logger        *logrus.Logger
+}
+
+// NewTeamsNotifier creates a TeamsNotifier. baseURL is used to link back to the outage.
+func NewTeamsNotifier(configManager *config.Manager[types.DashboardConfig], baseURL string, logger *logrus.Logger) *TeamsNotifier {
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &TeamsNotifier{
+		client:        &http.Client{Timeout: notifierHTTPTimeout},
+		configManager: configManager,
+		baseURL:       baseURL,
+		logger:        logger,
+	}
+}
+
+func (n *TeamsNotifier) Name() string {
+	return "teams"
+}
+
+func (n *TeamsNotifier) OutageCreated(outage *types.Outage) error {
+	return n.notify(outage, nil)
+}
+
+func (n *TeamsNotifier) OutageUpdated(outage, oldOutage *types.Outage) error {
+	return n.notify(outage, oldOutage)
+}
+
+func (n *TeamsNotifier) OutageResolved(outage, oldOutage *types.Outage) error {
+	return n.OutageUpdated(outage, oldOutage)
+}
+
+// targets returns the configured webhooks whose severity threshold is met by the outage,
+// or by its previous state so that downgrades and resolutions reach the channels that saw the outage.
+func (n *TeamsNotifier) targets(outage, oldOutage *types.Outage) []types.TeamsReportingConfig {
+	component := n.configManager.Get().GetComponentBySlug(outage.ComponentName)
+	if component == nil {
+		return nil
+	}
+	var targets []types.TeamsReportingConfig
+	for _, reporting := range types.GetTeamsReporting(component, component.GetSubComponentBySlug(outage.SubComponentName)) {
+		if meetsSeverityThreshold(outage.Severity, reporting.Severity) ||
+			(oldOutage != nil && meetsSeverityThreshold(oldOutage.Severity, reporting.Severity)) {
+			targets = append(targets, reporting)
+		}
+	}
+	return targets
+}
+
+// notify posts the outage to each target, without internal items unless the target is internal.
+func (n *TeamsNotifier) notify(outage, oldOutage *types.Outage) error {
+	var lastErr error
+	for _, target := range n.targets(outage, oldOutage) {
+		view, oldView, ok := outageViews(target.Visibility, outage, oldOutage)
+		if !ok {
+			continue
+		}
+		payload := buildAdaptiveCardMessage(buildNotification(n.configManager, n.baseURL, view, oldView))
+		if err := postJSON(n.client, target.WebhookURL, payload); err != nil {
+			// The webhook URL is a credential, so only the outage is logged.
+			n.logger.WithFields(logrus.Fields{
+				"outage_id": outage.ID,
+				"error":     err,
+			}).Error("Failed to post outage notification to Teams")
+			lastErr = err
+			continue
+		}
+		n.logger.WithField("outage_id", outage.ID).Info("Successfully posted outage notification to Teams")
+	}
+	return lastErr
+}
+
+// teamsMessage is the envelope Teams incoming webhooks expect around an Adaptive Card.
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string               `json:"$schema"`
+	Type    string               `json:"type"`
+	Version string               `json:"version"`
+	Body    []adaptiveCardBlock  `json:"body"`
+	Actions []adaptiveCardAction `json:"actions,omitempty"`
+}
+
+// adaptiveCardBlock covers the TextBlock and FactSet elements used in outage cards.
+type adaptiveCardBlock struct {
+	Type   string             `json:"type"`
+	Text   string             `json:"text,omitempty"`
+	Weight string             `json:"weight,omitempty"`
+	Size   string             `json:"size,omitempty"`
+	Wrap   bool               `json:"wrap,omitempty"`
+	Facts  []adaptiveCardFact `json:"facts,omitempty"`
+}
+
+type adaptiveCardFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type adaptiveCardAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+func buildAdaptiveCardMessage(notification outageNotification) teamsMessage {
+	card := adaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []adaptiveCardBlock{
+			{Type: "TextBlock", Text: notification.Title, Weight: "Bolder", Size: "Medium", Wrap: true},
+		},
+		Actions: []adaptiveCardAction{
+			{Type: "Action.OpenUrl", Title: "View Outage", URL: notification.URL},
+		},
+	}
+	if len(notification.Facts) > 0 {
+		facts := make([]adaptiveCardFact, 0, len(notification.Facts))
+		for _, fact := range notification.Facts {
+			facts = append(facts, adaptiveCardFact{Title: fact.Name, Value: fact.Value})
+		}
+		card.Body = append(card.Body, adaptiveCardBlock{Type: "FactSet", Facts: facts})
+	}
+	for _, line := range notification.Lines {
+		card.Body = append(card.Body, adaptiveCardBlock{Type: "TextBlock", Text: line, Wrap: true})
+	}
+	return teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{
+			{ContentType: "application/vnd.microsoft.card.adaptive", Content: card},
+		},
+	}
+}
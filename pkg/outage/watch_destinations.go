@@ -0,0 +1,93 @@
+package outage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"ship-status-dash/pkg/types"
+)
+
+// WatchDestinationPolicy decides where a user's watch notifications may be sent, so that subscriptions cannot be
+// used to message other people or to reach internal services. It is checked when a destination is saved and
+// again before each delivery.
+type WatchDestinationPolicy struct {
+	// SlackUser returns the dashboard user of a Slack user ID. slack_dm destinations are refused when it is nil.
+	SlackUser func(slackUserID string) (string, error)
+	// EmailDomain is the domain of users' email addresses: alice's address is alice@EmailDomain. Email
+	// destinations are refused when it is empty.
+	EmailDomain string
+	// WebhookHosts are the hosts webhook destinations may point at. An entry starting with "." allows every
+	// subdomain of it. Webhook destinations are refused when it is empty.
+	WebhookHosts []string
+}
+
+// Check returns an error, worded for the user, when user may not send notifications to destination over channel.
+func (p WatchDestinationPolicy) Check(user string, channel types.NotificationChannelType, destination string) error {
+	switch channel {
+	case types.NotificationChannelSlackDM:
+		if p.SlackUser == nil {
+			return errors.New("slack_dm notifications are not available")
+		}
+		if owner, err := p.SlackUser(destination); err != nil || owner != user {
+			return errors.New("slack_dm destination must be your own Slack user ID")
+		}
+	case types.NotificationChannelEmail:
+		if p.EmailDomain == "" {
+			return errors.New("email notifications are not available")
+		}
+		if own := user + "@" + p.EmailDomain; !strings.EqualFold(destination, own) {
+			return fmt.Errorf("email destination must be your own address, %s", own)
+		}
+	case types.NotificationChannelWebhook:
+		if len(p.WebhookHosts) == 0 {
+			return errors.New("webhook notifications are not available")
+		}
+		u, err := url.Parse(destination)
+		if err != nil || !p.webhookHostAllowed(u.Hostname()) {
+			return errors.New("webhook destination host is not allowed")
+		}
+	default:
+		return fmt.Errorf("unknown notification channel %q", channel)
+	}
+	return nil
+}
+
+func (p WatchDestinationPolicy) webhookHostAllowed(host string) bool {
+	host = strings.ToLower(host)
+	if host == "" {
+		return false
+	}
+	for _, allowed := range p.WebhookHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// errNonPublicAddress is returned when a webhook resolves to an address that is not on the public internet.
+var errNonPublicAddress = errors.New("webhook address is not public")
+
+// isPublicIP reports whether ip is a globally routable unicast address.
+func isPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast()
+}
+
+// dialPublicOnly is a net.Dialer Control function refusing connections to loopback, private, link-local and
+// other non-public addresses. It runs after name resolution, so hostnames resolving to them are refused too.
+func dialPublicOnly(_ context.Context, _, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", errNonPublicAddress, host)
+	}
+	return nil
+}
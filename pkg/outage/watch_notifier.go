@@ -0,0 +1,352 @@
+package outage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+// WatchEvent is the outage change a watch notification reports.
+type WatchEvent string
+
+const (
+	WatchEventCreated         WatchEvent = "created"
+	WatchEventSeverityChanged WatchEvent = "severity_changed"
+	WatchEventResolved        WatchEvent = "resolved"
+	// WatchEventConfirmed is sent to reporters when a suspected outage is confirmed or raised above Suspected.
+	WatchEventConfirmed WatchEvent = "confirmed"
+)
+
+// WatchNotification is the payload delivered to a single watcher or reporter.
+type WatchNotification struct {
+	Event               WatchEvent     `json:"event"`
+	OutageID            uint           `json:"outage_id"`
+	ComponentName       string         `json:"component_name"`
+	SubComponentName    string         `json:"sub_component_name"`
+	ComponentDisplay    string         `json:"component_display_name"`
+	SubComponentDisplay string         `json:"sub_component_display_name"`
+	Severity            types.Severity `json:"severity"`
+	PreviousSeverity    types.Severity `json:"previous_severity,omitempty"`
+	Description         string         `json:"description"`
+	URL                 string         `json:"url"`
+	SubscriptionID      uint           `json:"subscription_id,omitempty"`
+	ReportedByRecipient bool           `json:"reported_by_recipient,omitempty"`
+	RecipientUser       string         `json:"user"`
+	SubscriptionTarget  string         `json:"subscription_target,omitempty"`
+}
+
+// Title returns a one-line summary such as "Outage resolved: Build Farm/build01 (Down)".
+func (n WatchNotification) Title() string {
+	var verb string
+	switch n.Event {
+	case WatchEventCreated:
+		verb = "Outage created"
+	case WatchEventSeverityChanged:
+		verb = fmt.Sprintf("Outage severity changed from %s", n.PreviousSeverity)
+	case WatchEventResolved:
+		verb = "Outage resolved"
+	case WatchEventConfirmed:
+		verb = "Suspected outage you reported was confirmed"
+	default:
+		verb = "Outage updated"
+	}
+	return fmt.Sprintf("%s: %s/%s (%s)", verb, n.ComponentDisplay, n.SubComponentDisplay, n.Severity)
+}
+
+// Text returns the plain-text body of the notification.
+func (n WatchNotification) Text() string {
+	parts := []string{n.Title()}
+	if n.Description != "" {
+		parts = append(parts, "", truncateString(n.Description))
+	}
+	if n.SubscriptionTarget != "" {
+		parts = append(parts, "", fmt.Sprintf("You are receiving this because you watch %s.", n.SubscriptionTarget))
+	} else if n.ReportedByRecipient {
+		parts = append(parts, "", "You are receiving this because you reported this outage.")
+	}
+	parts = append(parts, "", n.URL)
+	return strings.Join(parts, "\n")
+}
+
+// WatchSender delivers a watch notification to one destination: a Slack user ID, an email address, or a webhook URL.
+type WatchSender interface {
+	Send(destination string, notification WatchNotification) error
+}
+
+// WatchNotifier notifies users about outages on items they watch and about suspected outages they reported.
+// Unlike other notifiers, it also hears of suspected outages before they are confirmed.
+type WatchNotifier struct {
+	repo          repositories.WatchSubscriptionRepository
+	configManager *config.Manager[types.DashboardConfig]
+	senders       map[types.NotificationChannelType]WatchSender
+	policy        WatchDestinationPolicy
+	baseURL       string
+	logger        *logrus.Logger
+}
+
+// NewWatchNotifier creates a WatchNotifier. Channels without a sender in senders are skipped with a warning, and
+// so are destinations policy does not allow for their user, such as ones saved before the policy changed.
+func NewWatchNotifier(
+	repo repositories.WatchSubscriptionRepository,
+	configManager *config.Manager[types.DashboardConfig],
+	senders map[types.NotificationChannelType]WatchSender,
+	policy WatchDestinationPolicy,
+	baseURL string,
+	logger *logrus.Logger,
+) *WatchNotifier {
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &WatchNotifier{
+		repo:          repo,
+		configManager: configManager,
+		senders:       senders,
+		policy:        policy,
+		baseURL:       baseURL,
+		logger:        logger,
+	}
+}
+
+// watchRecipient is one resolved delivery target for a notification.
+type watchRecipient struct {
+	user           string
+	channel        types.NotificationChannelType
+	destination    string
+	subscriptionID uint
+	target         string
+	reporter       bool
+}
+
+func (n *WatchNotifier) Name() string {
+	return "watch"
+}
+
+func (n *WatchNotifier) notifiesSuspectedOutages() {}
+
+// OutageCreated notifies watchers of a newly created outage. Delivery failures are logged per recipient.
+func (n *WatchNotifier) OutageCreated(outage *types.Outage) error {
+	recipients := n.watcherRecipients(outage)
+	n.deliver(outage, WatchEventCreated, "", recipients)
+	return nil
+}
+
+// OutageResolved notifies watchers and reporters of the resolution.
+func (n *WatchNotifier) OutageResolved(outage, oldOutage *types.Outage) error {
+	return n.OutageUpdated(outage, oldOutage)
+}
+
+// OutageUpdated notifies watchers of severity changes and resolution, and reporters of confirmation and resolution.
+// Other changes are ignored.
+func (n *WatchNotifier) OutageUpdated(outage, oldOutage *types.Outage) error {
+	if outage == nil || oldOutage == nil {
+		return nil
+	}
+	resolved := !oldOutage.EndTime.Valid && outage.EndTime.Valid
+	severityChanged := oldOutage.Severity != outage.Severity
+	confirmed := oldOutage.Severity == types.SeveritySuspected && outage.Severity != types.SeveritySuspected
+
+	var watcherEvent, reporterEvent WatchEvent
+	switch {
+	case resolved:
+		watcherEvent, reporterEvent = WatchEventResolved, WatchEventResolved
+	case confirmed:
+		watcherEvent, reporterEvent = WatchEventSeverityChanged, WatchEventConfirmed
+	case severityChanged:
+		watcherEvent = WatchEventSeverityChanged
+	default:
+		return nil
+	}
+
+	var previous types.Severity
+	if severityChanged {
+		previous = oldOutage.Severity
+	}
+
+	watchers := n.watcherRecipients(outage)
+	n.deliver(outage, watcherEvent, previous, watchers)
+
+	if reporterEvent != "" {
+		n.deliver(outage, reporterEvent, previous, n.reporterRecipients(outage, watchers))
+	}
+	return nil
+}
+
+// watcherRecipients resolves subscriptions matching the outage into deduplicated delivery targets.
+func (n *WatchNotifier) watcherRecipients(outage *types.Outage) []watchRecipient {
+	logger := n.logger.WithField("outage_id", outage.ID)
+
+	var tags []string
+	if component := n.configManager.Get().GetComponentBySlug(outage.ComponentName); component != nil {
+		if sub := component.GetSubComponentBySlug(outage.SubComponentName); sub != nil {
+			tags = sub.Tags
+		}
+	}
+
+	subscriptions, err := n.repo.FindMatchingSubscriptions(outage.ComponentName, outage.SubComponentName, tags, outage.ID)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to find watch subscriptions")
+		return nil
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	var needPreference []string
+	for _, subscription := range subscriptions {
+		if subscription.Channel == "" {
+			needPreference = append(needPreference, subscription.User)
+		}
+	}
+	preferences, err := n.repo.GetPreferences(needPreference)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to load notification preferences")
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var recipients []watchRecipient
+	for _, subscription := range subscriptions {
+		recipient := watchRecipient{
+			user:           subscription.User,
+			channel:        subscription.Channel,
+			destination:    subscription.Destination,
+			subscriptionID: subscription.ID,
+			target:         describeWatchTarget(subscription),
+		}
+		if recipient.channel == "" {
+			preference, ok := preferences[subscription.User]
+			if !ok {
+				logger.WithField("user", subscription.User).Debug("Skipping subscription without channel or notification preference")
+				continue
+			}
+			recipient.channel, recipient.destination = preference.Channel, preference.Destination
+		}
+		key := string(recipient.channel) + "|" + recipient.destination
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		recipients = append(recipients, recipient)
+	}
+	return recipients
+}
+
+// reporterRecipients resolves the users who reported the outage into delivery targets,
+// skipping destinations already notified as watchers.
+func (n *WatchNotifier) reporterRecipients(outage *types.Outage, watchers []watchRecipient) []watchRecipient {
+	logger := n.logger.WithField("outage_id", outage.ID)
+
+	reporters, err := n.repo.ListReporters(outage.ID)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to list outage reporters")
+		return nil
+	}
+	if len(reporters) == 0 {
+		return nil
+	}
+	preferences, err := n.repo.GetPreferences(reporters)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to load notification preferences")
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, watcher := range watchers {
+		seen[string(watcher.channel)+"|"+watcher.destination] = true
+	}
+	var recipients []watchRecipient
+	for _, user := range reporters {
+		preference, ok := preferences[user]
+		if !ok || !preference.NotifyReportedOutages {
+			continue
+		}
+		key := string(preference.Channel) + "|" + preference.Destination
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		recipients = append(recipients, watchRecipient{
+			user:        user,
+			channel:     preference.Channel,
+			destination: preference.Destination,
+			reporter:    true,
+		})
+	}
+	return recipients
+}
+
+func (n *WatchNotifier) deliver(outage *types.Outage, event WatchEvent, previous types.Severity, recipients []watchRecipient) {
+	if len(recipients) == 0 {
+		return
+	}
+
+	// Watch destinations are chosen by users, so they never get internal descriptions.
+	public := outage.PublicView()
+	base := WatchNotification{
+		Event:               event,
+		OutageID:            outage.ID,
+		ComponentName:       outage.ComponentName,
+		SubComponentName:    outage.SubComponentName,
+		ComponentDisplay:    outage.ComponentName,
+		SubComponentDisplay: outage.SubComponentName,
+		Severity:            outage.Severity,
+		PreviousSeverity:    previous,
+		Description:         public.Description,
+		URL:                 buildOutageURL(n.baseURL, outage),
+	}
+	if component := n.configManager.Get().GetComponentBySlug(outage.ComponentName); component != nil {
+		base.ComponentDisplay = component.Name
+		if sub := component.GetSubComponentBySlug(outage.SubComponentName); sub != nil {
+			base.SubComponentDisplay = sub.Name
+		}
+	}
+
+	for _, recipient := range recipients {
+		logger := n.logger.WithFields(logrus.Fields{
+			"outage_id": outage.ID,
+			"user":      recipient.user,
+			"channel":   recipient.channel,
+			"event":     event,
+		})
+		sender, ok := n.senders[recipient.channel]
+		if !ok {
+			logger.Warn("No sender configured for notification channel, skipping watch notification")
+			continue
+		}
+		if err := n.policy.Check(recipient.user, recipient.channel, recipient.destination); err != nil {
+			logger.WithField("error", err).Warn("Destination not allowed for user, skipping watch notification")
+			continue
+		}
+		notification := base
+		notification.RecipientUser = recipient.user
+		notification.SubscriptionID = recipient.subscriptionID
+		notification.SubscriptionTarget = recipient.target
+		notification.ReportedByRecipient = recipient.reporter
+		if err := sender.Send(recipient.destination, notification); err != nil {
+			logger.WithField("error", err).Error("Failed to send watch notification")
+			continue
+		}
+		logger.Debug("Sent watch notification")
+	}
+}
+
+func describeWatchTarget(subscription types.WatchSubscription) string {
+	switch subscription.TargetType {
+	case types.WatchTargetComponent:
+		return "component " + subscription.ComponentName
+	case types.WatchTargetSubComponent:
+		return "sub-component " + subscription.ComponentName + "/" + subscription.SubComponentName
+	case types.WatchTargetTag:
+		return "tag " + subscription.Tag
+	case types.WatchTargetOutage:
+		if subscription.OutageID != nil {
+			return fmt.Sprintf("outage #%d", *subscription.OutageID)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,330 @@
+package outage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/config"
+	"ship-status-dash/pkg/repositories"
+	"ship-status-dash/pkg/types"
+)
+
+type sentWatchNotification struct {
+	Destination string
+	Event       WatchEvent
+	User        string
+	Previous    types.Severity
+}
+
+type recordingWatchSender struct {
+	sent          []sentWatchNotification
+	notifications []WatchNotification
+	err           error
+}
+
+func (s *recordingWatchSender) Send(destination string, n WatchNotification) error {
+	s.sent = append(s.sent, sentWatchNotification{Destination: destination, Event: n.Event, User: n.RecipientUser, Previous: n.PreviousSeverity})
+	s.notifications = append(s.notifications, n)
+	return s.err
+}
+
+// testWatchDestinationPolicy allows the destinations used by the watch tests for their users.
+var testWatchDestinationPolicy = WatchDestinationPolicy{
+	SlackUser: func(slackUserID string) (string, error) {
+		users := map[string]string{"UBOB": "bob", "UONE": "user1", "UTWO": "user2"}
+		if user, ok := users[slackUserID]; ok {
+			return user, nil
+		}
+		return "", errors.New("unknown Slack user")
+	},
+	EmailDomain:  "example.com",
+	WebhookHosts: []string{"hooks.example.com"},
+}
+
+// setupWatchTest returns an outage manager without Slack whose watch notifier delivers every channel to sender.
+func setupWatchTest(t *testing.T, sender WatchSender) (*DBOutageManager, repositories.WatchSubscriptionRepository, *gorm.DB) {
+	t.Helper()
+	db := setupTestDB(t)
+	cfg := &types.DashboardConfig{
+		Components: []*types.Component{
+			{
+				Slug: "build-farm",
+				Name: "Build Farm",
+				Subcomponents: []types.SubComponent{
+					{Slug: "build01", Name: "Build01", Tags: []string{"clusters"}},
+					{Slug: "build02", Name: "Build02"},
+				},
+			},
+		},
+		Tags: []types.Tag{{Name: "clusters"}},
+	}
+	cfgManager, err := config.NewManager("", func(string) (*types.DashboardConfig, error) {
+		return cfg, nil
+	}, logrus.New(), time.Second)
+	require.NoError(t, err)
+	cfgManager.Get()
+
+	repo := repositories.NewGORMWatchSubscriptionRepository(db)
+	manager := NewDBOutageManager(db, nil, cfgManager, "https://test.example.com", "", logrus.New())
+	manager.AddNotifier(NewWatchNotifier(repo, cfgManager, map[types.NotificationChannelType]WatchSender{
+		types.NotificationChannelSlackDM: sender,
+		types.NotificationChannelEmail:   sender,
+		types.NotificationChannelWebhook: sender,
+	}, testWatchDestinationPolicy, "https://test.example.com", logrus.New()))
+	return manager, repo, db
+}
+
+func newWatchTestOutage(sub string, severity types.Severity) *types.Outage {
+	return &types.Outage{
+		ComponentName:    "build-farm",
+		SubComponentName: sub,
+		Severity:         severity,
+		StartTime:        time.Now().Add(-time.Hour),
+		Description:      "builds failing",
+		CreatedBy:        "alice",
+		DiscoveredFrom:   "frontend",
+	}
+}
+
+func TestWatchNotifier_Watchers(t *testing.T) {
+	sender := &recordingWatchSender{}
+	manager, repo, _ := setupWatchTest(t, sender)
+
+	require.NoError(t, repo.UpsertPreference(&types.NotificationPreference{User: "bob", Channel: types.NotificationChannelSlackDM, Destination: "UBOB"}))
+	for _, subscription := range []*types.WatchSubscription{
+		{User: "bob", TargetType: types.WatchTargetComponent, ComponentName: "build-farm"},
+		// Same destination as bob's component subscription; must not produce a second message.
+		{User: "bob", TargetType: types.WatchTargetSubComponent, ComponentName: "build-farm", SubComponentName: "build01"},
+		{User: "carol", TargetType: types.WatchTargetTag, Tag: "clusters", Channel: types.NotificationChannelEmail, Destination: "carol@example.com"},
+		{User: "dave", TargetType: types.WatchTargetSubComponent, ComponentName: "build-farm", SubComponentName: "build02", Channel: types.NotificationChannelWebhook, Destination: "https://hooks.example.com/dave"},
+		// No channel and no preference: skipped.
+		{User: "erin", TargetType: types.WatchTargetComponent, ComponentName: "build-farm"},
+	} {
+		require.NoError(t, repo.CreateSubscription(subscription))
+	}
+
+	outage := newWatchTestOutage("build01", types.SeverityDegraded)
+	require.NoError(t, manager.CreateOutage(outage, nil, "alice", ""))
+
+	want := []sentWatchNotification{
+		{Destination: "UBOB", Event: WatchEventCreated, User: "bob"},
+		{Destination: "carol@example.com", Event: WatchEventCreated, User: "carol"},
+	}
+	if diff := cmp.Diff(want, sender.sent); diff != "" {
+		t.Errorf("created notifications mismatch (-want +got):\n%s", diff)
+	}
+
+	sender.sent = nil
+	outageID := outage.ID
+	require.NoError(t, repo.CreateSubscription(&types.WatchSubscription{User: "frank", TargetType: types.WatchTargetOutage, OutageID: &outageID, Channel: types.NotificationChannelWebhook, Destination: "https://hooks.example.com/frank"}))
+
+	outage.Severity = types.SeverityDown
+	require.NoError(t, manager.UpdateOutage(outage, "alice"))
+	want = []sentWatchNotification{
+		{Destination: "UBOB", Event: WatchEventSeverityChanged, User: "bob", Previous: types.SeverityDegraded},
+		{Destination: "carol@example.com", Event: WatchEventSeverityChanged, User: "carol", Previous: types.SeverityDegraded},
+		{Destination: "https://hooks.example.com/frank", Event: WatchEventSeverityChanged, User: "frank", Previous: types.SeverityDegraded},
+	}
+	if diff := cmp.Diff(want, sender.sent); diff != "" {
+		t.Errorf("severity notifications mismatch (-want +got):\n%s", diff)
+	}
+
+	sender.sent = nil
+	outage.Description = "still failing"
+	require.NoError(t, manager.UpdateOutage(outage, "alice"))
+	assert.Empty(t, sender.sent, "description-only updates should not notify")
+
+	// Resolution wins over a simultaneous severity change.
+	outage.Severity = types.SeverityDegraded
+	outage.EndTime = sql.NullTime{Time: time.Now(), Valid: true}
+	require.NoError(t, manager.UpdateOutage(outage, "alice"))
+	want = []sentWatchNotification{
+		{Destination: "UBOB", Event: WatchEventResolved, User: "bob", Previous: types.SeverityDown},
+		{Destination: "carol@example.com", Event: WatchEventResolved, User: "carol", Previous: types.SeverityDown},
+		{Destination: "https://hooks.example.com/frank", Event: WatchEventResolved, User: "frank", Previous: types.SeverityDown},
+	}
+	if diff := cmp.Diff(want, sender.sent); diff != "" {
+		t.Errorf("resolved notifications mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWatchNotifier_Reporters(t *testing.T) {
+	sender := &recordingWatchSender{}
+	manager, repo, _ := setupWatchTest(t, sender)
+
+	require.NoError(t, repo.UpsertPreference(&types.NotificationPreference{User: "user1", Channel: types.NotificationChannelSlackDM, Destination: "UONE", NotifyReportedOutages: true}))
+	require.NoError(t, repo.UpsertPreference(&types.NotificationPreference{User: "user2", Channel: types.NotificationChannelSlackDM, Destination: "UTWO", NotifyReportedOutages: false}))
+
+	_, err := manager.ReportSuspectedOutage("build-farm", "build02", "", "user1", 3)
+	require.NoError(t, err)
+	result, err := manager.ReportSuspectedOutage("build-farm", "build02", "", "user2", 3)
+	require.NoError(t, err)
+	assert.Empty(t, sender.sent, "reporters are not notified until the outage is confirmed")
+
+	result.Outage.Severity = types.SeverityDown
+	result.Outage.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	require.NoError(t, manager.UpdateOutage(result.Outage, "alice"))
+	want := []sentWatchNotification{
+		{Destination: "UONE", Event: WatchEventConfirmed, User: "user1", Previous: types.SeveritySuspected},
+	}
+	if diff := cmp.Diff(want, sender.sent); diff != "" {
+		t.Errorf("confirmed notifications mismatch (-want +got):\n%s", diff)
+	}
+
+	sender.sent = nil
+	result.Outage.EndTime = sql.NullTime{Time: time.Now(), Valid: true}
+	require.NoError(t, manager.UpdateOutage(result.Outage, "alice"))
+	want = []sentWatchNotification{
+		{Destination: "UONE", Event: WatchEventResolved, User: "user1"},
+	}
+	if diff := cmp.Diff(want, sender.sent); diff != "" {
+		t.Errorf("resolved notifications mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWatchNotifier_AutoResolvedSuspected(t *testing.T) {
+	sender := &recordingWatchSender{}
+	manager, repo, _ := setupWatchTest(t, sender)
+
+	require.NoError(t, repo.UpsertPreference(&types.NotificationPreference{User: "user1", Channel: types.NotificationChannelEmail, Destination: "user1@example.com", NotifyReportedOutages: true}))
+	_, err := manager.ReportSuspectedOutage("build-farm", "build02", "", "user1", 3)
+	require.NoError(t, err)
+
+	confirmed := newWatchTestOutage("build02", types.SeverityDown)
+	confirmed.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	require.NoError(t, manager.CreateOutage(confirmed, nil, "alice", ""))
+
+	want := []sentWatchNotification{
+		{Destination: "user1@example.com", Event: WatchEventResolved, User: "user1"},
+	}
+	if diff := cmp.Diff(want, sender.sent); diff != "" {
+		t.Errorf("notifications mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWatchNotifier_SendErrorDoesNotFailUpdate(t *testing.T) {
+	sender := &recordingWatchSender{err: errors.New("boom")}
+	manager, repo, _ := setupWatchTest(t, sender)
+	require.NoError(t, repo.CreateSubscription(&types.WatchSubscription{User: "bob", TargetType: types.WatchTargetComponent, ComponentName: "build-farm", Channel: types.NotificationChannelSlackDM, Destination: "UBOB"}))
+
+	require.NoError(t, manager.CreateOutage(newWatchTestOutage("build01", types.SeverityDown), nil, "alice", ""))
+	assert.Len(t, sender.sent, 1)
+}
+
+func TestWatchNotifier_InternalDescription(t *testing.T) {
+	sender := &recordingWatchSender{}
+	manager, repo, _ := setupWatchTest(t, sender)
+	require.NoError(t, repo.CreateSubscription(&types.WatchSubscription{User: "bob", TargetType: types.WatchTargetComponent, ComponentName: "build-farm", Channel: types.NotificationChannelSlackDM, Destination: "UBOB"}))
+
+	outage := newWatchTestOutage("build01", types.SeverityDown)
+	outage.DescriptionVisibility = types.VisibilityInternal
+	require.NoError(t, manager.CreateOutage(outage, nil, "alice", ""))
+	require.Len(t, sender.notifications, 1)
+	assert.Empty(t, sender.notifications[0].Description)
+}
+
+func TestWebhookSender(t *testing.T) {
+	var got WatchNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	sender := newWebhookSender(time.Second, nil)
+	notification := WatchNotification{
+		Event:            WatchEventResolved,
+		OutageID:         7,
+		ComponentName:    "build-farm",
+		SubComponentName: "build01",
+		Severity:         types.SeverityDown,
+		URL:              "https://test.example.com/build-farm/build01/outages/7",
+		RecipientUser:    "bob",
+	}
+	require.NoError(t, sender.Send(server.URL+"/ok", notification))
+	if diff := cmp.Diff(notification, got); diff != "" {
+		t.Errorf("webhook payload mismatch (-want +got):\n%s", diff)
+	}
+
+	assert.EqualError(t, sender.Send(server.URL+"/fail", notification), "webhook returned status 502")
+}
+
+func TestWebhookSender_RefusesNonPublicAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the webhook must not be called")
+	}))
+	defer server.Close()
+
+	err := NewWebhookSender(time.Second).Send(server.URL, WatchNotification{})
+	assert.ErrorIs(t, err, errNonPublicAddress)
+}
+
+func TestWatchNotifier_DestinationPolicy(t *testing.T) {
+	sender := &recordingWatchSender{}
+	manager, repo, _ := setupWatchTest(t, sender)
+
+	for _, subscription := range []*types.WatchSubscription{
+		{User: "mallory", TargetType: types.WatchTargetComponent, ComponentName: "build-farm", Channel: types.NotificationChannelSlackDM, Destination: "UBOB"},
+		{User: "mallory", TargetType: types.WatchTargetComponent, ComponentName: "build-farm", Channel: types.NotificationChannelEmail, Destination: "carol@example.com"},
+		{User: "mallory", TargetType: types.WatchTargetComponent, ComponentName: "build-farm", Channel: types.NotificationChannelWebhook, Destination: "http://169.254.169.254/latest"},
+		{User: "bob", TargetType: types.WatchTargetComponent, ComponentName: "build-farm", Channel: types.NotificationChannelEmail, Destination: "bob@example.com"},
+	} {
+		require.NoError(t, repo.CreateSubscription(subscription))
+	}
+
+	require.NoError(t, manager.CreateOutage(newWatchTestOutage("build01", types.SeverityDown), nil, "alice", ""))
+	want := []sentWatchNotification{
+		{Destination: "bob@example.com", Event: WatchEventCreated, User: "bob"},
+	}
+	if diff := cmp.Diff(want, sender.sent); diff != "" {
+		t.Errorf("notifications mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWatchDestinationPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		user        string
+		channel     types.NotificationChannelType
+		destination string
+		wantErr     string
+	}{
+		{name: "own Slack user", user: "bob", channel: types.NotificationChannelSlackDM, destination: "UBOB"},
+		{name: "another Slack user", user: "mallory", channel: types.NotificationChannelSlackDM, destination: "UBOB", wantErr: "slack_dm destination must be your own Slack user ID"},
+		{name: "own email", user: "bob", channel: types.NotificationChannelEmail, destination: "Bob@Example.com"},
+		{name: "another email", user: "mallory", channel: types.NotificationChannelEmail, destination: "bob@example.com", wantErr: "email destination must be your own address, mallory@example.com"},
+		{name: "allowed webhook host", user: "bob", channel: types.NotificationChannelWebhook, destination: "https://hooks.example.com/bob"},
+		{name: "other webhook host", user: "bob", channel: types.NotificationChannelWebhook, destination: "https://evil.example.com/bob", wantErr: "webhook destination host is not allowed"},
+		{name: "allowed host as a suffix", user: "bob", channel: types.NotificationChannelWebhook, destination: "https://evilhooks.example.com/bob", wantErr: "webhook destination host is not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testWatchDestinationPolicy.Check(tt.user, tt.channel, tt.destination)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	policy := WatchDestinationPolicy{WebhookHosts: []string{".example.com"}}
+	assert.NoError(t, policy.Check("bob", types.NotificationChannelWebhook, "https://hooks.example.com/bob"))
+	assert.Error(t, policy.Check("bob", types.NotificationChannelWebhook, "https://example.org/bob"))
+	assert.EqualError(t, policy.Check("bob", types.NotificationChannelEmail, "bob@example.com"), "email notifications are not available")
+	assert.EqualError(t, policy.Check("bob", types.NotificationChannelSlackDM, "UBOB"), "slack_dm notifications are not available")
+}
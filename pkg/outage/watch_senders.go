@@ -0,0 +1,83 @@
+package outage
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// SlackDMSender delivers watch notifications as Slack direct messages. The destination is a Slack user ID.
+type SlackDMSender struct {
+	slackClient *slack.Client
+}
+
+// NewSlackDMSender creates a SlackDMSender.
+func NewSlackDMSender(slackClient *slack.Client) *SlackDMSender {
+	return &SlackDMSender{slackClient: slackClient}
+}
+
+// Send posts the notification to the user's direct message channel.
+func (s *SlackDMSender) Send(destination string, notification WatchNotification) error {
+	parts := []string{notification.Title()}
+	if notification.Description != "" {
+		parts = append(parts, truncateString(notification.Description))
+	}
+	parts = append(parts, fmt.Sprintf("<%s|View Outage>", notification.URL))
+	_, _, err := s.slackClient.PostMessage(
+		destination,
+		slack.MsgOptionText(strings.Join(parts, "\n"), false),
+		slack.MsgOptionAsUser(true),
+	)
+	return err
+}
+
+// WebhookSender delivers watch notifications by POSTing them as JSON. The destination is the webhook URL.
+type WebhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender creates a WebhookSender whose requests time out after timeout. It only connects to public
+// addresses and does not follow redirects, so webhooks cannot reach services inside the cluster.
+func NewWebhookSender(timeout time.Duration) *WebhookSender {
+	return newWebhookSender(timeout, dialPublicOnly)
+}
+
+// newWebhookSender creates a WebhookSender whose connections are vetted by control.
+func newWebhookSender(timeout time.Duration, control func(ctx context.Context, network, address string, c syscall.RawConn) error) *WebhookSender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: timeout, ControlContext: control}).DialContext
+	return &WebhookSender{client: &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// Send POSTs the notification and treats any non-2xx response as a failure.
+func (s *WebhookSender) Send(destination string, notification WatchNotification) error {
+	return postJSON(s.client, destination, notification)
+}
+
+// EmailSender delivers watch notifications as plain-text email. The destination is an email address.
+type EmailSender struct {
+	mailer *SMTPMailer
+}
+
+// NewEmailSender creates an EmailSender that sends through mailer.
+func NewEmailSender(mailer *SMTPMailer) *EmailSender {
+	return &EmailSender{mailer: mailer}
+}
+
+// Send emails the notification to destination.
+func (s *EmailSender) Send(destination string, notification WatchNotification) error {
+	return s.mailer.Send([]string{destination}, notification.Title(), notification.Text(), "")
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"ship-status-dash/pkg/types"
+)
+
+// APITokenRepository handles persistence for personal access tokens and the record of their use.
+type APITokenRepository interface {
+	CreateToken(token *types.APIToken) error
+	ListTokens(user string) ([]types.APIToken, error)
+	RevokeToken(user string, tokenID uint) error
+	GetTokenByHash(tokenHash string) (*types.APIToken, error)
+	RecordTokenUse(use *types.APITokenUse) error
+	DeleteTokenUsesBefore(cutoff time.Time) (int64, error)
+}
+
+type gormAPITokenRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMAPITokenRepository creates a new GORM-based APITokenRepository.
+func NewGORMAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &gormAPITokenRepository{db: db}
+}
+
+func (r *gormAPITokenRepository) CreateToken(token *types.APIToken) error {
+	return r.db.Create(token).Error
+}
+
+// ListTokens returns the user's tokens that have not been revoked, including expired ones.
+func (r *gormAPITokenRepository) ListTokens(user string) ([]types.APIToken, error) {
+	var tokens []types.APIToken
+	err := r.db.Where(clause.Eq{Column: clause.Column{Name: "user"}, Value: user}).
+		Order("created_at ASC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokeToken deletes a token owned by user.
+// Returns gorm.ErrRecordNotFound if the user has no token with that ID.
+func (r *gormAPITokenRepository) RevokeToken(user string, tokenID uint) error {
+	result := r.db.Where(clause.Eq{Column: clause.Column{Name: "user"}, Value: user}).
+		Delete(&types.APIToken{}, tokenID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetTokenByHash returns the token with the given hash, or nil when there is none or it was revoked.
+func (r *gormAPITokenRepository) GetTokenByHash(tokenHash string) (*types.APIToken, error) {
+	var token types.APIToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RecordTokenUse stores use and moves the token's last_used_at to its time.
+func (r *gormAPITokenRepository) RecordTokenUse(use *types.APITokenUse) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(use).Error; err != nil {
+			return err
+		}
+		return tx.Model(&types.APIToken{}).Where("id = ?", use.TokenID).Update("last_used_at", use.CreatedAt).Error
+	})
+}
+
+// DeleteTokenUsesBefore permanently deletes the uses recorded before cutoff and returns how many there were.
+// Tokens keep their last_used_at.
+func (r *gormAPITokenRepository) DeleteTokenUsesBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Unscoped().Where("created_at < ?", cutoff.UTC()).Delete(&types.APITokenUse{})
+	return result.RowsAffected, result.Error
+}
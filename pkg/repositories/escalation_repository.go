@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"ship-status-dash/pkg/types"
+)
+
+// EscalationRepository records the escalation steps that have fired for outages.
+type EscalationRepository interface {
+	// RecordEscalation stores escalation and reports whether it was new. It returns false when the same step
+	// already fired for the same outage activity, for example from another dashboard replica.
+	RecordEscalation(escalation *types.OutageEscalation) (bool, error)
+}
+
+type gormEscalationRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMEscalationRepository creates a new GORM-based EscalationRepository.
+func NewGORMEscalationRepository(db *gorm.DB) EscalationRepository {
+	return &gormEscalationRepository{db: db}
+}
+
+func (r *gormEscalationRepository) RecordEscalation(escalation *types.OutageEscalation) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "outage_id"}, {Name: "step"}, {Name: "activity_at"}},
+		DoNothing: true,
+	}).Create(escalation)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"ship-status-dash/pkg/types"
+)
+
+// IdempotencyKeyRepository handles persistence for idempotency keys of create requests.
+type IdempotencyKeyRepository interface {
+	// ReserveKey stores key unless its caller already has a key with the same value created at or after notBefore.
+	// It returns that existing key, or nil when key was stored. Older keys of the caller with the value are replaced.
+	ReserveKey(key *types.IdempotencyKey, notBefore time.Time) (*types.IdempotencyKey, error)
+	CompleteKey(id uint, statusCode int, responseBody []byte) error
+	ReleaseKey(id uint) error
+	DeleteKeysBefore(cutoff time.Time) (int64, error)
+}
+
+type gormIdempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMIdempotencyKeyRepository creates a new GORM-based IdempotencyKeyRepository.
+func NewGORMIdempotencyKeyRepository(db *gorm.DB) IdempotencyKeyRepository {
+	return &gormIdempotencyKeyRepository{db: db}
+}
+
+func (r *gormIdempotencyKeyRepository) ReserveKey(key *types.IdempotencyKey, notBefore time.Time) (*types.IdempotencyKey, error) {
+	var existing *types.IdempotencyKey
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		sameKey := tx.Unscoped().
+			Where(clause.Eq{Column: clause.Column{Name: "key"}, Value: key.Key}).
+			Where(clause.Eq{Column: clause.Column{Name: "user"}, Value: key.User}).
+			Where("delegator = ?", key.Delegator).
+			Session(&gorm.Session{})
+		if err := sameKey.Where("created_at < ?", notBefore.UTC()).Delete(&types.IdempotencyKey{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "key"}, {Name: "user"}, {Name: "delegator"}},
+			DoNothing: true,
+		}).Create(key)
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+
+		var found types.IdempotencyKey
+		if err := sameKey.First(&found).Error; err != nil {
+			return err
+		}
+		existing = &found
+		return nil
+	})
+	return existing, err
+}
+
+// CompleteKey stores the response to the request of the key with id.
+func (r *gormIdempotencyKeyRepository) CompleteKey(id uint, statusCode int, responseBody []byte) error {
+	return r.db.Model(&types.IdempotencyKey{}).Where("id = ?", id).Updates(map[string]any{
+		"status_code":   statusCode,
+		"response_body": responseBody,
+	}).Error
+}
+
+// ReleaseKey permanently deletes the key with id, so that the request can be retried with it.
+func (r *gormIdempotencyKeyRepository) ReleaseKey(id uint) error {
+	return r.db.Unscoped().Delete(&types.IdempotencyKey{}, id).Error
+}
+
+// DeleteKeysBefore permanently deletes the keys created before cutoff and returns how many there were.
+func (r *gormIdempotencyKeyRepository) DeleteKeysBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Unscoped().Where("created_at < ?", cutoff.UTC()).Delete(&types.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
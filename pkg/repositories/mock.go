@@ -37,6 +37,7 @@ type MockOutageRepository struct {
 	ActiveOutagesForComponent []types.Outage
 	AllActiveOutages          []types.Outage
 	OutageAuditLogs           []types.OutageAuditLog
+	AuditLogEntries           []types.AuditLogEntry
 	RecentlyClosedOutages     []types.Outage
 	FindReopenableOutageFn    func(string, string, string, time.Time, []types.Reason) (*types.Outage, error)
 }
@@ -49,6 +50,10 @@ func (m *MockOutageRepository) GetOutageAuditLogs(outageID uint) ([]types.Outage
 	return m.OutageAuditLogs, nil
 }
 
+func (m *MockOutageRepository) GetAuditLogs(filter types.AuditLogFilter) ([]types.AuditLogEntry, error) {
+	return m.AuditLogEntries, nil
+}
+
 // MockComponentPingRepository is a mock implementation of ComponentPingRepository for testing.
 type MockComponentPingRepository struct {
 	UpsertError      error
@@ -193,6 +198,14 @@ func (m *MockOutageRepository) GetStaleSuspectedOutages(cutoff time.Time) ([]typ
 	return nil, nil
 }
 
+func (m *MockOutageRepository) GetOutagesCreatedBy(createdBy string, since time.Time) ([]types.Outage, error) {
+	return nil, nil
+}
+
+func (m *MockOutageRepository) GetOutagesReportedBy(user string, since time.Time) ([]types.Outage, error) {
+	return nil, nil
+}
+
 func (m *MockOutageRepository) DeleteOutage(outage *types.Outage, user string) error {
 	outageCopy := *outage
 	m.DeletedOutages = append(m.DeletedOutages, &outageCopy)
@@ -211,8 +224,12 @@ type MockTriageNoteRepository struct {
 
 	AddTriageNoteFn    func(*types.TriageNote) error
 	GetTriageNoteFn    func(uint, uint) (*types.TriageNote, error)
-	UpdateTriageNoteFn func(uint, uint, string) (*types.TriageNote, error)
+	UpdateTriageNoteFn func(uint, uint, string, types.Visibility) (*types.TriageNote, error)
 	DeleteTriageNoteFn func(uint, uint) error
+	ListTriageNotesFn  func(uint) ([]types.TriageNote, error)
+
+	ListTriageNotesByAuthorFn     func(string, time.Time) ([]types.AuthoredTriageNote, error)
+	GetTriageNoteBySlackMessageFn func(uint, string) (*types.TriageNote, error)
 }
 
 func (m *MockTriageNoteRepository) AddTriageNote(note *types.TriageNote) error {
@@ -224,7 +241,17 @@ func (m *MockTriageNoteRepository) AddTriageNote(note *types.TriageNote) error {
 	return m.AddTriageNoteError
 }
 
-func (m *MockTriageNoteRepository) ListTriageNotes(_ uint) ([]types.TriageNote, error) {
+func (m *MockTriageNoteRepository) ListTriageNotes(outageID uint) ([]types.TriageNote, error) {
+	if m.ListTriageNotesFn != nil {
+		return m.ListTriageNotesFn(outageID)
+	}
+	return nil, nil
+}
+
+func (m *MockTriageNoteRepository) ListTriageNotesByAuthor(author string, since time.Time) ([]types.AuthoredTriageNote, error) {
+	if m.ListTriageNotesByAuthorFn != nil {
+		return m.ListTriageNotesByAuthorFn(author, since)
+	}
 	return nil, nil
 }
 
@@ -238,9 +265,9 @@ func (m *MockTriageNoteRepository) GetTriageNote(outageID, noteID uint) (*types.
 	return m.TriageNoteByID, nil
 }
 
-func (m *MockTriageNoteRepository) UpdateTriageNote(outageID, noteID uint, body string) (*types.TriageNote, error) {
+func (m *MockTriageNoteRepository) UpdateTriageNote(outageID, noteID uint, body string, visibility types.Visibility) (*types.TriageNote, error) {
 	if m.UpdateTriageNoteFn != nil {
-		return m.UpdateTriageNoteFn(outageID, noteID, body)
+		return m.UpdateTriageNoteFn(outageID, noteID, body, visibility)
 	}
 	if m.UpdateTriageNoteError != nil {
 		return nil, m.UpdateTriageNoteError
@@ -248,6 +275,13 @@ func (m *MockTriageNoteRepository) UpdateTriageNote(outageID, noteID uint, body
 	return m.TriageNoteByID, nil
 }
 
+func (m *MockTriageNoteRepository) GetTriageNoteBySlackMessage(outageID uint, messageTS string) (*types.TriageNote, error) {
+	if m.GetTriageNoteBySlackMessageFn != nil {
+		return m.GetTriageNoteBySlackMessageFn(outageID, messageTS)
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
 func (m *MockTriageNoteRepository) DeleteTriageNote(outageID, noteID uint) error {
 	if m.DeleteTriageNoteFn != nil {
 		return m.DeleteTriageNoteFn(outageID, noteID)
@@ -267,7 +301,7 @@ type MockOutageLinkRepository struct {
 
 	AddOutageLinkFn    func(*types.OutageLink) error
 	GetOutageLinkFn    func(uint, uint) (*types.OutageLink, error)
-	UpdateOutageLinkFn func(uint, uint, string, types.LinkType, string) (*types.OutageLink, error)
+	UpdateOutageLinkFn func(uint, uint, string, types.LinkType, string, types.Visibility) (*types.OutageLink, error)
 	DeleteOutageLinkFn func(uint, uint) error
 }
 
@@ -294,9 +328,9 @@ func (m *MockOutageLinkRepository) GetOutageLink(outageID, linkID uint) (*types.
 	return m.OutageLinkByID, nil
 }
 
-func (m *MockOutageLinkRepository) UpdateOutageLink(outageID, linkID uint, url string, linkType types.LinkType, description string) (*types.OutageLink, error) {
+func (m *MockOutageLinkRepository) UpdateOutageLink(outageID, linkID uint, url string, linkType types.LinkType, description string, visibility types.Visibility) (*types.OutageLink, error) {
 	if m.UpdateOutageLinkFn != nil {
-		return m.UpdateOutageLinkFn(outageID, linkID, url, linkType, description)
+		return m.UpdateOutageLinkFn(outageID, linkID, url, linkType, description, visibility)
 	}
 	if m.UpdateOutageLinkError != nil {
 		return nil, m.UpdateOutageLinkError
@@ -311,6 +345,14 @@ func (m *MockOutageLinkRepository) DeleteOutageLink(outageID, linkID uint) error
 	return m.DeleteOutageLinkError
 }
 
+func (m *MockOutageLinkRepository) ListOutageLinksByType(_ types.LinkType, _ time.Time) ([]types.OutageLink, error) {
+	return nil, nil
+}
+
+func (m *MockOutageLinkRepository) UpdateOutageLinkStatus(_ uint, _ string) error {
+	return nil
+}
+
 func (m *MockComponentPingRepository) UpsertComponentReportPing(componentSlug, subComponentSlug string, timestamp time.Time) error {
 	m.UpsertedPings = append(m.UpsertedPings, struct {
 		ComponentSlug    string
@@ -360,6 +402,7 @@ type MockSlackThreadRepository struct {
 	UpdateThreadFn         func(*types.SlackThread)
 	ThreadsForOutage       []types.SlackThread
 	ThreadForOutageChannel *types.SlackThread
+	ThreadsByTimestamp     []types.OutageSlackThread
 	CreatedThreads         []*types.SlackThread
 	UpdatedThreads         []*types.SlackThread
 }
@@ -390,6 +433,19 @@ func (m *MockSlackThreadRepository) GetThreadForOutageAndChannel(outageID uint,
 	return m.ThreadForOutageChannel, nil
 }
 
+// GetThreadByTimestamp looks the thread up in ThreadsByTimestamp.
+func (m *MockSlackThreadRepository) GetThreadByTimestamp(channelID, threadTimestamp string) (*types.OutageSlackThread, error) {
+	if m.GetThreadError != nil {
+		return nil, m.GetThreadError
+	}
+	for i := range m.ThreadsByTimestamp {
+		if m.ThreadsByTimestamp[i].ChannelID == channelID && m.ThreadsByTimestamp[i].ThreadTimestamp == threadTimestamp {
+			return &m.ThreadsByTimestamp[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
 func (m *MockSlackThreadRepository) UpdateThread(thread *types.SlackThread) error {
 	threadCopy := *thread
 	m.UpdatedThreads = append(m.UpdatedThreads, &threadCopy)
@@ -399,6 +455,291 @@ func (m *MockSlackThreadRepository) UpdateThread(thread *types.SlackThread) erro
 	return m.UpdateThreadError
 }
 
+// MockWatchSubscriptionRepository is an in-memory implementation of WatchSubscriptionRepository for testing.
+type MockWatchSubscriptionRepository struct {
+	Subscriptions []types.WatchSubscription
+	Preferences   map[string]types.NotificationPreference
+	Reporters     map[uint][]string
+
+	CreateSubscriptionError error
+	UpsertPreferenceError   error
+}
+
+func (m *MockWatchSubscriptionRepository) CreateSubscription(subscription *types.WatchSubscription) error {
+	if m.CreateSubscriptionError != nil {
+		return m.CreateSubscriptionError
+	}
+	subscription.ID = uint(len(m.Subscriptions) + 1)
+	m.Subscriptions = append(m.Subscriptions, *subscription)
+	return nil
+}
+
+func (m *MockWatchSubscriptionRepository) ListSubscriptions(user string) ([]types.WatchSubscription, error) {
+	var result []types.WatchSubscription
+	for _, subscription := range m.Subscriptions {
+		if subscription.User == user {
+			result = append(result, subscription)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockWatchSubscriptionRepository) CountSubscriptions(user string) (int64, error) {
+	subscriptions, err := m.ListSubscriptions(user)
+	return int64(len(subscriptions)), err
+}
+
+func (m *MockWatchSubscriptionRepository) DeleteSubscription(user string, subscriptionID uint) error {
+	for i, subscription := range m.Subscriptions {
+		if subscription.ID == subscriptionID && subscription.User == user {
+			m.Subscriptions = append(m.Subscriptions[:i], m.Subscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (m *MockWatchSubscriptionRepository) FindMatchingSubscriptions(componentSlug, subComponentSlug string, tags []string, outageID uint) ([]types.WatchSubscription, error) {
+	var result []types.WatchSubscription
+	for _, subscription := range m.Subscriptions {
+		switch subscription.TargetType {
+		case types.WatchTargetComponent:
+			if subscription.ComponentName == componentSlug {
+				result = append(result, subscription)
+			}
+		case types.WatchTargetSubComponent:
+			if subscription.ComponentName == componentSlug && subscription.SubComponentName == subComponentSlug {
+				result = append(result, subscription)
+			}
+		case types.WatchTargetOutage:
+			if subscription.OutageID != nil && *subscription.OutageID == outageID {
+				result = append(result, subscription)
+			}
+		case types.WatchTargetTag:
+			for _, tag := range tags {
+				if subscription.Tag == tag {
+					result = append(result, subscription)
+					break
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func (m *MockWatchSubscriptionRepository) GetPreference(user string) (*types.NotificationPreference, error) {
+	preference, ok := m.Preferences[user]
+	if !ok {
+		return nil, nil
+	}
+	return &preference, nil
+}
+
+func (m *MockWatchSubscriptionRepository) GetPreferences(users []string) (map[string]types.NotificationPreference, error) {
+	result := make(map[string]types.NotificationPreference)
+	for _, user := range users {
+		if preference, ok := m.Preferences[user]; ok {
+			result[user] = preference
+		}
+	}
+	return result, nil
+}
+
+func (m *MockWatchSubscriptionRepository) UpsertPreference(preference *types.NotificationPreference) error {
+	if m.UpsertPreferenceError != nil {
+		return m.UpsertPreferenceError
+	}
+	if m.Preferences == nil {
+		m.Preferences = make(map[string]types.NotificationPreference)
+	}
+	m.Preferences[preference.User] = *preference
+	return nil
+}
+
+func (m *MockWatchSubscriptionRepository) ListReporters(outageID uint) ([]string, error) {
+	return m.Reporters[outageID], nil
+}
+
+// MockAPITokenRepository is an in-memory implementation of APITokenRepository for testing.
+type MockAPITokenRepository struct {
+	Tokens []types.APIToken
+	Uses   []types.APITokenUse
+
+	RecordTokenUseError error
+}
+
+func (m *MockAPITokenRepository) CreateToken(token *types.APIToken) error {
+	token.ID = uint(len(m.Tokens) + 1)
+	m.Tokens = append(m.Tokens, *token)
+	return nil
+}
+
+func (m *MockAPITokenRepository) ListTokens(user string) ([]types.APIToken, error) {
+	var result []types.APIToken
+	for _, token := range m.Tokens {
+		if token.User == user {
+			result = append(result, token)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockAPITokenRepository) RevokeToken(user string, tokenID uint) error {
+	for i, token := range m.Tokens {
+		if token.ID == tokenID && token.User == user {
+			m.Tokens = append(m.Tokens[:i], m.Tokens[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (m *MockAPITokenRepository) GetTokenByHash(tokenHash string) (*types.APIToken, error) {
+	for _, token := range m.Tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockAPITokenRepository) RecordTokenUse(use *types.APITokenUse) error {
+	if m.RecordTokenUseError != nil {
+		return m.RecordTokenUseError
+	}
+	m.Uses = append(m.Uses, *use)
+	return nil
+}
+
+func (m *MockAPITokenRepository) DeleteTokenUsesBefore(cutoff time.Time) (int64, error) {
+	kept := m.Uses[:0]
+	for _, use := range m.Uses {
+		if !use.CreatedAt.Before(cutoff) {
+			kept = append(kept, use)
+		}
+	}
+	deleted := int64(len(m.Uses) - len(kept))
+	m.Uses = kept
+	return deleted, nil
+}
+
+// MockSecurityEventRepository is an in-memory implementation of SecurityEventRepository for testing.
+type MockSecurityEventRepository struct {
+	Events []types.SecurityEvent
+
+	RecordEventError error
+}
+
+func (m *MockSecurityEventRepository) RecordEvent(event *types.SecurityEvent) error {
+	if m.RecordEventError != nil {
+		return m.RecordEventError
+	}
+	event.ID = uint(len(m.Events) + 1)
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	m.Events = append(m.Events, *event)
+	return nil
+}
+
+// ListEvents returns the events matching filter, newest first.
+func (m *MockSecurityEventRepository) ListEvents(filter types.SecurityEventFilter) ([]types.SecurityEvent, error) {
+	var result []types.SecurityEvent
+	for i := len(m.Events) - 1; i >= 0; i-- {
+		event := m.Events[i]
+		if (filter.Type != "" && event.Type != filter.Type) ||
+			(filter.User != "" && event.User != filter.User) ||
+			(filter.Delegator != "" && event.Delegator != filter.Delegator) ||
+			(filter.ComponentSlug != "" && event.ComponentName != filter.ComponentSlug) ||
+			(!filter.Start.IsZero() && event.CreatedAt.Before(filter.Start)) ||
+			(!filter.End.IsZero() && event.CreatedAt.After(filter.End)) {
+			continue
+		}
+		result = append(result, event)
+		if filter.Limit > 0 && len(result) == filter.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (m *MockSecurityEventRepository) DeleteEventsBefore(cutoff time.Time) (int64, error) {
+	kept := m.Events[:0]
+	for _, event := range m.Events {
+		if !event.CreatedAt.Before(cutoff) {
+			kept = append(kept, event)
+		}
+	}
+	deleted := int64(len(m.Events) - len(kept))
+	m.Events = kept
+	return deleted, nil
+}
+
+// MockIdempotencyKeyRepository is an in-memory implementation of IdempotencyKeyRepository for testing.
+type MockIdempotencyKeyRepository struct {
+	Keys []types.IdempotencyKey
+
+	ReserveKeyError error
+}
+
+func (m *MockIdempotencyKeyRepository) ReserveKey(key *types.IdempotencyKey, notBefore time.Time) (*types.IdempotencyKey, error) {
+	if m.ReserveKeyError != nil {
+		return nil, m.ReserveKeyError
+	}
+	for i, existing := range m.Keys {
+		if existing.Key != key.Key || existing.User != key.User || existing.Delegator != key.Delegator {
+			continue
+		}
+		if !existing.CreatedAt.Before(notBefore) {
+			found := existing
+			return &found, nil
+		}
+		m.Keys = append(m.Keys[:i], m.Keys[i+1:]...)
+		break
+	}
+	key.ID = uint(len(m.Keys) + 1)
+	for _, existing := range m.Keys {
+		key.ID = max(key.ID, existing.ID+1)
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+	m.Keys = append(m.Keys, *key)
+	return nil, nil
+}
+
+func (m *MockIdempotencyKeyRepository) CompleteKey(id uint, statusCode int, responseBody []byte) error {
+	for i := range m.Keys {
+		if m.Keys[i].ID == id {
+			m.Keys[i].StatusCode = statusCode
+			m.Keys[i].ResponseBody = responseBody
+		}
+	}
+	return nil
+}
+
+func (m *MockIdempotencyKeyRepository) ReleaseKey(id uint) error {
+	for i := range m.Keys {
+		if m.Keys[i].ID == id {
+			m.Keys = append(m.Keys[:i], m.Keys[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *MockIdempotencyKeyRepository) DeleteKeysBefore(cutoff time.Time) (int64, error) {
+	kept := m.Keys[:0]
+	for _, key := range m.Keys {
+		if !key.CreatedAt.Before(cutoff) {
+			kept = append(kept, key)
+		}
+	}
+	deleted := int64(len(m.Keys) - len(kept))
+	m.Keys = kept
+	return deleted, nil
+}
+
 // TestConfig creates a test DashboardConfig for testing.
 func TestConfig(autoResolve, requiresConfirmation bool) *types.DashboardConfig {
 	subComponent := types.SubComponent{
@@ -421,3 +762,47 @@ func TestConfig(autoResolve, requiresConfirmation bool) *types.DashboardConfig {
 		},
 	}
 }
+
+// MockEscalationRepository is an in-memory implementation of EscalationRepository for testing.
+type MockEscalationRepository struct {
+	Recorded    []types.OutageEscalation
+	RecordError error
+}
+
+func (m *MockEscalationRepository) RecordEscalation(escalation *types.OutageEscalation) (bool, error) {
+	if m.RecordError != nil {
+		return false, m.RecordError
+	}
+	for _, recorded := range m.Recorded {
+		if recorded.OutageID == escalation.OutageID && recorded.Step == escalation.Step && recorded.ActivityAt.Equal(escalation.ActivityAt) {
+			return false, nil
+		}
+	}
+	m.Recorded = append(m.Recorded, *escalation)
+	return true, nil
+}
+
+// MockSlackChannelStatusRepository is an in-memory implementation of SlackChannelStatusRepository for testing.
+type MockSlackChannelStatusRepository struct {
+	Statuses  map[string]types.SlackChannelStatus
+	SaveError error
+}
+
+func (m *MockSlackChannelStatusRepository) GetChannelStatus(channel string) (*types.SlackChannelStatus, error) {
+	status, ok := m.Statuses[channel]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &status, nil
+}
+
+func (m *MockSlackChannelStatusRepository) SaveChannelStatus(status *types.SlackChannelStatus) error {
+	if m.SaveError != nil {
+		return m.SaveError
+	}
+	if m.Statuses == nil {
+		m.Statuses = map[string]types.SlackChannelStatus{}
+	}
+	m.Statuses[status.Channel] = *status
+	return nil
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"ship-status-dash/pkg/types"
 
 	"gorm.io/gorm"
@@ -11,8 +13,11 @@ type OutageLinkRepository interface {
 	AddOutageLink(link *types.OutageLink) error
 	ListOutageLinks(outageID uint) ([]types.OutageLink, error)
 	GetOutageLink(outageID, linkID uint) (*types.OutageLink, error)
-	UpdateOutageLink(outageID, linkID uint, url string, linkType types.LinkType, description string) (*types.OutageLink, error)
+	UpdateOutageLink(outageID, linkID uint, url string, linkType types.LinkType, description string, visibility types.Visibility) (*types.OutageLink, error)
 	DeleteOutageLink(outageID, linkID uint) error
+	// ListOutageLinksByType returns the links of linkType created after createdAfter, across all outages.
+	ListOutageLinksByType(linkType types.LinkType, createdAfter time.Time) ([]types.OutageLink, error)
+	UpdateOutageLinkStatus(linkID uint, status string) error
 }
 
 type gormOutageLinkRepository struct {
@@ -44,15 +49,20 @@ func (r *gormOutageLinkRepository) GetOutageLink(outageID, linkID uint) (*types.
 	return &link, nil
 }
 
+// An empty visibility leaves the link's unchanged.
 // Returns gorm.ErrRecordNotFound if no matching link exists for the given outage.
-func (r *gormOutageLinkRepository) UpdateOutageLink(outageID, linkID uint, url string, linkType types.LinkType, description string) (*types.OutageLink, error) {
+func (r *gormOutageLinkRepository) UpdateOutageLink(outageID, linkID uint, url string, linkType types.LinkType, description string, visibility types.Visibility) (*types.OutageLink, error) {
+	updates := map[string]interface{}{
+		"url":         url,
+		"link_type":   linkType,
+		"description": description,
+	}
+	if visibility != "" {
+		updates["visibility"] = visibility
+	}
 	result := r.db.Model(&types.OutageLink{}).
 		Where("id = ? AND outage_id = ?", linkID, outageID).
-		Updates(map[string]interface{}{
-			"url":         url,
-			"link_type":   linkType,
-			"description": description,
-		})
+		Updates(updates)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -77,3 +87,15 @@ func (r *gormOutageLinkRepository) DeleteOutageLink(outageID, linkID uint) error
 	}
 	return nil
 }
+
+func (r *gormOutageLinkRepository) ListOutageLinksByType(linkType types.LinkType, createdAfter time.Time) ([]types.OutageLink, error) {
+	var links []types.OutageLink
+	if err := r.db.Where("link_type = ? AND created_at > ?", linkType, createdAfter).Order("created_at ASC").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *gormOutageLinkRepository) UpdateOutageLinkStatus(linkID uint, status string) error {
+	return r.db.Model(&types.OutageLink{}).Where("id = ?", linkID).Update("status", status).Error
+}
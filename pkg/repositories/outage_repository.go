@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"ship-status-dash/pkg/types"
 )
@@ -35,8 +36,11 @@ type OutageRepository interface {
 	GetActiveSuspectedOutages(componentSlug, subComponentSlug string) ([]types.Outage, error)
 	GetActiveSuspectedOutagesForComponent(componentSlug string) ([]types.Outage, error)
 	GetStaleSuspectedOutages(cutoff time.Time) ([]types.Outage, error)
+	GetOutagesCreatedBy(createdBy string, since time.Time) ([]types.Outage, error)
+	GetOutagesReportedBy(user string, since time.Time) ([]types.Outage, error)
 
 	GetOutageAuditLogs(outageID uint) ([]types.OutageAuditLog, error)
+	GetAuditLogs(filter types.AuditLogFilter) ([]types.AuditLogEntry, error)
 
 	DeleteOutage(outage *types.Outage, user string) error
 }
@@ -76,7 +80,7 @@ func roundOutageTimes(outage *types.Outage) {
 // CreateOutage creates a new outage record in the database.
 func (r *gormOutageRepository) CreateOutage(outage *types.Outage, user string) error {
 	roundOutageTimes(outage)
-	return r.db.WithContext(context.WithValue(context.Background(), types.CurrentUserKey, user)).Create(outage).Error
+	return r.db.WithContext(context.WithValue(r.db.Statement.Context, types.CurrentUserKey, user)).Create(outage).Error
 }
 
 // CreateReason creates a new reason record in the database.
@@ -88,7 +92,7 @@ func (r *gormOutageRepository) CreateReason(reason *types.Reason) error {
 // If the outage does not exist, it will be created.
 func (r *gormOutageRepository) SaveOutage(outage *types.Outage, user string) error {
 	roundOutageTimes(outage)
-	return r.db.WithContext(context.WithValue(context.Background(), types.CurrentUserKey, user)).Save(outage).Error
+	return r.db.WithContext(context.WithValue(r.db.Statement.Context, types.CurrentUserKey, user)).Save(outage).Error
 }
 
 // GetOutageByID retrieves a specific outage by ID for a component/sub-component combination.
@@ -280,6 +284,31 @@ func (r *gormOutageRepository) GetOutagesDuring(queryStart, queryEnd time.Time,
 	return outages, nil
 }
 
+// GetOutagesCreatedBy returns outages created by createdBy that are still active or started at or after since, newest first.
+// Suspected outages are included.
+func (r *gormOutageRepository) GetOutagesCreatedBy(createdBy string, since time.Time) ([]types.Outage, error) {
+	var outages []types.Outage
+	now := time.Now().UTC()
+	err := r.db.Where("created_by = ? AND (end_time IS NULL OR end_time > ? OR start_time >= ?)", createdBy, now, since.UTC()).
+		Order("start_time DESC").
+		Find(&outages).Error
+	return outages, err
+}
+
+// GetOutagesReportedBy returns outages that user reported as suspected and that are still active or were reported
+// at or after since, newest first. Reports are preloaded.
+func (r *gormOutageRepository) GetOutagesReportedBy(user string, since time.Time) ([]types.Outage, error) {
+	var outages []types.Outage
+	now := time.Now().UTC()
+	err := r.db.Preload("Reports").
+		Joins("JOIN outage_reports ON outage_reports.outage_id = outages.id AND outage_reports.deleted_at IS NULL").
+		Where(clause.Eq{Column: clause.Column{Table: "outage_reports", Name: "user"}, Value: user}).
+		Where("outages.end_time IS NULL OR outages.end_time > ? OR outage_reports.created_at >= ?", now, since.UTC()).
+		Order("outage_reports.created_at DESC").
+		Find(&outages).Error
+	return outages, err
+}
+
 // applyRefsFilter restricts a query to rows matching any of the given (component, sub-component) pairs.
 func applyRefsFilter(q *gorm.DB, refs []types.SubComponentRef) *gorm.DB {
 	conds := make([]string, len(refs))
@@ -297,7 +326,57 @@ func (r *gormOutageRepository) GetOutageAuditLogs(outageID uint) ([]types.Outage
 	return outageAuditLogs, err
 }
 
+// auditLogRow is an audit log joined with the sub-component of its outage.
+type auditLogRow struct {
+	types.OutageAuditLog
+	ComponentName    string
+	SubComponentName string
+}
+
+// GetAuditLogs returns audit logs across all outages matching the filter, newest first, with field-level changes computed.
+// Deleted outages are included so that their audit trail remains visible.
+func (r *gormOutageRepository) GetAuditLogs(filter types.AuditLogFilter) ([]types.AuditLogEntry, error) {
+	q := r.db.Model(&types.OutageAuditLog{}).
+		Select("outage_audit_logs.*, outages.component_name, outages.sub_component_name").
+		Joins("LEFT JOIN outages ON outages.id = outage_audit_logs.outage_id")
+	if filter.User != "" {
+		q = q.Where(clause.Eq{Column: clause.Column{Table: "outage_audit_logs", Name: "user"}, Value: filter.User})
+	}
+	if filter.Operation != "" {
+		q = q.Where("outage_audit_logs.operation = ?", filter.Operation)
+	}
+	if filter.ComponentSlug != "" {
+		q = q.Where("outages.component_name = ?", filter.ComponentSlug)
+	}
+	if !filter.Start.IsZero() {
+		q = q.Where("outage_audit_logs.created_at >= ?", filter.Start.UTC())
+	}
+	if !filter.End.IsZero() {
+		q = q.Where("outage_audit_logs.created_at <= ?", filter.End.UTC())
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		q = q.Offset(filter.Offset)
+	}
+
+	var rows []auditLogRow
+	if err := q.Order("outage_audit_logs.created_at DESC, outage_audit_logs.id DESC").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("OutageRepository.GetAuditLogs: query audit logs: %w", err)
+	}
+
+	entries := make([]types.AuditLogEntry, 0, len(rows))
+	for _, row := range rows {
+		entry := types.NewAuditLogEntry(row.OutageAuditLog)
+		entry.ComponentName = row.ComponentName
+		entry.SubComponentName = row.SubComponentName
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
 // DeleteOutage deletes an outage from the database.
 func (r *gormOutageRepository) DeleteOutage(outage *types.Outage, user string) error {
-	return r.db.WithContext(context.WithValue(context.Background(), types.CurrentUserKey, user)).Delete(outage).Error
+	return r.db.WithContext(context.WithValue(r.db.Statement.Context, types.CurrentUserKey, user)).Delete(outage).Error
 }
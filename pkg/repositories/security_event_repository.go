@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"ship-status-dash/pkg/types"
+)
+
+// SecurityEventRepository handles persistence for the security event log.
+type SecurityEventRepository interface {
+	RecordEvent(event *types.SecurityEvent) error
+	ListEvents(filter types.SecurityEventFilter) ([]types.SecurityEvent, error)
+	DeleteEventsBefore(cutoff time.Time) (int64, error)
+}
+
+type gormSecurityEventRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMSecurityEventRepository creates a new GORM-based SecurityEventRepository.
+func NewGORMSecurityEventRepository(db *gorm.DB) SecurityEventRepository {
+	return &gormSecurityEventRepository{db: db}
+}
+
+func (r *gormSecurityEventRepository) RecordEvent(event *types.SecurityEvent) error {
+	return r.db.Create(event).Error
+}
+
+// ListEvents returns the events matching filter, newest first.
+func (r *gormSecurityEventRepository) ListEvents(filter types.SecurityEventFilter) ([]types.SecurityEvent, error) {
+	q := r.db.Model(&types.SecurityEvent{})
+	if filter.Type != "" {
+		q = q.Where(clause.Eq{Column: clause.Column{Name: "type"}, Value: filter.Type})
+	}
+	if filter.User != "" {
+		q = q.Where(clause.Eq{Column: clause.Column{Name: "user"}, Value: filter.User})
+	}
+	if filter.Delegator != "" {
+		q = q.Where("delegator = ?", filter.Delegator)
+	}
+	if filter.ComponentSlug != "" {
+		q = q.Where("component_name = ?", filter.ComponentSlug)
+	}
+	if !filter.Start.IsZero() {
+		q = q.Where("created_at >= ?", filter.Start.UTC())
+	}
+	if !filter.End.IsZero() {
+		q = q.Where("created_at <= ?", filter.End.UTC())
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+
+	var events []types.SecurityEvent
+	err := q.Order("created_at DESC, id DESC").Find(&events).Error
+	return events, err
+}
+
+// DeleteEventsBefore permanently deletes the events created before cutoff and returns how many there were.
+func (r *gormSecurityEventRepository) DeleteEventsBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Unscoped().Where("created_at < ?", cutoff.UTC()).Delete(&types.SecurityEvent{})
+	return result.RowsAffected, result.Error
+}
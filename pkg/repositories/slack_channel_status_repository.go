@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"ship-status-dash/pkg/types"
+)
+
+// SlackChannelStatusRepository stores the status last published to Slack channels with status_sync.
+type SlackChannelStatusRepository interface {
+	// GetChannelStatus returns gorm.ErrRecordNotFound when nothing was published to channel yet.
+	GetChannelStatus(channel string) (*types.SlackChannelStatus, error)
+	SaveChannelStatus(status *types.SlackChannelStatus) error
+}
+
+type gormSlackChannelStatusRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMSlackChannelStatusRepository creates a new GORM-based SlackChannelStatusRepository.
+func NewGORMSlackChannelStatusRepository(db *gorm.DB) SlackChannelStatusRepository {
+	return &gormSlackChannelStatusRepository{db: db}
+}
+
+func (r *gormSlackChannelStatusRepository) GetChannelStatus(channel string) (*types.SlackChannelStatus, error) {
+	var status types.SlackChannelStatus
+	if err := r.db.Where("channel = ?", channel).First(&status).Error; err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (r *gormSlackChannelStatusRepository) SaveChannelStatus(status *types.SlackChannelStatus) error {
+	return r.db.Save(status).Error
+}
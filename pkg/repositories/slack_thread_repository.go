@@ -12,6 +12,7 @@ type SlackThreadRepository interface {
 	GetThreadsForOutage(outageID uint) ([]types.SlackThread, error)
 	GetThreadForOutageAndChannel(outageID uint, channel string) (*types.SlackThread, error)
 	UpdateThread(thread *types.SlackThread) error
+	GetThreadByTimestamp(channelID, threadTimestamp string) (*types.OutageSlackThread, error)
 }
 
 // gormSlackThreadRepository is a GORM implementation of SlackThreadRepository.
@@ -51,3 +52,22 @@ func (r *gormSlackThreadRepository) GetThreadForOutageAndChannel(outageID uint,
 func (r *gormSlackThreadRepository) UpdateThread(thread *types.SlackThread) error {
 	return r.db.Save(thread).Error
 }
+
+// GetThreadByTimestamp retrieves the outage thread started by the message at threadTimestamp in channelID,
+// with the sub-component of its outage. Returns gorm.ErrRecordNotFound if no outage thread matches.
+func (r *gormSlackThreadRepository) GetThreadByTimestamp(channelID, threadTimestamp string) (*types.OutageSlackThread, error) {
+	var threads []types.OutageSlackThread
+	err := r.db.Model(&types.SlackThread{}).
+		Select("slack_threads.*, outages.component_name, outages.sub_component_name").
+		Joins("JOIN outages ON outages.id = slack_threads.outage_id AND outages.deleted_at IS NULL").
+		Where("slack_threads.channel_id = ? AND slack_threads.thread_timestamp = ? AND slack_threads.deleted_at IS NULL", channelID, threadTimestamp).
+		Limit(1).
+		Scan(&threads).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(threads) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &threads[0], nil
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"ship-status-dash/pkg/types"
 
 	"gorm.io/gorm"
@@ -11,8 +13,10 @@ type TriageNoteRepository interface {
 	AddTriageNote(note *types.TriageNote) error
 	ListTriageNotes(outageID uint) ([]types.TriageNote, error)
 	GetTriageNote(outageID, noteID uint) (*types.TriageNote, error)
-	UpdateTriageNote(outageID, noteID uint, body string) (*types.TriageNote, error)
+	UpdateTriageNote(outageID, noteID uint, body string, visibility types.Visibility) (*types.TriageNote, error)
 	DeleteTriageNote(outageID, noteID uint) error
+	ListTriageNotesByAuthor(author string, since time.Time) ([]types.AuthoredTriageNote, error)
+	GetTriageNoteBySlackMessage(outageID uint, messageTS string) (*types.TriageNote, error)
 }
 
 type gormTriageNoteRepository struct {
@@ -44,9 +48,14 @@ func (r *gormTriageNoteRepository) GetTriageNote(outageID, noteID uint) (*types.
 	return &note, nil
 }
 
+// An empty visibility leaves the note's unchanged.
 // Returns gorm.ErrRecordNotFound if no matching note exists for the given outage.
-func (r *gormTriageNoteRepository) UpdateTriageNote(outageID, noteID uint, body string) (*types.TriageNote, error) {
-	result := r.db.Model(&types.TriageNote{}).Where("id = ? AND outage_id = ?", noteID, outageID).Update("body", body)
+func (r *gormTriageNoteRepository) UpdateTriageNote(outageID, noteID uint, body string, visibility types.Visibility) (*types.TriageNote, error) {
+	updates := map[string]interface{}{"body": body}
+	if visibility != "" {
+		updates["visibility"] = visibility
+	}
+	result := r.db.Model(&types.TriageNote{}).Where("id = ? AND outage_id = ?", noteID, outageID).Updates(updates)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -71,3 +80,29 @@ func (r *gormTriageNoteRepository) DeleteTriageNote(outageID, noteID uint) error
 	}
 	return nil
 }
+
+// GetTriageNoteBySlackMessage returns the note mirrored from the Slack message at messageTS.
+// Returns gorm.ErrRecordNotFound if the message has not been mirrored.
+func (r *gormTriageNoteRepository) GetTriageNoteBySlackMessage(outageID uint, messageTS string) (*types.TriageNote, error) {
+	var note types.TriageNote
+	if err := r.db.Where("outage_id = ? AND slack_message_ts = ?", outageID, messageTS).First(&note).Error; err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// ListTriageNotesByAuthor returns notes written by author at or after since, newest first,
+// with the sub-component of each note's outage.
+func (r *gormTriageNoteRepository) ListTriageNotesByAuthor(author string, since time.Time) ([]types.AuthoredTriageNote, error) {
+	var notes []types.AuthoredTriageNote
+	err := r.db.Model(&types.TriageNote{}).
+		Select("triage_notes.*, outages.component_name, outages.sub_component_name").
+		Joins("JOIN outages ON outages.id = triage_notes.outage_id AND outages.deleted_at IS NULL").
+		Where("triage_notes.author = ? AND triage_notes.created_at >= ?", author, since.UTC()).
+		Order("triage_notes.created_at DESC").
+		Scan(&notes).Error
+	if err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"ship-status-dash/pkg/types"
+)
+
+// WatchSubscriptionRepository handles persistence for user watch subscriptions and notification preferences.
+type WatchSubscriptionRepository interface {
+	CreateSubscription(subscription *types.WatchSubscription) error
+	ListSubscriptions(user string) ([]types.WatchSubscription, error)
+	CountSubscriptions(user string) (int64, error)
+	DeleteSubscription(user string, subscriptionID uint) error
+	FindMatchingSubscriptions(componentSlug, subComponentSlug string, tags []string, outageID uint) ([]types.WatchSubscription, error)
+
+	GetPreference(user string) (*types.NotificationPreference, error)
+	GetPreferences(users []string) (map[string]types.NotificationPreference, error)
+	UpsertPreference(preference *types.NotificationPreference) error
+
+	ListReporters(outageID uint) ([]string, error)
+}
+
+type gormWatchSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMWatchSubscriptionRepository creates a new GORM-based WatchSubscriptionRepository.
+func NewGORMWatchSubscriptionRepository(db *gorm.DB) WatchSubscriptionRepository {
+	return &gormWatchSubscriptionRepository{db: db}
+}
+
+func (r *gormWatchSubscriptionRepository) CreateSubscription(subscription *types.WatchSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+func (r *gormWatchSubscriptionRepository) ListSubscriptions(user string) ([]types.WatchSubscription, error) {
+	var subscriptions []types.WatchSubscription
+	err := r.db.Where(clause.Eq{Column: clause.Column{Name: "user"}, Value: user}).
+		Order("created_at ASC").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *gormWatchSubscriptionRepository) CountSubscriptions(user string) (int64, error) {
+	var count int64
+	err := r.db.Model(&types.WatchSubscription{}).
+		Where(clause.Eq{Column: clause.Column{Name: "user"}, Value: user}).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteSubscription removes a subscription owned by user.
+// Returns gorm.ErrRecordNotFound if the user has no subscription with that ID.
+func (r *gormWatchSubscriptionRepository) DeleteSubscription(user string, subscriptionID uint) error {
+	result := r.db.Where(clause.Eq{Column: clause.Column{Name: "user"}, Value: user}).
+		Delete(&types.WatchSubscription{}, subscriptionID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindMatchingSubscriptions returns subscriptions following the component, the sub-component, any of tags, or the outage.
+func (r *gormWatchSubscriptionRepository) FindMatchingSubscriptions(componentSlug, subComponentSlug string, tags []string, outageID uint) ([]types.WatchSubscription, error) {
+	matches := r.db.Where("target_type = ? AND component_name = ?", types.WatchTargetComponent, componentSlug).
+		Or("target_type = ? AND component_name = ? AND sub_component_name = ?", types.WatchTargetSubComponent, componentSlug, subComponentSlug).
+		Or("target_type = ? AND outage_id = ?", types.WatchTargetOutage, outageID)
+	if len(tags) > 0 {
+		matches = matches.Or("target_type = ? AND tag IN ?", types.WatchTargetTag, tags)
+	}
+	var subscriptions []types.WatchSubscription
+	err := r.db.Where(matches).Order("id ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// GetPreference returns the user's notification preference, or nil when none is set.
+func (r *gormWatchSubscriptionRepository) GetPreference(user string) (*types.NotificationPreference, error) {
+	var preference types.NotificationPreference
+	err := r.db.Where(clause.Eq{Column: clause.Column{Name: "user"}, Value: user}).First(&preference).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+// GetPreferences returns the notification preferences of the given users keyed by user. Users without one are omitted.
+func (r *gormWatchSubscriptionRepository) GetPreferences(users []string) (map[string]types.NotificationPreference, error) {
+	result := make(map[string]types.NotificationPreference)
+	if len(users) == 0 {
+		return result, nil
+	}
+	var preferences []types.NotificationPreference
+	if err := r.db.Where(clause.IN{Column: clause.Column{Name: "user"}, Values: toAnySlice(users)}).Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+	for _, preference := range preferences {
+		result[preference.User] = preference
+	}
+	return result, nil
+}
+
+// UpsertPreference creates or replaces the preference for preference.User.
+func (r *gormWatchSubscriptionRepository) UpsertPreference(preference *types.NotificationPreference) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user"}},
+		DoUpdates: clause.AssignmentColumns([]string{"channel", "destination", "notify_reported_outages", "updated_at"}),
+	}).Create(preference).Error
+}
+
+// ListReporters returns the users who reported the outage as suspected.
+func (r *gormWatchSubscriptionRepository) ListReporters(outageID uint) ([]string, error) {
+	var users []string
+	err := r.db.Model(&types.OutageReport{}).Where("outage_id = ?", outageID).Order("created_at ASC").Pluck("user", &users).Error
+	return users, err
+}
+
+func toAnySlice(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
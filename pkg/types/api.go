@@ -14,11 +14,15 @@ type UpsertOutageRequest struct {
 	DiscoveredFrom    *string       `json:"discovered_from,omitempty"`
 	Confirmed         *bool         `json:"confirmed,omitempty"`
 	InitialTriageNote *string       `json:"initial_triage_note,omitempty"`
+	// DescriptionVisibility is public or internal. New outages have public descriptions unless it is set.
+	DescriptionVisibility *string `json:"description_visibility,omitempty"`
 }
 
 // TriageNoteBodyRequest represents the body of a request to add or update a triage note.
 type TriageNoteBodyRequest struct {
 	Body string `json:"body"`
+	// Visibility is public or internal. New notes are public unless it is set, and updates without it keep the note's.
+	Visibility string `json:"visibility,omitempty"`
 }
 
 // OutageLinkRequest represents the body of a request to add or update an outage link.
@@ -26,6 +30,8 @@ type OutageLinkRequest struct {
 	URL         string `json:"url"`
 	LinkType    string `json:"link_type,omitempty"`
 	Description string `json:"description,omitempty"`
+	// Visibility is public or internal, defaulting as it does for triage notes.
+	Visibility string `json:"visibility,omitempty"`
 }
 
 // ComponentMonitorReportRequest represents a report from a component monitor.
@@ -54,10 +60,90 @@ type ReportSuspectedOutageRequest struct {
 	Description string `json:"description"`
 }
 
-// OutageDayBucket holds aggregated outage data for a single calendar day.
-type OutageDayBucket struct {
-	Date               string  `json:"date"`                 // YYYY-MM-DD
-	HighestSeverity    *string `json:"highest_severity"`     // null when no outages that day
-	TotalOutageMinutes float64 `json:"total_outage_minutes"` // merged, non-overlapping minutes
-	OutageCount        int     `json:"outage_count"`
+// OutageHistoryBucket holds aggregated outage data for a single hour, day, or week.
+type OutageHistoryBucket struct {
+	Date               string             `json:"date"`                 // YYYY-MM-DD of the bucket start in the requested time zone
+	Start              time.Time          `json:"start"`                // inclusive bucket start
+	End                time.Time          `json:"end"`                  // exclusive bucket end
+	HighestSeverity    *string            `json:"highest_severity"`     // null when no outages in the bucket
+	TotalOutageMinutes float64            `json:"total_outage_minutes"` // merged, non-overlapping minutes
+	SeverityMinutes    map[string]float64 `json:"severity_minutes"`     // minutes attributed to the most severe outage active at the time
+	OutageCount        int                `json:"outage_count"`
+}
+
+// SubComponentHistory holds the history buckets of one sub-component in an aggregated history response.
+type SubComponentHistory struct {
+	ComponentName    string                `json:"component_name"`
+	SubComponentName string                `json:"sub_component_name"`
+	Buckets          []OutageHistoryBucket `json:"buckets"`
+}
+
+// ReportedOutageState describes where a suspected outage reported by a user currently stands.
+type ReportedOutageState string
+
+const (
+	// ReportedOutageOpen means the outage is active and still Suspected.
+	ReportedOutageOpen ReportedOutageState = "open"
+	// ReportedOutageConfirmed means the outage is active and was confirmed or raised above Suspected.
+	ReportedOutageConfirmed ReportedOutageState = "confirmed"
+	// ReportedOutageResolved means the outage has ended.
+	ReportedOutageResolved ReportedOutageState = "resolved"
+)
+
+// ReportedOutage is an outage a user reported as suspected, with the time of their report and the outage's current state.
+type ReportedOutage struct {
+	Outage     Outage              `json:"outage"`
+	ReportedAt time.Time           `json:"reported_at"`
+	State      ReportedOutageState `json:"state"`
+}
+
+// AuthoredTriageNote is a triage note together with the sub-component of the outage it belongs to.
+type AuthoredTriageNote struct {
+	TriageNote
+	ComponentName    string `json:"component_name"`
+	SubComponentName string `json:"sub_component_name"`
+}
+
+// UserActivity summarizes what a user has done and what they are responsible for.
+type UserActivity struct {
+	Username           string               `json:"username"`
+	CreatedOutages     []Outage             `json:"created_outages"`
+	ReportedOutages    []ReportedOutage     `json:"reported_outages"`
+	TriageNotes        []AuthoredTriageNote `json:"triage_notes"`
+	OwnedComponents    []string             `json:"owned_components"`
+	OwnedActiveOutages []Outage             `json:"owned_active_outages"`
+}
+
+// WatchSubscriptionRequest is the body for creating a watch subscription.
+// ComponentName is required for component, sub_component, and outage targets; SubComponentName for sub_component
+// and outage targets; Tag for tag targets; OutageID for outage targets.
+type WatchSubscriptionRequest struct {
+	TargetType       string `json:"target_type"`
+	ComponentName    string `json:"component_name"`
+	SubComponentName string `json:"sub_component_name"`
+	Tag              string `json:"tag"`
+	OutageID         uint   `json:"outage_id"`
+	Channel          string `json:"channel"`
+	Destination      string `json:"destination"`
+}
+
+// NotificationPreferenceRequest is the body for setting a user's default notification channel.
+type NotificationPreferenceRequest struct {
+	Channel               string `json:"channel"`
+	Destination           string `json:"destination"`
+	NotifyReportedOutages *bool  `json:"notify_reported_outages"`
+}
+
+// APITokenRequest is the body for creating a personal access token.
+// Scopes must be known permissions; ExpiresAt, when set, must be in the future.
+type APITokenRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreatedAPIToken is returned when a token is created. Token is the secret and is not shown again.
+type CreatedAPIToken struct {
+	APIToken
+	Token string `json:"token"`
 }
@@ -0,0 +1,207 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// AuditFieldChange describes a single field that differs between the old and new snapshot of an audit log entry.
+// Field is a dotted path; elements of nested lists are addressed by ID, e.g. "triage_notes[12].body".
+// Old is null for added fields and New is null for removed fields.
+type AuditFieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// AuditLogEntry is an audit log as returned by the API: the stored record, the sub-component of
+// the audited outage, and the field-level changes computed from the Old and New snapshots.
+type AuditLogEntry struct {
+	OutageAuditLog
+	ComponentName    string             `json:"component_name,omitempty"`
+	SubComponentName string             `json:"sub_component_name,omitempty"`
+	Changes          []AuditFieldChange `json:"changes"`
+}
+
+// AuditLogFilter narrows the global audit log feed. Zero values are ignored.
+type AuditLogFilter struct {
+	User          string
+	Operation     string
+	ComponentSlug string
+	Start         time.Time
+	End           time.Time
+	Limit         int
+	// Offset skips that many of the newest matching entries, to read the feed in pages.
+	Offset int
+}
+
+// auditIgnoredFields are bookkeeping fields that change on every write and carry no audit value.
+var auditIgnoredFields = map[string]bool{
+	"UpdatedAt":             true,
+	"last_auditable_update": true,
+	"audit_logs":            true,
+}
+
+// NewAuditLogEntry builds an AuditLogEntry for log, computing its field-level changes.
+// Snapshots that cannot be parsed yield no changes rather than an error, so one malformed
+// record does not hide the rest of the history.
+func NewAuditLogEntry(log OutageAuditLog) AuditLogEntry {
+	changes, err := DiffAuditSnapshots(log.Old, log.New)
+	if err != nil {
+		changes = []AuditFieldChange{}
+	}
+	return AuditLogEntry{OutageAuditLog: log, Changes: changes}
+}
+
+// DiffAuditSnapshots compares two JSON outage snapshots and returns the changed fields sorted by path.
+// An empty snapshot is treated as an empty object, so creates and deletes list every field.
+func DiffAuditSnapshots(oldJSON, newJSON []byte) ([]AuditFieldChange, error) {
+	oldVal, err := decodeSnapshot(oldJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode old snapshot: %w", err)
+	}
+	newVal, err := decodeSnapshot(newJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode new snapshot: %w", err)
+	}
+
+	changes := []AuditFieldChange{}
+	diffValues("", oldVal, newVal, &changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
+
+func decodeSnapshot(data []byte) (map[string]any, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return map[string]any{}, nil
+	}
+	var v map[string]any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func diffValues(path string, oldVal, newVal any, changes *[]AuditFieldChange) {
+	oldVal, newVal = flattenNullTime(oldVal), flattenNullTime(newVal)
+	oldMap, oldIsMap := oldVal.(map[string]any)
+	newMap, newIsMap := newVal.(map[string]any)
+	if oldIsMap && newIsMap {
+		diffMaps(path, oldMap, newMap, changes)
+		return
+	}
+
+	oldList, oldIsList := oldVal.([]any)
+	newList, newIsList := newVal.([]any)
+	if (oldIsList || oldVal == nil) && (newIsList || newVal == nil) && (oldIsList || newIsList) {
+		diffLists(path, oldList, newList, changes)
+		return
+	}
+
+	if !reflect.DeepEqual(oldVal, newVal) {
+		*changes = append(*changes, AuditFieldChange{Field: path, Old: oldVal, New: newVal})
+	}
+}
+
+func diffMaps(path string, oldMap, newMap map[string]any, changes *[]AuditFieldChange) {
+	keys := make(map[string]struct{}, len(oldMap)+len(newMap))
+	for k := range oldMap {
+		keys[k] = struct{}{}
+	}
+	for k := range newMap {
+		keys[k] = struct{}{}
+	}
+	for k := range keys {
+		if auditIgnoredFields[k] {
+			continue
+		}
+		diffValues(joinAuditPath(path, k), oldMap[k], newMap[k], changes)
+	}
+}
+
+// diffLists matches list elements by their ID when every element has one (triage notes, links, reasons,
+// Slack threads), so that inserting or removing one element does not report every following element as changed.
+func diffLists(path string, oldList, newList []any, changes *[]AuditFieldChange) {
+	oldByID, oldOK := indexByID(oldList)
+	newByID, newOK := indexByID(newList)
+	if oldOK && newOK {
+		ids := make(map[string]struct{}, len(oldByID)+len(newByID))
+		for id := range oldByID {
+			ids[id] = struct{}{}
+		}
+		for id := range newByID {
+			ids[id] = struct{}{}
+		}
+		for id := range ids {
+			elemPath := fmt.Sprintf("%s[%s]", path, id)
+			oldElem, inOld := oldByID[id]
+			newElem, inNew := newByID[id]
+			switch {
+			case !inOld:
+				*changes = append(*changes, AuditFieldChange{Field: elemPath, New: newElem})
+			case !inNew:
+				*changes = append(*changes, AuditFieldChange{Field: elemPath, Old: oldElem})
+			default:
+				diffValues(elemPath, oldElem, newElem, changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(oldList, newList) {
+		*changes = append(*changes, AuditFieldChange{Field: path, Old: nilIfEmpty(oldList), New: nilIfEmpty(newList)})
+	}
+}
+
+func indexByID(list []any) (map[string]any, bool) {
+	byID := make(map[string]any, len(list))
+	for _, elem := range list {
+		m, ok := elem.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		id, ok := m["ID"]
+		if !ok {
+			return nil, false
+		}
+		byID[fmt.Sprint(id)] = m
+	}
+	return byID, true
+}
+
+// flattenNullTime reduces a serialized sql.NullTime ({"Time": ..., "Valid": ...}) to its time or nil,
+// so that setting an end time is reported as one change instead of two.
+func flattenNullTime(v any) any {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) != 2 {
+		return v
+	}
+	valid, hasValid := m["Valid"].(bool)
+	t, hasTime := m["Time"]
+	if !hasValid || !hasTime {
+		return v
+	}
+	if !valid {
+		return nil
+	}
+	return t
+}
+
+func nilIfEmpty(list []any) any {
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+func joinAuditPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
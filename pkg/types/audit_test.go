@@ -0,0 +1,86 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffAuditSnapshots(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want []AuditFieldChange
+	}{
+		{
+			name: "identical snapshots have no changes",
+			old:  `{"ID":1,"severity":"Down"}`,
+			new:  `{"ID":1,"severity":"Down"}`,
+			want: []AuditFieldChange{},
+		},
+		{
+			name: "scalar field change",
+			old:  `{"ID":1,"severity":"Down","description":"x"}`,
+			new:  `{"ID":1,"severity":"Degraded","description":"x"}`,
+			want: []AuditFieldChange{{Field: "severity", Old: "Down", New: "Degraded"}},
+		},
+		{
+			name: "bookkeeping fields are ignored",
+			old:  `{"ID":1,"UpdatedAt":"2024-01-01T00:00:00Z","last_auditable_update":"2024-01-01T00:00:00Z"}`,
+			new:  `{"ID":1,"UpdatedAt":"2024-01-02T00:00:00Z","last_auditable_update":"2024-01-02T00:00:00Z"}`,
+			want: []AuditFieldChange{},
+		},
+		{
+			name: "null time set is a single change",
+			old:  `{"end_time":{"Time":"0001-01-01T00:00:00Z","Valid":false}}`,
+			new:  `{"end_time":{"Time":"2024-01-02T00:00:00Z","Valid":true}}`,
+			want: []AuditFieldChange{{Field: "end_time", Old: nil, New: "2024-01-02T00:00:00Z"}},
+		},
+		{
+			name: "nested triage note edit is addressed by ID",
+			old:  `{"triage_notes":[{"ID":3,"body":"a","author":"u"},{"ID":4,"body":"b","author":"u"}]}`,
+			new:  `{"triage_notes":[{"ID":3,"body":"a","author":"u"},{"ID":4,"body":"c","author":"u"}]}`,
+			want: []AuditFieldChange{{Field: "triage_notes[4].body", Old: "b", New: "c"}},
+		},
+		{
+			name: "added link",
+			old:  `{}`,
+			new:  `{"links":[{"ID":7,"url":"https://example.com"}]}`,
+			want: []AuditFieldChange{
+				{Field: "links[7]", New: map[string]any{"ID": float64(7), "url": "https://example.com"}},
+			},
+		},
+		{
+			name: "removed link",
+			old:  `{"links":[{"ID":7,"url":"https://example.com"}]}`,
+			new:  `{"links":[]}`,
+			want: []AuditFieldChange{
+				{Field: "links[7]", Old: map[string]any{"ID": float64(7), "url": "https://example.com"}},
+			},
+		},
+		{
+			name: "delete lists every field as removed",
+			old:  `{"ID":1,"severity":"Down"}`,
+			new:  ``,
+			want: []AuditFieldChange{
+				{Field: "ID", Old: float64(1)},
+				{Field: "severity", Old: "Down"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffAuditSnapshots([]byte(tt.old), []byte(tt.new))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewAuditLogEntry_InvalidSnapshot(t *testing.T) {
+	entry := NewAuditLogEntry(OutageAuditLog{Old: []byte("not json"), New: []byte(`{}`)})
+	assert.Equal(t, []AuditFieldChange{}, entry.Changes)
+}
@@ -1,10 +1,23 @@
 package types
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 // DashboardConfig contains the dashboardapplication configuration including component definitions.
 type DashboardConfig struct {
-	Components        []*Component `json:"components" yaml:"components"`
-	Tags              []Tag        `json:"tags" yaml:"tags"`
-	TrustedDelegators []string     `json:"trusted_delegators,omitempty" yaml:"trusted_delegators,omitempty"`
+	Components        []*Component        `json:"components" yaml:"components"`
+	Tags              []Tag               `json:"tags" yaml:"tags"`
+	TrustedDelegators []string            `json:"trusted_delegators,omitempty" yaml:"trusted_delegators,omitempty"`
+	SlackDigests      []SlackDigestConfig `json:"slack_digests,omitempty" yaml:"slack_digests,omitempty"`
+	// GlobalRoles grants identities a role on every component, in addition to the component owners.
+	GlobalRoles []Owner `json:"-" yaml:"global_roles,omitempty"`
+	// DelegationRules lists delegators restricted to some components, permissions and users.
+	DelegationRules []DelegationRule `json:"-" yaml:"delegation_rules,omitempty"`
+	// RateLimits overrides DefaultRateLimits per route class.
+	RateLimits map[RateLimitClass]RateLimit `json:"-" yaml:"rate_limits,omitempty"`
 }
 
 func (c *DashboardConfig) GetComponentBySlug(slug string) *Component {
@@ -64,8 +77,16 @@ type Component struct {
 	Description    string                 `json:"description" yaml:"description"`
 	ShipTeam       string                 `json:"ship_team" yaml:"ship_team"`
 	SlackReporting []SlackReportingConfig `json:"slack_reporting,omitempty" yaml:"slack_reporting,omitempty"`
-	Subcomponents  []SubComponent         `json:"sub_components" yaml:"sub_components"`
-	Owners         []Owner                `json:"owners" yaml:"owners"`
+	// Teams and webhook reporting hold URLs that act as credentials, and email reporting holds addresses,
+	// so none of them are serialized to JSON.
+	TeamsReporting   []TeamsReportingConfig   `json:"-" yaml:"teams_reporting,omitempty"`
+	WebhookReporting []WebhookReportingConfig `json:"-" yaml:"webhook_reporting,omitempty"`
+	EmailReporting   []EmailReportingConfig   `json:"-" yaml:"email_reporting,omitempty"`
+	Paging           []PagingConfig           `json:"paging,omitempty" yaml:"paging,omitempty"`
+	Escalation       []EscalationStep         `json:"escalation,omitempty" yaml:"escalation,omitempty"`
+	Jira             *JiraConfig              `json:"jira,omitempty" yaml:"jira,omitempty"`
+	Subcomponents    []SubComponent           `json:"sub_components" yaml:"sub_components"`
+	Owners           []Owner                  `json:"owners" yaml:"owners"`
 }
 
 func (c *Component) GetSubComponentBySlug(slug string) *SubComponent {
@@ -89,8 +110,13 @@ type SubComponent struct {
 	RequiresConfirmation bool        `json:"requires_confirmation" yaml:"requires_confirmation"`
 	// Critical indicates that an outage on this sub-component should propagate its severity
 	// to the parent component status, bypassing the generic "partial" roll-up.
-	Critical       bool                   `json:"critical,omitempty" yaml:"critical,omitempty"`
-	SlackReporting []SlackReportingConfig `json:"slack_reporting,omitempty" yaml:"slack_reporting,omitempty"`
+	Critical         bool                     `json:"critical,omitempty" yaml:"critical,omitempty"`
+	SlackReporting   []SlackReportingConfig   `json:"slack_reporting,omitempty" yaml:"slack_reporting,omitempty"`
+	TeamsReporting   []TeamsReportingConfig   `json:"-" yaml:"teams_reporting,omitempty"`
+	WebhookReporting []WebhookReportingConfig `json:"-" yaml:"webhook_reporting,omitempty"`
+	EmailReporting   []EmailReportingConfig   `json:"-" yaml:"email_reporting,omitempty"`
+	Paging           []PagingConfig           `json:"paging,omitempty" yaml:"paging,omitempty"`
+	Escalation       []EscalationStep         `json:"escalation,omitempty" yaml:"escalation,omitempty"`
 	// ReportThreshold is the number of community reports required to upgrade a suspected outage
 	// to degraded and trigger Slack notifications. Defaults to 3 when unset.
 	ReportThreshold int `json:"report_threshold,omitempty" yaml:"report_threshold,omitempty"`
@@ -117,6 +143,10 @@ type Owner struct {
 	ServiceAccount string `json:"service_account,omitempty" yaml:"service_account,omitempty"`
 	// User is a username of a user who is an admin of the component, this is used for development/testing purposes only
 	User string `json:"user,omitempty" yaml:"user,omitempty"`
+	// SlackMention is the Slack user (U...) or user group (S...) ID mentioned when an escalation step mentions owners.
+	SlackMention string `json:"slack_mention,omitempty" yaml:"slack_mention,omitempty"`
+	// Role limits what the owner can do on the component. Defaults to admin.
+	Role Role `json:"role,omitempty" yaml:"role,omitempty"`
 }
 
 // ComponentMonitorConfig contains the configuration for the component monitor.
@@ -251,8 +281,24 @@ type HTTPMonitor struct {
 type SlackReportingConfig struct {
 	Channel  string    `json:"channel" yaml:"channel"`
 	Severity *Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// MirrorThreadReplies copies human replies in this channel's outage threads into triage notes.
+	MirrorThreadReplies bool `json:"mirror_thread_replies,omitempty" yaml:"mirror_thread_replies,omitempty"`
+	// StatusSync keeps the channel topic or a pinned message showing the current status of the
+	// sub-components reported to this channel. Unset leaves the channel alone.
+	StatusSync SlackStatusSync `json:"status_sync,omitempty" yaml:"status_sync,omitempty"`
+	// Visibility is internal for channels that may see internal descriptions, triage notes and links. Other
+	// channels get outage messages without them. Mirrored replies are internal notes unless it is public.
+	Visibility Visibility `json:"visibility,omitempty" yaml:"visibility,omitempty"`
 }
 
+// SlackStatusSync is where a channel shows the current status of the sub-components reported to it.
+type SlackStatusSync string
+
+const (
+	SlackStatusSyncTopic  SlackStatusSync = "topic"
+	SlackStatusSyncPinned SlackStatusSync = "pinned"
+)
+
 // GetSlackReporting returns the Slack reporting configuration for a sub-component.
 // If the sub-component has its own SlackReporting config, it is returned.
 // Otherwise, the component's SlackReporting config is returned.
@@ -266,6 +312,222 @@ func GetSlackReporting(component *Component, subComponent *SubComponent) []Slack
 	return nil
 }
 
+// TeamsReportingConfig defines a Microsoft Teams incoming webhook that receives outage notifications as Adaptive Cards.
+type TeamsReportingConfig struct {
+	WebhookURL string    `json:"webhook_url" yaml:"webhook_url"`
+	Severity   *Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Visibility is internal for channels that may see internal descriptions, triage notes and links.
+	Visibility Visibility `json:"visibility,omitempty" yaml:"visibility,omitempty"`
+}
+
+// WebhookFlavor selects the link syntax used in generic chat webhook messages.
+type WebhookFlavor string
+
+const (
+	// WebhookFlavorMattermost formats links as Markdown, as understood by Mattermost and Rocket.Chat.
+	WebhookFlavorMattermost WebhookFlavor = "mattermost"
+	// WebhookFlavorGoogleChat formats links as <url|label>, as understood by Google Chat.
+	WebhookFlavorGoogleChat WebhookFlavor = "google_chat"
+)
+
+// WebhookReportingConfig defines a generic chat webhook that receives outage notifications as {"text": ...} payloads.
+// Flavor defaults to mattermost.
+type WebhookReportingConfig struct {
+	URL      string        `json:"url" yaml:"url"`
+	Flavor   WebhookFlavor `json:"flavor,omitempty" yaml:"flavor,omitempty"`
+	Severity *Severity     `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Visibility is internal to include internal descriptions, triage notes and links in the messages.
+	Visibility Visibility `json:"visibility,omitempty" yaml:"visibility,omitempty"`
+}
+
+// EmailReportingConfig defines a set of email recipients for outage notifications with an optional severity threshold.
+type EmailReportingConfig struct {
+	Recipients []string  `json:"recipients" yaml:"recipients"`
+	Severity   *Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Visibility is internal for recipients who may read internal outage descriptions.
+	Visibility Visibility `json:"visibility,omitempty" yaml:"visibility,omitempty"`
+}
+
+// PagingConfig defines a PagerDuty service that is paged through the Events API v2.
+// RoutingKeyRef names an entry in the routing keys file rather than holding the key itself.
+// Severity defaults to Down, so only Down outages page unless a lower threshold is set.
+type PagingConfig struct {
+	RoutingKeyRef string    `json:"routing_key_ref" yaml:"routing_key_ref"`
+	Severity      *Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Visibility is internal to send internal outage descriptions to the service.
+	Visibility Visibility `json:"visibility,omitempty" yaml:"visibility,omitempty"`
+}
+
+// JiraConfig files and tracks Jira issues for a component's outages.
+type JiraConfig struct {
+	// Project is the key of the Jira project issues are filed in.
+	Project string `json:"project" yaml:"project"`
+	// IssueType is the name of the issue type to file. Defaults to Bug.
+	IssueType string `json:"issue_type,omitempty" yaml:"issue_type,omitempty"`
+	// Severity is the lowest outage severity an issue is filed for. Defaults to Down.
+	Severity *Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// AutoCreate files an issue when an outage meets Severity. Without it, only issues linked to outages
+	// by hand are commented on when the outage resolves.
+	AutoCreate bool `json:"auto_create,omitempty" yaml:"auto_create,omitempty"`
+	// Visibility is internal for projects whose issues may quote internal outage descriptions.
+	Visibility Visibility `json:"visibility,omitempty" yaml:"visibility,omitempty"`
+}
+
+// EscalationStep reminds responders about an active outage that has had no update or triage note for After.
+// Each step fires once per quiet period: any update or note restarts the clock.
+type EscalationStep struct {
+	// After is how long the outage must be quiet, as a Go duration such as "15m".
+	After string `json:"after" yaml:"after"`
+	// Severity is the lowest outage severity the step applies to. Defaults to Down.
+	Severity *Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Channel is notified when set. Otherwise the reminder is posted in the outage's Slack threads.
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+	// MentionOwners mentions the SlackMention of each of the component's owners.
+	MentionOwners bool `json:"mention_owners,omitempty" yaml:"mention_owners,omitempty"`
+}
+
+// DigestSchedule is how often a Slack digest is posted.
+type DigestSchedule string
+
+const (
+	DigestScheduleDaily  DigestSchedule = "daily"
+	DigestScheduleWeekly DigestSchedule = "weekly"
+)
+
+// SlackDigestConfig schedules a summary of outage activity posted to a Slack channel.
+type SlackDigestConfig struct {
+	Channel  string         `json:"channel" yaml:"channel"`
+	Schedule DigestSchedule `json:"schedule" yaml:"schedule"`
+	// Time is the time of day the digest is posted, as HH:MM in Timezone.
+	Time string `json:"time" yaml:"time"`
+	// Weekday is the day weekly digests are posted, such as "monday". Defaults to Monday.
+	Weekday string `json:"weekday,omitempty" yaml:"weekday,omitempty"`
+	// Timezone is an IANA time zone name. Defaults to UTC.
+	Timezone string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	// Components limits the digest to the named components. All components are included when empty.
+	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
+	// Visibility is internal to include internal outage descriptions, which are left out by default.
+	Visibility Visibility `json:"visibility,omitempty" yaml:"visibility,omitempty"`
+}
+
+// Period is the span of activity a digest covers: a day for daily digests and a week for weekly ones.
+func (d SlackDigestConfig) Period() time.Duration {
+	if d.Schedule == DigestScheduleWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// LastScheduledRun returns the most recent time at or before now that the digest was due.
+// It returns an error if the schedule, time, weekday or time zone is invalid.
+func (d SlackDigestConfig) LastScheduledRun(now time.Time) (time.Time, error) {
+	location := time.UTC
+	if d.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(d.Timezone); err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone %q: %w", d.Timezone, err)
+		}
+	}
+	timeOfDay, err := time.Parse("15:04", d.Time)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected HH:MM", d.Time)
+	}
+
+	local := now.In(location)
+	run := time.Date(local.Year(), local.Month(), local.Day(), timeOfDay.Hour(), timeOfDay.Minute(), 0, 0, location)
+	if run.After(local) {
+		run = run.AddDate(0, 0, -1)
+	}
+
+	switch d.Schedule {
+	case DigestScheduleDaily:
+		return run, nil
+	case DigestScheduleWeekly:
+		weekday, err := d.weekday()
+		if err != nil {
+			return time.Time{}, err
+		}
+		for run.Weekday() != weekday {
+			run = run.AddDate(0, 0, -1)
+		}
+		return run, nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid schedule %q, expected %q or %q", d.Schedule, DigestScheduleDaily, DigestScheduleWeekly)
+	}
+}
+
+func (d SlackDigestConfig) weekday() (time.Weekday, error) {
+	if d.Weekday == "" {
+		return time.Monday, nil
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(d.Weekday, day.String()) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", d.Weekday)
+}
+
+// GetEscalation returns the escalation steps for a sub-component,
+// preferring the sub-component's own steps over the component's.
+func GetEscalation(component *Component, subComponent *SubComponent) []EscalationStep {
+	if subComponent != nil && len(subComponent.Escalation) > 0 {
+		return subComponent.Escalation
+	}
+	if component != nil {
+		return component.Escalation
+	}
+	return nil
+}
+
+// GetTeamsReporting returns the Teams reporting configuration for a sub-component,
+// preferring the sub-component's own configuration over the component's.
+func GetTeamsReporting(component *Component, subComponent *SubComponent) []TeamsReportingConfig {
+	if subComponent != nil && len(subComponent.TeamsReporting) > 0 {
+		return subComponent.TeamsReporting
+	}
+	if component != nil && len(component.TeamsReporting) > 0 {
+		return component.TeamsReporting
+	}
+	return nil
+}
+
+// GetWebhookReporting returns the chat webhook reporting configuration for a sub-component,
+// preferring the sub-component's own configuration over the component's.
+func GetWebhookReporting(component *Component, subComponent *SubComponent) []WebhookReportingConfig {
+	if subComponent != nil && len(subComponent.WebhookReporting) > 0 {
+		return subComponent.WebhookReporting
+	}
+	if component != nil && len(component.WebhookReporting) > 0 {
+		return component.WebhookReporting
+	}
+	return nil
+}
+
+// GetEmailReporting returns the email reporting configuration for a sub-component,
+// preferring the sub-component's own configuration over the component's.
+func GetEmailReporting(component *Component, subComponent *SubComponent) []EmailReportingConfig {
+	if subComponent != nil && len(subComponent.EmailReporting) > 0 {
+		return subComponent.EmailReporting
+	}
+	if component != nil && len(component.EmailReporting) > 0 {
+		return component.EmailReporting
+	}
+	return nil
+}
+
+// GetPaging returns the paging configuration for a sub-component,
+// preferring the sub-component's own configuration over the component's.
+func GetPaging(component *Component, subComponent *SubComponent) []PagingConfig {
+	if subComponent != nil && len(subComponent.Paging) > 0 {
+		return subComponent.Paging
+	}
+	if component != nil && len(component.Paging) > 0 {
+		return component.Paging
+	}
+	return nil
+}
+
 type Tag struct {
 	Name        string `json:"name" yaml:"name"`
 	Description string `json:"description" yaml:"description"`
@@ -2,8 +2,10 @@ package types
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDashboardConfig_SubComponentRefsMatching(t *testing.T) {
@@ -73,3 +75,86 @@ func TestDashboardConfig_SubComponentRefsMatching(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTeamsAndWebhookReporting(t *testing.T) {
+	componentTeams := []TeamsReportingConfig{{WebhookURL: "https://component.example.com"}}
+	subTeams := []TeamsReportingConfig{{WebhookURL: "https://sub.example.com"}}
+	componentWebhooks := []WebhookReportingConfig{{URL: "https://component.example.com"}}
+
+	component := &Component{TeamsReporting: componentTeams, WebhookReporting: componentWebhooks}
+	withOverride := &SubComponent{TeamsReporting: subTeams}
+	withoutOverride := &SubComponent{}
+
+	assert.Equal(t, subTeams, GetTeamsReporting(component, withOverride))
+	assert.Equal(t, componentTeams, GetTeamsReporting(component, withoutOverride))
+	assert.Equal(t, componentWebhooks, GetWebhookReporting(component, withOverride))
+	assert.Nil(t, GetWebhookReporting(&Component{}, withoutOverride))
+	assert.Nil(t, GetTeamsReporting(nil, nil))
+}
+
+func TestSlackDigestConfig_LastScheduledRun(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	// Wednesday 2024-01-17 10:30 UTC
+	now := time.Date(2024, 1, 17, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		digest  SlackDigestConfig
+		want    time.Time
+		wantErr string
+	}{
+		{
+			name:   "daily, already run today",
+			digest: SlackDigestConfig{Schedule: DigestScheduleDaily, Time: "09:00"},
+			want:   time.Date(2024, 1, 17, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "daily, not yet run today",
+			digest: SlackDigestConfig{Schedule: DigestScheduleDaily, Time: "11:00"},
+			want:   time.Date(2024, 1, 16, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "daily in a time zone",
+			digest: SlackDigestConfig{Schedule: DigestScheduleDaily, Time: "11:00", Timezone: "Europe/Berlin"},
+			want:   time.Date(2024, 1, 17, 11, 0, 0, 0, berlin),
+		},
+		{
+			name:   "weekly defaults to Monday",
+			digest: SlackDigestConfig{Schedule: DigestScheduleWeekly, Time: "09:00"},
+			want:   time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "weekly on the current day before the scheduled time",
+			digest: SlackDigestConfig{Schedule: DigestScheduleWeekly, Time: "11:00", Weekday: "Wednesday"},
+			want:   time.Date(2024, 1, 10, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid schedule",
+			digest:  SlackDigestConfig{Schedule: "hourly", Time: "09:00"},
+			wantErr: `invalid schedule "hourly", expected "daily" or "weekly"`,
+		},
+		{
+			name:    "invalid time",
+			digest:  SlackDigestConfig{Schedule: DigestScheduleDaily, Time: "9am"},
+			wantErr: `invalid time "9am", expected HH:MM`,
+		},
+		{
+			name:    "invalid weekday",
+			digest:  SlackDigestConfig{Schedule: DigestScheduleWeekly, Time: "09:00", Weekday: "funday"},
+			wantErr: `invalid weekday "funday"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.digest.LastScheduledRun(now)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelegationRuleCovers(t *testing.T) {
+	alpha := &Component{Name: "Alpha Service", Slug: "alpha-service"}
+	tagged := &SubComponent{Name: "One", Slug: "one", Tags: []string{"CI"}}
+	untagged := &SubComponent{Name: "Two", Slug: "two"}
+
+	tests := []struct {
+		name         string
+		rule         DelegationRule
+		subComponent *SubComponent
+		want         bool
+	}{
+		{name: "no scope covers everything", subComponent: untagged, want: true},
+		{name: "component by name", rule: DelegationRule{Components: []string{"Alpha Service"}}, subComponent: untagged, want: true},
+		{name: "component by slug", rule: DelegationRule{Components: []string{"alpha-service"}}, subComponent: untagged, want: true},
+		{name: "other component", rule: DelegationRule{Components: []string{"beta"}}, subComponent: tagged, want: false},
+		{name: "tag on the sub-component", rule: DelegationRule{Tags: []string{"ci"}}, subComponent: tagged, want: true},
+		{name: "tag missing from the sub-component", rule: DelegationRule{Tags: []string{"ci"}}, subComponent: untagged, want: false},
+		{name: "tags need a sub-component", rule: DelegationRule{Tags: []string{"ci"}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rule.Covers(alpha, tt.subComponent))
+		})
+	}
+}
+
+func TestDelegationRuleMayActFor(t *testing.T) {
+	groupMembers := func(groupName string) []string {
+		return map[string][]string{"oncall": {"carol"}}[groupName]
+	}
+
+	anyone := DelegationRule{}
+	assert.True(t, anyone.MayActFor("mallory", groupMembers))
+
+	restricted := DelegationRule{Users: []string{"alice"}, RoverGroup: "oncall"}
+	assert.True(t, restricted.MayActFor("alice", groupMembers))
+	assert.True(t, restricted.MayActFor("carol", groupMembers))
+	assert.False(t, restricted.MayActFor("mallory", groupMembers))
+}
@@ -0,0 +1,25 @@
+package types
+
+import "gorm.io/gorm"
+
+// IdempotencyKey records a create request sent with an Idempotency-Key header and the response it got, so that
+// retries of the request get the same response instead of creating a duplicate.
+type IdempotencyKey struct {
+	gorm.Model
+	Key string `gorm:"column:key;not null;uniqueIndex:idx_idempotency_keys_caller_key"`
+	// User and Delegator are the caller the key belongs to. The same key sent by another caller is a different key.
+	User      string `gorm:"column:user;not null;uniqueIndex:idx_idempotency_keys_caller_key"`
+	Delegator string `gorm:"column:delegator;not null;default:'';uniqueIndex:idx_idempotency_keys_caller_key"`
+	Method    string `gorm:"column:method;not null"`
+	Path      string `gorm:"column:path;not null"`
+	// RequestHash is the hex SHA-256 of the request body, to tell a retry from a different request reusing the key.
+	RequestHash string `gorm:"column:request_hash;not null"`
+	// StatusCode and ResponseBody are the response to the original request. StatusCode is 0 while it is in progress.
+	StatusCode   int    `gorm:"column:status_code;not null;default:0"`
+	ResponseBody []byte `gorm:"column:response_body"`
+}
+
+// Completed reports whether the original request has finished and its response is stored.
+func (k IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -82,6 +83,8 @@ type Outage struct {
 	StartTime        time.Time    `json:"start_time" gorm:"column:start_time;not null;index"`
 	EndTime          sql.NullTime `json:"end_time" gorm:"column:end_time;index"`
 	Description      string       `json:"description" gorm:"column:description;type:text;not null"`
+	// DescriptionVisibility is internal when the description is only for authenticated users and internal Slack channels.
+	DescriptionVisibility Visibility `json:"description_visibility" gorm:"column:description_visibility;not null;default:'public'"`
 	// DiscoveredFrom describes where this outage was created: frontend, component-monitor, MCP, API
 	DiscoveredFrom string       `json:"discovered_from" gorm:"column:discovered_from;not null"`
 	CreatedBy      string       `json:"created_by" gorm:"column:created_by;not null"`
@@ -139,6 +142,8 @@ type contextKey string
 const (
 	OldOutageKey   contextKey = "old_outage"
 	CurrentUserKey contextKey = "current_user"
+	// CurrentDelegatorKey holds the delegator acting for the current user, when there is one.
+	CurrentDelegatorKey contextKey = "current_delegator"
 )
 
 // normalizeOutageTimesUTC converts outage timestamps to UTC so audit log diffs
@@ -228,10 +233,12 @@ func (o *Outage) after(db *gorm.DB, operation OperationType) error {
 	if !ok {
 		return fmt.Errorf("current user in context has invalid type %T, expected string", userVal)
 	}
+	delegator, _ := db.Statement.Context.Value(CurrentDelegatorKey).(string)
 	audit := OutageAuditLog{
 		Operation: string(operation),
 		OutageID:  o.ID,
 		User:      userStr,
+		Delegator: delegator,
 		Old:       oldOutageJSON,
 		New:       newTriageJSON,
 	}
@@ -269,6 +276,36 @@ type SlackThread struct {
 	ChannelID       string `json:"channel_id" gorm:"column:channel_id;not null"`
 	ThreadTimestamp string `json:"thread_timestamp" gorm:"column:thread_timestamp;not null"`
 	ThreadURL       string `json:"thread_url" gorm:"column:thread_url;not null"`
+	// Grouped is set when the thread's message announced several outages at once.
+	Grouped bool `json:"grouped" gorm:"column:grouped;not null;default:false"`
+}
+
+// SlackChannelStatus is the status the dashboard last published to a Slack channel with status_sync.
+type SlackChannelStatus struct {
+	gorm.Model
+	Channel string `json:"channel" gorm:"column:channel;not null;uniqueIndex"`
+	// ChannelID is the Slack ID of Channel, which topic and pinned message updates require.
+	ChannelID string `json:"channel_id" gorm:"column:channel_id"`
+	// MessageTS is the pinned status message, empty when none is pinned.
+	MessageTS string `json:"message_ts" gorm:"column:message_ts"`
+	// Text is the published topic or pinned message, empty when cleared.
+	Text string `json:"text" gorm:"column:text"`
+}
+
+// OutageEscalation records that an escalation step fired for an outage. ActivityAt is the outage's last update
+// when the step fired, so the step fires again only after new activity followed by another quiet period.
+type OutageEscalation struct {
+	gorm.Model
+	OutageID   uint      `json:"outage_id" gorm:"column:outage_id;not null;uniqueIndex:idx_outage_escalation_step"`
+	Step       int       `json:"step" gorm:"column:step;not null;uniqueIndex:idx_outage_escalation_step"`
+	ActivityAt time.Time `json:"activity_at" gorm:"column:activity_at;not null;uniqueIndex:idx_outage_escalation_step"`
+}
+
+// OutageSlackThread is a Slack thread together with the sub-component of the outage it belongs to.
+type OutageSlackThread struct {
+	SlackThread
+	ComponentName    string `json:"component_name"`
+	SubComponentName string `json:"sub_component_name"`
 }
 
 type OperationType string
@@ -279,10 +316,13 @@ const (
 	Delete OperationType = "DELETE"
 )
 
+// OutageAuditLog records a change to an outage. Delegator is set when a trusted delegator made the change on
+// behalf of User.
 type OutageAuditLog struct {
 	gorm.Model
 	OutageID  uint   `json:"outage_id" gorm:"column:outage_id;not null;index"`
 	User      string `json:"user" gorm:"column:user;not null"`
+	Delegator string `json:"delegator,omitempty" gorm:"column:delegator"`
 	Operation string `json:"operation" gorm:"column:operation;not null"`
 	Old       []byte `json:"old,omitempty" gorm:"column:old;type:jsonb"`
 	New       []byte `json:"new,omitempty" gorm:"column:new;type:jsonb"`
@@ -298,9 +338,13 @@ type OutageReport struct {
 // TriageNote represents a single note added to an outage during triage.
 type TriageNote struct {
 	gorm.Model
-	OutageID uint   `json:"outage_id" gorm:"column:outage_id;not null;index"`
+	OutageID uint   `json:"outage_id" gorm:"column:outage_id;not null;index;uniqueIndex:idx_triage_note_slack_message"`
 	Body     string `json:"body" gorm:"column:body;type:text;not null"`
 	Author   string `json:"author" gorm:"column:author;not null"`
+	// Visibility restricts internal notes to authenticated users and internal Slack channels.
+	Visibility Visibility `json:"visibility" gorm:"column:visibility;not null;default:'public'"`
+	// SlackMessageTS is set on notes mirrored from a reply in the outage's Slack thread.
+	SlackMessageTS *string `json:"slack_message_ts,omitempty" gorm:"column:slack_message_ts;uniqueIndex:idx_triage_note_slack_message"`
 }
 
 // LinkType represents the category of an outage link.
@@ -310,11 +354,15 @@ const (
 	LinkTypeIncidentChannelThread LinkType = "incident_channel_thread"
 	LinkTypeRCA                   LinkType = "rca"
 	LinkTypeOther                 LinkType = "other"
+	// LinkTypePagerDutyIncident is added automatically when an outage pages through PagerDuty.
+	LinkTypePagerDutyIncident LinkType = "pagerduty_incident"
+	// LinkTypeJiraIssue is added automatically when an issue is filed for an outage with a jira block.
+	LinkTypeJiraIssue LinkType = "jira_issue"
 )
 
 func IsValidLinkType(lt string) bool {
 	switch LinkType(lt) {
-	case LinkTypeIncidentChannelThread, LinkTypeRCA, LinkTypeOther:
+	case LinkTypeIncidentChannelThread, LinkTypeRCA, LinkTypeOther, LinkTypePagerDutyIncident, LinkTypeJiraIssue:
 		return true
 	default:
 		return false
@@ -328,4 +376,112 @@ type OutageLink struct {
 	URL         string   `json:"url" gorm:"column:url;not null"`
 	LinkType    LinkType `json:"link_type" gorm:"column:link_type;not null;default:'other'"`
 	Description string   `json:"description" gorm:"column:description;type:text"`
+	// Status is the tracked issue's status, synced from Jira for jira_issue links.
+	Status string `json:"status,omitempty" gorm:"column:status"`
+	// Visibility works as it does for triage notes.
+	Visibility Visibility `json:"visibility" gorm:"column:visibility;not null;default:'public'"`
+}
+
+// WatchTargetType is the kind of item a watch subscription follows.
+type WatchTargetType string
+
+const (
+	WatchTargetComponent    WatchTargetType = "component"
+	WatchTargetSubComponent WatchTargetType = "sub_component"
+	WatchTargetTag          WatchTargetType = "tag"
+	WatchTargetOutage       WatchTargetType = "outage"
+)
+
+// IsValidWatchTargetType reports whether t is a recognized WatchTargetType value.
+func IsValidWatchTargetType(t string) bool {
+	switch WatchTargetType(t) {
+	case WatchTargetComponent, WatchTargetSubComponent, WatchTargetTag, WatchTargetOutage:
+		return true
+	default:
+		return false
+	}
+}
+
+// NotificationChannelType is how a user-level notification is delivered.
+type NotificationChannelType string
+
+const (
+	// NotificationChannelSlackDM delivers a direct message to a Slack user ID.
+	NotificationChannelSlackDM NotificationChannelType = "slack_dm"
+	// NotificationChannelEmail delivers an email to an address.
+	NotificationChannelEmail NotificationChannelType = "email"
+	// NotificationChannelWebhook POSTs a JSON payload to a URL.
+	NotificationChannelWebhook NotificationChannelType = "webhook"
+)
+
+// IsValidNotificationChannelType reports whether c is a recognized NotificationChannelType value.
+func IsValidNotificationChannelType(c string) bool {
+	switch NotificationChannelType(c) {
+	case NotificationChannelSlackDM, NotificationChannelEmail, NotificationChannelWebhook:
+		return true
+	default:
+		return false
+	}
+}
+
+// WatchSubscription records that a user follows a component, sub-component, tag, or outage.
+// Channel and Destination override the user's NotificationPreference; when empty the preference is used.
+type WatchSubscription struct {
+	gorm.Model
+	User             string                  `json:"user" gorm:"column:user;not null;index"`
+	TargetType       WatchTargetType         `json:"target_type" gorm:"column:target_type;not null;index"`
+	ComponentName    string                  `json:"component_name,omitempty" gorm:"column:component_name;index"`
+	SubComponentName string                  `json:"sub_component_name,omitempty" gorm:"column:sub_component_name"`
+	Tag              string                  `json:"tag,omitempty" gorm:"column:tag;index"`
+	OutageID         *uint                   `json:"outage_id,omitempty" gorm:"column:outage_id;index"`
+	Channel          NotificationChannelType `json:"channel,omitempty" gorm:"column:channel"`
+	Destination      string                  `json:"destination,omitempty" gorm:"column:destination"`
+}
+
+// NotificationPreference holds a user's default notification channel, used by subscriptions without
+// their own channel and for updates on suspected outages the user reported.
+type NotificationPreference struct {
+	gorm.Model
+	User        string                  `json:"user" gorm:"column:user;not null;uniqueIndex"`
+	Channel     NotificationChannelType `json:"channel" gorm:"column:channel;not null"`
+	Destination string                  `json:"destination" gorm:"column:destination;not null"`
+	// NotifyReportedOutages sends confirmation and resolution of suspected outages the user reported.
+	NotifyReportedOutages bool `json:"notify_reported_outages" gorm:"column:notify_reported_outages;not null"`
+}
+
+// APIToken is a personal access token a user created for API automation. Requests made with it act as User.
+// Only the SHA-256 hash of the secret is stored; the secret itself is returned once, when the token is created.
+// Revoking a token soft-deletes it.
+type APIToken struct {
+	gorm.Model
+	User      string `json:"user" gorm:"column:user;not null;index"`
+	Name      string `json:"name" gorm:"column:name;not null"`
+	TokenHash string `json:"-" gorm:"column:token_hash;not null;uniqueIndex"`
+	// Prefix is the start of the secret, shown so users can tell their tokens apart.
+	Prefix string `json:"prefix" gorm:"column:prefix;not null"`
+	// Scopes limits the token to these permissions on top of the user's roles. An empty list does not limit it.
+	Scopes     []Permission `json:"scopes" gorm:"column:scopes;type:jsonb;serializer:json"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty" gorm:"column:expires_at"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty" gorm:"column:last_used_at"`
+}
+
+// Expired reports whether the token has an expiry that is not after now.
+func (t *APIToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !t.ExpiresAt.After(now)
+}
+
+// AllowsPermission reports whether the token's scopes include permission. Unscoped tokens allow everything
+// the user's roles grant.
+func (t *APIToken) AllowsPermission(permission Permission) bool {
+	return len(t.Scopes) == 0 || slices.Contains(t.Scopes, permission)
+}
+
+// APITokenUse records one authenticated request made with an APIToken.
+type APITokenUse struct {
+	gorm.Model
+	TokenID    uint   `json:"token_id" gorm:"column:token_id;not null;index"`
+	User       string `json:"user" gorm:"column:user;not null;index"`
+	Method     string `json:"method" gorm:"column:method;not null"`
+	Path       string `json:"path" gorm:"column:path;not null"`
+	RemoteAddr string `json:"remote_addr" gorm:"column:remote_addr"`
 }
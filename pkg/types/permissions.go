@@ -0,0 +1,117 @@
+package types
+
+// Role is the level of access an owner has on a component. Each role includes the permissions of the
+// roles below it: viewer < triager < responder < admin.
+type Role string
+
+const (
+	RoleViewer    Role = "viewer"
+	RoleTriager   Role = "triager"
+	RoleResponder Role = "responder"
+	RoleAdmin     Role = "admin"
+)
+
+// roleRank orders the roles; unknown roles rank below viewer and grant nothing.
+var roleRank = map[Role]int{
+	RoleViewer:    1,
+	RoleTriager:   2,
+	RoleResponder: 3,
+	RoleAdmin:     4,
+}
+
+// IsValidRole reports whether role is one of the known roles.
+func IsValidRole(role string) bool {
+	_, ok := roleRank[Role(role)]
+	return ok
+}
+
+// Permission is an action a user can take on a component.
+type Permission string
+
+const (
+	// PermissionView allows reading items that are not public.
+	PermissionView Permission = "view"
+	// PermissionAddTriageNote allows adding triage notes. Authors can always edit and delete their own notes.
+	PermissionAddTriageNote Permission = "triage_note:create"
+	// PermissionWriteLink allows adding and editing outage links.
+	PermissionWriteLink    Permission = "link:write"
+	PermissionCreateOutage Permission = "outage:create"
+	// PermissionUpdateOutage allows changing an outage, including confirming and resolving it.
+	PermissionUpdateOutage Permission = "outage:update"
+	PermissionDeleteLink   Permission = "link:delete"
+	// PermissionReportStatus allows a component monitor to report the status of the component.
+	PermissionReportStatus Permission = "status:report"
+	PermissionDeleteOutage Permission = "outage:delete"
+	// PermissionManageTriageNotes allows editing and deleting triage notes written by others.
+	PermissionManageTriageNotes Permission = "triage_note:manage"
+)
+
+// permissionRoles lists every permission with the lowest role that grants it, in the order permissions are reported.
+var permissionRoles = []struct {
+	permission Permission
+	role       Role
+}{
+	{PermissionView, RoleViewer},
+	{PermissionAddTriageNote, RoleTriager},
+	{PermissionWriteLink, RoleTriager},
+	{PermissionCreateOutage, RoleResponder},
+	{PermissionUpdateOutage, RoleResponder},
+	{PermissionDeleteLink, RoleResponder},
+	{PermissionReportStatus, RoleResponder},
+	{PermissionDeleteOutage, RoleAdmin},
+	{PermissionManageTriageNotes, RoleAdmin},
+}
+
+// IsValidPermission reports whether permission is one of the known permissions.
+func IsValidPermission(permission string) bool {
+	for _, entry := range permissionRoles {
+		if entry.permission == Permission(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// Grants reports whether the role includes permission.
+func (r Role) Grants(permission Permission) bool {
+	for _, entry := range permissionRoles {
+		if entry.permission == permission {
+			return roleRank[r] > 0 && roleRank[r] >= roleRank[entry.role]
+		}
+	}
+	return false
+}
+
+// Permissions returns every permission the role includes.
+func (r Role) Permissions() []Permission {
+	permissions := []Permission{}
+	for _, entry := range permissionRoles {
+		if r.Grants(entry.permission) {
+			permissions = append(permissions, entry.permission)
+		}
+	}
+	return permissions
+}
+
+// HigherRole returns whichever of a and b grants more.
+func HigherRole(a, b Role) Role {
+	if roleRank[b] > roleRank[a] {
+		return b
+	}
+	return a
+}
+
+// EffectiveRole returns the owner's role. Owners without one are admins, which is the access every
+// owner had before roles were introduced.
+func (o Owner) EffectiveRole() Role {
+	if o.Role == "" {
+		return RoleAdmin
+	}
+	return o.Role
+}
+
+// Matches reports whether the owner entry names identity directly, through its user or service account.
+// Rover group membership is resolved by the caller.
+func (o Owner) Matches(identity string) bool {
+	return (o.User != "" && o.User == identity) || (o.ServiceAccount != "" && o.ServiceAccount == identity)
+}
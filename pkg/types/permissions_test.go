@@ -0,0 +1,67 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleGrants(t *testing.T) {
+	tests := []struct {
+		role    Role
+		granted []Permission
+		denied  []Permission
+	}{
+		{
+			role:    RoleViewer,
+			granted: []Permission{PermissionView},
+			denied:  []Permission{PermissionAddTriageNote, PermissionCreateOutage},
+		},
+		{
+			role:    RoleTriager,
+			granted: []Permission{PermissionView, PermissionAddTriageNote, PermissionWriteLink},
+			denied:  []Permission{PermissionCreateOutage, PermissionUpdateOutage, PermissionDeleteLink},
+		},
+		{
+			role:    RoleResponder,
+			granted: []Permission{PermissionAddTriageNote, PermissionCreateOutage, PermissionUpdateOutage, PermissionDeleteLink, PermissionReportStatus},
+			denied:  []Permission{PermissionDeleteOutage, PermissionManageTriageNotes},
+		},
+		{
+			role:    RoleAdmin,
+			granted: []Permission{PermissionView, PermissionUpdateOutage, PermissionDeleteOutage, PermissionManageTriageNotes},
+		},
+		{
+			role:   "",
+			denied: []Permission{PermissionView},
+		},
+		{
+			role:   "owner",
+			denied: []Permission{PermissionView},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			for _, permission := range tt.granted {
+				assert.True(t, tt.role.Grants(permission), permission)
+			}
+			for _, permission := range tt.denied {
+				assert.False(t, tt.role.Grants(permission), permission)
+			}
+			assert.False(t, tt.role.Grants("unknown"))
+		})
+	}
+}
+
+func TestRolePermissions(t *testing.T) {
+	assert.Equal(t, []Permission{PermissionView, PermissionAddTriageNote, PermissionWriteLink}, RoleTriager.Permissions())
+	assert.Len(t, RoleAdmin.Permissions(), len(permissionRoles))
+	assert.Empty(t, Role("").Permissions())
+}
+
+func TestOwnerEffectiveRole(t *testing.T) {
+	assert.Equal(t, RoleAdmin, Owner{User: "alice"}.EffectiveRole(), "owners without a role keep full access")
+	assert.Equal(t, RoleTriager, Owner{User: "alice", Role: RoleTriager}.EffectiveRole())
+	assert.Equal(t, RoleResponder, HigherRole(RoleTriager, RoleResponder))
+	assert.Equal(t, RoleViewer, HigherRole(RoleViewer, ""))
+}
@@ -0,0 +1,54 @@
+package types
+
+import "time"
+
+// RateLimitClass groups the write routes that share a rate limit.
+type RateLimitClass string
+
+const (
+	RateLimitOutages     RateLimitClass = "outages"
+	RateLimitTriageNotes RateLimitClass = "triage_notes"
+	RateLimitLinks       RateLimitClass = "links"
+	// RateLimitReports covers community reports of suspected outages.
+	RateLimitReports RateLimitClass = "reports"
+	// RateLimitAccount covers changes to the caller's own tokens, subscriptions and notification preferences.
+	RateLimitAccount RateLimitClass = "account"
+)
+
+// RateLimit is how many requests of a class each caller may make per period.
+type RateLimit struct {
+	// Requests per Period. Zero disables the limit.
+	Requests int `json:"requests" yaml:"requests"`
+	// Period is a duration such as "10m" or "1h".
+	Period string `json:"period" yaml:"period"`
+	// SubComponents limits how many distinct sub-components a caller may send requests for per Period.
+	// Only the reports class supports it.
+	SubComponents int `json:"sub_components,omitempty" yaml:"sub_components,omitempty"`
+}
+
+// DefaultRateLimits apply to the classes missing from rate_limits.
+var DefaultRateLimits = map[RateLimitClass]RateLimit{
+	RateLimitOutages:     {Requests: 30, Period: "10m"},
+	RateLimitTriageNotes: {Requests: 60, Period: "10m"},
+	RateLimitLinks:       {Requests: 60, Period: "10m"},
+	RateLimitReports:     {Requests: 10, Period: "1h", SubComponents: 5},
+	RateLimitAccount:     {Requests: 30, Period: "10m"},
+}
+
+// RateLimitFor returns the configured rate limit of class, or its default.
+func (c *DashboardConfig) RateLimitFor(class RateLimitClass) RateLimit {
+	if limit, ok := c.RateLimits[class]; ok {
+		return limit
+	}
+	return DefaultRateLimits[class]
+}
+
+// PeriodDuration parses Period. Invalid periods are rejected when the config is loaded, and disable the limit
+// if one gets through.
+func (l RateLimit) PeriodDuration() time.Duration {
+	period, err := time.ParseDuration(l.Period)
+	if err != nil || period <= 0 {
+		return 0
+	}
+	return period
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SecurityEventType classifies a SecurityEvent.
+type SecurityEventType string
+
+const (
+	// SecurityEventAuthFailure is a protected request rejected before a user was established: a missing header,
+	// a bad HMAC signature, an invalid bearer token or session, or a delegator without X-Acting-For.
+	SecurityEventAuthFailure SecurityEventType = "auth_failure"
+	// SecurityEventDelegation is a request a delegator made on behalf of another user.
+	SecurityEventDelegation SecurityEventType = "delegation"
+	// SecurityEventAccessDenied is an authenticated request rejected with 403.
+	SecurityEventAccessDenied SecurityEventType = "access_denied"
+	// SecurityEventAdminAction is a request to an admin endpoint by a global admin.
+	SecurityEventAdminAction SecurityEventType = "admin_action"
+)
+
+// SecurityEventTypes lists the valid SecurityEventType values.
+var SecurityEventTypes = []SecurityEventType{
+	SecurityEventAuthFailure,
+	SecurityEventDelegation,
+	SecurityEventAccessDenied,
+	SecurityEventAdminAction,
+}
+
+// SecurityEvent records a request that failed authentication or authorization, or that used elevated access.
+type SecurityEvent struct {
+	gorm.Model
+	Type SecurityEventType `json:"type" gorm:"column:type;not null;index"`
+	// User is the user the request was made as: the acting-for user of delegated requests, and the claimed
+	// X-Forwarded-User of requests that failed authentication. It is empty when nothing identified the caller.
+	User      string `json:"user,omitempty" gorm:"column:user;index"`
+	Delegator string `json:"delegator,omitempty" gorm:"column:delegator;index"`
+	// ComponentName and SubComponentName are the slugs in the request path, when it has them.
+	ComponentName    string     `json:"component_name,omitempty" gorm:"column:component_name;index"`
+	SubComponentName string     `json:"sub_component_name,omitempty" gorm:"column:sub_component_name"`
+	Permission       Permission `json:"permission,omitempty" gorm:"column:permission"`
+	Method           string     `json:"method" gorm:"column:method;not null"`
+	Path             string     `json:"path" gorm:"column:path;not null"`
+	RemoteAddr       string     `json:"remote_addr,omitempty" gorm:"column:remote_addr"`
+	// Reason is the error sent to rejected callers. It is empty for delegations and admin actions.
+	Reason string `json:"reason,omitempty" gorm:"column:reason"`
+}
+
+// SecurityEventFilter narrows the security event log. Zero values are ignored.
+type SecurityEventFilter struct {
+	Type          SecurityEventType
+	User          string
+	Delegator     string
+	ComponentSlug string
+	Start         time.Time
+	End           time.Time
+	Limit         int
+}
@@ -0,0 +1,137 @@
+package types
+
+import "encoding/json"
+
+// Visibility controls who can see an outage description, a triage note or a link.
+type Visibility string
+
+const (
+	// VisibilityPublic items are shown to everyone, including unauthenticated readers of the public API.
+	VisibilityPublic Visibility = "public"
+	// VisibilityInternal items are shown to authenticated users and posted only to Slack channels set to internal.
+	VisibilityInternal Visibility = "internal"
+)
+
+// IsValidVisibility reports whether visibility is one of the known visibilities.
+func IsValidVisibility(visibility string) bool {
+	switch Visibility(visibility) {
+	case VisibilityPublic, VisibilityInternal:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsInternal reports whether v is internal. Empty values, as stored before visibility existed, are public.
+func (v Visibility) IsInternal() bool {
+	return v == VisibilityInternal
+}
+
+// PublicView returns a copy of the outage as unauthenticated readers see it: without internal triage notes and
+// links, with an internal description cleared, and with its audit log snapshots redacted the same way.
+func (o Outage) PublicView() Outage {
+	public := o
+	if o.DescriptionVisibility.IsInternal() {
+		public.Description = ""
+	}
+	public.TriageNotes = PublicTriageNotes(o.TriageNotes)
+	public.Links = PublicOutageLinks(o.Links)
+	if o.AuditLogs != nil {
+		public.AuditLogs = make([]OutageAuditLog, len(o.AuditLogs))
+		for i, log := range o.AuditLogs {
+			public.AuditLogs[i] = log.publicView()
+		}
+	}
+	return public
+}
+
+// PublicOutages returns the PublicView of every outage.
+func PublicOutages(outages []Outage) []Outage {
+	if outages == nil {
+		return nil
+	}
+	public := make([]Outage, len(outages))
+	for i, outage := range outages {
+		public[i] = outage.PublicView()
+	}
+	return public
+}
+
+// PublicTriageNotes returns the notes that are not internal.
+func PublicTriageNotes(notes []TriageNote) []TriageNote {
+	if notes == nil {
+		return nil
+	}
+	public := []TriageNote{}
+	for _, note := range notes {
+		if !note.Visibility.IsInternal() {
+			public = append(public, note)
+		}
+	}
+	return public
+}
+
+// PublicOutageLinks returns the links that are not internal.
+func PublicOutageLinks(links []OutageLink) []OutageLink {
+	if links == nil {
+		return nil
+	}
+	public := []OutageLink{}
+	for _, link := range links {
+		if !link.Visibility.IsInternal() {
+			public = append(public, link)
+		}
+	}
+	return public
+}
+
+// PublicView returns the entry as unauthenticated readers see it, with its snapshots redacted and its changes
+// recomputed from them. It returns false when the change was only to internal items, so there is nothing to show.
+func (e AuditLogEntry) PublicView() (AuditLogEntry, bool) {
+	public := NewAuditLogEntry(e.OutageAuditLog.publicView())
+	public.ComponentName = e.ComponentName
+	public.SubComponentName = e.SubComponentName
+	if len(public.Changes) == 0 && len(e.Changes) > 0 {
+		return AuditLogEntry{}, false
+	}
+	return public, true
+}
+
+func (l OutageAuditLog) publicView() OutageAuditLog {
+	l.Old = redactSnapshot(l.Old)
+	l.New = redactSnapshot(l.New)
+	return l
+}
+
+// redactSnapshot removes what an outage JSON snapshot holds about internal items. Snapshots that cannot be
+// parsed are dropped, since there is no telling what they contain.
+func redactSnapshot(data []byte) []byte {
+	if len(data) == 0 || string(data) == "null" {
+		return data
+	}
+	var snapshot map[string]any
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil
+	}
+	if snapshot["description_visibility"] == string(VisibilityInternal) {
+		snapshot["description"] = ""
+	}
+	for _, key := range []string{"triage_notes", "links"} {
+		items, ok := snapshot[key].([]any)
+		if !ok {
+			continue
+		}
+		public := []any{}
+		for _, item := range items {
+			if fields, ok := item.(map[string]any); !ok || fields["visibility"] != string(VisibilityInternal) {
+				public = append(public, item)
+			}
+		}
+		snapshot[key] = public
+	}
+	redacted, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
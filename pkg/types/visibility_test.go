@@ -0,0 +1,99 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutage_PublicView(t *testing.T) {
+	outage := Outage{
+		Description:           "bad deploy of the auth proxy",
+		DescriptionVisibility: VisibilityInternal,
+		TriageNotes: []TriageNote{
+			{Body: "public note", Visibility: VisibilityPublic},
+			{Body: "internal note", Visibility: VisibilityInternal},
+			{Body: "note from before visibility"},
+		},
+		Links: []OutageLink{
+			{URL: "https://internal.example.com", Visibility: VisibilityInternal},
+		},
+	}
+
+	public := outage.PublicView()
+	assert.Empty(t, public.Description)
+	assert.Equal(t, []TriageNote{
+		{Body: "public note", Visibility: VisibilityPublic},
+		{Body: "note from before visibility"},
+	}, public.TriageNotes)
+	assert.Equal(t, []OutageLink{}, public.Links)
+	assert.Equal(t, "bad deploy of the auth proxy", outage.Description, "the original outage is unchanged")
+	assert.Len(t, outage.TriageNotes, 3)
+
+	outage.DescriptionVisibility = VisibilityPublic
+	assert.Equal(t, "bad deploy of the auth proxy", outage.PublicView().Description)
+	assert.Nil(t, Outage{}.PublicView().TriageNotes)
+}
+
+func TestAuditLogEntry_PublicView(t *testing.T) {
+	tests := []struct {
+		name       string
+		old        string
+		new        string
+		wantOK     bool
+		wantFields []string
+	}{
+		{
+			name:       "public change is kept",
+			old:        `{"severity":"Down"}`,
+			new:        `{"severity":"Degraded"}`,
+			wantOK:     true,
+			wantFields: []string{"severity"},
+		},
+		{
+			name:   "internal note only is dropped",
+			old:    `{"triage_notes":[]}`,
+			new:    `{"triage_notes":[{"ID":1,"body":"secret","visibility":"internal"}]}`,
+			wantOK: false,
+		},
+		{
+			name:   "internal description edit is dropped",
+			old:    `{"description":"a","description_visibility":"internal"}`,
+			new:    `{"description":"b","description_visibility":"internal"}`,
+			wantOK: false,
+		},
+		{
+			name:       "internal items are redacted from mixed changes",
+			old:        `{"severity":"Down","links":[]}`,
+			new:        `{"severity":"Degraded","links":[{"ID":2,"url":"https://internal.example.com","visibility":"internal"}]}`,
+			wantOK:     true,
+			wantFields: []string{"severity"},
+		},
+		{
+			name:       "making a public note internal reads as its removal",
+			old:        `{"triage_notes":[{"ID":1,"body":"x","visibility":"public"}]}`,
+			new:        `{"triage_notes":[{"ID":1,"body":"x","visibility":"internal"}]}`,
+			wantOK:     true,
+			wantFields: []string{"triage_notes[1]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := NewAuditLogEntry(OutageAuditLog{Old: []byte(tt.old), New: []byte(tt.new)})
+			entry.ComponentName = "alpha"
+
+			public, ok := entry.PublicView()
+			assert.Equal(t, tt.wantOK, ok)
+			if !ok {
+				return
+			}
+			fields := []string{}
+			for _, change := range public.Changes {
+				fields = append(fields, change.Field)
+			}
+			assert.Equal(t, tt.wantFields, fields)
+			assert.Equal(t, "alpha", public.ComponentName)
+			assert.NotContains(t, string(public.New), "internal.example.com")
+		})
+	}
+}